@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Tokenizers Dgraph accepts on each indexable type
+var typeTokenizers = map[string][]string{
+	"string":   {"exact", "hash", "term", "fulltext", "trigram"},
+	"int":      {"int"},
+	"float":    {"float"},
+	"bool":     {"bool"},
+	"datetime": {"year", "month", "day", "hour"},
+	"geo":      {"geo"},
+}
+
+// predicateDirectives are the directives dgraph_add_predicate can set
+var predicateDirectives = []string{"upsert", "count", "lang", "reverse"}
+
+// Build a predicate definition from its parts, checking that the type,
+// tokenizers and directives go together
+func buildPredicate(name, typ string, list bool, tokenizers, directives []string) (predicateSchema, error) {
+	if err := validateName("predicate", name); err != nil {
+		return predicateSchema{}, err
+	}
+	if isInternalName(name) {
+		return predicateSchema{}, fmt.Errorf("Dgraph's own predicates can't be changed")
+	}
+	if !isSchemaTypeName(typ) {
+		return predicateSchema{}, fmt.Errorf("unknown type %q; use default, int, float, string, bool, datetime, geo, password or uid", typ)
+	}
+	p := predicateSchema{Predicate: name, Type: typ, List: list}
+
+	for _, tok := range tokenizers {
+		if !slices.Contains(typeTokenizers[typ], tok) {
+			if len(typeTokenizers[typ]) == 0 {
+				return predicateSchema{}, fmt.Errorf("predicates of type %s can't be indexed", typ)
+			}
+			return predicateSchema{}, fmt.Errorf("tokenizer %q can't index type %s; use %s", tok, typ, strings.Join(typeTokenizers[typ], ", "))
+		}
+		if !slices.Contains(p.Tokenizer, tok) {
+			p.Tokenizer = append(p.Tokenizer, tok)
+		}
+	}
+	p.Index = len(p.Tokenizer) > 0
+
+	for _, d := range directives {
+		switch strings.TrimPrefix(d, "@") {
+		case "upsert":
+			p.Upsert = true
+		case "count":
+			p.Count = true
+		case "lang":
+			p.Lang = true
+		case "reverse":
+			p.Reverse = true
+		default:
+			return predicateSchema{}, fmt.Errorf("unknown directive %q; use %s", d, strings.Join(predicateDirectives, ", "))
+		}
+	}
+	if err := checkDirectives(p); err != nil {
+		return predicateSchema{}, err
+	}
+	return p, nil
+}
+
+// Check the directives of a predicate against its type and index
+func checkDirectives(p predicateSchema) error {
+	if p.Upsert && !p.Index {
+		return fmt.Errorf("@upsert needs an index on %s", p.Predicate)
+	}
+	if p.Count && p.Type != "uid" && !p.List {
+		return fmt.Errorf("@count only applies to uid predicates and scalar lists, not %s", p.Type)
+	}
+	if p.Lang && (p.Type != "string" || p.List) {
+		return fmt.Errorf("@lang only applies to string predicates that are not lists")
+	}
+	if p.Reverse && p.Type != "uid" {
+		return fmt.Errorf("@reverse only applies to uid predicates, not %s", p.Type)
+	}
+	return nil
+}
+
+// Merge a new definition into an existing one: tokenizers and directives
+// are added to the ones the predicate has already. The type can't change,
+// as Dgraph would have to convert every stored value.
+func mergePredicate(existing, p predicateSchema) (predicateSchema, error) {
+	if existing.Type != p.Type || existing.List != p.List {
+		return predicateSchema{}, fmt.Errorf("predicate %q already exists as %s; change its type with dgraph_alter_schema", p.Predicate, existing.String())
+	}
+	merged := existing
+	merged.Tokenizer = append([]string(nil), existing.Tokenizer...)
+	for _, tok := range p.Tokenizer {
+		if !slices.Contains(merged.Tokenizer, tok) {
+			merged.Tokenizer = append(merged.Tokenizer, tok)
+		}
+	}
+	merged.Index = len(merged.Tokenizer) > 0
+	merged.Upsert = existing.Upsert || p.Upsert
+	merged.Count = existing.Count || p.Count
+	merged.Lang = existing.Lang || p.Lang
+	merged.Reverse = existing.Reverse || p.Reverse
+	return merged, checkDirectives(merged)
+}
+
+// Create handler for the add predicate tool
+func createAddPredicateHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+		typ, ok := request.Params.Arguments["type"].(string)
+		if !ok {
+			return nil, fmt.Errorf("type must be a string")
+		}
+		list, err := boolArgument(request, "list", false)
+		if err != nil {
+			return nil, err
+		}
+		tokenizers, err := stringsArgument(request, "index")
+		if err != nil {
+			return nil, err
+		}
+		directives, err := stringsArgument(request, "directives")
+		if err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.check(name); err != nil {
+			return nil, err
+		}
+
+		p, err := buildPredicate(name, typ, list, tokenizers, directives)
+		if err != nil {
+			return nil, err
+		}
+
+		// Merge against the live schema, not a cached copy
+		schema, err := fetchSchema(ctx, client, true)
+		if err != nil {
+			return nil, err
+		}
+		existing, exists := schema.predicate(name)
+		if exists {
+			if p, err = mergePredicate(existing, p); err != nil {
+				return nil, err
+			}
+		}
+
+		result := struct {
+			Predicate string   `json:"predicate"`
+			Schema    string   `json:"schema"`
+			Created   bool     `json:"created"`
+			Changes   []string `json:"changes"`
+			Reindex   bool     `json:"reindex"`
+		}{Predicate: name, Schema: p.String(), Created: !exists, Changes: []string{}}
+		if exists {
+			if change, changed := comparePredicates(existing, p); changed {
+				result.Changes, result.Reindex = change.Changes, change.Reindex
+			}
+		}
+
+		// Leave the schema alone when the predicate already has it all
+		if !exists || len(result.Changes) > 0 {
+			if err := snapshotSchema(ctx, client, "dgraph_add_predicate"); err != nil {
+				return nil, err
+			}
+			if err := alterSchema(ctx, client, result.Schema); err != nil {
+				return nil, err
+			}
+		}
+
+		out, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildPredicate(t *testing.T) {
+	tests := []struct {
+		name       string
+		predicate  string
+		typ        string
+		list       bool
+		tokenizers []string
+		directives []string
+		want       string
+		wantErr    string
+	}{
+		{"plain", "age", "int", false, nil, nil, "age: int .", ""},
+		{"indexed upsert", "email", "string", false, []string{"exact", "hash", "exact"}, []string{"upsert"}, "email: string @index(exact, hash) @upsert .", ""},
+		{"edge", "friend", "uid", true, nil, []string{"@reverse", "count"}, "friend: [uid] @reverse @count .", ""},
+		{"lang", "name", "string", false, []string{"term"}, []string{"lang"}, "name: string @index(term) @lang .", ""},
+		{"scalar list count", "tags", "string", true, nil, []string{"count"}, "tags: [string] @count .", ""},
+		{"unknown type", "age", "integer", false, nil, nil, "", "unknown type"},
+		{"wrong tokenizer", "age", "int", false, []string{"term"}, nil, "", `tokenizer "term" can't index type int`},
+		{"unindexable type", "friend", "uid", false, []string{"exact"}, nil, "", "can't be indexed"},
+		{"upsert without index", "email", "string", false, nil, []string{"upsert"}, "", "@upsert needs an index"},
+		{"count on scalar", "age", "int", false, nil, []string{"count"}, "", "@count only applies"},
+		{"lang on list", "names", "string", true, nil, []string{"lang"}, "", "@lang only applies"},
+		{"reverse on scalar", "name", "string", false, nil, []string{"reverse"}, "", "@reverse only applies"},
+		{"unknown directive", "name", "string", false, nil, []string{"noconflict"}, "", "unknown directive"},
+		{"invalid name", "name .\nx", "string", false, nil, nil, "", "invalid predicate"},
+		{"internal", "dgraph.type", "string", true, nil, nil, "", "Dgraph's own predicates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := buildPredicate(tt.predicate, tt.typ, tt.list, tt.tokenizers, tt.directives)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("buildPredicate() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildPredicate() failed: %v", err)
+			}
+			if got := p.String(); got != tt.want {
+				t.Errorf("buildPredicate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePredicate(t *testing.T) {
+	existing := predicateSchema{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact"}, Lang: true}
+
+	add, err := buildPredicate("name", "string", false, []string{"term", "exact"}, []string{"upsert"})
+	if err != nil {
+		t.Fatalf("buildPredicate() failed: %v", err)
+	}
+	merged, err := mergePredicate(existing, add)
+	if err != nil {
+		t.Fatalf("mergePredicate() failed: %v", err)
+	}
+	if want := "name: string @index(exact, term) @upsert @lang ."; merged.String() != want {
+		t.Errorf("mergePredicate() = %q, want %q", merged.String(), want)
+	}
+	if len(existing.Tokenizer) != 1 {
+		t.Errorf("mergePredicate() changed the existing tokenizers: %v", existing.Tokenizer)
+	}
+
+	if _, err := mergePredicate(existing, predicateSchema{Predicate: "name", Type: "string", List: true}); err == nil || !strings.Contains(err.Error(), "already exists as name: string") {
+		t.Errorf("mergePredicate() with a different type = %v, want an error", err)
+	}
+}
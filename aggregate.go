@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Supported aggregations
+var aggregations = []string{"min", "max", "sum", "avg", "count"}
+
+// Build the root function selecting the nodes to aggregate over, from
+// either a type name or a raw root function
+func aggregateRoot(typeName, rootFunc string) (string, error) {
+	switch {
+	case typeName != "" && rootFunc != "":
+		return "", fmt.Errorf("give either type or func, not both")
+	case typeName != "":
+		if err := validateName("type", typeName); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("type(%s)", typeName), nil
+	case rootFunc != "":
+		return rootFunc, nil
+	}
+	return "", fmt.Errorf("type or func is required")
+}
+
+// Build a query computing an aggregation over a predicate of the nodes
+// matched by root. The value is returned as result[0].value. count counts
+// the matched nodes having the predicate, or all of them without one.
+func buildAggregateQuery(root, predicate, aggregation string) (string, error) {
+	if predicate != "" {
+		if err := validateName("predicate", predicate); err != nil {
+			return "", err
+		}
+	}
+
+	switch aggregation {
+	case "count":
+		filter := ""
+		if predicate != "" {
+			filter = fmt.Sprintf(" @filter(has(%s))", predicate)
+		}
+		return fmt.Sprintf(`{
+	result(func: %s)%s {
+		value: count(uid)
+	}
+}`, root, filter), nil
+	case "min", "max", "sum", "avg":
+		if predicate == "" {
+			return "", fmt.Errorf("%s requires a predicate", aggregation)
+		}
+		return fmt.Sprintf(`{
+	var(func: %s) {
+		v as %s
+	}
+	result() {
+		value: %s(val(v))
+	}
+}`, root, predicate, aggregation), nil
+	}
+	return "", fmt.Errorf("aggregation must be one of %s", strings.Join(aggregations, ", "))
+}
+
+// Check that a predicate's type supports an aggregation
+func checkAggregatePredicate(schema *schemaInfo, predicate, aggregation string) error {
+	if predicate == "" {
+		return nil
+	}
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		return fmt.Errorf("predicate %q is not in the schema", predicate)
+	}
+	switch aggregation {
+	case "sum", "avg":
+		if p.Type != "int" && p.Type != "float" {
+			return fmt.Errorf("%s requires a numeric predicate, but %q has type %s", aggregation, predicate, p.Type)
+		}
+	case "min", "max":
+		if p.Type != "int" && p.Type != "float" && p.Type != "datetime" {
+			return fmt.Errorf("%s requires a numeric or datetime predicate, but %q has type %s", aggregation, predicate, p.Type)
+		}
+	}
+	return nil
+}
+
+// Create handler for the aggregate tool
+func createAggregateHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		typeName, _ := request.Params.Arguments["type"].(string)
+		rootFunc, _ := request.Params.Arguments["func"].(string)
+		predicate, _ := request.Params.Arguments["predicate"].(string)
+
+		aggregation, ok := request.Params.Arguments["aggregation"].(string)
+		if !ok {
+			return nil, fmt.Errorf("aggregation must be a string")
+		}
+
+		root, err := aggregateRoot(typeName, rootFunc)
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := buildAggregateQuery(root, predicate, aggregation)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkAggregatePredicate(schema, predicate, aggregation); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		var result struct {
+			Result []struct {
+				Value json.RawMessage `json:"value"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(resp.Json, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+
+		// Aggregating over no values returns no result
+		value := json.RawMessage("null")
+		if len(result.Result) > 0 && result.Result[0].Value != nil {
+			value = result.Result[0].Value
+		}
+
+		out, err := json.Marshal(struct {
+			Aggregation string          `json:"aggregation"`
+			Predicate   string          `json:"predicate,omitempty"`
+			Value       json.RawMessage `json:"value"`
+		}{aggregation, predicate, value})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildAggregateQuery(t *testing.T) {
+	tests := []struct {
+		root, predicate, aggregation string
+		want                         []string
+	}{
+		{"type(Person)", "age", "avg", []string{"var(func: type(Person))", "v as age", "value: avg(val(v))"}},
+		{"has(age)", "age", "max", []string{"var(func: has(age))", "value: max(val(v))"}},
+		{"type(Person)", "age", "count", []string{"result(func: type(Person)) @filter(has(age))", "value: count(uid)"}},
+		{"type(Person)", "", "count", []string{"result(func: type(Person)) {", "value: count(uid)"}},
+	}
+	for _, tt := range tests {
+		got, err := buildAggregateQuery(tt.root, tt.predicate, tt.aggregation)
+		if err != nil {
+			t.Errorf("buildAggregateQuery(%q, %q, %q) failed: %v", tt.root, tt.predicate, tt.aggregation, err)
+			continue
+		}
+		for _, part := range tt.want {
+			if !strings.Contains(got, part) {
+				t.Errorf("buildAggregateQuery(%q, %q, %q) = %s, missing %q", tt.root, tt.predicate, tt.aggregation, got, part)
+			}
+		}
+	}
+
+	for _, tt := range []struct{ predicate, aggregation string }{
+		{"", "sum"},
+		{"age", "median"},
+		{"age }", "min"},
+	} {
+		if _, err := buildAggregateQuery("type(Person)", tt.predicate, tt.aggregation); err == nil {
+			t.Errorf("buildAggregateQuery(%q, %q) succeeded, want error", tt.predicate, tt.aggregation)
+		}
+	}
+}
+
+func TestAggregateRoot(t *testing.T) {
+	if got, err := aggregateRoot("Person", ""); err != nil || got != "type(Person)" {
+		t.Errorf("aggregateRoot(Person) = %q, %v", got, err)
+	}
+	if got, err := aggregateRoot("", "has(age)"); err != nil || got != "has(age)" {
+		t.Errorf("aggregateRoot(has(age)) = %q, %v", got, err)
+	}
+	for _, tt := range [][2]string{{"", ""}, {"Person", "has(age)"}, {"Per son", ""}} {
+		if _, err := aggregateRoot(tt[0], tt[1]); err == nil {
+			t.Errorf("aggregateRoot(%q, %q) succeeded, want error", tt[0], tt[1])
+		}
+	}
+}
+
+func TestCheckAggregatePredicate(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "age", Type: "int"},
+		{Predicate: "born", Type: "datetime"},
+		{Predicate: "name", Type: "string"},
+	}}
+
+	tests := []struct {
+		predicate, aggregation string
+		ok                     bool
+	}{
+		{"age", "sum", true},
+		{"age", "avg", true},
+		{"born", "max", true},
+		{"born", "avg", false},
+		{"name", "min", false},
+		{"name", "count", true},
+		{"", "count", true},
+		{"missing", "count", false},
+	}
+	for _, tt := range tests {
+		err := checkAggregatePredicate(schema, tt.predicate, tt.aggregation)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkAggregatePredicate(%q, %q) = %v, want ok %t", tt.predicate, tt.aggregation, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"google.golang.org/grpc"
+)
+
+// alphaConn is the connection to one Dgraph Alpha
+type alphaConn struct {
+	host        string
+	conn        *grpc.ClientConn
+	client      api.DgraphClient
+	err         error              // why the Alpha was unreachable at startup, if it was
+	compression bool               // whether the connection compresses requests
+	pool        []*grpc.ClientConn // further connections to the Alpha
+}
+
+// Split a comma-separated list of Alpha addresses
+func parseHosts(hosts string) ([]string, error) {
+	var parsed []string
+	seen := map[string]bool{}
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		parsed = append(parsed, host)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("no Dgraph host given")
+	}
+	return parsed, nil
+}
+
+// Connect to an Alpha and wait for it to become reachable, reconnecting
+// without compression if it can't decompress requests
+func connectAlpha(host string, dial func(host string, compression bool) (*grpc.ClientConn, error), compression bool, timeout time.Duration) alphaConn {
+	conn, err := dial(host, compression)
+	if err != nil {
+		return alphaConn{host: host, err: err}
+	}
+	alpha := alphaConn{host: host, conn: conn, client: api.NewDgraphClient(conn), compression: compression}
+	alpha.err = waitForDgraph(context.Background(), alpha.client, timeout)
+
+	if compression && isCompressionUnsupported(alpha.err) {
+		slog.Warn("Dgraph does not support gzip compression, connecting without it", "host", host, "error", alpha.err)
+		conn.Close()
+		if conn, err = dial(host, false); err != nil {
+			return alphaConn{host: host, err: err}
+		}
+		alpha.conn, alpha.client, alpha.compression = conn, api.NewDgraphClient(conn), false
+		alpha.err = waitForDgraph(context.Background(), alpha.client, timeout)
+	}
+	return alpha
+}
+
+// Connect to all Alphas concurrently, so unreachable ones don't add up
+// their timeouts
+func connectAlphas(hosts []string, dial func(host string, compression bool) (*grpc.ClientConn, error), compression bool, timeout time.Duration) []alphaConn {
+	alphas := make([]alphaConn, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			alphas[i] = connectAlpha(host, dial, compression, timeout)
+		}()
+	}
+	wg.Wait()
+	return alphas
+}
+
+// Pick the Alphas to send requests to. Unreachable Alphas are skipped, unless
+// none is reachable, in which case every Alpha that could be dialed is kept
+// so the server can start anyway. Reports whether any Alpha was reachable.
+func usableAlphas(alphas []alphaConn) ([]alphaConn, bool) {
+	var reachable, dialed []alphaConn
+	for _, alpha := range alphas {
+		if alpha.conn == nil {
+			continue
+		}
+		dialed = append(dialed, alpha)
+		if alpha.err == nil {
+			reachable = append(reachable, alpha)
+		}
+	}
+	if len(reachable) > 0 {
+		return reachable, true
+	}
+	return dialed, false
+}
+
+// Open further connections to an Alpha until it has size of them, with the
+// compression its first connection settled on. gRPC multiplexes concurrent
+// calls over a single HTTP/2 connection, which caps throughput under many
+// concurrent sessions. The connections start connecting right away, so they
+// are warm by the first tool call.
+func (a *alphaConn) openPool(size int, dial func(host string, compression bool) (*grpc.ClientConn, error)) error {
+	for len(a.pool)+1 < size {
+		conn, err := dial(a.host, a.compression)
+		if err != nil {
+			return err
+		}
+		conn.Connect()
+		a.pool = append(a.pool, conn)
+	}
+	return nil
+}
+
+// Close every connection to an Alpha
+func (a *alphaConn) close() error {
+	err := a.conn.Close()
+	for _, conn := range a.pool {
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Return a Dgraph client for every connection to the Alphas. dgo picks one
+// at random for each transaction and sends all of the transaction's requests
+// over it, so concurrent requests spread over the pool while a transaction
+// stays on a single connection for its lifetime.
+func alphaClients(alphas []alphaConn) []api.DgraphClient {
+	var clients []api.DgraphClient
+	for _, alpha := range alphas {
+		clients = append(clients, externalTxnClient{alpha.client})
+		for _, conn := range alpha.pool {
+			clients = append(clients, externalTxnClient{api.NewDgraphClient(conn)})
+		}
+	}
+	return clients
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestParseHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", hosts: "localhost:9080", want: []string{"localhost:9080"}},
+		{name: "multiple", hosts: "alpha1:9080,alpha2:9080", want: []string{"alpha1:9080", "alpha2:9080"}},
+		{name: "spaces and empty entries", hosts: " alpha1:9080 , ,alpha2:9080,", want: []string{"alpha1:9080", "alpha2:9080"}},
+		{name: "duplicates", hosts: "alpha1:9080,alpha1:9080", want: []string{"alpha1:9080"}},
+		{name: "empty", hosts: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHosts(tt.hosts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHosts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHosts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsableAlphas(t *testing.T) {
+	conn := &grpc.ClientConn{}
+	down := errors.New("unreachable")
+	tests := []struct {
+		name          string
+		alphas        []alphaConn
+		wantHosts     []string
+		wantReachable bool
+	}{
+		{
+			name:          "all reachable",
+			alphas:        []alphaConn{{host: "a", conn: conn}, {host: "b", conn: conn}},
+			wantHosts:     []string{"a", "b"},
+			wantReachable: true,
+		},
+		{
+			name:          "skips unreachable",
+			alphas:        []alphaConn{{host: "a", conn: conn, err: down}, {host: "b", conn: conn}},
+			wantHosts:     []string{"b"},
+			wantReachable: true,
+		},
+		{
+			name:      "keeps all dialed when none is reachable",
+			alphas:    []alphaConn{{host: "a", conn: conn, err: down}, {host: "b", err: down}, {host: "c", conn: conn, err: down}},
+			wantHosts: []string{"a", "c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reachable := usableAlphas(tt.alphas)
+			var hosts []string
+			for _, alpha := range got {
+				hosts = append(hosts, alpha.host)
+			}
+			if !reflect.DeepEqual(hosts, tt.wantHosts) || reachable != tt.wantReachable {
+				t.Errorf("usableAlphas() = %v, %v, want %v, %v", hosts, reachable, tt.wantHosts, tt.wantReachable)
+			}
+		})
+	}
+}
+
+func TestOpenPool(t *testing.T) {
+	var dialed []bool
+	dial := func(host string, compression bool) (*grpc.ClientConn, error) {
+		dialed = append(dialed, compression)
+		return grpc.Dial("passthrough:///"+host, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	conn, err := dial("alpha", false)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	dialed = nil
+	alpha := alphaConn{host: "alpha", conn: conn, client: api.NewDgraphClient(conn), compression: true}
+	defer alpha.close()
+
+	if err := alpha.openPool(3, dial); err != nil {
+		t.Fatalf("openPool failed: %v", err)
+	}
+	if len(alpha.pool) != 2 {
+		t.Errorf("pool has %d connections, want 2", len(alpha.pool))
+	}
+	if !reflect.DeepEqual(dialed, []bool{true, true}) {
+		t.Errorf("pooled connections dialed with compression %v, want the first connection's", dialed)
+	}
+
+	// Three connections to one Alpha and one to another
+	other := alphaConn{host: "other", client: api.NewDgraphClient(conn)}
+	clients := alphaClients([]alphaConn{alpha, other})
+	if len(clients) != 4 {
+		t.Errorf("alphaClients() returned %d clients, want 4", len(clients))
+	}
+
+	failing := alphaConn{host: "down", conn: conn}
+	if err := failing.openPool(2, func(string, bool) (*grpc.ClientConn, error) {
+		return nil, errors.New("dial failed")
+	}); err == nil {
+		t.Errorf("openPool with a failing dial succeeded, want error")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Get a boolean argument. LLM clients sometimes send booleans as the
+// strings "true" or "false", so those are accepted too. Any other value is
+// an error rather than silently falling back to the default.
+func boolArgument(request mcp.CallToolRequest, name string, fallback bool) (bool, error) {
+	switch v := request.Params.Arguments[name].(type) {
+	case nil:
+		return fallback, nil
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%s must be a boolean, got %v", name, request.Params.Arguments[name])
+}
+
+// Get an integer argument. JSON numbers arrive as float64, so values with a
+// fractional part are rejected.
+func intArgument(request mcp.CallToolRequest, name string, fallback int) (int, error) {
+	switch v := request.Params.Arguments[name].(type) {
+	case nil:
+		return fallback, nil
+	case float64:
+		if v != float64(int(v)) {
+			return 0, fmt.Errorf("%s must be an integer, got %v", name, v)
+		}
+		return int(v), nil
+	}
+	return 0, fmt.Errorf("%s must be a number, got %v", name, request.Params.Arguments[name])
+}
+
+// Get an optional list of strings argument
+func stringsArgument(request mcp.CallToolRequest, name string) ([]string, error) {
+	switch v := request.Params.Arguments[name].(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a list of strings", name)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("%s must be a list of strings", name)
+}
+
+// Get an argument that is either a boolean or a list of strings, such as
+// cascade. A list enables the option for just the listed values.
+func boolOrStringsArgument(request mcp.CallToolRequest, name string) (bool, []string, error) {
+	if _, ok := request.Params.Arguments[name].([]interface{}); ok {
+		values, err := stringsArgument(request, name)
+		return err == nil, values, err
+	}
+	enabled, err := boolArgument(request, name, false)
+	if err != nil {
+		return false, nil, fmt.Errorf("%s must be a boolean or a list of strings, got %v", name, request.Params.Arguments[name])
+	}
+	return enabled, nil, nil
+}
+
+// Get query variables for QueryWithVars. Values are passed to Dgraph
+// separately from the query text, so quotes and backslashes in them can't
+// break or inject into the query. Names are prefixed with $ if needed.
+func queryVarsArgument(request mcp.CallToolRequest, name string) (map[string]string, error) {
+	switch v := request.Params.Arguments[name].(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return toQueryVars(v)
+	}
+	return nil, fmt.Errorf("%s must be an object", name)
+}
+
+// Convert JSON values into Dgraph's string query variables
+func toQueryVars(values map[string]interface{}) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for key, value := range values {
+		if !strings.HasPrefix(key, "$") {
+			key = "$" + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			vars[key] = v
+		case float64:
+			vars[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			vars[key] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("variable %s must be a string, number or boolean", key)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBoolArgument(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		fallback bool
+		want     bool
+		wantErr  bool
+	}{
+		{nil, true, true, false},
+		{nil, false, false, false},
+		{true, false, true, false},
+		{false, true, false, false},
+		{"true", false, true, false},
+		{"FALSE", true, false, false},
+		{" True ", false, true, false},
+		{"yes", true, false, true},
+		{1.0, true, false, true},
+	}
+
+	for _, tt := range tests {
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]interface{}{}
+		if tt.value != nil {
+			request.Params.Arguments["commit"] = tt.value
+		}
+
+		got, err := boolArgument(request, "commit", tt.fallback)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("boolArgument(%v) error = %v, wantErr %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("boolArgument(%v) = %t, want %t", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestQueryVarsArgument(t *testing.T) {
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{
+		"variables": map[string]interface{}{
+			"term":   `he said "hi" \ bye`,
+			"$first": 10.0,
+			"exact":  true,
+		},
+	}
+
+	vars, err := queryVarsArgument(request, "variables")
+	if err != nil {
+		t.Fatalf("queryVarsArgument failed: %v", err)
+	}
+
+	want := map[string]string{
+		"$term":  `he said "hi" \ bye`,
+		"$first": "10",
+		"$exact": "true",
+	}
+	if len(vars) != len(want) {
+		t.Fatalf("vars = %v, want %v", vars, want)
+	}
+	for k, v := range want {
+		if vars[k] != v {
+			t.Errorf("vars[%q] = %q, want %q", k, vars[k], v)
+		}
+	}
+
+	request.Params.Arguments["variables"] = map[string]interface{}{"bad": []interface{}{"x"}}
+	if _, err := queryVarsArgument(request, "variables"); err == nil {
+		t.Error("expected error for non-scalar variable")
+	}
+}
+
+func TestBoolOrStringsArgument(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		enabled bool
+		values  []string
+		wantErr bool
+	}{
+		{nil, false, nil, false},
+		{true, true, nil, false},
+		{"false", false, nil, false},
+		{[]interface{}{"name", "email"}, true, []string{"name", "email"}, false},
+		{[]interface{}{"name", 1.0}, false, nil, true},
+		{"name", false, nil, true},
+	}
+
+	for _, tt := range tests {
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]interface{}{}
+		if tt.value != nil {
+			request.Params.Arguments["cascade"] = tt.value
+		}
+
+		enabled, values, err := boolOrStringsArgument(request, "cascade")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("boolOrStringsArgument(%v) error = %v, wantErr %t", tt.value, err, tt.wantErr)
+			continue
+		}
+		if enabled != tt.enabled || strings.Join(values, ",") != strings.Join(tt.values, ",") {
+			t.Errorf("boolOrStringsArgument(%v) = %t, %v, want %t, %v", tt.value, enabled, values, tt.enabled, tt.values)
+		}
+	}
+}
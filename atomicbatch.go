@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Read the mutation blocks of an atomic batch. Each block is checked like a
+// dgraph_do mutation; there is no query, so blocks can't have conditions.
+func atomicBatchMutations(value interface{}) ([]*api.Mutation, error) {
+	items, ok := value.([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("mutations must be a non-empty list of objects")
+	}
+	mutations := make([]*api.Mutation, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mutation %d must be an object", i)
+		}
+		if _, ok := obj["cond"]; ok {
+			return nil, fmt.Errorf("mutation %d: cond is not supported; use dgraph_do or dgraph_upsert for conditional mutations", i)
+		}
+		mu, err := doMutationArgument(obj, "")
+		if err != nil {
+			return nil, fmt.Errorf("mutation %d: %v", i, err)
+		}
+		mutations[i] = mu
+	}
+	return mutations, nil
+}
+
+// Create handler for the atomic batch mutation tool
+func createAtomicBatchHandler(client *dgo.Dgraph, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		mutations, err := atomicBatchMutations(request.Params.Arguments["mutations"])
+		if err != nil {
+			return nil, err
+		}
+
+		// All blocks go in one request committed with it, so Dgraph applies
+		// all of them or none, and blank nodes are shared between blocks.
+		// An aborted attempt applied nothing and is retried whole.
+		req := &api.Request{Mutations: mutations, CommitNow: true}
+		resp, attempts, err := runUpsert(ctx, client, retry, req)
+		if err != nil {
+			return nil, fmt.Errorf("atomic batch failed after %d attempts: %v", attempts, err)
+		}
+		invalidateCaches()
+
+		out := map[string]interface{}{
+			"message":   fmt.Sprintf("Applied %d %s in one transaction", len(mutations), plural(len(mutations), "mutation", "mutations")),
+			"mutations": len(mutations),
+			"uids":      resp.Uids,
+			"attempts":  attempts,
+		}
+		if resp.Txn != nil && resp.Txn.CommitTs > 0 {
+			out["commit_ts"] = resp.Txn.CommitTs
+		}
+		encoded, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response: %v", err)
+		}
+		return mcp.NewToolResultText(string(encoded)), nil
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAtomicBatchMutations(t *testing.T) {
+	mutations, err := atomicBatchMutations([]interface{}{
+		map[string]interface{}{"set_nquads": `_:a <name> "A" .`},
+		map[string]interface{}{"set_json": map[string]interface{}{"uid": "_:b", "friend": map[string]interface{}{"uid": "_:a"}}, "del_nquads": "<0x1> <name> * ."},
+	})
+	if err != nil {
+		t.Fatalf("atomicBatchMutations() failed: %v", err)
+	}
+	if len(mutations) != 2 || string(mutations[0].SetNquads) != `_:a <name> "A" .` || mutations[1].SetJson == nil || mutations[1].DelNquads == nil {
+		t.Errorf("atomicBatchMutations() = %v", mutations)
+	}
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr string
+	}{
+		{"missing", nil, "non-empty list"},
+		{"empty", []interface{}{}, "non-empty list"},
+		{"not an object", []interface{}{"_:a <name> \"A\" ."}, "mutation 0 must be an object"},
+		{"empty block", []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`}, map[string]interface{}{}}, "mutation 1: needs set_nquads"},
+		{"invalid nquads", []interface{}{map[string]interface{}{"set_nquads": "_:a <name>"}}, "mutation 0: set_nquads"},
+		{"cond", []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`, "cond": "@if(eq(len(v), 0))"}}, "cond is not supported"},
+		{"unknown field", []interface{}{map[string]interface{}{"set": `_:a <name> "A" .`}}, `unknown field "set"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := atomicBatchMutations(tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("atomicBatchMutations() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// autoWrapBlock is the name of the block a bare selection is wrapped in
+const autoWrapBlock = "q"
+
+// queryKeywordRe matches the keyword starting a named query or a schema query
+var queryKeywordRe = regexp.MustCompile(`^(?:query|schema)\b`)
+
+// Check whether a query is a bare selection, e.g. `name age friend { name }`,
+// rather than a full query. A selection enclosed in a single pair of braces,
+// e.g. `{ name age }`, counts as bare too, as it has no blocks. Returns the
+// selection.
+func bareSelection(query string) (string, bool) {
+	text := strings.TrimSpace(query)
+	if queryKeywordRe.MatchString(text) {
+		return "", false
+	}
+	if !strings.HasPrefix(text, "{") {
+		return text, true
+	}
+	if _, err := parseQueryBlocks(text); err == nil {
+		return "", false
+	}
+	end, err := matchingDelim(text, 0)
+	if err != nil || end != len(text)-1 {
+		return "", false
+	}
+	return strings.TrimSpace(text[1:end]), true
+}
+
+// Wrap a bare selection into a full query with a single block selecting
+// the nodes matched by rootFunc. Full queries are returned unchanged, as
+// are queries that fail to parse either way, so Dgraph reports the error.
+// Returns whether the query was wrapped.
+func autoWrapQuery(query, rootFunc string) (string, bool, error) {
+	selection, bare := bareSelection(query)
+	if !bare {
+		return query, false, nil
+	}
+	if strings.TrimSpace(rootFunc) == "" {
+		return "", false, fmt.Errorf("query is a bare selection without a query block; give func, e.g. has(name), to wrap it into one")
+	}
+	if selection == "" {
+		selection = "uid"
+	}
+
+	wrapped := fmt.Sprintf("{\n\t%s(func: %s) {\n\t\t%s\n\t}\n}", autoWrapBlock, strings.TrimSpace(rootFunc), selection)
+
+	// Catch unbalanced fragments before they can change the block structure
+	blocks, err := parseQueryBlocks(wrapped)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to wrap the selection: %v", err)
+	}
+	if len(blocks) != 1 {
+		return "", false, fmt.Errorf("func and the selection must not contain unbalanced braces or parentheses")
+	}
+	return wrapped, true, nil
+}
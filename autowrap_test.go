@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAutoWrapQuery(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		rootFunc    string
+		want        string
+		wantWrapped bool
+		wantErr     string
+	}{
+		{
+			name:        "bare selection",
+			query:       "name age friend { name }",
+			rootFunc:    "has(name)",
+			want:        "{\n\tq(func: has(name)) {\n\t\tname age friend { name }\n\t}\n}",
+			wantWrapped: true,
+		},
+		{
+			name:        "selection in braces",
+			query:       " { name\n  age } ",
+			rootFunc:    `eq(name, "Alice")`,
+			want:        "{\n\tq(func: eq(name, \"Alice\")) {\n\t\tname\n  age\n\t}\n}",
+			wantWrapped: true,
+		},
+		{
+			name:  "full query",
+			query: "{ me(func: has(name)) { name } }",
+			want:  "{ me(func: has(name)) { name } }",
+		},
+		{
+			name:  "query with a header",
+			query: "query q($n: string) { me(func: eq(name, $n)) { name } }",
+			want:  "query q($n: string) { me(func: eq(name, $n)) { name } }",
+		},
+		{
+			name:    "bare selection without func",
+			query:   "name age",
+			wantErr: "bare selection",
+		},
+		{
+			name:     "unbalanced func",
+			query:    "name",
+			rootFunc: "has(name)) { name } b(func: has(age)",
+			wantErr:  "unbalanced",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, wrapped, err := autoWrapQuery(tt.query, tt.rootFunc)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("autoWrapQuery() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("autoWrapQuery() failed: %v", err)
+			}
+			if got != tt.want || wrapped != tt.wantWrapped {
+				t.Errorf("autoWrapQuery() = %q, %t; want %q, %t", got, wrapped, tt.want, tt.wantWrapped)
+			}
+		})
+	}
+}
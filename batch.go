@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxBatchQueries is the largest number of queries run in one batch
+const maxBatchQueries = 32
+
+// batchQuery is one named query of a batch
+type batchQuery struct {
+	Name  string
+	Query string
+	Vars  map[string]string
+}
+
+// Read the queries of a batch from the tool arguments
+func batchQueriesArgument(request mcp.CallToolRequest, name string) ([]batchQuery, error) {
+	items, ok := request.Params.Arguments[name].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("%s must be a non-empty list of queries", name)
+	}
+	if len(items) > maxBatchQueries {
+		return nil, fmt.Errorf("%s has %d queries, at most %d are allowed", name, len(items), maxBatchQueries)
+	}
+
+	queries := make([]batchQuery, 0, len(items))
+	seen := map[string]bool{}
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("query %d must be an object", i)
+		}
+
+		var q batchQuery
+		for key, value := range obj {
+			var err error
+			switch key {
+			case "name":
+				q.Name, ok = value.(string)
+			case "query":
+				q.Query, ok = value.(string)
+			case "variables":
+				var vars map[string]interface{}
+				if vars, ok = value.(map[string]interface{}); ok {
+					if q.Vars, err = toQueryVars(vars); err != nil {
+						return nil, fmt.Errorf("query %d: %v", i, err)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("query %d has unknown field %q", i, key)
+			}
+			if !ok {
+				return nil, fmt.Errorf("query %d has an invalid %s", i, key)
+			}
+		}
+
+		if err := validateName("query", q.Name); err != nil {
+			return nil, fmt.Errorf("query %d: %v", i, err)
+		}
+		if seen[q.Name] {
+			return nil, fmt.Errorf("query %d: duplicate name %q", i, q.Name)
+		}
+		seen[q.Name] = true
+		if q.Query == "" {
+			return nil, fmt.Errorf("query %q has no query", q.Name)
+		}
+		queries = append(queries, q)
+	}
+	return queries, nil
+}
+
+// Create handler for the batch query tool
+func createBatchQueryHandler(client *dgo.Dgraph, limits queryLimits) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		queries, err := batchQueriesArgument(request, "queries")
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range queries {
+			if err := limits.check(q.Query); err != nil {
+				return nil, fmt.Errorf("query %q: %v", q.Name, err)
+			}
+			if err := predicateAccess.checkQuery(q.Query); err != nil {
+				return nil, fmt.Errorf("query %q: %v", q.Name, err)
+			}
+		}
+
+		consistent, err := boolArgument(request, "consistent", false)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]*api.Response, len(queries))
+		errs := make([]error, len(queries))
+		if consistent {
+			// A read-only transaction reads every query at the timestamp
+			// of its first one, so all see the same database version
+			txn := client.NewReadOnlyTxn()
+			defer txn.Discard(ctx)
+			for i, q := range queries {
+				if results[i], errs[i] = txn.QueryWithVars(ctx, q.Query, q.Vars); errs[i] != nil {
+					break
+				}
+			}
+		} else {
+			// Independent queries run concurrently, each in its own transaction
+			var wg sync.WaitGroup
+			for i, q := range queries {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					txn := client.NewReadOnlyTxn()
+					defer txn.Discard(ctx)
+					results[i], errs[i] = txn.QueryWithVars(ctx, q.Query, q.Vars)
+				}()
+			}
+			wg.Wait()
+		}
+
+		out := make(map[string]json.RawMessage, len(queries))
+		for i, q := range queries {
+			if errs[i] != nil {
+				return nil, fmt.Errorf("query %q failed: %v%s", q.Name, errs[i], notIndexedHint(ctx, client, q.Query, errs[i]))
+			}
+			out[q.Name] = results[i].Json
+			if len(out[q.Name]) == 0 {
+				out[q.Name] = json.RawMessage("{}")
+			}
+		}
+
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBatchQueriesArgument(t *testing.T) {
+	query := "{ q(func: has(name)) { name } }"
+	tests := []struct {
+		name    string
+		queries interface{}
+		want    []batchQuery
+		wantErr string
+	}{
+		{
+			name: "valid",
+			queries: []interface{}{
+				map[string]interface{}{"name": "people", "query": query},
+				map[string]interface{}{"name": "one", "query": query, "variables": map[string]interface{}{"name": "Alice", "$first": float64(1)}},
+			},
+			want: []batchQuery{
+				{Name: "people", Query: query},
+				{Name: "one", Query: query, Vars: map[string]string{"$name": "Alice", "$first": "1"}},
+			},
+		},
+		{name: "missing", queries: nil, wantErr: "non-empty list"},
+		{name: "empty", queries: []interface{}{}, wantErr: "non-empty list"},
+		{name: "not an object", queries: []interface{}{"q"}, wantErr: "must be an object"},
+		{name: "unknown field", queries: []interface{}{map[string]interface{}{"name": "q", "query": query, "vars": map[string]interface{}{}}}, wantErr: "unknown field"},
+		{name: "invalid name", queries: []interface{}{map[string]interface{}{"name": "a b", "query": query}}, wantErr: "invalid query name"},
+		{name: "duplicate name", queries: []interface{}{map[string]interface{}{"name": "q", "query": query}, map[string]interface{}{"name": "q", "query": query}}, wantErr: "duplicate name"},
+		{name: "no query", queries: []interface{}{map[string]interface{}{"name": "q"}}, wantErr: "has no query"},
+		{name: "invalid variables", queries: []interface{}{map[string]interface{}{"name": "q", "query": query, "variables": "x"}}, wantErr: "invalid variables"},
+		{name: "too many", queries: make([]interface{}, maxBatchQueries+1), wantErr: "at most"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]interface{}{"queries": tt.queries}
+
+			got, err := batchQueriesArgument(request, "queries")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("batchQueriesArgument() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("batchQueriesArgument() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batchQueriesArgument() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
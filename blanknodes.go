@@ -0,0 +1,179 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long an unused blank node session is kept
+const blankNodeSessionTTL = time.Hour
+
+// blankNodeSession remembers the uids assigned to blank nodes
+type blankNodeSession struct {
+	uids     map[string]string
+	lastUsed time.Time
+}
+
+// blankNodeSessions caches blank node to uid mappings per named session,
+// so a logical import spread over several mutations can keep referring to
+// nodes by the blank node names it created them with
+type blankNodeSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*blankNodeSession
+}
+
+// Create an empty session cache
+func newBlankNodeSessions() *blankNodeSessions {
+	return &blankNodeSessions{sessions: make(map[string]*blankNodeSession)}
+}
+
+// Get a copy of the uids assigned so far in a session
+func (s *blankNodeSessions) get(name string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune()
+
+	uids := make(map[string]string)
+	if session, ok := s.sessions[name]; ok {
+		session.lastUsed = time.Now()
+		for k, v := range session.uids {
+			uids[k] = v
+		}
+	}
+	return uids
+}
+
+// Record newly assigned uids in a session
+func (s *blankNodeSessions) record(name string, uids map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[name]
+	if !ok {
+		session = &blankNodeSession{uids: make(map[string]string)}
+		s.sessions[name] = session
+	}
+	session.lastUsed = time.Now()
+	for k, v := range uids {
+		session.uids[k] = v
+	}
+}
+
+// Drop sessions that haven't been used recently. Must be called with the lock held.
+func (s *blankNodeSessions) prune() {
+	for name, session := range s.sessions {
+		if time.Since(session.lastUsed) > blankNodeSessionTTL {
+			delete(s.sessions, name)
+		}
+	}
+}
+
+// Replace references to already assigned blank nodes (_:name) with their
+// uids (<0x1>), leaving string literals untouched
+func rewriteBlankNodes(nquads string, uids map[string]string) string {
+	if len(uids) == 0 {
+		return nquads
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(nquads); i++ {
+		c := nquads[i]
+		switch {
+		case c == '"':
+			end, err := skipString(nquads, i)
+			if err != nil {
+				end = len(nquads) - 1
+			}
+			b.WriteString(nquads[i : end+1])
+			i = end
+		case c == '_' && strings.HasPrefix(nquads[i:], "_:"):
+			j := i + 2
+			for j < len(nquads) && isBlankNodeChar(nquads[j]) {
+				j++
+			}
+			if uid, ok := uids[nquads[i+2:j]]; ok {
+				b.WriteString("<" + uid + ">")
+			} else {
+				b.WriteString(nquads[i:j])
+			}
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Check whether a character can be part of a blank node name
+func isBlankNodeChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+var typedBlankNodeRe = regexp.MustCompile(`^_:([\w.-]+)\s+<dgraph\.type>`)
+
+// List the blank nodes of N-Quads in order of appearance, leaving out those
+// the N-Quads already give a dgraph.type. String literals and comment lines
+// are skipped.
+func untypedBlankNodes(nquads string) []string {
+	var names []string
+	seen := map[string]bool{}
+	typed := map[string]bool{}
+	for _, line := range strings.Split(nquads, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := typedBlankNodeRe.FindStringSubmatch(line); m != nil {
+			typed[m[1]] = true
+		}
+
+		for i := 0; i < len(line); i++ {
+			switch {
+			case line[i] == '"':
+				end, err := skipString(line, i)
+				if err != nil {
+					end = len(line) - 1
+				}
+				i = end
+			case strings.HasPrefix(line[i:], "_:"):
+				j := i + 2
+				for j < len(line) && isBlankNodeChar(line[j]) {
+					j++
+				}
+				if name := line[i+2 : j]; name != "" && !seen[name] {
+					seen[name] = true
+					names = append(names, name)
+				}
+				i = j - 1
+			}
+		}
+	}
+
+	untyped := names[:0]
+	for _, name := range names {
+		if !typed[name] {
+			untyped = append(untyped, name)
+		}
+	}
+	return untyped
+}
+
+// Append a dgraph.type triple for every blank node without one, so that
+// the nodes a mutation creates can be found with type() and expand(_all_)
+func addBlankNodeTypes(nquads, typeName string) string {
+	untyped := untypedBlankNodes(nquads)
+	if len(untyped) == 0 {
+		return nquads
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(nquads, " \t\n"))
+	for _, name := range untyped {
+		b.WriteString("\n_:" + name + " <dgraph.type> " + strconv.Quote(typeName) + " .")
+	}
+	return b.String()
+}
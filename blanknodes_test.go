@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRewriteBlankNodes(t *testing.T) {
+	uids := map[string]string{"alice": "0x1", "bob.smith": "0x2"}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`_:alice <friend> _:bob.smith .`, `<0x1> <friend> <0x2> .`},
+		{`_:carol <friend> _:alice .`, `_:carol <friend> <0x1> .`},
+		{`_:alicex <name> "Alice" .`, `_:alicex <name> "Alice" .`},
+		{`_:alice <note> "see _:bob.smith" .`, `<0x1> <note> "see _:bob.smith" .`},
+		{`_:alice <note> "a \"_:alice\" b" .`, `<0x1> <note> "a \"_:alice\" b" .`},
+	}
+	for _, tt := range tests {
+		if got := rewriteBlankNodes(tt.in, uids); got != tt.want {
+			t.Errorf("rewriteBlankNodes(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBlankNodeSessions(t *testing.T) {
+	s := newBlankNodeSessions()
+	if got := s.get("import"); len(got) != 0 {
+		t.Fatalf("get on new session = %v, want empty", got)
+	}
+
+	s.record("import", map[string]string{"alice": "0x1"})
+	s.record("import", map[string]string{"bob": "0x2"})
+	s.record("other", map[string]string{"alice": "0x3"})
+
+	got := s.get("import")
+	if len(got) != 2 || got["alice"] != "0x1" || got["bob"] != "0x2" {
+		t.Errorf("get(import) = %v", got)
+	}
+	if got := s.get("other"); got["alice"] != "0x3" {
+		t.Errorf("get(other) = %v", got)
+	}
+
+	// The returned map is a copy
+	got["carol"] = "0x4"
+	if _, ok := s.get("import")["carol"]; ok {
+		t.Errorf("modifying the result of get changed the session")
+	}
+}
+
+func TestUntypedBlankNodes(t *testing.T) {
+	nquads := `# _:comment <name> "x" .
+_:alice <name> "Alice" .
+_:alice <friend> _:bob .
+_:bob <name> "says _:notanode" .
+_:bob <dgraph.type> "Person" .
+<0x1> <friend> _:carol .`
+
+	got := untypedBlankNodes(nquads)
+	if strings.Join(got, ",") != "alice,carol" {
+		t.Errorf("untypedBlankNodes = %v, want [alice carol]", got)
+	}
+}
+
+func TestAddBlankNodeTypes(t *testing.T) {
+	got := addBlankNodeTypes("_:a <name> \"A\" .\n_:a <friend> _:b .\n", "Person")
+	want := "_:a <name> \"A\" .\n_:a <friend> _:b .\n_:a <dgraph.type> \"Person\" .\n_:b <dgraph.type> \"Person\" ."
+	if got != want {
+		t.Errorf("addBlankNodeTypes\n got: %s\nwant: %s", got, want)
+	}
+
+	if got := addBlankNodeTypes(`<0x1> <name> "A" .`, "Person"); got != `<0x1> <name> "A" .` {
+		t.Errorf("addBlankNodeTypes without blank nodes = %s", got)
+	}
+}
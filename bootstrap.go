@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+// Read and parse the schema file given in DGRAPH_BOOTSTRAP_SCHEMA
+func readBootstrapSchema(path string) (string, *schemaInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open bootstrap schema: %v", err)
+	}
+	defer f.Close()
+
+	schema, err := readSchema(f)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read bootstrap schema: %v", err)
+	}
+	parsed, err := parseSchema(schema)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid bootstrap schema: %v", err)
+	}
+	if len(parsed.Predicates) == 0 && len(parsed.Types) == 0 {
+		return "", nil, fmt.Errorf("invalid bootstrap schema: it defines no predicates or types")
+	}
+	return schema, parsed, nil
+}
+
+// Check whether applying a schema would add or change anything. Predicates
+// and types missing from it are left alone by an alter, so removals don't
+// count.
+func schemaNeedsApply(diff schemaDiff) bool {
+	return len(diff.AddedPredicates) > 0 || len(diff.ChangedPredicates) > 0 ||
+		len(diff.AddedTypes) > 0 || len(diff.ChangedTypes) > 0
+}
+
+// Make sure the live schema contains the bootstrap schema, applying it only
+// if it would change something, so restarts don't trigger reindexing.
+// Reports whether the schema was applied.
+func bootstrapSchema(ctx context.Context, client *dgo.Dgraph, path string) (bool, error) {
+	schema, proposed, err := readBootstrapSchema(path)
+	if err != nil {
+		return false, err
+	}
+	_, current, err := loadSchema(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	diff := diffSchema(current, proposed)
+	if !schemaNeedsApply(diff) {
+		return false, nil
+	}
+	if reindex := diff.reindexChanges(); len(reindex) > 0 {
+		slog.Warn("Bootstrap schema changes indexes, Dgraph will reindex", "changes", reindex)
+	}
+	if err := alterSchema(ctx, client, schema); err != nil {
+		return false, err
+	}
+	slog.Info("Applied bootstrap schema", "path", path, "added_predicates", diff.AddedPredicates, "added_types", diff.AddedTypes, "changed_predicates", len(diff.ChangedPredicates), "changed_types", len(diff.ChangedTypes))
+	return true, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadBootstrapSchema(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	path := write("valid.schema", "name: string @index(exact) .\ntype Person {\n\tname\n}\n")
+	schema, parsed, err := readBootstrapSchema(path)
+	if err != nil {
+		t.Fatalf("readBootstrapSchema() error = %v", err)
+	}
+	if schema == "" || len(parsed.Predicates) != 1 || len(parsed.Types) != 1 {
+		t.Errorf("readBootstrapSchema() = %q, %+v", schema, parsed)
+	}
+
+	for name, path := range map[string]string{
+		"missing": filepath.Join(dir, "missing.schema"),
+		"invalid": write("invalid.schema", "name: string @index(exact)\nage int ."),
+		"empty":   write("empty.schema", "# nothing yet\n"),
+	} {
+		if _, _, err := readBootstrapSchema(path); err == nil {
+			t.Errorf("readBootstrapSchema(%s) expected error", name)
+		}
+	}
+}
+
+func TestSchemaNeedsApply(t *testing.T) {
+	current, err := parseSchema("name: string @index(exact) .\nage: int .\ntype Person {\n\tname\n\tage\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		schema string
+		want   bool
+	}{
+		{"same", "name: string @index(exact) .\ntype Person {\n\tname\n\tage\n}", false},
+		{"subset", "age: int .", false},
+		{"added predicate", "email: string .", true},
+		{"changed index", "name: string @index(term) .", true},
+		{"added type", "type Movie {\n\tname\n}", true},
+		{"changed type", "type Person {\n\tname\n\tage\n\temail\n}", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proposed, err := parseSchema(tt.schema)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schemaNeedsApply(diffSchema(current, proposed)); got != tt.want {
+				t.Errorf("schemaNeedsApply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
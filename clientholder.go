@@ -0,0 +1,25 @@
+package main
+
+import (
+	"sync/atomic"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+// sharedClient is the client handlers use when a call doesn't go through
+// the namespace client pool, i.e. without ACL credentials. Handlers run
+// concurrently, one goroutine per SSE request, so the client is held in an
+// atomic pointer: replacing it, e.g. after reconnecting, never races with
+// handlers reading it, and calls already running finish on the client they
+// started with.
+var sharedClient atomic.Pointer[dgo.Dgraph]
+
+// Get the shared client, or nil before one is set
+func getClient() *dgo.Dgraph {
+	return sharedClient.Load()
+}
+
+// Replace the shared client for all later tool calls
+func setClient(client *dgo.Dgraph) {
+	sharedClient.Store(client)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestClientFromContextUsesSharedClient(t *testing.T) {
+	t.Cleanup(func() { setClient(nil) })
+	fallback, shared := &dgo.Dgraph{}, &dgo.Dgraph{}
+
+	if client, err := clientFromContext(context.Background(), fallback); err != nil || client != fallback {
+		t.Errorf("clientFromContext() before setClient = %p, %v; want the fallback", client, err)
+	}
+	setClient(shared)
+	if client, err := clientFromContext(context.Background(), fallback); err != nil || client != shared {
+		t.Errorf("clientFromContext() after setClient = %p, %v; want the shared client", client, err)
+	}
+}
+
+// Run with -race: handlers read the shared client while it is replaced
+func TestConcurrentHandlersWhileClientChanges(t *testing.T) {
+	const clients, calls = 8, 50
+
+	var (
+		mu    sync.Mutex
+		names = map[*dgo.Dgraph]string{}
+		pool  []*dgo.Dgraph
+	)
+	for i := 0; i < clients; i++ {
+		c := &dgo.Dgraph{}
+		names[c] = fmt.Sprintf("client%d", i)
+		pool = append(pool, c)
+	}
+
+	// Serve each client a schema naming it, so results show which one answered
+	load := schemas.load
+	schemas.load = func(ctx context.Context, client *dgo.Dgraph) ([]byte, *schemaInfo, error) {
+		mu.Lock()
+		name := names[client]
+		mu.Unlock()
+		if name == "" {
+			return nil, nil, fmt.Errorf("unknown client %p", client)
+		}
+		return []byte(name), &schemaInfo{Predicates: []predicateSchema{{Predicate: name, Type: "string"}}}, nil
+	}
+	t.Cleanup(func() {
+		schemas.load = load
+		schemas.invalidate()
+		setClient(nil)
+	})
+	setClient(pool[0])
+
+	schemaResource := createSchemaResourceHandler(nil)
+	history := createSchemaHistoryHandler(nil)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*clients*calls)
+	for i := 0; i < clients; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				setClient(pool[(i+j)%clients])
+				if j%10 == 0 {
+					schemas.invalidate()
+				}
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				contents, err := schemaResource(context.Background(), mcp.ReadResourceRequest{})
+				if err != nil {
+					errs <- err
+					continue
+				}
+				text := contents[0].(mcp.TextResourceContents).Text
+				if !strings.HasPrefix(text, "client") {
+					errs <- fmt.Errorf("schema resource returned %q", text)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < calls; j++ {
+				if _, err := history(context.Background(), mcp.CallToolRequest{}); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Default client pool settings
+const (
+	defaultNamespace = 0
+	defaultLoginTTL  = time.Hour
+)
+
+// loginFunc logs into a namespace over one of conns, returning the access
+// token calls to that namespace are sent with
+type loginFunc func(ctx context.Context, conns []api.DgraphClient, namespace uint64) (string, error)
+
+// namespaceConn is a Dgraph connection sending the access token of a
+// namespace login with every call, as dgo does for its own login
+type namespaceConn struct {
+	api.DgraphClient
+	accessJwt *atomic.Value
+}
+
+// Add the access token, once logged in, to a call's metadata
+func (c namespaceConn) withToken(ctx context.Context) context.Context {
+	if jwt, _ := c.accessJwt.Load().(string); jwt != "" {
+		return metadata.AppendToOutgoingContext(ctx, "accessJwt", jwt)
+	}
+	return ctx
+}
+
+func (c namespaceConn) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	return c.DgraphClient.Query(c.withToken(ctx), in, opts...)
+}
+
+func (c namespaceConn) Alter(ctx context.Context, in *api.Operation, opts ...grpc.CallOption) (*api.Payload, error) {
+	return c.DgraphClient.Alter(c.withToken(ctx), in, opts...)
+}
+
+func (c namespaceConn) CommitOrAbort(ctx context.Context, in *api.TxnContext, opts ...grpc.CallOption) (*api.TxnContext, error) {
+	return c.DgraphClient.CommitOrAbort(c.withToken(ctx), in, opts...)
+}
+
+func (c namespaceConn) CheckVersion(ctx context.Context, in *api.Check, opts ...grpc.CallOption) (*api.Version, error) {
+	return c.DgraphClient.CheckVersion(c.withToken(ctx), in, opts...)
+}
+
+// pooledClient is a client logged into a single namespace
+type pooledClient struct {
+	mu        sync.Mutex
+	client    *dgo.Dgraph
+	accessJwt atomic.Value
+	loggedIn  time.Time
+}
+
+// namespaceClientPool keeps a warm, logged-in client per namespace so that
+// requests targeting the same namespace don't pay the login cost every time.
+// Clients are logged in lazily on first use and again once the TTL expires,
+// as their access token expires in turn: dgo only renews the tokens of its
+// own login, which can't target a namespace.
+type namespaceClientPool struct {
+	mu      sync.Mutex
+	conns   []api.DgraphClient
+	ttl     time.Duration
+	login   loginFunc
+	now     func() time.Time
+	clients map[uint64]*pooledClient
+}
+
+// Create a client pool sharing the given connections across namespaces
+func newNamespaceClientPool(conns []api.DgraphClient, ttl time.Duration, login loginFunc) *namespaceClientPool {
+	return &namespaceClientPool{
+		conns:   conns,
+		ttl:     ttl,
+		login:   login,
+		now:     time.Now,
+		clients: make(map[uint64]*pooledClient),
+	}
+}
+
+// Create a client for a namespace, its calls not yet carrying a token
+func (p *namespaceClientPool) newClient() *pooledClient {
+	pc := &pooledClient{}
+	conns := make([]api.DgraphClient, len(p.conns))
+	for i, conn := range p.conns {
+		conns[i] = namespaceConn{conn, &pc.accessJwt}
+	}
+	pc.client = dgo.NewDgraphClient(conns...)
+	return pc
+}
+
+// Get the logged-in client for a namespace, logging in if needed
+func (p *namespaceClientPool) get(ctx context.Context, namespace uint64) (*dgo.Dgraph, error) {
+	p.mu.Lock()
+	pc, ok := p.clients[namespace]
+	if !ok {
+		pc = p.newClient()
+		p.clients[namespace] = pc
+	}
+	p.mu.Unlock()
+
+	// Log in under the per-namespace lock so other namespaces aren't blocked
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if pc.loggedIn.IsZero() || p.now().Sub(pc.loggedIn) >= p.ttl {
+		jwt, err := p.login(ctx, p.conns, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("login to namespace %d failed: %v", namespace, err)
+		}
+		pc.accessJwt.Store(jwt)
+		pc.loggedIn = p.now()
+	}
+
+	return pc.client, nil
+}
+
+// Create a login function using ACL credentials
+func aclLogin(user, password string) loginFunc {
+	return func(ctx context.Context, conns []api.DgraphClient, namespace uint64) (string, error) {
+		if len(conns) == 0 {
+			return "", fmt.Errorf("no Dgraph connection to log in over")
+		}
+
+		request := &api.LoginRequest{Userid: user, Password: password}
+		if namespace != defaultNamespace {
+			// The dgo v2 login request predates namespaces, so the
+			// namespace field, number 4, is sent as an unknown field
+			request.XXX_unrecognized = binary.AppendUvarint([]byte{4 << 3}, namespace)
+		}
+		resp, err := conns[rand.Intn(len(conns))].Login(ctx, request)
+		if err != nil {
+			return "", err
+		}
+
+		var jwt api.Jwt
+		if err := jwt.Unmarshal(resp.Json); err != nil {
+			return "", fmt.Errorf("failed to decode login response: %v", err)
+		}
+		return jwt.AccessJwt, nil
+	}
+}
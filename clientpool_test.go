@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+)
+
+// countingLogin records how many times each namespace was logged into,
+// handing out a token naming the namespace and login
+type countingLogin struct {
+	mu     sync.Mutex
+	counts map[uint64]int
+	err    error
+}
+
+func (c *countingLogin) login(ctx context.Context, conns []api.DgraphClient, namespace uint64) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return "", c.err
+	}
+	c.counts[namespace]++
+	return fmt.Sprintf("token-%d-%d", namespace, c.counts[namespace]), nil
+}
+
+// tokenRecordingClient records the access token of the last query and the
+// last login request, answering logins with a token
+type tokenRecordingClient struct {
+	api.DgraphClient
+	mu        sync.Mutex
+	accessJwt []string
+	login     *api.LoginRequest
+}
+
+func (c *tokenRecordingClient) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	c.mu.Lock()
+	c.accessJwt = md.Get("accessJwt")
+	c.mu.Unlock()
+	return &api.Response{Json: []byte("{}"), Txn: &api.TxnContext{StartTs: in.StartTs}}, nil
+}
+
+func (c *tokenRecordingClient) Login(ctx context.Context, in *api.LoginRequest, opts ...grpc.CallOption) (*api.Response, error) {
+	c.login = in
+	jwt, err := (&api.Jwt{AccessJwt: "access", RefreshJwt: "refresh"}).Marshal()
+	return &api.Response{Json: jwt}, err
+}
+
+func TestNamespaceClientPoolReusesClient(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	pool := newNamespaceClientPool(nil, time.Hour, logins.login)
+	ctx := context.Background()
+
+	first, err := pool.get(ctx, 1)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		client, err := pool.get(ctx, 1)
+		if err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+		if client != first {
+			t.Fatalf("expected the same client to be reused")
+		}
+	}
+
+	other, err := pool.get(ctx, 2)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if other == first {
+		t.Fatalf("expected a different client for another namespace")
+	}
+
+	if logins.counts[1] != 1 || logins.counts[2] != 1 {
+		t.Errorf("expected one login per namespace, got %v", logins.counts)
+	}
+}
+
+func TestNamespaceClientPoolSendsToken(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	conn := &tokenRecordingClient{}
+	pool := newNamespaceClientPool([]api.DgraphClient{conn}, time.Minute, logins.login)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	query := func(namespace uint64) []string {
+		client, err := pool.get(ctx, namespace)
+		if err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+		if _, err := client.NewReadOnlyTxn().Query(ctx, "{ q(func: uid(0x1)) { uid } }"); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return conn.accessJwt
+	}
+
+	if got := query(3); len(got) != 1 || got[0] != "token-3-1" {
+		t.Errorf("query in namespace 3 sent tokens %v, want token-3-1", got)
+	}
+	if got := query(4); len(got) != 1 || got[0] != "token-4-1" {
+		t.Errorf("query in namespace 4 sent tokens %v, want token-4-1", got)
+	}
+
+	// Once the TTL expires the client logs in again and sends the new token
+	now = now.Add(time.Minute)
+	if got := query(3); len(got) != 1 || got[0] != "token-3-2" {
+		t.Errorf("query after the TTL sent tokens %v, want token-3-2", got)
+	}
+}
+
+func TestNamespaceClientPoolRefreshesAfterTTL(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	pool := newNamespaceClientPool(nil, time.Minute, logins.login)
+	now := time.Now()
+	pool.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	first, _ := pool.get(ctx, 1)
+	now = now.Add(30 * time.Second)
+	pool.get(ctx, 1)
+	now = now.Add(time.Minute)
+	second, _ := pool.get(ctx, 1)
+
+	if first != second {
+		t.Errorf("expected the client to be reused across refreshes")
+	}
+	if logins.counts[1] != 2 {
+		t.Errorf("expected 2 logins after TTL expiry, got %d", logins.counts[1])
+	}
+}
+
+func TestNamespaceClientPoolConcurrentAccess(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	pool := newNamespaceClientPool(nil, time.Hour, logins.login)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(ns uint64) {
+			defer wg.Done()
+			if _, err := pool.get(ctx, ns); err != nil {
+				t.Errorf("get failed: %v", err)
+			}
+		}(uint64(i % 3))
+	}
+	wg.Wait()
+
+	for ns := uint64(0); ns < 3; ns++ {
+		if logins.counts[ns] != 1 {
+			t.Errorf("namespace %d: expected 1 login, got %d", ns, logins.counts[ns])
+		}
+	}
+}
+
+func TestNamespaceClientPoolLoginError(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}, err: errors.New("denied")}
+	pool := newNamespaceClientPool(nil, time.Hour, logins.login)
+
+	if _, err := pool.get(context.Background(), 1); err == nil {
+		t.Fatal("expected login error")
+	}
+
+	// A failed login must be retried on the next request
+	logins.err = nil
+	if _, err := pool.get(context.Background(), 1); err != nil {
+		t.Fatalf("get failed after recovery: %v", err)
+	}
+}
+
+func TestACLLogin(t *testing.T) {
+	codec := encoding.GetCodec("proto")
+	tests := []struct {
+		name      string
+		namespace uint64
+		want      []byte // the namespace field as sent
+	}{
+		{"default namespace", 0, nil},
+		{"namespace 2", 2, []byte{0x20, 0x02}},
+		{"namespace 300", 300, []byte{0x20, 0xac, 0x02}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &tokenRecordingClient{}
+			jwt, err := aclLogin("groot", "password")(context.Background(), []api.DgraphClient{conn}, tt.namespace)
+			if err != nil || jwt != "access" {
+				t.Fatalf("login = %q, %v; want the access token", jwt, err)
+			}
+
+			sent, err := codec.Marshal(conn.login)
+			if err != nil {
+				t.Fatalf("Marshal() failed: %v", err)
+			}
+			want, _ := codec.Marshal(&api.LoginRequest{Userid: "groot", Password: "password"})
+			want = append(want, tt.want...)
+			if !bytes.Equal(sent, want) {
+				t.Errorf("login request = %x, want %x", sent, want)
+			}
+		})
+	}
+
+	if _, err := aclLogin("groot", "password")(context.Background(), nil, 0); err == nil {
+		t.Errorf("login without connections succeeded, want error")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Flatten the top-level blocks of a JSON query result into CSV, one row per
+// node and one column per predicate, uid first. Lists and nested objects
+// are JSON-encoded in their cell. A result with several blocks gets a
+// leading block column naming the block of each row.
+func resultToCSV(data []byte) (string, error) {
+	var result map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode query result: %v", err)
+	}
+
+	blocks := sortedKeys(result)
+	rows := map[string][]interface{}{}
+	seen := map[string]bool{}
+	var columns []string
+	for _, name := range blocks {
+		nodes, ok := result[name].([]interface{})
+		if !ok {
+			nodes = []interface{}{result[name]}
+		}
+		rows[name] = nodes
+		for _, node := range nodes {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				obj = map[string]interface{}{"value": node}
+			}
+			for key := range obj {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if (columns[i] == "uid") != (columns[j] == "uid") {
+			return columns[i] == "uid"
+		}
+		return columns[i] < columns[j]
+	})
+
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	header := columns
+	if len(blocks) > 1 {
+		header = append([]string{"block"}, columns...)
+	}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, name := range blocks {
+		for _, node := range rows[name] {
+			obj, ok := node.(map[string]interface{})
+			if !ok {
+				obj = map[string]interface{}{"value": node}
+			}
+			record := make([]string, 0, len(header))
+			if len(blocks) > 1 {
+				record = append(record, name)
+			}
+			for _, column := range columns {
+				cell, err := csvCell(obj[column])
+				if err != nil {
+					return "", err
+				}
+				record = append(record, cell)
+			}
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", fmt.Errorf("failed to write CSV: %v", err)
+	}
+	return b.String(), nil
+}
+
+// Format a value for a CSV cell: scalars as they are, lists and objects as
+// compact JSON and missing values as an empty cell
+func csvCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return "", fmt.Errorf("failed to encode cell: %v", err)
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestResultToCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			"single block",
+			`{"q": [{"name": "Alice", "uid": "0x1", "age": 31, "friend": [{"name": "Bob"}]}, {"uid": "0x2", "name": "Bob, Jr."}]}`,
+			"uid,age,friend,name\n0x1,31,\"[{\"\"name\"\":\"\"Bob\"\"}]\",Alice\n0x2,,,\"Bob, Jr.\"\n",
+		},
+		{
+			"lists and nesting",
+			`{"q": [{"uid": "0x1", "tags": ["a", "b"], "home": {"city": "<Paris>"}, "active": true, "score": 1.50}]}`,
+			"uid,active,home,score,tags\n0x1,true,\"{\"\"city\"\":\"\"<Paris>\"\"}\",1.50,\"[\"\"a\"\",\"\"b\"\"]\"\n",
+		},
+		{
+			"several blocks",
+			`{"b": [{"count": 2}], "a": [{"uid": "0x1"}]}`,
+			"block,uid,count\na,0x1,\nb,,2\n",
+		},
+		{
+			"empty",
+			`{"q": []}`,
+			"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resultToCSV([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("resultToCSV() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("resultToCSV() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
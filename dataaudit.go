@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default and maximum number of offending uids returned per finding
+const (
+	defaultAuditSampleSize = 5
+	maxAuditSampleSize     = 100
+)
+
+// dataAuditPlan records which predicates a data audit query checks
+type dataAuditPlan struct {
+	predicates []string // predicates used to find untyped nodes
+	uidEdges   []string // uid predicates checked for dangling references
+	typeless   []string // predicates not declared in any type
+}
+
+// auditFinding is a count of offending nodes with a sample of their uids
+type auditFinding struct {
+	Predicate string   `json:"predicate,omitempty"`
+	Count     int      `json:"count"`
+	Sample    []string `json:"sample"`
+}
+
+// dataAudit is the result of the data audit tool
+type dataAudit struct {
+	UntypedNodes          auditFinding   `json:"untyped_nodes"`
+	DanglingReferences    []auditFinding `json:"dangling_references"`
+	PredicatesWithoutType []auditFinding `json:"predicates_without_type"`
+}
+
+// Work out which predicates a data audit needs to check, leaving out those
+// predicate access is denied to
+func planDataAudit(schema *schemaInfo) dataAuditPlan {
+	inType := map[string]bool{}
+	for _, t := range schema.Types {
+		for _, f := range t.Fields {
+			inType[f.Name] = true
+		}
+	}
+
+	var plan dataAuditPlan
+	for _, p := range schema.Predicates {
+		if isInternalName(p.Predicate) || predicateAccess.check(p.Predicate) != nil {
+			continue
+		}
+		plan.predicates = append(plan.predicates, p.Predicate)
+		if p.Type == "uid" {
+			plan.uidEdges = append(plan.uidEdges, p.Predicate)
+		}
+		if !inType[p.Predicate] {
+			plan.typeless = append(plan.typeless, p.Predicate)
+		}
+	}
+	sort.Strings(plan.predicates)
+	sort.Strings(plan.uidEdges)
+	sort.Strings(plan.typeless)
+	return plan
+}
+
+// Build a query counting and sampling each kind of problem in one request.
+// A node is untyped if it has data but no dgraph.type, and a uid reference
+// dangles if its target has no predicates at all.
+func buildDataAuditQuery(plan dataAuditPlan, sampleSize int) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+
+	// Writes a count block and a sample block over the same selection
+	finding := func(name, root, filter string) {
+		fmt.Fprintf(&b, "\t%s_count(func: %s)%s {\n\t\tcount(uid)\n\t}\n", name, root, filter)
+		fmt.Fprintf(&b, "\t%s_sample(func: %s, first: %d)%s {\n\t\tuid\n\t}\n", name, root, sampleSize, filter)
+	}
+
+	if len(plan.predicates) > 0 {
+		vars := make([]string, len(plan.predicates))
+		hasAny := make([]string, len(plan.predicates))
+		for i, p := range plan.predicates {
+			vars[i] = fmt.Sprintf("u%d", i)
+			hasAny[i] = fmt.Sprintf("has(<%s>)", p)
+			fmt.Fprintf(&b, "\t%s as var(func: has(<%s>)) @filter(NOT has(dgraph.type)) {\n\t\tuid\n\t}\n", vars[i], p)
+		}
+		finding("untyped", "uid("+strings.Join(vars, ", ")+")", "")
+
+		noData := fmt.Sprintf(" @filter(NOT (has(dgraph.type) OR %s))", strings.Join(hasAny, " OR "))
+		for i, p := range plan.uidEdges {
+			fmt.Fprintf(&b, "\tvar(func: has(<%s>)) {\n\t\td%d as <%s>\n\t}\n", p, i, p)
+			finding(fmt.Sprintf("dangling%d", i), fmt.Sprintf("uid(d%d)", i), noData)
+		}
+	}
+
+	for i, p := range plan.typeless {
+		finding(fmt.Sprintf("typeless%d", i), fmt.Sprintf("has(<%s>)", p), "")
+	}
+
+	b.WriteString("}")
+	return b.String()
+}
+
+// Collect the findings of a data audit query response
+func parseDataAudit(data []byte, plan dataAuditPlan) (dataAudit, error) {
+	var blocks map[string][]struct {
+		UID   string `json:"uid"`
+		Count int    `json:"count"`
+	}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return dataAudit{}, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	collect := func(name, predicate string) auditFinding {
+		f := auditFinding{Predicate: predicate, Sample: []string{}}
+		if counts := blocks[name+"_count"]; len(counts) > 0 {
+			f.Count = counts[0].Count
+		}
+		for _, node := range blocks[name+"_sample"] {
+			f.Sample = append(f.Sample, node.UID)
+		}
+		return f
+	}
+
+	audit := dataAudit{
+		UntypedNodes:          collect("untyped", ""),
+		DanglingReferences:    []auditFinding{},
+		PredicatesWithoutType: []auditFinding{},
+	}
+	for i, p := range plan.uidEdges {
+		if f := collect(fmt.Sprintf("dangling%d", i), p); f.Count > 0 {
+			audit.DanglingReferences = append(audit.DanglingReferences, f)
+		}
+	}
+	for i, p := range plan.typeless {
+		if f := collect(fmt.Sprintf("typeless%d", i), p); f.Count > 0 {
+			audit.PredicatesWithoutType = append(audit.PredicatesWithoutType, f)
+		}
+	}
+	return audit, nil
+}
+
+// Create handler for the data audit tool
+func createDataAuditHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		sampleSize, err := intArgument(request, "sample_size", defaultAuditSampleSize)
+		if err != nil {
+			return nil, err
+		}
+		if sampleSize < 1 || sampleSize > maxAuditSampleSize {
+			return nil, fmt.Errorf("sample_size must be between 1 and %d", maxAuditSampleSize)
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		plan := planDataAudit(schema)
+		query := buildDataAuditQuery(plan, sampleSize)
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		audit, err := parseDataAudit(resp.Json, plan)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := json.Marshal(audit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
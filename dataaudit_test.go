@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanDataAudit(t *testing.T) {
+	schema := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "name", Type: "string"},
+			{Predicate: "friend", Type: "uid"},
+			{Predicate: "nickname", Type: "string"},
+			{Predicate: "dgraph.type", Type: "string"},
+		},
+		Types: []typeSchema{
+			{Name: "Person", Fields: []typeField{{Name: "name"}, {Name: "friend"}}},
+		},
+	}
+
+	plan := planDataAudit(schema)
+	if !reflect.DeepEqual(plan.predicates, []string{"friend", "name", "nickname"}) {
+		t.Errorf("predicates = %v", plan.predicates)
+	}
+	if !reflect.DeepEqual(plan.uidEdges, []string{"friend"}) {
+		t.Errorf("uidEdges = %v", plan.uidEdges)
+	}
+	if !reflect.DeepEqual(plan.typeless, []string{"nickname"}) {
+		t.Errorf("typeless = %v", plan.typeless)
+	}
+
+	query := buildDataAuditQuery(plan, 3)
+	for _, want := range []string{
+		"u0 as var(func: has(<friend>)) @filter(NOT has(dgraph.type))",
+		"untyped_count(func: uid(u0, u1, u2)) {",
+		"untyped_sample(func: uid(u0, u1, u2), first: 3) {",
+		"d0 as <friend>",
+		"dangling0_count(func: uid(d0)) @filter(NOT (has(dgraph.type) OR has(<friend>) OR has(<name>) OR has(<nickname>)))",
+		"typeless0_sample(func: has(<nickname>), first: 3) {",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("buildDataAuditQuery missing %q:\n%s", want, query)
+		}
+	}
+	if _, err := parseQueryBlocks(query); err != nil {
+		t.Errorf("buildDataAuditQuery produced an unparsable query: %v", err)
+	}
+}
+
+func TestParseDataAudit(t *testing.T) {
+	plan := dataAuditPlan{
+		predicates: []string{"friend", "name"},
+		uidEdges:   []string{"friend"},
+		typeless:   []string{"name"},
+	}
+	data := []byte(`{
+		"untyped_count": [{"count": 2}],
+		"untyped_sample": [{"uid": "0x1"}, {"uid": "0x2"}],
+		"dangling0_count": [{"count": 1}],
+		"dangling0_sample": [{"uid": "0x9"}],
+		"typeless0_count": [{"count": 0}],
+		"typeless0_sample": []
+	}`)
+
+	audit, err := parseDataAudit(data, plan)
+	if err != nil {
+		t.Fatalf("parseDataAudit failed: %v", err)
+	}
+	if audit.UntypedNodes.Count != 2 || !reflect.DeepEqual(audit.UntypedNodes.Sample, []string{"0x1", "0x2"}) {
+		t.Errorf("UntypedNodes = %+v", audit.UntypedNodes)
+	}
+	want := []auditFinding{{Predicate: "friend", Count: 1, Sample: []string{"0x9"}}}
+	if !reflect.DeepEqual(audit.DanglingReferences, want) {
+		t.Errorf("DanglingReferences = %+v, want %+v", audit.DanglingReferences, want)
+	}
+	if len(audit.PredicatesWithoutType) != 0 {
+		t.Errorf("PredicatesWithoutType = %+v, want none", audit.PredicatesWithoutType)
+	}
+}
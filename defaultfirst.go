@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Add a first: limit to the result blocks of a query that have none, so a
+// query the model forgot to bound can't return the whole database. Only
+// blocks where a limit can't change the meaning of the query are touched:
+// blocks defining variables, which later blocks may read in full, blocks
+// only counting their nodes, shortest path blocks and blocks without a
+// root function are left alone, as are blocks with an explicit first: and
+// schema queries.
+// Returns the rewritten query and the names of the blocks limited.
+func applyDefaultFirst(query string, first int) (string, []string, error) {
+	if first <= 0 || isSchemaQuery(query) {
+		return query, nil, nil
+	}
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Rewrite from the end so earlier offsets stay valid
+	var names []string
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.isVar() || b.Var != "" || b.Name == "shortest" || b.argsStart < 0 {
+			continue
+		}
+		if !rootFuncRe.MatchString(b.Args) || firstValueRe.MatchString(b.Args) {
+			continue
+		}
+		if countOnly(query[b.bodyStart+1 : b.bodyEnd]) {
+			continue
+		}
+
+		args := fmt.Sprintf("%s, first: %d", strings.TrimRight(b.Args, ", \t\r\n"), first)
+		query = query[:b.argsStart] + "(" + args + ")" + query[b.argsEnd+1:]
+		names = append([]string{b.Name}, names...)
+	}
+	return query, names, nil
+}
+
+// Describe the default limits added to a query, for the tool result
+func defaultFirstNote(first int, names []string) string {
+	return fmt.Sprintf("Note: no first: limit was given, so DGRAPH_DEFAULT_FIRST applied first: %d to %s %s. Results may be truncated; add an explicit first: to fetch more.",
+		first, plural(len(names), "block", "blocks"), strings.Join(names, ", "))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyDefaultFirst(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		first     int
+		want      string
+		wantNames []string
+	}{
+		{
+			name:      "adds a limit",
+			query:     `{ q(func: has(name)) { name } }`,
+			first:     100,
+			want:      `{ q(func: has(name), first: 100) { name } }`,
+			wantNames: []string{"q"},
+		},
+		{
+			name:  "schema query",
+			query: `schema(pred: [name]) { type index }`,
+			first: 100,
+			want:  `schema(pred: [name]) { type index }`,
+		},
+		{
+			name:  "disabled",
+			query: `{ q(func: has(name)) { name } }`,
+			want:  `{ q(func: has(name)) { name } }`,
+		},
+		{
+			name:  "keeps an explicit limit",
+			query: `{ q(func: has(name), first: 5) { name } }`,
+			first: 100,
+			want:  `{ q(func: has(name), first: 5) { name } }`,
+		},
+		{
+			name:  "keeps a limit given as a variable",
+			query: `query q($n: int) { q(func: has(name), first: $n) { name } }`,
+			first: 100,
+			want:  `query q($n: int) { q(func: has(name), first: $n) { name } }`,
+		},
+		{
+			name:      "skips variable and count blocks",
+			query:     `{ var(func: has(age)) { a as age } A as q(func: has(name)) { name } c(func: has(name)) { count(uid) } r(func: uid(a), orderasc: val(a)) @filter(gt(val(a), 18)) { name } }`,
+			first:     10,
+			want:      `{ var(func: has(age)) { a as age } A as q(func: has(name)) { name } c(func: has(name)) { count(uid) } r(func: uid(a), orderasc: val(a), first: 10) @filter(gt(val(a), 18)) { name } }`,
+			wantNames: []string{"r"},
+		},
+		{
+			name:  "skips blocks without a root function",
+			query: `{ schema(pred: [name]) { type } }`,
+			first: 10,
+			want:  `{ schema(pred: [name]) { type } }`,
+		},
+		{
+			name:      "leaves blocks alone when first: appears in a string",
+			query:     "{\n\ta(func: type(Person)) { name }\n\tb(func: eq(name, \"first: 3\")) { name }\n}",
+			first:     20,
+			want:      "{\n\ta(func: type(Person), first: 20) { name }\n\tb(func: eq(name, \"first: 3\")) { name }\n}",
+			wantNames: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, names, err := applyDefaultFirst(tt.query, tt.first)
+			if err != nil {
+				t.Fatalf("applyDefaultFirst() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("applyDefaultFirst() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("applyDefaultFirst() names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+
+	if _, _, err := applyDefaultFirst(`{ q(func: has(name)) { name }`, 10); err == nil {
+		t.Error("applyDefaultFirst() of an unbalanced query succeeded")
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// degreeEdge is an edge predicate counted by dgraph_node_degree, with the
+// aliases of its outbound and inbound counts in the query
+type degreeEdge struct {
+	predicate string
+	out       string
+	in        string // empty without @reverse
+}
+
+// Pick the edge predicates to count: the given ones, which must be uid
+// predicates, or all uid predicates the server may read. Inbound edges can
+// only be counted through @reverse.
+func degreeEdges(schema *schemaInfo, predicates []string) ([]degreeEdge, error) {
+	var candidates []predicateSchema
+	if len(predicates) > 0 {
+		for _, name := range predicates {
+			p, ok := schema.predicate(name)
+			if !ok {
+				return nil, fmt.Errorf("predicate %q is not in the schema", name)
+			}
+			if p.Type != "uid" {
+				return nil, fmt.Errorf("predicate %q has type %s; only uid predicates are edges", name, p.Type)
+			}
+			candidates = append(candidates, p)
+		}
+	} else {
+		for _, p := range schema.Predicates {
+			if p.Type == "uid" && !isInternalName(p.Predicate) && predicateAccess.check(p.Predicate) == nil {
+				candidates = append(candidates, p)
+			}
+		}
+	}
+
+	edges := make([]degreeEdge, len(candidates))
+	for i, p := range candidates {
+		if err := validateName("predicate", p.Predicate); err != nil {
+			return nil, err
+		}
+		edges[i] = degreeEdge{predicate: p.Predicate, out: fmt.Sprintf("out%d", i)}
+		if p.Reverse {
+			edges[i].in = fmt.Sprintf("in%d", i)
+		}
+	}
+	return edges, nil
+}
+
+// Build a query counting the edges of a node, one aliased count per
+// direction and predicate
+func buildNodeDegreeQuery(uid string, edges []degreeEdge) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\n\tnode(func: uid(%s)) {\n\t\tuid", uid)
+	for _, e := range edges {
+		fmt.Fprintf(&b, "\n\t\t%s: count(<%s>)", e.out, e.predicate)
+		if e.in != "" {
+			fmt.Fprintf(&b, "\n\t\t%s: count(~<%s>)", e.in, e.predicate)
+		}
+	}
+	b.WriteString("\n\t}\n}")
+	return b.String()
+}
+
+// nodeDegree is the number of edges of a node per predicate and direction.
+// Predicates without edges are left out.
+type nodeDegree struct {
+	UID       string         `json:"uid"`
+	Degree    int            `json:"degree"`
+	OutDegree int            `json:"out_degree"`
+	InDegree  int            `json:"in_degree"`
+	Outbound  map[string]int `json:"outbound"`
+	Inbound   map[string]int `json:"inbound"`
+	// Predicates whose inbound edges can't be counted, lacking @reverse
+	NoReverse []string `json:"no_reverse,omitempty"`
+}
+
+// Read the counts of a degree query result
+func parseNodeDegree(data []byte, uid string, edges []degreeEdge) (nodeDegree, error) {
+	var result struct {
+		Node []map[string]json.RawMessage `json:"node"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nodeDegree{}, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	d := nodeDegree{UID: uid, Outbound: map[string]int{}, Inbound: map[string]int{}}
+	var counts map[string]json.RawMessage
+	if len(result.Node) > 0 {
+		counts = result.Node[0]
+	}
+	count := func(alias string) (int, error) {
+		raw, ok := counts[alias]
+		if !ok {
+			return 0, nil
+		}
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return 0, fmt.Errorf("failed to parse count %s: %v", alias, err)
+		}
+		return n, nil
+	}
+
+	for _, e := range edges {
+		out, err := count(e.out)
+		if err != nil {
+			return nodeDegree{}, err
+		}
+		if out > 0 {
+			d.Outbound[e.predicate] = out
+			d.OutDegree += out
+		}
+		if e.in == "" {
+			d.NoReverse = append(d.NoReverse, e.predicate)
+			continue
+		}
+		in, err := count(e.in)
+		if err != nil {
+			return nodeDegree{}, err
+		}
+		if in > 0 {
+			d.Inbound[e.predicate] = in
+			d.InDegree += in
+		}
+	}
+	d.Degree = d.OutDegree + d.InDegree
+	return d, nil
+}
+
+// Create handler for the node degree tool
+func createNodeDegreeHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := uidArgument(request, "uid")
+		if err != nil {
+			return nil, err
+		}
+		predicates, err := stringsArgument(request, "predicates")
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range predicates {
+			if err := predicateAccess.check(p); err != nil {
+				return nil, err
+			}
+		}
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		edges, err := degreeEdges(schema, predicates)
+		if err != nil {
+			return nil, err
+		}
+		if len(edges) == 0 {
+			return nil, fmt.Errorf("the schema has no uid predicates to count edges of")
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		resp, err := txn.Query(ctx, buildNodeDegreeQuery(uid, edges))
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		degree, err := parseNodeDegree(resp.Json, uid, edges)
+		if err != nil {
+			return nil, err
+		}
+		out, err := json.Marshal(degree)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode degree: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNodeDegree(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string"},
+		{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+		{Predicate: "directed", Type: "uid", List: true},
+		{Predicate: "dgraph.user.group", Type: "uid", List: true, Reverse: true},
+	}}
+
+	edges, err := degreeEdges(schema, nil)
+	if err != nil {
+		t.Fatalf("degreeEdges() failed: %v", err)
+	}
+	want := []degreeEdge{{"friend", "out0", "in0"}, {"directed", "out1", ""}}
+	if !reflect.DeepEqual(edges, want) {
+		t.Errorf("degreeEdges() = %v, want %v", edges, want)
+	}
+
+	query := buildNodeDegreeQuery("0x1", edges)
+	wantQuery := "{\n\tnode(func: uid(0x1)) {\n\t\tuid\n\t\tout0: count(<friend>)\n\t\tin0: count(~<friend>)\n\t\tout1: count(<directed>)\n\t}\n}"
+	if query != wantQuery {
+		t.Errorf("buildNodeDegreeQuery() =\n%s\nwant\n%s", query, wantQuery)
+	}
+
+	degree, err := parseNodeDegree([]byte(`{"node": [{"uid": "0x1", "out0": 3, "in0": 40, "out1": 0}]}`), "0x1", edges)
+	if err != nil {
+		t.Fatalf("parseNodeDegree() failed: %v", err)
+	}
+	wantDegree := nodeDegree{
+		UID: "0x1", Degree: 43, OutDegree: 3, InDegree: 40,
+		Outbound:  map[string]int{"friend": 3},
+		Inbound:   map[string]int{"friend": 40},
+		NoReverse: []string{"directed"},
+	}
+	if !reflect.DeepEqual(degree, wantDegree) {
+		t.Errorf("parseNodeDegree() = %+v, want %+v", degree, wantDegree)
+	}
+
+	if degree, err := parseNodeDegree([]byte(`{"node": []}`), "0x2", edges); err != nil || degree.Degree != 0 {
+		t.Errorf("parseNodeDegree() of a missing node = %+v, %v", degree, err)
+	}
+}
+
+func TestDegreeEdgesErrors(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{{Predicate: "name", Type: "string"}}}
+	for _, tt := range []struct {
+		predicates []string
+		wantErr    string
+	}{
+		{[]string{"missing"}, "not in the schema"},
+		{[]string{"name"}, "only uid predicates are edges"},
+	} {
+		if _, err := degreeEdges(schema, tt.predicates); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("degreeEdges(%v) error = %v, want it to contain %q", tt.predicates, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// deleteCountBlock is the block added to a delete by query to count the
+// nodes it matches
+const deleteCountBlock = "delete_by_query_count"
+
+// Add a block counting the nodes bound to variable v to a query, which must
+// define v
+func buildDeleteByQuery(query, v string) (string, error) {
+	defined, err := queryDefinedVariables(query)
+	if err != nil {
+		return "", err
+	}
+	if !defined[v] {
+		return "", fmt.Errorf("query must bind the nodes to delete to variable %s, e.g. { q(func: type(Temp)) { %s as uid } }", v, v)
+	}
+	query = strings.TrimSpace(query)
+	if !strings.HasSuffix(query, "}") {
+		return "", fmt.Errorf("query must end with its closing brace")
+	}
+	return query[:len(query)-1] + "\n\t" + deleteCountBlock + "(func: uid(" + v + ")) { count(uid) }\n}", nil
+}
+
+// Read the number of matched nodes from the counting block of a delete by
+// query's response
+func parseDeletedCount(data []byte) (int, error) {
+	var result map[string][]struct {
+		Count int `json:"count"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse count: %v", err)
+	}
+	if rows := result[deleteCountBlock]; len(rows) > 0 {
+		return rows[0].Count, nil
+	}
+	return 0, nil
+}
+
+// Create handler for the delete by query tool
+func createDeleteByQueryHandler(client *dgo.Dgraph, limits queryLimits, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		query, ok := request.Params.Arguments["query"].(string)
+		if !ok || strings.TrimSpace(query) == "" {
+			return nil, fmt.Errorf("query must be a non-empty string")
+		}
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+		v, ok := request.Params.Arguments["var"].(string)
+		if !ok && request.Params.Arguments["var"] != nil {
+			return nil, fmt.Errorf("var must be a string")
+		}
+		if v == "" {
+			v = "v"
+		}
+		confirm, err := boolArgument(request, "confirm", false)
+		if err != nil {
+			return nil, err
+		}
+		vars, err := queryVarsArgument(request, "variables")
+		if err != nil {
+			return nil, err
+		}
+
+		countQuery, err := buildDeleteByQuery(query, v)
+		if err != nil {
+			return nil, err
+		}
+		deletion := fmt.Sprintf("uid(%s) * * .", v)
+		if err := predicateAccess.checkNQuads(deletion); err != nil {
+			return nil, err
+		}
+
+		// Without confirmation only report what would be deleted
+		if !confirm {
+			txn := client.NewReadOnlyTxn()
+			defer txn.Discard(ctx)
+
+			resp, err := txn.QueryWithVars(ctx, countQuery, vars)
+			if err != nil {
+				return nil, fmt.Errorf("query failed: %v%s", err, notIndexedHint(ctx, client, query, err))
+			}
+			count, err := parseDeletedCount(resp.Json)
+			if err != nil {
+				return nil, err
+			}
+			out, err := json.Marshal(map[string]interface{}{
+				"message": fmt.Sprintf("%d nodes match; nothing was deleted. Pass confirm: true to delete them", count),
+				"count":   count,
+				"deleted": false,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode delete response: %v", err)
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		}
+
+		// Count and delete the nodes in one committed upsert block, so the
+		// count is of the nodes actually deleted
+		resp, attempts, err := runUpsert(ctx, client, retry, &api.Request{
+			Query:     countQuery,
+			Vars:      vars,
+			Mutations: []*api.Mutation{{DelNquads: []byte(deletion)}},
+			CommitNow: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("delete failed after %d attempts: %v", attempts, err)
+		}
+		invalidateCaches()
+
+		count, err := parseDeletedCount(resp.Json)
+		if err != nil {
+			return nil, err
+		}
+		out, err := json.Marshal(map[string]interface{}{
+			"message": fmt.Sprintf("Deleted %d nodes", count),
+			"count":   count,
+			"deleted": true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode delete response: %v", err)
+		}
+		return addDebugMeta(ctx, mcp.NewToolResultText(string(out)), "attempts", attempts), nil
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildDeleteByQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		v       string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "default variable",
+			query: "{ q(func: type(Temp)) { v as uid } }",
+			v:     "v",
+			want:  "{ q(func: type(Temp)) { v as uid } \n\tdelete_by_query_count(func: uid(v)) { count(uid) }\n}",
+		},
+		{
+			name:  "named query",
+			query: "query q($t: string) { old as var(func: type(Temp)) @filter(eq(tag, $t)) }\n",
+			v:     "old",
+			want:  "query q($t: string) { old as var(func: type(Temp)) @filter(eq(tag, $t)) \n\tdelete_by_query_count(func: uid(old)) { count(uid) }\n}",
+		},
+		{name: "undefined variable", query: "{ q(func: type(Temp)) { u as uid } }", v: "v", wantErr: true},
+		{name: "variable only in a string", query: `{ q(func: eq(name, "v as uid")) { uid } }`, v: "v", wantErr: true},
+		{name: "trailing text", query: "{ q(func: type(Temp)) { v as uid } } # done", v: "v", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildDeleteByQuery(tt.query, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildDeleteByQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("buildDeleteByQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The built query must still be a valid DQL query
+	query, _ := buildDeleteByQuery("{ q(func: type(Temp)) { v as uid } }", "v")
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		t.Fatalf("parseQueryBlocks() error = %v", err)
+	}
+	var names []string
+	for _, b := range blocks {
+		names = append(names, b.Name)
+	}
+	if strings.Join(names, ",") != "q,delete_by_query_count" {
+		t.Errorf("blocks = %v, want q and delete_by_query_count", names)
+	}
+}
+
+func TestParseDeletedCount(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{data: `{"q": [{"uid": "0x1"}], "delete_by_query_count": [{"count": 3}]}`, want: 3},
+		{data: `{"delete_by_query_count": []}`, want: 0},
+		{data: `{}`, want: 0},
+		{data: `not json`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseDeletedCount([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDeletedCount(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDeletedCount(%s) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Read one mutation of a dgraph_do request. N-Quads and JSON are checked
+// like in the dedicated mutation tools, and a condition against the
+// variables the request's query defines.
+func doMutationArgument(obj map[string]interface{}, query string) (*api.Mutation, error) {
+	mu := &api.Mutation{}
+	for key, value := range obj {
+		switch key {
+		case "set_nquads", "del_nquads":
+			nquads, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string", key)
+			}
+			deletion := key == "del_nquads"
+			if err := validateNQuads(nquads, deletion); err != nil {
+				return nil, fmt.Errorf("%s: %v", key, err)
+			}
+			if err := predicateAccess.checkNQuads(nquads); err != nil {
+				return nil, err
+			}
+			if deletion {
+				mu.DelNquads = []byte(nquads)
+			} else {
+				mu.SetNquads = []byte(nquads)
+			}
+		case "set_json", "delete_json":
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+			default:
+				return nil, fmt.Errorf("%s must be an object or a list of objects", key)
+			}
+			if err := predicateAccess.checkJSON(value); err != nil {
+				return nil, err
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode %s: %v", key, err)
+			}
+			if key == "set_json" {
+				mu.SetJson = data
+			} else {
+				mu.DeleteJson = data
+			}
+		case "cond":
+			cond, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("cond must be a string")
+			}
+			if strings.TrimSpace(query) == "" {
+				return nil, fmt.Errorf("cond needs a query defining the variables it uses")
+			}
+			var err error
+			if mu.Cond, err = upsertCond(cond, query); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unknown field %q", key)
+		}
+	}
+	if mu.SetNquads == nil && mu.DelNquads == nil && mu.SetJson == nil && mu.DeleteJson == nil {
+		return nil, fmt.Errorf("needs set_nquads, del_nquads, set_json or delete_json")
+	}
+	return mu, nil
+}
+
+// Read a request in Dgraph's native shape from the dgraph_do arguments and
+// check that its fields fit together. Without commit_now a request with
+// mutations is committed, unless it runs in an open transaction.
+func doRequestArgument(request mcp.CallToolRequest, inTxn bool) (*api.Request, error) {
+	req := &api.Request{}
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok && request.Params.Arguments["query"] != nil {
+		return nil, fmt.Errorf("query must be a string")
+	}
+	req.Query = query
+
+	var err error
+	if req.Vars, err = queryVarsArgument(request, "variables"); err != nil {
+		return nil, err
+	}
+	if req.ReadOnly, err = boolArgument(request, "read_only", false); err != nil {
+		return nil, err
+	}
+	if req.BestEffort, err = boolArgument(request, "best_effort", false); err != nil {
+		return nil, err
+	}
+	if req.CommitNow, err = boolArgument(request, "commit_now", !inTxn); err != nil {
+		return nil, err
+	}
+
+	if value := request.Params.Arguments["mutations"]; value != nil {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("mutations must be a list of objects")
+		}
+		for i, item := range items {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("mutation %d must be an object", i)
+			}
+			mu, err := doMutationArgument(obj, query)
+			if err != nil {
+				return nil, fmt.Errorf("mutation %d: %v", i, err)
+			}
+			req.Mutations = append(req.Mutations, mu)
+		}
+	}
+
+	switch {
+	case strings.TrimSpace(req.Query) == "" && len(req.Mutations) == 0:
+		return nil, fmt.Errorf("query or mutations must be given")
+	case req.ReadOnly && len(req.Mutations) > 0:
+		return nil, fmt.Errorf("read_only requests cannot have mutations")
+	case req.BestEffort && !req.ReadOnly:
+		return nil, fmt.Errorf("best_effort requires read_only")
+	case req.ReadOnly && inTxn:
+		return nil, fmt.Errorf("read_only cannot be combined with txn_id")
+	case req.CommitNow && inTxn:
+		return nil, fmt.Errorf("commit_now cannot be combined with txn_id; commit with dgraph_commit_txn")
+	case !req.CommitNow && !inTxn && len(req.Mutations) > 0:
+		return nil, fmt.Errorf("mutations outside a transaction must be committed; set commit_now or pass a txn_id from dgraph_begin_txn")
+	}
+	if len(req.Mutations) == 0 {
+		// Dgraph only commits requests with mutations
+		req.CommitNow = false
+	}
+	return req, nil
+}
+
+// Create handler for the raw request tool
+func createDoHandler(client *dgo.Dgraph, txns *txnRegistry, limits queryLimits) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		txnID, ok := request.Params.Arguments["txn_id"].(string)
+		if !ok && request.Params.Arguments["txn_id"] != nil {
+			return nil, fmt.Errorf("txn_id must be a string")
+		}
+		req, err := doRequestArgument(request, txnID != "")
+		if err != nil {
+			return nil, err
+		}
+		if req.Query != "" {
+			if err := limits.check(req.Query); err != nil {
+				return nil, err
+			}
+			if err := predicateAccess.checkQuery(req.Query); err != nil {
+				return nil, err
+			}
+		}
+
+		var resp *api.Response
+		run := func(txn *dgo.Txn) error {
+			var err error
+			resp, err = txn.Do(ctx, req)
+			return err
+		}
+		switch {
+		case txnID != "":
+			err = txns.use(txnID, run)
+		case req.ReadOnly:
+			txn := client.NewReadOnlyTxn()
+			defer txn.Discard(ctx)
+			err = run(txn)
+		default:
+			txn := activity.startTxn(client.NewTxn())
+			defer activity.finishTxn(ctx, txn)
+			err = run(txn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %v", err)
+		}
+		if req.CommitNow {
+			invalidateCaches()
+		}
+
+		data := json.RawMessage(resp.Json)
+		if len(data) == 0 {
+			data = json.RawMessage("{}")
+		}
+		out := map[string]interface{}{
+			"data":      data,
+			"uids":      resp.Uids,
+			"committed": req.CommitNow,
+		}
+		if resp.Txn != nil {
+			out["start_ts"] = resp.Txn.StartTs
+			if resp.Txn.CommitTs > 0 {
+				out["commit_ts"] = resp.Txn.CommitTs
+			}
+		}
+		encoded, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode response: %v", err)
+		}
+		return mcp.NewToolResultText(string(encoded)), nil
+	}
+}
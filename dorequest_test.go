@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestDoRequestArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		inTxn   bool
+		wantErr bool
+		check   func(t *testing.T, mutations int, commitNow, readOnly bool)
+	}{
+		{
+			name: "query only",
+			args: map[string]interface{}{"query": "{ q(func: has(name)) { uid } }"},
+			check: func(t *testing.T, mutations int, commitNow, readOnly bool) {
+				if mutations != 0 || commitNow {
+					t.Errorf("mutations = %d, commit_now = %v", mutations, commitNow)
+				}
+			},
+		},
+		{
+			name: "upsert",
+			args: map[string]interface{}{
+				"query": `{ q(func: eq(email, "a@b.c")) { v as uid } }`,
+				"mutations": []interface{}{
+					map[string]interface{}{"set_nquads": `uid(v) <email> "a@b.c" .`, "cond": "eq(len(v), 0)"},
+					map[string]interface{}{"set_json": map[string]interface{}{"uid": "uid(v)", "seen": true}, "cond": "@if(gt(len(v), 0))"},
+				},
+			},
+			check: func(t *testing.T, mutations int, commitNow, readOnly bool) {
+				if mutations != 2 || !commitNow {
+					t.Errorf("mutations = %d, commit_now = %v", mutations, commitNow)
+				}
+			},
+		},
+		{
+			name:  "in transaction",
+			args:  map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"del_nquads": "<0x1> * * ."}}},
+			inTxn: true,
+			check: func(t *testing.T, mutations int, commitNow, readOnly bool) {
+				if commitNow {
+					t.Errorf("commit_now = true in a transaction")
+				}
+			},
+		},
+		{
+			name: "read only best effort",
+			args: map[string]interface{}{"query": "{ q(func: has(name)) { uid } }", "read_only": true, "best_effort": true},
+			check: func(t *testing.T, mutations int, commitNow, readOnly bool) {
+				if !readOnly {
+					t.Errorf("read_only = false")
+				}
+			},
+		},
+		{name: "empty", args: map[string]interface{}{}, wantErr: true},
+		{name: "read only mutation", args: map[string]interface{}{"read_only": true, "mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`}}}, wantErr: true},
+		{name: "best effort without read only", args: map[string]interface{}{"query": "{ q(func: has(name)) { uid } }", "best_effort": true}, wantErr: true},
+		{name: "uncommitted outside transaction", args: map[string]interface{}{"commit_now": false, "mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`}}}, wantErr: true},
+		{name: "commit in transaction", args: map[string]interface{}{"commit_now": true, "mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`}}}, inTxn: true, wantErr: true},
+		{name: "empty mutation", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{}}}, wantErr: true},
+		{name: "unknown mutation field", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"set": `_:a <name> "A" .`}}}, wantErr: true},
+		{name: "invalid nquads", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A"`}}}, wantErr: true},
+		{name: "wildcard set", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"set_nquads": `<0x1> * * .`}}}, wantErr: true},
+		{name: "json string", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"set_json": `{"name": "A"}`}}}, wantErr: true},
+		{name: "cond without query", args: map[string]interface{}{"mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`, "cond": "eq(len(v), 0)"}}}, wantErr: true},
+		{name: "cond on undefined variable", args: map[string]interface{}{"query": "{ q(func: has(name)) { uid } }", "mutations": []interface{}{map[string]interface{}{"set_nquads": `_:a <name> "A" .`, "cond": "eq(len(v), 0)"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = tt.args
+			req, err := doRequestArgument(request, tt.inTxn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("doRequestArgument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && tt.check != nil {
+				tt.check(t, len(req.Mutations), req.CommitNow, req.ReadOnly)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// queryBlock is a top-level block of a DQL query, e.g.
+// `A as me(func: has(name)) @filter(...) { name }`
+type queryBlock struct {
+	Name      string // the block name, which is also its key in the result
+	Var       string // the variable the block defines, if any
+	Args      string // the raw arguments between the parentheses
+	argsStart int    // index of the opening parenthesis, -1 without arguments
+	argsEnd   int    // index of the closing parenthesis
+	bodyStart int    // index of the opening brace
+	bodyEnd   int    // index of the closing brace
+}
+
+// Check whether the block only defines variables and produces no result
+func (b queryBlock) isVar() bool {
+	return b.Name == "var"
+}
+
+// Find the index of the delimiter closing the one at open, skipping
+// string literals, regular expression literals and comments
+func matchingDelim(s string, open int) (int, error) {
+	var close byte
+	switch s[open] {
+	case '(':
+		close = ')'
+	case '{':
+		close = '}'
+	case '[':
+		close = ']'
+	default:
+		return -1, fmt.Errorf("no opening delimiter at offset %d", open)
+	}
+
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			end, err := skipString(s, i)
+			if err != nil {
+				return -1, err
+			}
+			i = end
+		case '/':
+			if isRegexpStart(s, i) {
+				end, err := skipRegexp(s, i)
+				if err != nil {
+					return -1, err
+				}
+				i = end
+			}
+		case '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case s[open]:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return -1, fmt.Errorf("unbalanced %q at offset %d", s[open], open)
+}
+
+// Return the index of the quote closing the string literal starting at start
+func skipString(s string, start int) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// Report whether the slash at i opens a regular expression literal, as in
+// regexp(name, /^Al.*$/i), rather than being a division in math(). Regular
+// expressions are only passed as function arguments, so the slash follows
+// a comma or an opening parenthesis.
+func isRegexpStart(s string, i int) bool {
+	j := i - 1
+	for j >= 0 && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+		j--
+	}
+	return j >= 0 && (s[j] == ',' || s[j] == '(')
+}
+
+// Find the index of the slash closing the regular expression literal
+// opened at start. Escaped characters, such as \/, are skipped; the flags
+// after the closing slash are left to the caller.
+func skipRegexp(s string, start int) (int, error) {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '/':
+			return i, nil
+		case '\n':
+			return -1, fmt.Errorf("unterminated regular expression at offset %d", start)
+		}
+	}
+	return -1, fmt.Errorf("unterminated regular expression at offset %d", start)
+}
+
+// Skip whitespace and comments
+func skipSpace(s string, i int) int {
+	for i < len(s) {
+		switch s[i] {
+		case ' ', '\t', '\n', '\r', ',':
+			i++
+		case '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// Report whether a query is a schema query, such as `schema {}` or
+// `schema(pred: [name]) { type index }`, which has no blocks
+func isSchemaQuery(query string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(query), "schema")
+	return ok && (rest == "" || strings.ContainsRune(" \t\r\n({", rune(rest[0])))
+}
+
+// Parse the top-level blocks of a DQL query. An optional
+// `query name($var: type)` header before the query body is skipped.
+func parseQueryBlocks(query string) ([]queryBlock, error) {
+	// Find the brace opening the query body
+	start := -1
+	for i := 0; i < len(query) && start < 0; i++ {
+		switch query[i] {
+		case '(':
+			end, err := matchingDelim(query, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case '{':
+			start = i
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("query has no body")
+	}
+	end, err := matchingDelim(query, start)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []queryBlock
+	for i := skipSpace(query, start+1); i < end; i = skipSpace(query, i) {
+		// Read the block header up to its arguments or body
+		j := i
+		for j < end && query[j] != '(' && query[j] != '{' && query[j] != '@' {
+			j++
+		}
+		block := queryBlock{argsStart: -1}
+		switch header := strings.Fields(query[i:j]); {
+		case len(header) == 1:
+			block.Name = header[0]
+		case len(header) == 3 && header[1] == "as":
+			block.Var, block.Name = header[0], header[2]
+		default:
+			return nil, fmt.Errorf("unable to parse query block %q", strings.TrimSpace(query[i:j]))
+		}
+
+		if j < end && query[j] == '(' {
+			argsEnd, err := matchingDelim(query, j)
+			if err != nil {
+				return nil, err
+			}
+			block.argsStart, block.argsEnd = j, argsEnd
+			block.Args = strings.TrimSpace(query[j+1 : argsEnd])
+			j = argsEnd + 1
+		}
+
+		// Skip directives up to the block body
+		for j < end && query[j] != '{' {
+			if query[j] == '(' {
+				if j, err = matchingDelim(query, j); err != nil {
+					return nil, err
+				}
+			}
+			j++
+		}
+		if j >= end {
+			return nil, fmt.Errorf("query block %q has no body", block.Name)
+		}
+		bodyEnd, err := matchingDelim(query, j)
+		if err != nil {
+			return nil, err
+		}
+		block.bodyStart, block.bodyEnd = j, bodyEnd
+
+		blocks = append(blocks, block)
+		i = bodyEnd + 1
+	}
+
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("query has no blocks")
+	}
+	return blocks, nil
+}
+
+var nameRe = regexp.MustCompile(`^[A-Za-z_][\w.]*$`)
+
+// Check that a type or predicate name is safe to embed in a query
+func validateName(kind, name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q", kind, name)
+	}
+	return nil
+}
+
+// Rewrite a query so every result block fetches at most one uid. Returns
+// the rewritten query and the names of the result blocks.
+func rewriteExistsOnly(query string) (string, []string, error) {
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Rewrite from the end so earlier offsets stay valid
+	var names []string
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.isVar() {
+			continue
+		}
+		if b.argsStart < 0 {
+			return "", nil, fmt.Errorf("query block %q has no root function", b.Name)
+		}
+
+		args := b.Args
+		if firstValueRe.MatchString(args) {
+			args = firstValueRe.ReplaceAllString(args, "first: 1")
+		} else {
+			args += ", first: 1"
+		}
+
+		query = query[:b.argsStart] + "(" + args + ")" + query[b.argsEnd+1:b.bodyStart] + "{ uid }" + query[b.bodyEnd+1:]
+		names = append([]string{b.Name}, names...)
+	}
+
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("query has no result blocks")
+	}
+	return query, names, nil
+}
+
+// Check whether any of the named result blocks returned a node
+func resultHasData(data []byte, names []string) (bool, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return false, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	for _, name := range names {
+		var nodes []json.RawMessage
+		if err := json.Unmarshal(result[name], &nodes); err == nil && len(nodes) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Check whether every top-level block of a query result is empty, meaning
+// the query matched no nodes. Blocks that aren't lists, such as schema
+// results, count as data.
+func resultIsEmpty(data []byte) (bool, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return false, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	for _, block := range result {
+		var nodes []json.RawMessage
+		if err := json.Unmarshal(block, &nodes); err != nil || len(nodes) > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Rewrite a query so every result block also fetches all predicates of its
+// nodes with expand(_all_), along with dgraph.type which expand(_all_)
+// depends on. Returns the rewritten query and the names of the result blocks.
+func rewriteExpandAll(query string) (string, []string, error) {
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Rewrite from the end so earlier offsets stay valid
+	var names []string
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.isVar() {
+			continue
+		}
+		names = append([]string{b.Name}, names...)
+		if strings.Contains(query[b.bodyStart:b.bodyEnd], "expand(") {
+			continue
+		}
+		query = query[:b.bodyStart+1] + " uid dgraph.type expand(_all_)" + query[b.bodyStart+1:]
+	}
+
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("query has no result blocks")
+	}
+	return query, names, nil
+}
+
+// Count the nodes of the named result blocks that have no dgraph.type, for
+// which expand(_all_) returns nothing
+func countUntypedNodes(data []byte, names []string) (untyped, total int, err error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	for _, name := range names {
+		var nodes []map[string]json.RawMessage
+		if err := json.Unmarshal(result[name], &nodes); err != nil {
+			continue
+		}
+		for _, node := range nodes {
+			total++
+			if _, ok := node["dgraph.type"]; !ok {
+				untyped++
+			}
+		}
+	}
+	return untyped, total, nil
+}
+
+var aliasRe = regexp.MustCompile(`[A-Za-z_][\w.@]*\s*:`)
+
+// Check whether a block body aliases at least one field, ignoring
+// arguments and string literals
+func hasAlias(body string) bool {
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '"':
+			end, err := skipString(body, i)
+			if err != nil {
+				return false
+			}
+			i = end
+		case '(':
+			end, err := matchingDelim(body, i)
+			if err != nil {
+				return false
+			}
+			i = end
+		default:
+			b.WriteByte(body[i])
+		}
+	}
+	return aliasRe.MatchString(b.String())
+}
+
+// Rewrite a query so every result block flattens its results with
+// @normalize. Only aliased fields are kept by @normalize, so blocks
+// without any alias are rejected.
+func rewriteNormalize(query string) (string, error) {
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", err
+	}
+
+	// Rewrite from the end so earlier offsets stay valid
+	rewritten := false
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.isVar() {
+			continue
+		}
+		rewritten = true
+		if !hasAlias(query[b.bodyStart+1 : b.bodyEnd]) {
+			return "", fmt.Errorf("query block %q has no aliased fields; @normalize only returns fields with an alias, e.g. name: name", b.Name)
+		}
+
+		directivesStart := b.argsEnd + 1
+		if b.argsStart < 0 {
+			directivesStart = b.bodyStart
+		}
+		if strings.Contains(query[directivesStart:b.bodyStart], "@normalize") {
+			continue
+		}
+		query = query[:b.bodyStart] + "@normalize " + query[b.bodyStart:]
+	}
+
+	if !rewritten {
+		return "", fmt.Errorf("query has no result blocks")
+	}
+	return query, nil
+}
+
+// Rewrite a query so every result block drops nodes missing any of the
+// requested predicates with @cascade, or only those listed with
+// @cascade(pred, ...). Blocks that already cascade are left alone.
+func rewriteCascade(query string, predicates []string) (string, error) {
+	for _, p := range predicates {
+		if err := validateName("cascade predicate", p); err != nil {
+			return "", err
+		}
+	}
+	directive := "@cascade"
+	if len(predicates) > 0 {
+		directive += "(" + strings.Join(predicates, ", ") + ")"
+	}
+
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", err
+	}
+
+	// Rewrite from the end so earlier offsets stay valid
+	rewritten := false
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.isVar() {
+			continue
+		}
+		rewritten = true
+
+		directivesStart := b.argsEnd + 1
+		if b.argsStart < 0 {
+			directivesStart = b.bodyStart
+		}
+		if strings.Contains(query[directivesStart:b.bodyStart], "@cascade") {
+			continue
+		}
+		query = query[:b.bodyStart] + directive + " " + query[b.bodyStart:]
+	}
+
+	if !rewritten {
+		return "", fmt.Errorf("query has no result blocks")
+	}
+	return query, nil
+}
+
+// queryVariable is a variable declared in a query header, e.g. `$first: int = 10`
+type queryVariable struct {
+	Name       string // including the leading $
+	Type       string
+	Default    string // the raw default value, if any
+	HasDefault bool
+}
+
+// Parse the variables declared by a `query name($var: type, ...)` header.
+// Queries without a header declare no variables.
+func parseQueryVariables(query string) ([]queryVariable, error) {
+	i := skipSpace(query, 0)
+	if !strings.HasPrefix(query[i:], "query") {
+		return nil, nil
+	}
+	body := strings.IndexByte(query, '{')
+	open := strings.IndexByte(query, '(')
+	if open < 0 || body >= 0 && open > body {
+		return nil, nil
+	}
+	end, err := matchingDelim(query, open)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the declarations on commas outside string literals
+	var decls []string
+	start := open + 1
+	for j := start; j < end; j++ {
+		switch query[j] {
+		case '"':
+			if j, err = skipString(query, j); err != nil {
+				return nil, err
+			}
+		case ',':
+			decls = append(decls, query[start:j])
+			start = j + 1
+		}
+	}
+	decls = append(decls, query[start:end])
+
+	var vars []queryVariable
+	for _, decl := range decls {
+		if strings.TrimSpace(decl) == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(decl, ":")
+		name = strings.TrimSpace(name)
+		if !ok || !strings.HasPrefix(name, "$") || validateName("variable", name[1:]) != nil {
+			return nil, fmt.Errorf("invalid variable declaration %q", strings.TrimSpace(decl))
+		}
+		v := queryVariable{Name: name}
+		typ, def, hasDefault := strings.Cut(rest, "=")
+		v.Type = strings.TrimSpace(typ)
+		if v.Type == "" {
+			return nil, fmt.Errorf("variable %s has no type", name)
+		}
+		if hasDefault {
+			v.Default, v.HasDefault = strings.TrimSpace(def), true
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQueryBlocks(t *testing.T) {
+	query := `query people($name: string) {
+		A as var(func: eq(name, "a \"quoted\" {brace}")) { uid } # comment with {
+		me(func: uid(A)) @filter(has(age)) {
+			name
+			friend { name }
+		}
+		total as count(func: has(name)) { count(uid) }
+	}`
+
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		t.Fatalf("parseQueryBlocks failed: %v", err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks, got %d: %+v", len(blocks), blocks)
+	}
+
+	want := []struct{ name, v, args string }{
+		{"var", "A", `func: eq(name, "a \"quoted\" {brace}")`},
+		{"me", "", "func: uid(A)"},
+		{"count", "total", "func: has(name)"},
+	}
+	for i, w := range want {
+		b := blocks[i]
+		if b.Name != w.name || b.Var != w.v || b.Args != w.args {
+			t.Errorf("block %d = {%q %q %q}, want {%q %q %q}", i, b.Name, b.Var, b.Args, w.name, w.v, w.args)
+		}
+	}
+}
+
+func TestParseQueryBlocksRegexp(t *testing.T) {
+	tests := []struct {
+		query string
+		args  []string
+	}{
+		{`{ q(func: regexp(name, /\(x/)) { name } }`, []string{`func: regexp(name, /\(x/)`}},
+		{`{ q(func: has(tag)) @filter(regexp(tag, /#go/)) { tag } }`, []string{"func: has(tag)"}},
+		{`{ q(func: regexp(path, /^a\/b{2}[)}]/i)) { path } }`, []string{`func: regexp(path, /^a\/b{2}[)}]/i)`}},
+		{"{ q(func: regexp(name,/^Al\\\\/)) { name }\n  r(func: has(x)) { x } }", []string{`func: regexp(name,/^Al\\/)`, "func: has(x)"}},
+		// A slash inside math() is a division, not a regular expression
+		{`{ var(func: has(a)) { x as a  y as b  z as math(x / y) } q(func: uid(z)) { val(z) } }`, []string{"func: has(a)", "func: uid(z)"}},
+	}
+	for _, tt := range tests {
+		blocks, err := parseQueryBlocks(tt.query)
+		if err != nil {
+			t.Errorf("parseQueryBlocks(%q) failed: %v", tt.query, err)
+			continue
+		}
+		var args []string
+		for _, b := range blocks {
+			args = append(args, b.Args)
+		}
+		if !reflect.DeepEqual(args, tt.args) {
+			t.Errorf("parseQueryBlocks(%q) args = %q, want %q", tt.query, args, tt.args)
+		}
+	}
+
+	if _, err := parseQueryBlocks(`{ q(func: regexp(name, /unterminated)) { name } }`); err == nil {
+		t.Errorf("parseQueryBlocks with an unterminated regular expression succeeded, want error")
+	}
+}
+
+func TestParseQueryBlocksErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"{}",
+		"{ me(func: has(name)) { name }",
+		"{ me(func: has(name) { name } }",
+		"{ a b c(func: has(name)) { name } }",
+	} {
+		if _, err := parseQueryBlocks(query); err == nil {
+			t.Errorf("parseQueryBlocks(%q) succeeded, want error", query)
+		}
+	}
+}
+
+func TestRewriteExistsOnly(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		names []string
+	}{
+		{
+			`{ me(func: has(name)) { name friend { name } } }`,
+			`{ me(func: has(name), first: 1) { uid } }`,
+			[]string{"me"},
+		},
+		{
+			`{ me(func: has(name), first: 50) @filter(eq(age, 30)) { name } }`,
+			`{ me(func: has(name), first: 1) @filter(eq(age, 30)) { uid } }`,
+			[]string{"me"},
+		},
+		{
+			`{ A as var(func: has(name)) { f as friend } q(func: uid(f)) { name } }`,
+			`{ A as var(func: has(name)) { f as friend } q(func: uid(f), first: 1) { uid } }`,
+			[]string{"q"},
+		},
+		{
+			`query q($n: int) { me(func: has(name), first: $n) { name } }`,
+			`query q($n: int) { me(func: has(name), first: 1) { uid } }`,
+			[]string{"me"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, names, err := rewriteExistsOnly(tt.query)
+		if err != nil {
+			t.Errorf("rewriteExistsOnly(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rewriteExistsOnly(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("rewriteExistsOnly(%q) names = %v, want %v", tt.query, names, tt.names)
+		}
+	}
+}
+
+func TestResultHasData(t *testing.T) {
+	tests := []struct {
+		json string
+		want bool
+	}{
+		{`{"me":[{"uid":"0x1"}]}`, true},
+		{`{"me":[]}`, false},
+		{`{}`, false},
+		{`{"other":[{"uid":"0x1"}],"me":[]}`, false},
+	}
+	for _, tt := range tests {
+		got, err := resultHasData([]byte(tt.json), []string{"me"})
+		if err != nil {
+			t.Errorf("resultHasData(%s) failed: %v", tt.json, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resultHasData(%s) = %t, want %t", tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestResultIsEmpty(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    bool
+		wantErr bool
+	}{
+		{json: `{"q":[]}`, want: true},
+		{json: `{ "a": [], "b": [] }`, want: true},
+		{json: `{}`, want: true},
+		{json: `{"a":[],"b":[{"uid":"0x1"}]}`, want: false},
+		{json: `{"total":[{"count":0}]}`, want: false},
+		{json: `{"schema":{"name":"x"}}`, want: false},
+		{json: `not json`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := resultIsEmpty([]byte(tt.json))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resultIsEmpty(%s) error = %v, wantErr %v", tt.json, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resultIsEmpty(%s) = %t, want %t", tt.json, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteExpandAll(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		names []string
+	}{
+		{
+			`{ me(func: has(name)) { friend { name } } }`,
+			`{ me(func: has(name)) { uid dgraph.type expand(_all_) friend { name } } }`,
+			[]string{"me"},
+		},
+		{
+			`{ me(func: has(name)) {} }`,
+			`{ me(func: has(name)) { uid dgraph.type expand(_all_)} }`,
+			[]string{"me"},
+		},
+		{
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) { expand(Person) } }`,
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) { expand(Person) } }`,
+			[]string{"q"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, names, err := rewriteExpandAll(tt.query)
+		if err != nil {
+			t.Errorf("rewriteExpandAll(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rewriteExpandAll(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+		}
+		if !reflect.DeepEqual(names, tt.names) {
+			t.Errorf("rewriteExpandAll(%q) names = %v, want %v", tt.query, names, tt.names)
+		}
+	}
+}
+
+func TestCountUntypedNodes(t *testing.T) {
+	data := []byte(`{"me":[{"uid":"0x1","dgraph.type":["Person"]},{"uid":"0x2"}],"other":[{"uid":"0x3"}]}`)
+	untyped, total, err := countUntypedNodes(data, []string{"me"})
+	if err != nil {
+		t.Fatalf("countUntypedNodes failed: %v", err)
+	}
+	if untyped != 1 || total != 2 {
+		t.Errorf("countUntypedNodes = %d, %d, want 1, 2", untyped, total)
+	}
+}
+
+func TestRewriteNormalize(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			`{ me(func: has(name)) { n: name friend { f: name } } }`,
+			`{ me(func: has(name)) @normalize { n: name friend { f: name } } }`,
+		},
+		{
+			`{ me(func: has(name)) @filter(has(age)) { n: name } }`,
+			`{ me(func: has(name)) @filter(has(age)) @normalize { n: name } }`,
+		},
+		{
+			`{ me(func: has(name)) @normalize { n: name } }`,
+			`{ me(func: has(name)) @normalize { n: name } }`,
+		},
+		{
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) { n : name@en } }`,
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) @normalize { n : name@en } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := rewriteNormalize(tt.query)
+		if err != nil {
+			t.Errorf("rewriteNormalize(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rewriteNormalize(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteNormalizeWithoutAlias(t *testing.T) {
+	for _, query := range []string{
+		`{ me(func: has(name)) { name } }`,
+		`{ me(func: has(name)) { friend(first: 10, orderasc: name) { name } } }`,
+		`{ me(func: has(name)) { name @filter(eq(name, "a: b")) } }`,
+	} {
+		if _, err := rewriteNormalize(query); err == nil {
+			t.Errorf("rewriteNormalize(%q) succeeded, want error", query)
+		}
+	}
+}
+
+func TestRewriteCascade(t *testing.T) {
+	tests := []struct {
+		query      string
+		predicates []string
+		want       string
+	}{
+		{
+			`{ me(func: has(name)) { name friend { name } } }`,
+			nil,
+			`{ me(func: has(name)) @cascade { name friend { name } } }`,
+		},
+		{
+			`{ me(func: has(name)) @filter(has(age)) { name email } }`,
+			[]string{"name", "email"},
+			`{ me(func: has(name)) @filter(has(age)) @cascade(name, email) { name email } }`,
+		},
+		{
+			`{ me(func: has(name)) @cascade(name) { name email } }`,
+			nil,
+			`{ me(func: has(name)) @cascade(name) { name email } }`,
+		},
+		{
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) { name } }`,
+			nil,
+			`{ f as var(func: has(name)) { uid } q(func: uid(f)) @cascade { name } }`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := rewriteCascade(tt.query, tt.predicates)
+		if err != nil {
+			t.Errorf("rewriteCascade(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("rewriteCascade(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+		}
+	}
+
+	if _, err := rewriteCascade(`{ me(func: has(name)) { name } }`, []string{"name) { x"}); err == nil {
+		t.Errorf("rewriteCascade with invalid predicate succeeded, want error")
+	}
+}
+
+func TestParseQueryVariables(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    []queryVariable
+		wantErr bool
+	}{
+		{name: "no header", query: `{ q(func: has(name)) { name } }`},
+		{name: "header without variables", query: `query q { q(func: has(name)) { name } }`},
+		{
+			name:  "variables",
+			query: `query q($name: string, $first: int = 10, $sep: string = "a, b") { q(func: eq(name, $name), first: $first) { name } }`,
+			want: []queryVariable{
+				{Name: "$name", Type: "string"},
+				{Name: "$first", Type: "int", Default: "10", HasDefault: true},
+				{Name: "$sep", Type: "string", Default: `"a, b"`, HasDefault: true},
+			},
+		},
+		{name: "missing dollar", query: `query q(name: string) { q(func: has(name)) { name } }`, wantErr: true},
+		{name: "missing type", query: `query q($name) { q(func: has(name)) { name } }`, wantErr: true},
+		{name: "unbalanced", query: `query q($name: string { }`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseQueryVariables(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseQueryVariables() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseQueryVariables() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/dgraph-io/dgo/v2"
 	"github.com/dgraph-io/dgo/v2/protos/api"
@@ -41,7 +45,7 @@ func main() {
 		mcp.WithString("search_type",
 			mcp.Description("Type of search: title, actor, director, or genre"),
 			mcp.Enum("title", "actor", "director", "genre", "any"),
-			mcp.Default("any"),
+			mcp.DefaultString("any"),
 		),
 	)
 
@@ -104,11 +108,33 @@ func setupMovieSchema(client *dgo.Dgraph) {
 	}
 	
 	// If no movies, add sample data
-	if string(resp.Json) == "{\"movies\":[]}" {
+	count, err := movieCount(resp.Json)
+	if err != nil {
+		log.Fatalf("Failed to count movies: %v", err)
+	}
+	if count == 0 {
 		addSampleMovies(client)
 	}
 }
 
+// Read the result of a { movies(func: ...) { count(uid) } } query. Dgraph
+// reports the count as [{"count": 0}] rather than an empty list, and may
+// format the JSON differently, so the result is parsed instead of compared.
+func movieCount(data []byte) (int, error) {
+	var result struct {
+		Movies []struct {
+			Count int `json:"count"`
+		} `json:"movies"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, fmt.Errorf("failed to parse count: %v", err)
+	}
+	if len(result.Movies) == 0 {
+		return 0, nil
+	}
+	return result.Movies[0].Count, nil
+}
+
 // Add some sample movies to the database
 func addSampleMovies(client *dgo.Dgraph) {
 	ctx := context.Background()
@@ -170,6 +196,36 @@ func addSampleMovies(client *dgo.Dgraph) {
 	log.Println("Sample movies added successfully")
 }
 
+// Build the search query for a search type. The search term is passed as
+// the $term variable rather than interpolated, so quotes and backslashes in
+// it can't break or inject into the query.
+func buildMovieSearchQuery(searchType string) string {
+	var filter, fields string
+	switch searchType {
+	case "title":
+		filter = "alloftext(title, $term)"
+	case "actor":
+		filter, fields = "allofterms(actors, $term)", "actors"
+	case "director":
+		filter = "allofterms(director, $term)"
+	case "genre":
+		filter, fields = "allofterms(genres, $term)", "genres"
+	default: // "any"
+		filter = "anyoftext(title director actors genres, $term)"
+	}
+
+	return fmt.Sprintf(`query search($term: string) {
+		movies(func: %s) {
+			uid
+			title
+			release_year
+			director
+			%s
+			rating
+		}
+	}`, filter, fields)
+}
+
 // Create handler for the movie search tool
 func createMovieSearchHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -177,94 +233,84 @@ func createMovieSearchHandler(client *dgo.Dgraph) func(ctx context.Context, requ
 		if !ok {
 			return nil, fmt.Errorf("search_term must be a string")
 		}
-		
+
 		searchType := "any"
 		if st, ok := request.Params.Arguments["search_type"].(string); ok {
 			searchType = st
 		}
-		
-		// Build query based on search type
-		var query string
-		switch searchType {
-		case "title":
-			query = fmt.Sprintf(`{
-				movies(func: alloftext(title, "%s")) {
-					uid
-					title
-					release_year
-					director
-					rating
-				}
-			}`, searchTerm)
-		case "actor":
-			query = fmt.Sprintf(`{
-				movies(func: allofterms(actors, "%s")) {
-					uid
-					title
-					release_year
-					director
-					actors
-					rating
-				}
-			}`, searchTerm)
-		case "director":
-			query = fmt.Sprintf(`{
-				movies(func: allofterms(director, "%s")) {
-					uid
-					title
-					release_year
-					director
-					rating
-				}
-			}`, searchTerm)
-		case "genre":
-			query = fmt.Sprintf(`{
-				movies(func: allofterms(genres, "%s")) {
-					uid
-					title
-					release_year
-					director
-					genres
-					rating
-				}
-			}`, searchTerm)
-		default: // "any"
-			query = fmt.Sprintf(`{
-				movies(func: anyoftext(title director actors genres, "%s")) {
-					uid
-					title
-					release_year
-					director
-					rating
-				}
-			}`, searchTerm)
-		}
-		
-		// Execute query
+
+		// Execute query with the search term as a variable
 		txn := client.NewTxn()
 		defer txn.Discard(ctx)
-		resp, err := txn.Query(ctx, query)
+		resp, err := txn.QueryWithVars(ctx, buildMovieSearchQuery(searchType), map[string]string{"$term": searchTerm})
 		if err != nil {
 			return nil, fmt.Errorf("query failed: %v", err)
 		}
-		
+
 		// Return the JSON result
 		return mcp.NewToolResultText(string(resp.Json)), nil
 	}
 }
 
+// Extract the movie uid from a movies://{id} request. The server fills in
+// the template variables when it matches the URI against the template;
+// otherwise the URI is parsed, ignoring query parameters and trailing slashes.
+func movieIDFromRequest(request mcp.ReadResourceRequest) (string, error) {
+	var id string
+	switch v := request.Params.Arguments["id"].(type) {
+	case string:
+		id = v
+	case []string:
+		if len(v) == 1 {
+			id = v[0]
+		}
+	default:
+		u, err := url.Parse(request.Params.URI)
+		if err != nil || u.Scheme != "movies" {
+			return "", fmt.Errorf("invalid movie URI %q, expected movies://{id}", request.Params.URI)
+		}
+		id = u.Host
+		if id == "" {
+			id = u.Opaque
+		}
+	}
+	id = strings.Trim(id, "/")
+
+	// Accept only uids, which are safe to embed in the query
+	uid, err := normalizeUID(id)
+	if err != nil {
+		return "", fmt.Errorf("invalid movie uid %q in %q, expected a uid such as movies://0x1 or movies://1", id, request.Params.URI)
+	}
+	return uid, nil
+}
+
+// Normalize a uid given as 0x-prefixed hex or decimal into the 0x form
+// uid() expects, so that a decimal uid doesn't silently match nothing
+func normalizeUID(uid string) (string, error) {
+	var (
+		n   uint64
+		err error
+	)
+	if hex, ok := strings.CutPrefix(strings.ToLower(uid), "0x"); ok {
+		n, err = strconv.ParseUint(hex, 16, 64)
+	} else {
+		n, err = strconv.ParseUint(uid, 10, 64)
+	}
+	if err != nil || n == 0 {
+		return "", fmt.Errorf("invalid uid %q: expected a non-zero 0x-prefixed hex or decimal value", uid)
+	}
+	return fmt.Sprintf("0x%x", n), nil
+}
+
 // Create handler for the movie details resource
 func createMovieDetailsHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		// Extract movie ID from the URI
-		// The URI format is "movies://{id}"
-		uri := request.Params.URI
-		if len(uri) < 9 { // "movies://" is 9 characters
-			return nil, fmt.Errorf("invalid URI format: %s", uri)
+		movieID, err := movieIDFromRequest(request)
+		if err != nil {
+			return nil, err
 		}
-		
-		movieID := uri[9:] // Extract the ID part
-		
+		uri := request.Params.URI
+
 		// Query for movie details
 		query := fmt.Sprintf(`{
 			movie(func: uid(%s)) {
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMovieIDFromRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		uri       string
+		arguments map[string]interface{}
+		want      string
+		wantErr   bool
+	}{
+		{name: "uid", uri: "movies://0x1", want: "0x1"},
+		{name: "template variable", uri: "movies://0x1", arguments: map[string]interface{}{"id": "0x1"}, want: "0x1"},
+		{name: "template variable list", uri: "movies://0x1", arguments: map[string]interface{}{"id": []string{"0x1"}}, want: "0x1"},
+		{name: "trailing slash", uri: "movies://0x2a/", want: "0x2a"},
+		{name: "query parameters", uri: "movies://0x2A?lang=en", want: "0x2a"},
+		{name: "leading zeros", uri: "movies://0x0001", want: "0x1"},
+		{name: "not a uid", uri: "movies://star-wars", wantErr: true},
+		{name: "decimal", uri: "movies://42", want: "0x2a"},
+		{name: "decimal template variable", uri: "movies://42", arguments: map[string]interface{}{"id": "42"}, want: "0x2a"},
+		{name: "hex without prefix", uri: "movies://2a", wantErr: true},
+		{name: "negative", uri: "movies://-1", wantErr: true},
+		{name: "too large", uri: "movies://0x10000000000000000", wantErr: true},
+		{name: "zero", uri: "movies://0x0", wantErr: true},
+		{name: "injection", uri: "movies://0x1) { uid } q(func: has(title)", wantErr: true},
+		{name: "missing id", uri: "movies://", wantErr: true},
+		{name: "wrong scheme", uri: "films://0x1", wantErr: true},
+		{name: "too short", uri: "movies:", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.ReadResourceRequest
+			request.Params.URI = tt.uri
+			request.Params.Arguments = tt.arguments
+
+			got, err := movieIDFromRequest(request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("movieIDFromRequest(%q) error = %v, wantErr %v", tt.uri, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("movieIDFromRequest(%q) = %q, want %q", tt.uri, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovieCount(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{data: `{"movies":[{"count":3}]}`, want: 3},
+		{data: `{"movies":[{"count":0}]}`, want: 0},
+		{data: `{ "movies": [ { "count": 0 } ] }`, want: 0},
+		{data: `{"movies":[]}`, want: 0},
+		{data: `{}`, want: 0},
+		{data: `not json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := movieCount([]byte(tt.data))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("movieCount(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("movieCount(%s) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Functions listed as searchable in the schema explanation, in the order
+// they are mentioned
+var explainedFuncs = []string{"eq", "lt", "le", "gt", "ge", "allofterms", "anyofterms", "alloftext", "anyoftext", "regexp", "match", "near", "within", "contains", "intersects"}
+
+// List the indexed functions a predicate can be searched with
+func searchFunctions(p predicateSchema) []string {
+	if !p.Index {
+		return nil
+	}
+	var fns []string
+	for _, fn := range explainedFuncs {
+		// Geo predicates are only searched by geo functions
+		if p.Type == "geo" && funcTokenizers[fn][0] != "geo" {
+			continue
+		}
+		if _, missing := missingTokenizer(p, fn); !missing {
+			fns = append(fns, fn)
+		}
+	}
+	return fns
+}
+
+// Find the type with the most edges to other nodes, breaking ties by the
+// number of fields and then by name
+func mostConnectedType(schema *schemaInfo) (typeSchema, bool) {
+	var best typeSchema
+	bestEdges, found := -1, false
+	for _, t := range schema.Types {
+		if isInternalName(t.Name) {
+			continue
+		}
+		edges := 0
+		for _, f := range t.Fields {
+			if p, ok := schema.predicate(f.Name); ok && p.Type == "uid" {
+				edges++
+			}
+		}
+		better := edges > bestEdges ||
+			edges == bestEdges && len(t.Fields) > len(best.Fields) ||
+			edges == bestEdges && len(t.Fields) == len(best.Fields) && t.Name < best.Name
+		if !found || better {
+			best, bestEdges, found = t, edges, true
+		}
+	}
+	return best, found
+}
+
+// Describe a scalar predicate, e.g. `name` (string, searchable with eq, allofterms)
+func describeScalar(p predicateSchema) string {
+	var notes []string
+	typ := p.Type
+	if p.List {
+		typ = "[" + typ + "]"
+	}
+	notes = append(notes, typ)
+	if p.Lang {
+		notes = append(notes, "language tags")
+	}
+	if p.Upsert {
+		notes = append(notes, "unique key")
+	}
+	if fns := searchFunctions(p); len(fns) > 0 {
+		notes = append(notes, "searchable with "+strings.Join(fns, ", "))
+	}
+	return fmt.Sprintf("`%s` (%s)", p.Predicate, strings.Join(notes, ", "))
+}
+
+// Describe an edge predicate, e.g. `friend` (many, reverse ~friend)
+func describeEdge(p predicateSchema) string {
+	notes := []string{"one"}
+	if p.List {
+		notes[0] = "many"
+	}
+	if p.Reverse {
+		notes = append(notes, "reverse ~"+p.Predicate)
+	}
+	if p.Count {
+		notes = append(notes, "countable")
+	}
+	return fmt.Sprintf("`%s` (%s)", p.Predicate, strings.Join(notes, ", "))
+}
+
+// Summarize a schema in prose: its types with their fields and edges, the
+// searchable predicates, and example queries for the most connected type
+func explainSchema(schema *schemaInfo) string {
+	var predicates []predicateSchema
+	typed := map[string]bool{}
+	indexed := 0
+	for _, p := range schema.Predicates {
+		if isInternalName(p.Predicate) {
+			continue
+		}
+		predicates = append(predicates, p)
+		if p.Index {
+			indexed++
+		}
+	}
+	sort.Slice(predicates, func(i, j int) bool { return predicates[i].Predicate < predicates[j].Predicate })
+
+	var types []typeSchema
+	for _, t := range schema.Types {
+		if !isInternalName(t.Name) {
+			types = append(types, t)
+		}
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].Name < types[j].Name })
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "The database has %d types and %d predicates, %d of them indexed.\n", len(types), len(predicates), indexed)
+	if len(predicates) == 0 {
+		b.WriteString("\nThe schema is empty; define predicates and types with dgraph_alter_schema.\n")
+		return b.String()
+	}
+
+	if len(types) > 0 {
+		b.WriteString("\n## Types\n")
+	}
+	for _, t := range types {
+		var scalars, edges []string
+		for _, f := range t.Fields {
+			typed[f.Name] = true
+			p, ok := schema.predicate(f.Name)
+			switch {
+			case !ok:
+				scalars = append(scalars, fmt.Sprintf("`%s` (not in the schema)", f.Name))
+			case p.Type == "uid":
+				edges = append(edges, describeEdge(p))
+			default:
+				scalars = append(scalars, describeScalar(p))
+			}
+		}
+		fmt.Fprintf(&b, "\n### %s\n", t.Name)
+		if len(scalars) > 0 {
+			fmt.Fprintf(&b, "Fields: %s.\n", strings.Join(scalars, "; "))
+		}
+		if len(edges) > 0 {
+			fmt.Fprintf(&b, "Edges to other nodes: %s.\n", strings.Join(edges, "; "))
+		}
+		if len(t.Fields) == 0 {
+			b.WriteString("No fields.\n")
+		}
+	}
+
+	var untyped []string
+	for _, p := range predicates {
+		if typed[p.Predicate] {
+			continue
+		}
+		if p.Type == "uid" {
+			untyped = append(untyped, describeEdge(p))
+		} else {
+			untyped = append(untyped, describeScalar(p))
+		}
+	}
+	if len(untyped) > 0 {
+		fmt.Fprintf(&b, "\nPredicates not in any type, which expand(_all_) won't return: %s.\n", strings.Join(untyped, "; "))
+	}
+
+	var searchable []string
+	for _, p := range predicates {
+		if fns := searchFunctions(p); len(fns) > 0 {
+			searchable = append(searchable, fmt.Sprintf("- `%s`: %s", p.Predicate, strings.Join(fns, ", ")))
+		}
+	}
+	b.WriteString("\n## Searchable predicates\n")
+	if len(searchable) == 0 {
+		b.WriteString("No predicate is indexed, so queries can only start from uid(), type() or has().\n")
+	} else {
+		b.WriteString("Root functions and filters other than uid(), type() and has() need an index:\n")
+		b.WriteString(strings.Join(searchable, "\n") + "\n")
+	}
+
+	if t, ok := mostConnectedType(schema); ok {
+		fmt.Fprintf(&b, "\n## Example queries for %s\n", t.Name)
+		b.WriteString(exampleQueries(schema, t))
+	}
+	return b.String()
+}
+
+// Write example queries for a type: listing its nodes, searching them by an
+// indexed field and following its edges
+func exampleQueries(schema *schemaInfo, t typeSchema) string {
+	var scalars, edges []predicateSchema
+	for _, f := range t.Fields {
+		if p, ok := schema.predicate(f.Name); ok {
+			if p.Type == "uid" {
+				edges = append(edges, p)
+			} else {
+				scalars = append(scalars, p)
+			}
+		}
+	}
+	fields := "uid"
+	for _, p := range scalars {
+		fields += " " + p.Predicate
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "List nodes:\n```\n{ q(func: type(%s), first: 10) { %s } }\n```\n", t.Name, fields)
+
+	for _, p := range scalars {
+		fns := searchFunctions(p)
+		if len(fns) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "Search by %s:\n```\n{ q(func: %s(%s, %s), first: 10) @filter(type(%s)) { %s } }\n```\n", p.Predicate, fns[0], p.Predicate, exampleValue(p, fns[0]), t.Name, fields)
+		break
+	}
+
+	if len(edges) > 0 {
+		var sub []string
+		for _, p := range edges {
+			sub = append(sub, fmt.Sprintf("%s { uid dgraph.type }", p.Predicate))
+		}
+		fmt.Fprintf(&b, "Follow edges:\n```\n{ q(func: type(%s), first: 10) { %s %s } }\n```\n", t.Name, fields, strings.Join(sub, " "))
+	}
+	for _, p := range edges {
+		if p.Reverse {
+			fmt.Fprintf(&b, "Follow %s backwards:\n```\n{ q(func: type(%s), first: 10) { uid ~%s { uid dgraph.type } } }\n```\n", p.Predicate, t.Name, p.Predicate)
+			break
+		}
+	}
+	return b.String()
+}
+
+// Return a placeholder argument for a function on a predicate
+func exampleValue(p predicateSchema, fn string) string {
+	switch {
+	case fn == "regexp":
+		return "/pattern/i"
+	case fn == "match":
+		return `"value", 8`
+	case fn == "near":
+		return "[-122.4, 37.8], 1000"
+	case fn == "within" || fn == "contains" || fn == "intersects":
+		return "[[[-122.5, 37.7], [-122.3, 37.7], [-122.3, 37.9], [-122.5, 37.7]]]"
+	case p.Type == "int" || p.Type == "float":
+		return "0"
+	case p.Type == "bool":
+		return "true"
+	case p.Type == "datetime":
+		return `"2024-01-01"`
+	default:
+		return `"value"`
+	}
+}
+
+// Create handler for the explain schema tool
+func createExplainSchemaHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		return mcp.NewToolResultText(explainSchema(schema)), nil
+	}
+}
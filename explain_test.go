@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSearchFunctions(t *testing.T) {
+	tests := []struct {
+		name string
+		p    predicateSchema
+		want []string
+	}{
+		{name: "not indexed", p: predicateSchema{Predicate: "bio", Type: "string"}},
+		{name: "hash", p: predicateSchema{Predicate: "email", Type: "string", Index: true, Tokenizer: []string{"hash"}}, want: []string{"eq"}},
+		{name: "exact and term", p: predicateSchema{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}}, want: []string{"eq", "lt", "le", "gt", "ge", "allofterms", "anyofterms"}},
+		{name: "trigram", p: predicateSchema{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"trigram"}}, want: []string{"regexp", "match"}},
+		{name: "int", p: predicateSchema{Predicate: "age", Type: "int", Index: true, Tokenizer: []string{"int"}}, want: []string{"eq", "lt", "le", "gt", "ge"}},
+		{name: "geo", p: predicateSchema{Predicate: "loc", Type: "geo", Index: true, Tokenizer: []string{"geo"}}, want: []string{"near", "within", "contains", "intersects"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchFunctions(tt.p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("searchFunctions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMostConnectedType(t *testing.T) {
+	schema := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "name", Type: "string"},
+			{Predicate: "friend", Type: "uid", List: true},
+			{Predicate: "works_at", Type: "uid"},
+		},
+		Types: []typeSchema{
+			{Name: "Company", Fields: []typeField{{Name: "name"}}},
+			{Name: "Person", Fields: []typeField{{Name: "name"}, {Name: "friend"}, {Name: "works_at"}}},
+			{Name: "dgraph.graphql", Fields: []typeField{{Name: "friend"}, {Name: "works_at"}, {Name: "name"}}},
+		},
+	}
+	got, ok := mostConnectedType(schema)
+	if !ok || got.Name != "Person" {
+		t.Errorf("mostConnectedType() = %q, %v, want Person", got.Name, ok)
+	}
+
+	if _, ok := mostConnectedType(&schemaInfo{}); ok {
+		t.Error("mostConnectedType() found a type in an empty schema")
+	}
+}
+
+func TestExplainSchema(t *testing.T) {
+	schema := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"term"}, Lang: true},
+			{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+			{Predicate: "works_at", Type: "uid"},
+			{Predicate: "legacy_id", Type: "int"},
+			{Predicate: "dgraph.type", Type: "string", Index: true, Tokenizer: []string{"exact"}, List: true},
+		},
+		Types: []typeSchema{
+			{Name: "Company", Fields: []typeField{{Name: "name"}}},
+			{Name: "Person", Fields: []typeField{{Name: "name"}, {Name: "friend"}, {Name: "works_at"}}},
+		},
+	}
+
+	got := explainSchema(schema)
+	for _, want := range []string{
+		"The database has 2 types and 4 predicates, 1 of them indexed.",
+		"### Person\nFields: `name` (string, language tags, searchable with eq, allofterms, anyofterms).\nEdges to other nodes: `friend` (many, reverse ~friend); `works_at` (one).",
+		"Predicates not in any type, which expand(_all_) won't return: `legacy_id` (int).",
+		"- `name`: eq, allofterms, anyofterms",
+		"## Example queries for Person",
+		`{ q(func: eq(name, "value"), first: 10) @filter(type(Person)) { uid name } }`,
+		"{ q(func: type(Person), first: 10) { uid name friend { uid dgraph.type } works_at { uid dgraph.type } } }",
+		"~friend { uid dgraph.type }",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("explainSchema() is missing %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "dgraph.type`") {
+		t.Errorf("explainSchema() mentions internal predicates:\n%s", got)
+	}
+
+	if got := explainSchema(&schemaInfo{}); !strings.Contains(got, "The schema is empty") {
+		t.Errorf("explainSchema() of an empty schema = %q", got)
+	}
+}
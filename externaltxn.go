@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+)
+
+// txnContextOption lets a query or mutation tool join an externally managed
+// transaction
+var txnContextOption = mcp.WithObject("txn_context",
+	mcp.Description("Join a transaction managed by an external coordinator, given its context {\"start_ts\": 123} and optionally the keys and preds returned by earlier mutations. The server never commits or aborts it (optional)"),
+)
+
+// externalTxnKey is the context key of the transaction context of an
+// externally managed transaction a request joins
+type externalTxnKey struct{}
+
+// Run the Dgraph requests made with ctx in an externally managed transaction
+func withExternalTxn(ctx context.Context, txnCtx *api.TxnContext) context.Context {
+	return context.WithValue(ctx, externalTxnKey{}, txnCtx)
+}
+
+// externalTxnClient is a Dgraph connection that runs requests at the start
+// timestamp of an externally managed transaction when their context carries
+// one. dgo can't join a transaction it didn't start, so the timestamp is set
+// below it. Such transactions are never committed or aborted here: that is
+// left to their coordinator.
+type externalTxnClient struct {
+	api.DgraphClient
+}
+
+func (c externalTxnClient) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	if txnCtx, ok := ctx.Value(externalTxnKey{}).(*api.TxnContext); ok {
+		in.StartTs = txnCtx.StartTs
+		in.CommitNow = false
+	}
+	return c.DgraphClient.Query(ctx, in, opts...)
+}
+
+func (c externalTxnClient) CommitOrAbort(ctx context.Context, in *api.TxnContext, opts ...grpc.CallOption) (*api.TxnContext, error) {
+	if _, ok := ctx.Value(externalTxnKey{}).(*api.TxnContext); ok {
+		return nil, fmt.Errorf("externally managed transactions are committed or aborted by their coordinator")
+	}
+	return c.DgraphClient.CommitOrAbort(ctx, in, opts...)
+}
+
+// Read the transaction context of an externally managed transaction from
+// the txn_context argument, or nil without one. It needs the start_ts the
+// coordinator got from Dgraph, and may carry the keys and preds of earlier
+// requests to merge new ones into. Committed or aborted contexts are rejected.
+func txnContextArgument(request mcp.CallToolRequest) (*api.TxnContext, error) {
+	value, ok := request.Params.Arguments["txn_context"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("txn_context must be an object")
+	}
+
+	txnCtx := &api.TxnContext{}
+	for key, v := range obj {
+		switch key {
+		case "start_ts":
+			ts, ok := v.(float64)
+			if !ok || ts < 1 || ts != math.Trunc(ts) || ts > 1<<53 {
+				return nil, fmt.Errorf("txn_context.start_ts must be a positive integer timestamp")
+			}
+			txnCtx.StartTs = uint64(ts)
+		case "commit_ts":
+			if ts, ok := v.(float64); !ok || ts != 0 {
+				return nil, fmt.Errorf("txn_context has a commit_ts, so the transaction is already committed")
+			}
+		case "aborted":
+			if aborted, ok := v.(bool); !ok || aborted {
+				return nil, fmt.Errorf("txn_context is aborted")
+			}
+		case "keys", "preds":
+			items, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("txn_context.%s must be a list of strings", key)
+			}
+			list := make([]string, len(items))
+			for i, item := range items {
+				if list[i], ok = item.(string); !ok {
+					return nil, fmt.Errorf("txn_context.%s must be a list of strings", key)
+				}
+			}
+			if key == "keys" {
+				txnCtx.Keys = list
+			} else {
+				txnCtx.Preds = list
+			}
+		default:
+			return nil, fmt.Errorf("txn_context has unknown field %q", key)
+		}
+	}
+	if txnCtx.StartTs == 0 {
+		return nil, fmt.Errorf("txn_context.start_ts is required")
+	}
+	return txnCtx, nil
+}
+
+// Merge the keys and preds a request added to an external transaction into
+// its context, which the coordinator needs to commit it
+func mergeTxnContext(txnCtx, resp *api.TxnContext) map[string]interface{} {
+	merge := func(a, b []string) []string {
+		seen := map[string]bool{}
+		merged := []string{}
+		for _, s := range append(append([]string{}, a...), b...) {
+			if !seen[s] {
+				seen[s] = true
+				merged = append(merged, s)
+			}
+		}
+		sort.Strings(merged)
+		return merged
+	}
+	var keys, preds []string
+	if resp != nil {
+		keys, preds = resp.Keys, resp.Preds
+	}
+	return map[string]interface{}{
+		"start_ts": txnCtx.StartTs,
+		"keys":     merge(txnCtx.Keys, keys),
+		"preds":    merge(txnCtx.Preds, preds),
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+)
+
+// recordingDgraphClient answers every request and records the last one
+type recordingDgraphClient struct {
+	api.DgraphClient
+	last      *api.Request
+	committed bool
+}
+
+func (c *recordingDgraphClient) Query(ctx context.Context, in *api.Request, opts ...grpc.CallOption) (*api.Response, error) {
+	c.last = in
+	return &api.Response{Json: []byte("{}"), Txn: &api.TxnContext{StartTs: in.StartTs, Keys: []string{"k2"}, Preds: []string{"1-name"}}}, nil
+}
+
+func (c *recordingDgraphClient) CommitOrAbort(ctx context.Context, in *api.TxnContext, opts ...grpc.CallOption) (*api.TxnContext, error) {
+	c.committed = true
+	return in, nil
+}
+
+func TestTxnContextArgument(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		want    *api.TxnContext
+		wantErr bool
+	}{
+		{name: "absent"},
+		{name: "start_ts", value: map[string]interface{}{"start_ts": float64(42)}, want: &api.TxnContext{StartTs: 42}},
+		{
+			name:  "keys and preds",
+			value: map[string]interface{}{"start_ts": float64(42), "commit_ts": float64(0), "aborted": false, "keys": []interface{}{"k1"}, "preds": []interface{}{"1-name"}},
+			want:  &api.TxnContext{StartTs: 42, Keys: []string{"k1"}, Preds: []string{"1-name"}},
+		},
+		{name: "not an object", value: "42", wantErr: true},
+		{name: "missing start_ts", value: map[string]interface{}{"keys": []interface{}{}}, wantErr: true},
+		{name: "zero start_ts", value: map[string]interface{}{"start_ts": float64(0)}, wantErr: true},
+		{name: "fractional start_ts", value: map[string]interface{}{"start_ts": 1.5}, wantErr: true},
+		{name: "string start_ts", value: map[string]interface{}{"start_ts": "42"}, wantErr: true},
+		{name: "committed", value: map[string]interface{}{"start_ts": float64(42), "commit_ts": float64(43)}, wantErr: true},
+		{name: "aborted", value: map[string]interface{}{"start_ts": float64(42), "aborted": true}, wantErr: true},
+		{name: "bad keys", value: map[string]interface{}{"start_ts": float64(42), "keys": []interface{}{1}}, wantErr: true},
+		{name: "unknown field", value: map[string]interface{}{"start_ts": float64(42), "read_only": true}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = map[string]interface{}{}
+			if tt.value != nil {
+				request.Params.Arguments["txn_context"] = tt.value
+			}
+			got, err := txnContextArgument(request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("txnContextArgument() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("txnContextArgument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTxnContext(t *testing.T) {
+	got := mergeTxnContext(
+		&api.TxnContext{StartTs: 42, Keys: []string{"k2", "k1"}, Preds: []string{"1-name"}},
+		&api.TxnContext{StartTs: 42, Keys: []string{"k1", "k3"}, Preds: []string{"1-age"}},
+	)
+	want := map[string]interface{}{
+		"start_ts": uint64(42),
+		"keys":     []string{"k1", "k2", "k3"},
+		"preds":    []string{"1-age", "1-name"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeTxnContext() = %v, want %v", got, want)
+	}
+
+	got = mergeTxnContext(&api.TxnContext{StartTs: 42}, nil)
+	if !reflect.DeepEqual(got["keys"], []string{}) {
+		t.Errorf("mergeTxnContext() keys = %v, want none", got["keys"])
+	}
+}
+
+func TestExternalTxnClient(t *testing.T) {
+	conn := &recordingDgraphClient{}
+	client := dgo.NewDgraphClient(externalTxnClient{conn})
+	ctx := withExternalTxn(context.Background(), &api.TxnContext{StartTs: 42})
+
+	txn := client.NewTxn()
+	if _, err := txn.Mutate(ctx, &api.Mutation{SetNquads: []byte(`_:a <name> "A" .`), CommitNow: true}); err != nil {
+		t.Fatalf("Mutate failed: %v", err)
+	}
+	if conn.last.StartTs != 42 || conn.last.CommitNow {
+		t.Errorf("request start_ts = %d, commit_now = %v, want 42 and false", conn.last.StartTs, conn.last.CommitNow)
+	}
+	if err := txn.Commit(ctx); err == nil {
+		t.Errorf("Commit of an external transaction succeeded, want error")
+	}
+	if conn.committed {
+		t.Errorf("external transaction was committed")
+	}
+
+	// Requests without an external transaction pass through unchanged
+	if _, err := client.NewTxn().Query(context.Background(), "{ q(func: has(name)) { uid } }"); err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if conn.last.StartTs != 0 {
+		t.Errorf("request start_ts = %d, want 0", conn.last.StartTs)
+	}
+}
+
+func TestQueryTxnContextReadOnly(t *testing.T) {
+	conn := &recordingDgraphClient{}
+	handler := createQueryHandler(dgo.NewDgraphClient(conn), nil, nil, queryLimits{}, true)
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = map[string]interface{}{
+		"query":       `{ q(func: uid(0x1)) { name } }`,
+		"txn_context": map[string]interface{}{"start_ts": float64(42)},
+	}
+	if _, err := handler(context.Background(), request); err == nil || !strings.Contains(err.Error(), "read-only mode") {
+		t.Errorf("query with txn_context in read-only mode = %v, want a read-only error", err)
+	}
+	if conn.last != nil {
+		t.Errorf("query reached Dgraph in read-only mode")
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Number of valid fields listed when a requested field is unknown
+const maxListedFields = 50
+
+// Check requested fields against the schema. Fields may carry a language
+// suffix such as name@fr. Unknown fields are rejected, listing the fields
+// that exist, since Dgraph silently leaves them out of the result.
+func checkSchemaFields(schema *schemaInfo, fields []string, param string) error {
+	var unknown []string
+	for _, f := range fields {
+		name, _, _ := strings.Cut(f, "@")
+		if name == "uid" || name == "dgraph.type" {
+			continue
+		}
+		if _, ok := schema.predicate(name); !ok {
+			unknown = append(unknown, f)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	valid := []string{"uid", "dgraph.type"}
+	for _, p := range schema.Predicates {
+		if !isInternalName(p.Predicate) && predicateAccess.check(p.Predicate) == nil {
+			valid = append(valid, p.Predicate)
+		}
+	}
+	sort.Strings(valid[2:])
+	listed := strings.Join(valid, ", ")
+	if len(valid) > maxListedFields {
+		listed = fmt.Sprintf("%s and %d more", strings.Join(valid[:maxListedFields], ", "), len(valid)-maxListedFields)
+	}
+	return fmt.Errorf("unknown %s %s; valid fields are %s", param, strings.Join(unknown, ", "), listed)
+}
+
+// Read an optional list of fields to return, checked against the schema.
+// Returns nil when the argument isn't given.
+func fieldsArgument(ctx context.Context, client *dgo.Dgraph, request mcp.CallToolRequest, param string) ([]string, error) {
+	fields, err := stringsArgument(request, param)
+	if err != nil || len(fields) == 0 {
+		return fields, err
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return nil, err
+		}
+	}
+
+	refresh, err := boolArgument(request, "refresh", false)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := fetchSchema(ctx, client, refresh)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaFields(schema, fields, param); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchemaFields(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string"},
+		{Predicate: "age", Type: "int"},
+		{Predicate: "dgraph.xid", Type: "string"},
+	}}
+
+	if err := checkSchemaFields(schema, []string{"uid", "dgraph.type", "name@fr:.", "age"}, "fields"); err != nil {
+		t.Errorf("checkSchemaFields() = %v, want nil", err)
+	}
+
+	err := checkSchemaFields(schema, []string{"name", "nmae", "agee"}, "fields")
+	want := "unknown fields nmae, agee; valid fields are uid, dgraph.type, age, name"
+	if err == nil || err.Error() != want {
+		t.Errorf("checkSchemaFields() = %v, want %q", err, want)
+	}
+
+	// Long schemas are cut short in the error
+	many := &schemaInfo{}
+	for i := 0; i < 60; i++ {
+		many.Predicates = append(many.Predicates, predicateSchema{Predicate: fmt.Sprintf("p%02d", i), Type: "string"})
+	}
+	err = checkSchemaFields(many, []string{"missing"}, "fields")
+	if err == nil || !strings.HasSuffix(err.Error(), "p47 and 12 more") {
+		t.Errorf("checkSchemaFields() = %v, want the list cut after 50 fields", err)
+	}
+}
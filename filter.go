@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// filterOption gives a query tool a structured filter
+var filterOption = mcp.WithObject("filter",
+	mcp.Description("A structured filter compiled into @filter: a condition {\"predicate\": \"age\", \"op\": \"ge\", \"value\": 18} with op eq, lt, le, gt, ge, between (value [low, high]), allofterms, anyofterms or has (no value), or a group {\"and\": [...]}, {\"or\": [...]} or {\"not\": {...}} of filters (optional)"),
+)
+
+// filterOps are the operators of structured filter conditions, with the
+// number of values each takes
+var filterOps = map[string]int{
+	"eq": 1, "lt": 1, "le": 1, "gt": 1, "ge": 1,
+	"between": 2, "allofterms": 1, "anyofterms": 1, "has": 0,
+}
+
+// compiledFilter is a structured filter compiled into a @filter expression
+// whose values are passed as query variables
+type compiledFilter struct {
+	Expr  string            // the expression inside @filter(...)
+	Vars  map[string]string // variable values by name, e.g. $f0
+	decls []string          // variable declarations, e.g. $f0: int
+}
+
+// Compile a structured filter. A filter is a condition
+// {"predicate": "age", "op": "ge", "value": 18} or a group
+// {"and": [...]}, {"or": [...]} or {"not": {...}} of filters; a list of
+// filters is an and group. Values never end up in the query text, so
+// they need no escaping.
+func compileFilter(filter interface{}) (compiledFilter, error) {
+	c := compiledFilter{Vars: map[string]string{}}
+	expr, err := c.compile(filter, "filter")
+	if err != nil {
+		return compiledFilter{}, err
+	}
+	c.Expr = expr
+	return c, nil
+}
+
+// Read an optional structured filter argument
+func filterArgument(request mcp.CallToolRequest, name string) (compiledFilter, error) {
+	value, ok := request.Params.Arguments[name]
+	if !ok || value == nil {
+		return compiledFilter{}, nil
+	}
+	return compileFilter(value)
+}
+
+// Compile a filter or group found at path, which locates errors
+func (c *compiledFilter) compile(filter interface{}, path string) (string, error) {
+	if items, ok := filter.([]interface{}); ok {
+		return c.group(items, "and", path)
+	}
+	obj, ok := filter.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("%s must be an object", path)
+	}
+
+	for _, op := range []string{"and", "or", "not"} {
+		value, ok := obj[op]
+		if !ok {
+			continue
+		}
+		if len(obj) != 1 {
+			return "", fmt.Errorf("%s: %s cannot be combined with other fields", path, op)
+		}
+		if op == "not" {
+			expr, err := c.compile(value, path+".not")
+			if err != nil {
+				return "", err
+			}
+			return "NOT " + expr, nil
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("%s.%s must be a list of filters", path, op)
+		}
+		return c.group(items, op, path+"."+op)
+	}
+	return c.condition(obj, path)
+}
+
+// Compile the filters of an and or or group, in parentheses
+func (c *compiledFilter) group(items []interface{}, op, path string) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("%s must not be empty", path)
+	}
+	exprs := make([]string, len(items))
+	for i, item := range items {
+		expr, err := c.compile(item, fmt.Sprintf("%s[%d]", path, i))
+		if err != nil {
+			return "", err
+		}
+		exprs[i] = expr
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return "(" + strings.Join(exprs, " "+strings.ToUpper(op)+" ") + ")", nil
+}
+
+// Compile a single condition into a function call
+func (c *compiledFilter) condition(obj map[string]interface{}, path string) (string, error) {
+	for key := range obj {
+		if key != "predicate" && key != "op" && key != "value" {
+			return "", fmt.Errorf("%s has unknown field %q; use predicate, op and value, or and, or and not", path, key)
+		}
+	}
+	predicate, ok := obj["predicate"].(string)
+	if !ok {
+		return "", fmt.Errorf("%s.predicate must be a string", path)
+	}
+	if err := validateField("predicate", predicate); err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+	op, ok := obj["op"].(string)
+	if !ok {
+		return "", fmt.Errorf("%s.op must be a string", path)
+	}
+	arity, ok := filterOps[op]
+	if !ok {
+		return "", fmt.Errorf("%s: unknown op %q; use eq, lt, le, gt, ge, between, allofterms, anyofterms or has", path, op)
+	}
+
+	var values []interface{}
+	switch value, given := obj["value"]; {
+	case arity == 0:
+		if given {
+			return "", fmt.Errorf("%s: %s takes no value", path, op)
+		}
+		return fmt.Sprintf("%s(%s)", op, predicate), nil
+	case arity == 2:
+		if values, ok = value.([]interface{}); !ok || len(values) != 2 {
+			return "", fmt.Errorf("%s: between needs a value of two bounds, e.g. [18, 30]", path)
+		}
+	default:
+		values = []interface{}{value}
+	}
+
+	args := []string{predicate}
+	for _, v := range values {
+		name, err := c.variable(v)
+		if err != nil {
+			return "", fmt.Errorf("%s.value: %v", path, err)
+		}
+		args = append(args, name)
+	}
+	return fmt.Sprintf("%s(%s)", op, strings.Join(args, ", ")), nil
+}
+
+// Declare a variable holding a value, typed after its JSON type
+func (c *compiledFilter) variable(value interface{}) (string, error) {
+	var typ, text string
+	switch v := value.(type) {
+	case string:
+		typ, text = "string", v
+	case bool:
+		typ, text = "bool", strconv.FormatBool(v)
+	case float64:
+		if v == float64(int64(v)) {
+			typ, text = "int", strconv.FormatInt(int64(v), 10)
+		} else {
+			typ, text = "float", strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case nil:
+		return "", fmt.Errorf("value is required")
+	default:
+		return "", fmt.Errorf("value must be a string, number or boolean")
+	}
+	name := fmt.Sprintf("$f%d", len(c.decls))
+	c.decls = append(c.decls, name+": "+typ)
+	c.Vars[name] = text
+	return name, nil
+}
+
+// Return the @filter directive, or "" for an empty filter
+func (c compiledFilter) directive() string {
+	if c.Expr == "" {
+		return ""
+	}
+	return " @filter(" + c.Expr + ")"
+}
+
+// Declare the filter's variables in front of a query body
+func (c compiledFilter) withHeader(query string) string {
+	if len(c.decls) == 0 {
+		return query
+	}
+	return "query filtered(" + strings.Join(c.decls, ", ") + ") " + query
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter interface{}
+		expr   string
+		vars   map[string]string
+		decls  []string
+	}{
+		{
+			"condition",
+			map[string]interface{}{"predicate": "age", "op": "ge", "value": float64(18)},
+			"ge(age, $f0)", map[string]string{"$f0": "18"}, []string{"$f0: int"},
+		},
+		{
+			"between",
+			map[string]interface{}{"predicate": "rating", "op": "between", "value": []interface{}{3.5, float64(5)}},
+			"between(rating, $f0, $f1)", map[string]string{"$f0": "3.5", "$f1": "5"}, []string{"$f0: float", "$f1: int"},
+		},
+		{
+			"has",
+			map[string]interface{}{"predicate": "email", "op": "has"},
+			"has(email)", map[string]string{}, nil,
+		},
+		{
+			"nested groups",
+			map[string]interface{}{"and": []interface{}{
+				map[string]interface{}{"predicate": "name@en", "op": "allofterms", "value": `Jane "JJ" Doe`},
+				map[string]interface{}{"or": []interface{}{
+					map[string]interface{}{"predicate": "active", "op": "eq", "value": true},
+					map[string]interface{}{"not": map[string]interface{}{"predicate": "banned", "op": "has"}},
+				}},
+			}},
+			"(allofterms(name@en, $f0) AND (eq(active, $f1) OR NOT has(banned)))",
+			map[string]string{"$f0": `Jane "JJ" Doe`, "$f1": "true"}, []string{"$f0: string", "$f1: bool"},
+		},
+		{
+			"list is an and group",
+			[]interface{}{
+				map[string]interface{}{"predicate": "a", "op": "lt", "value": float64(1)},
+				map[string]interface{}{"predicate": "b", "op": "gt", "value": float64(2)},
+			},
+			"(lt(a, $f0) AND gt(b, $f1))", map[string]string{"$f0": "1", "$f1": "2"}, []string{"$f0: int", "$f1: int"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := compileFilter(tt.filter)
+			if err != nil {
+				t.Fatalf("compileFilter() failed: %v", err)
+			}
+			if c.Expr != tt.expr || !reflect.DeepEqual(c.Vars, tt.vars) || !reflect.DeepEqual(c.decls, tt.decls) {
+				t.Errorf("compileFilter() = %q %v %v, want %q %v %v", c.Expr, c.Vars, c.decls, tt.expr, tt.vars, tt.decls)
+			}
+		})
+	}
+}
+
+func TestCompileFilterErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  interface{}
+		wantErr string
+	}{
+		{"not an object", "age > 18", "filter must be an object"},
+		{"unknown op", map[string]interface{}{"predicate": "age", "op": "regexp", "value": "x"}, "unknown op"},
+		{"invalid predicate", map[string]interface{}{"predicate": "age) OR has(x", "op": "eq", "value": "x"}, "invalid predicate"},
+		{"missing value", map[string]interface{}{"predicate": "age", "op": "eq"}, "value is required"},
+		{"list value", map[string]interface{}{"predicate": "age", "op": "eq", "value": []interface{}{"a"}}, "must be a string, number or boolean"},
+		{"between bounds", map[string]interface{}{"predicate": "age", "op": "between", "value": float64(1)}, "two bounds"},
+		{"has with value", map[string]interface{}{"predicate": "age", "op": "has", "value": float64(1)}, "takes no value"},
+		{"empty group", map[string]interface{}{"or": []interface{}{}}, "filter.or must not be empty"},
+		{"mixed group", map[string]interface{}{"and": []interface{}{}, "predicate": "age"}, "cannot be combined"},
+		{"unknown field", map[string]interface{}{"predicate": "age", "op": "eq", "value": "1", "lang": "en"}, `unknown field "lang"`},
+		{"nested path", map[string]interface{}{"and": []interface{}{map[string]interface{}{"predicate": "a", "op": "bad"}}}, "filter.and[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileFilter(tt.filter)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("compileFilter() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilteredQueries(t *testing.T) {
+	filter, err := compileFilter(map[string]interface{}{"predicate": "year", "op": "ge", "value": float64(2000)})
+	if err != nil {
+		t.Fatalf("compileFilter() failed: %v", err)
+	}
+
+	query, err := buildTypeQuery("Movie", []string{"title"}, 10, 0, "", filter)
+	if err != nil {
+		t.Fatalf("buildTypeQuery() failed: %v", err)
+	}
+	want := "query filtered($f0: int) {\n\tq(func: type(Movie), first: 10) @filter(ge(year, $f0)) {\n\t\tuid\n\t\ttitle\n\t}\n}"
+	if query != want {
+		t.Errorf("buildTypeQuery() =\n%s\nwant\n%s", query, want)
+	}
+	vars, err := parseQueryVariables(query)
+	if err != nil || len(vars) != 1 || vars[0].Name != "$f0" || vars[0].Type != "int" {
+		t.Errorf("parseQueryVariables() = %v, %v, want $f0: int", vars, err)
+	}
+	predicates, _, err := queryPredicates(query)
+	if err != nil {
+		t.Fatalf("queryPredicates() failed: %v", err)
+	}
+	for _, p := range predicates {
+		if strings.HasPrefix(p, "$") || p == "int" || p == "filtered" {
+			t.Errorf("queryPredicates() = %v, want no variables or header names", predicates)
+		}
+	}
+
+	paginated := buildPaginatedQuery("type(Movie)", "title", 1, 10, "", filter)
+	if !strings.HasPrefix(paginated, "query filtered($f0: int) {") || strings.Count(paginated, "@filter(ge(year, $f0))") != 2 {
+		t.Errorf("buildPaginatedQuery() = %s, want the filter on the total and the page", paginated)
+	}
+	if unfiltered := buildPaginatedQuery("type(Movie)", "title", 1, 10, "", compiledFilter{}); strings.Contains(unfiltered, "@filter") || strings.HasPrefix(unfiltered, "query") {
+		t.Errorf("buildPaginatedQuery() without filter = %s", unfiltered)
+	}
+}
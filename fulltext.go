@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Full-text match modes: all terms (alloftext) or any term (anyoftext)
+var fulltextMatches = []string{"all", "any"}
+
+// Build a full-text search query. The search term is passed as the $term
+// variable rather than interpolated, so it can't break or inject into the
+// query. Without fields the searched predicate is returned.
+func buildFulltextSearchQuery(predicate, match, typeName string, fields []string, first, offset int) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	if typeName != "" {
+		if err := validateName("type", typeName); err != nil {
+			return "", err
+		}
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", err
+		}
+	}
+
+	var fn string
+	switch match {
+	case "all":
+		fn = "alloftext"
+	case "any":
+		fn = "anyoftext"
+	default:
+		return "", fmt.Errorf("match must be one of: %s", strings.Join(fulltextMatches, ", "))
+	}
+
+	args := fmt.Sprintf("func: %s(%s, $term)", fn, predicate)
+	if first > 0 {
+		args += fmt.Sprintf(", first: %d", first)
+	}
+	if offset > 0 {
+		args += fmt.Sprintf(", offset: %d", offset)
+	}
+	filter := ""
+	if typeName != "" {
+		filter = fmt.Sprintf(" @filter(type(%s))", typeName)
+	}
+	if len(fields) == 0 {
+		fields = []string{predicate}
+	}
+
+	return fmt.Sprintf(`query search($term: string) {
+	q(%s)%s {
+		uid
+		%s
+	}
+}`, args, filter, strings.Join(fields, "\n\t\t")), nil
+}
+
+// Create handler for the full-text search tool
+func createFulltextSearchHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+
+		term, ok := request.Params.Arguments["term"].(string)
+		if !ok || strings.TrimSpace(term) == "" {
+			return nil, fmt.Errorf("term must be a non-empty string")
+		}
+
+		match := "all"
+		if v, ok := request.Params.Arguments["match"]; ok {
+			if match, ok = v.(string); !ok {
+				return nil, fmt.Errorf("match must be a string")
+			}
+		}
+
+		typeName := ""
+		if v, ok := request.Params.Arguments["type"]; ok {
+			if typeName, ok = v.(string); !ok {
+				return nil, fmt.Errorf("type must be a string")
+			}
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			fields = []string{predicate}
+		}
+		if fields, err = langFields(ctx, client, request, fields, "fields"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", 0)
+		if err != nil {
+			return nil, err
+		}
+		offset, err := intArgument(request, "offset", 0)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 || offset < 0 {
+			return nil, fmt.Errorf("first and offset must not be negative")
+		}
+
+		query, err := buildFulltextSearchQuery(predicate, match, typeName, fields, first, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIndexedPredicate(schema, predicate, "alloftext"); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query with the search term as a variable
+		resp, err := txn.QueryWithVars(ctx, query, map[string]string{"$term": term})
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
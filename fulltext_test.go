@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildFulltextSearchQuery(t *testing.T) {
+	got, err := buildFulltextSearchQuery("bio", "all", "", nil, 0, 0)
+	if err != nil {
+		t.Fatalf("buildFulltextSearchQuery failed: %v", err)
+	}
+	if !strings.Contains(got, "query search($term: string)") || !strings.Contains(got, "q(func: alloftext(bio, $term)) {") || !strings.Contains(got, "\t\tbio\n") {
+		t.Errorf("buildFulltextSearchQuery = %s", got)
+	}
+
+	got, err = buildFulltextSearchQuery("bio", "any", "Person", []string{"name", "bio"}, 10, 20)
+	if err != nil {
+		t.Fatalf("buildFulltextSearchQuery failed: %v", err)
+	}
+	if !strings.Contains(got, "q(func: anyoftext(bio, $term), first: 10, offset: 20) @filter(type(Person)) {") || !strings.Contains(got, "name\n\t\tbio") {
+		t.Errorf("buildFulltextSearchQuery with options = %s", got)
+	}
+
+	for _, args := range [][]string{
+		{"bio }", "all", ""},
+		{"bio", "some", ""},
+		{"bio", "all", "Person)"},
+	} {
+		if _, err := buildFulltextSearchQuery(args[0], args[1], args[2], nil, 0, 0); err == nil {
+			t.Errorf("buildFulltextSearchQuery(%q) succeeded, want error", args)
+		}
+	}
+}
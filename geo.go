@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Geo query modes, named after the Dgraph functions they generate
+var geoModes = []string{"near", "within", "contains", "intersects"}
+
+// geoPoint is a [longitude, latitude] pair
+type geoPoint [2]float64
+
+// Format a point the way Dgraph geo functions expect it
+func (p geoPoint) String() string {
+	return "[" + strconv.FormatFloat(p[0], 'f', -1, 64) + ", " + strconv.FormatFloat(p[1], 'f', -1, 64) + "]"
+}
+
+// Check that a point has a valid longitude and latitude
+func (p geoPoint) validate() error {
+	if p[0] < -180 || p[0] > 180 {
+		return fmt.Errorf("longitude %g is out of range [-180, 180]", p[0])
+	}
+	if p[1] < -90 || p[1] > 90 {
+		return fmt.Errorf("latitude %g is out of range [-90, 90]", p[1])
+	}
+	return nil
+}
+
+// Format a polygon as a single closed ring, closing it if needed
+func formatPolygon(points []geoPoint) (string, error) {
+	if len(points) < 3 {
+		return "", fmt.Errorf("polygon needs at least 3 points")
+	}
+	for _, p := range points {
+		if err := p.validate(); err != nil {
+			return "", err
+		}
+	}
+	if points[0] != points[len(points)-1] {
+		points = append(points, points[0])
+	}
+
+	ring := make([]string, len(points))
+	for i, p := range points {
+		ring[i] = p.String()
+	}
+	return "[[" + strings.Join(ring, ", ") + "]]", nil
+}
+
+// Build a query using one of Dgraph's geo functions at the root. near takes
+// a point and a radius; within and intersects take a polygon; contains takes
+// either a point or a polygon.
+func buildGeoQuery(mode, predicate string, point *geoPoint, radius float64, polygon []geoPoint, fields []string, first int) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", err
+		}
+	}
+
+	var shape string
+	switch mode {
+	case "near":
+		if point == nil {
+			return "", fmt.Errorf("near requires point")
+		}
+		if err := point.validate(); err != nil {
+			return "", err
+		}
+		if radius <= 0 {
+			return "", fmt.Errorf("near requires a positive radius_meters")
+		}
+		shape = point.String() + ", " + strconv.FormatFloat(radius, 'f', -1, 64)
+	case "within", "intersects":
+		if polygon == nil {
+			return "", fmt.Errorf("%s requires polygon", mode)
+		}
+		var err error
+		if shape, err = formatPolygon(polygon); err != nil {
+			return "", err
+		}
+	case "contains":
+		switch {
+		case point != nil && polygon != nil:
+			return "", fmt.Errorf("contains takes either point or polygon, not both")
+		case point != nil:
+			if err := point.validate(); err != nil {
+				return "", err
+			}
+			shape = point.String()
+		case polygon != nil:
+			var err error
+			if shape, err = formatPolygon(polygon); err != nil {
+				return "", err
+			}
+		default:
+			return "", fmt.Errorf("contains requires point or polygon")
+		}
+	default:
+		return "", fmt.Errorf("mode must be one of %s", strings.Join(geoModes, ", "))
+	}
+
+	args := fmt.Sprintf("func: %s(%s, %s)", mode, predicate, shape)
+	if first > 0 {
+		args += fmt.Sprintf(", first: %d", first)
+	}
+
+	selection := append([]string{"uid"}, fields...)
+	selection = append(selection, predicate)
+
+	return fmt.Sprintf(`{
+	q(%s) {
+		%s
+	}
+}`, args, strings.Join(selection, "\n\t\t")), nil
+}
+
+// Read a [longitude, latitude] pair from a list of two numbers
+func toGeoPoint(value interface{}, name string) (geoPoint, error) {
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return geoPoint{}, fmt.Errorf("%s must be a [longitude, latitude] pair", name)
+	}
+	var p geoPoint
+	for i, v := range pair {
+		f, ok := v.(float64)
+		if !ok {
+			return geoPoint{}, fmt.Errorf("%s must be a [longitude, latitude] pair", name)
+		}
+		p[i] = f
+	}
+	return p, nil
+}
+
+// Create handler for the geo query tool
+func createGeoQueryHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+
+		mode := "near"
+		if modeArg, ok := request.Params.Arguments["mode"].(string); ok {
+			mode = modeArg
+		}
+
+		var point *geoPoint
+		if v, ok := request.Params.Arguments["point"]; ok && v != nil {
+			p, err := toGeoPoint(v, "point")
+			if err != nil {
+				return nil, err
+			}
+			point = &p
+		}
+
+		var polygon []geoPoint
+		if v, ok := request.Params.Arguments["polygon"]; ok && v != nil {
+			items, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("polygon must be a list of [longitude, latitude] pairs")
+			}
+			polygon = make([]geoPoint, 0, len(items))
+			for _, item := range items {
+				p, err := toGeoPoint(item, "each polygon point")
+				if err != nil {
+					return nil, err
+				}
+				polygon = append(polygon, p)
+			}
+		}
+
+		radius := 0.0
+		if v, ok := request.Params.Arguments["radius_meters"]; ok && v != nil {
+			if radius, ok = v.(float64); !ok {
+				return nil, fmt.Errorf("radius_meters must be a number")
+			}
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if fields, err = langFields(ctx, client, request, fields, "fields"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", 0)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 {
+			return nil, fmt.Errorf("first must not be negative")
+		}
+
+		query, err := buildGeoQuery(mode, predicate, point, radius, polygon, fields, first)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
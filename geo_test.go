@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildGeoQuery(t *testing.T) {
+	point := &geoPoint{-122.4194, 37.7749}
+	square := []geoPoint{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+
+	tests := []struct {
+		name    string
+		mode    string
+		point   *geoPoint
+		radius  float64
+		polygon []geoPoint
+		want    string
+	}{
+		{"near", "near", point, 1000, nil, "near(loc, [-122.4194, 37.7749], 1000)"},
+		{"within closes ring", "within", nil, 0, square, "within(loc, [[[0, 0], [0, 1], [1, 1], [1, 0], [0, 0]]])"},
+		{"intersects", "intersects", nil, 0, append(square, geoPoint{0, 0}), "intersects(loc, [[[0, 0], [0, 1], [1, 1], [1, 0], [0, 0]]])"},
+		{"contains point", "contains", point, 0, nil, "contains(loc, [-122.4194, 37.7749])"},
+		{"contains polygon", "contains", nil, 0, square, "contains(loc, [[[0, 0],"},
+	}
+	for _, tt := range tests {
+		got, err := buildGeoQuery(tt.mode, "loc", tt.point, tt.radius, tt.polygon, []string{"name"}, 5)
+		if err != nil {
+			t.Errorf("%s: buildGeoQuery failed: %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(got, tt.want) || !strings.Contains(got, "first: 5") || !strings.Contains(got, "name") {
+			t.Errorf("%s: buildGeoQuery = %q, want it to contain %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildGeoQueryInvalid(t *testing.T) {
+	point := &geoPoint{10, 10}
+	square := []geoPoint{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+
+	tests := []struct {
+		name      string
+		mode      string
+		predicate string
+		point     *geoPoint
+		radius    float64
+		polygon   []geoPoint
+	}{
+		{"unknown mode", "around", "loc", point, 10, nil},
+		{"bad predicate", "near", "loc)", point, 10, nil},
+		{"near without point", "near", "loc", nil, 10, nil},
+		{"near without radius", "near", "loc", point, 0, nil},
+		{"longitude out of range", "near", "loc", &geoPoint{181, 0}, 10, nil},
+		{"latitude out of range", "near", "loc", &geoPoint{0, -91}, 10, nil},
+		{"within without polygon", "within", "loc", point, 0, nil},
+		{"polygon too small", "within", "loc", nil, 0, square[:2]},
+		{"contains both", "contains", "loc", point, 0, square},
+		{"contains neither", "contains", "loc", nil, 0, nil},
+	}
+	for _, tt := range tests {
+		if _, err := buildGeoQuery(tt.mode, tt.predicate, tt.point, tt.radius, tt.polygon, nil, 0); err == nil {
+			t.Errorf("%s: buildGeoQuery succeeded, want error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Maximum number of levels of linked nodes dgraph_get_node expands
+const maxGetNodeDepth = 2
+
+// Build a query fetching all predicates of a node, expanding linked nodes
+// depth levels deep and following the given reverse edges one level back
+func buildGetNodeQuery(uid string, depth int, reverse []string) (string, error) {
+	for _, p := range reverse {
+		if err := validateName("predicate", p); err != nil {
+			return "", err
+		}
+	}
+
+	selection := "uid dgraph.type expand(_all_)"
+	for i := 0; i < depth; i++ {
+		selection = "uid dgraph.type expand(_all_) { " + selection + " }"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\n\tnode(func: uid(%s)) {\n\t\t%s", uid, selection)
+	for _, p := range reverse {
+		fmt.Fprintf(&b, "\n\t\t~%s { uid dgraph.type }", p)
+	}
+	b.WriteString("\n\t}\n}")
+	return b.String(), nil
+}
+
+// Build a query fetching the given predicates of a node. Edges are
+// returned as the uids and types of the linked nodes, since Dgraph returns
+// nothing for an edge without a selection.
+func buildGetNodeFieldsQuery(schema *schemaInfo, uid string, fields, reverse []string) (string, error) {
+	for _, p := range reverse {
+		if err := validateName("predicate", p); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "{\n\tnode(func: uid(%s)) {\n\t\tuid", uid)
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", err
+		}
+		if f == "uid" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\t\t%s", f)
+		if p, ok := schema.predicate(f); ok && p.Type == "uid" {
+			b.WriteString(" { uid dgraph.type }")
+		}
+	}
+	for _, p := range reverse {
+		fmt.Fprintf(&b, "\n\t\t~%s { uid dgraph.type }", p)
+	}
+	b.WriteString("\n\t}\n}")
+	return b.String(), nil
+}
+
+// Create handler for the get node tool
+func createGetNodeHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := uidArgument(request, "uid")
+		if err != nil {
+			return nil, err
+		}
+
+		depth, err := intArgument(request, "depth", 1)
+		if err != nil {
+			return nil, err
+		}
+		if depth < 0 || depth > maxGetNodeDepth {
+			return nil, fmt.Errorf("depth must be between 0 and %d", maxGetNodeDepth)
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 && request.Params.Arguments["depth"] != nil {
+			return nil, fmt.Errorf("fields cannot be combined with depth")
+		}
+
+		includeReverse, err := boolArgument(request, "include_reverse", false)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		// expand(_all_) doesn't follow reverse edges, so list them from the
+		// schema, which also tells which fields are edges
+		var (
+			schema  *schemaInfo
+			reverse []string
+		)
+		if includeReverse || len(fields) > 0 {
+			if schema, err = fetchSchema(ctx, client, refresh); err != nil {
+				return nil, err
+			}
+		}
+		if includeReverse {
+			for _, p := range schema.Predicates {
+				if p.Reverse && !isInternalName(p.Predicate) {
+					reverse = append(reverse, p.Predicate)
+				}
+			}
+		}
+
+		var query string
+		if len(fields) > 0 {
+			query, err = buildGetNodeFieldsQuery(schema, uid, fields, reverse)
+		} else {
+			query, err = buildGetNodeQuery(uid, depth, reverse)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		var result struct {
+			Node []map[string]json.RawMessage `json:"node"`
+		}
+		if err := json.Unmarshal(resp.Json, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+		if len(result.Node) == 0 || len(result.Node[0]) <= 1 {
+			if len(fields) > 0 {
+				return nil, fmt.Errorf("node %s not found or has none of the fields %s", uid, strings.Join(fields, ", "))
+			}
+			return nil, fmt.Errorf("node %s not found or has no predicates", uid)
+		}
+
+		node, err := json.Marshal(result.Node[0])
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode node: %v", err)
+		}
+		toolResult := mcp.NewToolResultText(string(node))
+
+		// expand(_all_) silently returns nothing for nodes without a type
+		if _, ok := result.Node[0]["dgraph.type"]; !ok && len(fields) == 0 {
+			slog.Warn("dgraph_get_node matched an untyped node", "uid", uid)
+			toolResult.Content = append(toolResult.Content, mcp.NewTextContent(fmt.Sprintf("Warning: node %s has no dgraph.type, so expand(_all_) returned none of its predicates.", uid)))
+		}
+		return toolResult, nil
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildGetNodeQuery(t *testing.T) {
+	got, err := buildGetNodeQuery("0x1", 0, nil)
+	if err != nil {
+		t.Fatalf("buildGetNodeQuery failed: %v", err)
+	}
+	want := "{\n\tnode(func: uid(0x1)) {\n\t\tuid dgraph.type expand(_all_)\n\t}\n}"
+	if got != want {
+		t.Errorf("buildGetNodeQuery depth 0\n got: %s\nwant: %s", got, want)
+	}
+
+	got, err = buildGetNodeQuery("0x1", 2, []string{"friend", "directed_by"})
+	if err != nil {
+		t.Fatalf("buildGetNodeQuery failed: %v", err)
+	}
+	for _, part := range []string{
+		"uid dgraph.type expand(_all_) { uid dgraph.type expand(_all_) { uid dgraph.type expand(_all_) } }",
+		"~friend { uid dgraph.type }",
+		"~directed_by { uid dgraph.type }",
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("buildGetNodeQuery depth 2 = %s, missing %q", got, part)
+		}
+	}
+
+	if _, err := buildGetNodeQuery("0x1", 0, []string{"bad }"}); err == nil {
+		t.Errorf("buildGetNodeQuery with invalid reverse predicate succeeded, want error")
+	}
+}
+
+func TestBuildGetNodeFieldsQuery(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string", Lang: true},
+		{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+	}}
+	got, err := buildGetNodeFieldsQuery(schema, "0x1", []string{"uid", "name@fr", "friend"}, []string{"friend"})
+	if err != nil {
+		t.Fatalf("buildGetNodeFieldsQuery failed: %v", err)
+	}
+	want := "{\n\tnode(func: uid(0x1)) {\n\t\tuid\n\t\tname@fr\n\t\tfriend { uid dgraph.type }\n\t\t~friend { uid dgraph.type }\n\t}\n}"
+	if got != want {
+		t.Errorf("buildGetNodeFieldsQuery\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, err := buildGetNodeFieldsQuery(schema, "0x1", []string{"name } x"}, nil); err == nil {
+		t.Errorf("buildGetNodeFieldsQuery with invalid field succeeded, want error")
+	}
+}
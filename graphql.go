@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default GraphQL endpoint settings
+const (
+	defaultAdminEndpoint   = "http://localhost:8080/admin"
+	defaultGraphQLEndpoint = "http://localhost:8080/graphql"
+	graphQLTimeout         = 60 * time.Second
+	maxGraphQLResponse     = 64 << 20 // 64MB
+)
+
+// Send a GraphQL request to an HTTP endpoint and return the response body.
+// Responses with a non-2xx status are errors.
+func postGraphQL(ctx context.Context, endpoint string, headers map[string]string, query string, variables map[string]interface{}) ([]byte, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, graphQLTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxGraphQLResponse+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	if len(data) > maxGraphQLResponse {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxGraphQLResponse)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("request failed: %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return data, nil
+}
+
+// Get the query and variables arguments of a GraphQL tool
+func graphQLArguments(request mcp.CallToolRequest) (string, map[string]interface{}, error) {
+	query, ok := request.Params.Arguments["query"].(string)
+	if !ok || query == "" {
+		return "", nil, fmt.Errorf("query must be a non-empty string")
+	}
+
+	var variables map[string]interface{}
+	if v, ok := request.Params.Arguments["variables"]; ok && v != nil {
+		if variables, ok = v.(map[string]interface{}); !ok {
+			return "", nil, fmt.Errorf("variables must be an object")
+		}
+	}
+	return query, variables, nil
+}
+
+// graphQLResponse is the standard GraphQL response envelope
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string        `json:"message"`
+		Path    []interface{} `json:"path"`
+	} `json:"errors"`
+}
+
+// Split a GraphQL response into its data and error messages. Error
+// messages include the path of the field they belong to, if any.
+func parseGraphQLResponse(body []byte) (json.RawMessage, []string, error) {
+	var resp graphQLResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse response: %v", err)
+	}
+
+	messages := make([]string, len(resp.Errors))
+	for i, e := range resp.Errors {
+		messages[i] = e.Message
+		if len(e.Path) > 0 {
+			path := make([]string, len(e.Path))
+			for j, p := range e.Path {
+				path[j] = fmt.Sprint(p)
+			}
+			messages[i] = strings.Join(path, ".") + ": " + e.Message
+		}
+	}
+
+	if string(resp.Data) == "null" {
+		resp.Data = nil
+	}
+	return resp.Data, messages, nil
+}
+
+// Check whether a byte can be part of a GraphQL name
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Check whether a GraphQL document only holds queries, i.e. every operation
+// in it is a query or a query shorthand ({ ... }). Keywords are only looked
+// for outside selections, arguments, strings and comments.
+func isGraphQLQuery(doc string) bool {
+	depth := 0
+	for i := 0; i < len(doc); i++ {
+		switch c := doc[i]; {
+		case c == '#':
+			for i < len(doc) && doc[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(doc[i:], `"""`):
+			end := strings.Index(doc[i+3:], `"""`)
+			if end < 0 {
+				return false
+			}
+			i += end + 5
+		case c == '"':
+			for i++; i < len(doc) && doc[i] != '"'; i++ {
+				if doc[i] == '\\' {
+					i++
+				}
+			}
+		case c == '{' || c == '(' || c == '[':
+			depth++
+		case c == '}' || c == ')' || c == ']':
+			depth--
+		case depth == 0 && isGraphQLNameChar(c):
+			start := i
+			for i < len(doc) && isGraphQLNameChar(doc[i]) {
+				i++
+			}
+			if word := doc[start:i]; word == "mutation" || word == "subscription" {
+				return false
+			}
+			i--
+		}
+	}
+	return true
+}
+
+// Create handler for the GraphQL tool. GraphQL errors without any data fail
+// the call; errors alongside partial data are returned as a warning.
+// Mutations may change data and schema the caches hold, so they start over
+// after any operation that isn't a plain query, even a failed one, as it may
+// have been applied in part.
+func createGraphQLHandler(endpoint string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, variables, err := graphQLArguments(request)
+		if err != nil {
+			return nil, err
+		}
+		if !isGraphQLQuery(query) {
+			defer invalidateCaches()
+		}
+
+		body, err := postGraphQL(ctx, endpoint, nil, query, variables)
+		if err != nil {
+			return nil, fmt.Errorf("graphql request failed: %v", err)
+		}
+
+		data, messages, err := parseGraphQLResponse(body)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			if len(messages) == 0 {
+				return nil, fmt.Errorf("graphql response has neither data nor errors")
+			}
+			return nil, fmt.Errorf("graphql request failed: %s", strings.Join(messages, "; "))
+		}
+
+		result := mcp.NewToolResultText(string(data))
+		if len(messages) > 0 {
+			warning := fmt.Sprintf("Warning: the result is partial, some fields failed: %s", strings.Join(messages, "; "))
+			result.Content = append(result.Content, mcp.NewTextContent(warning))
+		}
+		return result, nil
+	}
+}
+
+// Headers for admin endpoint requests. The auth token, if any, is sent as
+// the X-Dgraph-AuthToken header Dgraph's --security token option expects.
+func adminHeaders(authToken string) map[string]string {
+	headers := map[string]string{}
+	if authToken != "" {
+		headers["X-Dgraph-AuthToken"] = authToken
+	}
+	return headers
+}
+
+// Create handler for the admin GraphQL tool. Admin operations such as
+// restores, drops and GraphQL schema updates change data and schema behind
+// the caches' back, so the caches start over after every call.
+func createAdminHandler(endpoint, authToken string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	headers := adminHeaders(authToken)
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		query, variables, err := graphQLArguments(request)
+		if err != nil {
+			return nil, err
+		}
+		defer invalidateCaches()
+
+		data, err := postGraphQL(ctx, endpoint, headers, query, variables)
+		if err != nil {
+			return nil, fmt.Errorf("admin request failed: %v", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
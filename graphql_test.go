@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestPostGraphQL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Dgraph-AuthToken") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"query": body.Query, "id": body.Variables["id"]},
+		})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	data, err := postGraphQL(ctx, srv.URL, map[string]string{"X-Dgraph-AuthToken": "secret"}, "{ health { status } }", map[string]interface{}{"id": "1"})
+	if err != nil {
+		t.Fatalf("postGraphQL failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"query":"{ health { status } }"`) || !strings.Contains(string(data), `"id":"1"`) {
+		t.Errorf("postGraphQL = %s", data)
+	}
+
+	_, err = postGraphQL(ctx, srv.URL, nil, "{ health { status } }", nil)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("postGraphQL without token = %v, want 401 error", err)
+	}
+}
+
+func TestParseGraphQLResponse(t *testing.T) {
+	tests := []struct {
+		body     string
+		data     string
+		messages []string
+	}{
+		{`{"data": {"getUser": {"name": "Alice"}}}`, `{"getUser": {"name": "Alice"}}`, nil},
+		{
+			`{"data": {"a": null, "b": 1}, "errors": [{"message": "not found", "path": ["a"]}]}`,
+			`{"a": null, "b": 1}`,
+			[]string{"a: not found"},
+		},
+		{
+			`{"data": null, "errors": [{"message": "syntax error"}, {"message": "bad field", "path": ["q", 0, "x"]}]}`,
+			"",
+			[]string{"syntax error", "q.0.x: bad field"},
+		},
+	}
+
+	for _, tt := range tests {
+		data, messages, err := parseGraphQLResponse([]byte(tt.body))
+		if err != nil {
+			t.Errorf("parseGraphQLResponse(%s) failed: %v", tt.body, err)
+			continue
+		}
+		if string(data) != tt.data || strings.Join(messages, "|") != strings.Join(tt.messages, "|") {
+			t.Errorf("parseGraphQLResponse(%s) = %s, %q, want %s, %q", tt.body, data, messages, tt.data, tt.messages)
+		}
+	}
+}
+
+func TestIsGraphQLQuery(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want bool
+	}{
+		{"{ queryUser { name } }", true},
+		{"query { queryUser { name } }", true},
+		{"query Q($id: ID!) { getUser(id: $id) { name } }", true},
+		{`query { queryPost(filter: { title: { anyofterms: "mutation" } }) { mutation: title } }`, true},
+		{"# mutation { deleteUser }\nquery { queryUser { name } }", true},
+		{"mutation { addUser(input: [{ name: \"Alice\" }]) { numUids } }", false},
+		{"mutation AddUser($name: String!) { addUser(input: [{ name: $name }]) { numUids } }", false},
+		{"query A { a } mutation B { b }", false},
+		{"subscription { queryUser { name } }", false},
+		{`"""a mutation""" mutation { deleteUser(filter: {}) { numUids } }`, false},
+		{`"""unterminated`, false},
+	}
+	for _, tt := range tests {
+		if got := isGraphQLQuery(tt.doc); got != tt.want {
+			t.Errorf("isGraphQLQuery(%q) = %v, want %v", tt.doc, got, tt.want)
+		}
+	}
+}
+
+// Cache a result, returning a check of whether it is still cached
+func cachedResult(t *testing.T) func() bool {
+	results := queryResults
+	queryResults = newQueryCache(10, time.Minute)
+	t.Cleanup(func() { queryResults = results })
+
+	key := newQueryCacheKey(nil, "{ q(func: has(name)) { name } }", nil)
+	_, generation, _ := queryResults.get(key)
+	queryResults.put(key, generation, []byte(`{"q":[]}`))
+	return func() bool {
+		_, _, ok := queryResults.get(key)
+		return ok
+	}
+}
+
+func TestGraphQLHandlerInvalidatesCaches(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"addUser": {"numUids": 1}}}`))
+	}))
+	defer srv.Close()
+	handler := createGraphQLHandler(srv.URL)
+
+	tests := []struct {
+		query       string
+		invalidates bool
+	}{
+		{"query { queryUser { name } }", false},
+		{"{ queryUser { name } }", false},
+		{"mutation { addUser(input: [{ name: \"Alice\" }]) { numUids } }", true},
+	}
+	for _, tt := range tests {
+		cached := cachedResult(t)
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]interface{}{"query": tt.query}
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("%s: handler failed: %v", tt.query, err)
+		}
+		if cached() == tt.invalidates {
+			t.Errorf("%s: cached result kept = %v, want %v", tt.query, cached(), !tt.invalidates)
+		}
+	}
+}
+
+func TestAdminHandlerInvalidatesCaches(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": {"health": [{"status": "healthy"}]}}`))
+	}))
+	defer srv.Close()
+	handler := createAdminHandler(srv.URL, "")
+
+	for _, query := range []string{"{ health { status } }", `mutation { dropAll: drop(all: true) }`} {
+		cached := cachedResult(t)
+		var request mcp.CallToolRequest
+		request.Params.Arguments = map[string]interface{}{"query": query}
+		if _, err := handler(context.Background(), request); err != nil {
+			t.Fatalf("%s: handler failed: %v", query, err)
+		}
+		if cached() {
+			t.Errorf("%s: cached result kept after an admin call", query)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Check that a predicate can be grouped by, and that a label predicate is
+// only given for a uid predicate
+func checkGroupPredicate(schema *schemaInfo, predicate, label string) error {
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		return fmt.Errorf("predicate %q is not in the schema", predicate)
+	}
+	switch {
+	case p.List:
+		return fmt.Errorf("@groupby doesn't support list predicates, but %q has type [%s]", predicate, p.Type)
+	case p.Type == "password":
+		return fmt.Errorf("@groupby doesn't support password predicates such as %q", predicate)
+	}
+	if label == "" {
+		return nil
+	}
+	if p.Type != "uid" {
+		return fmt.Errorf("label_predicate names the nodes of a uid predicate, but %q has type %s", predicate, p.Type)
+	}
+	if _, ok := schema.predicate(label); !ok {
+		return fmt.Errorf("predicate %q is not in the schema", label)
+	}
+	return nil
+}
+
+// Build a query counting the nodes matched by root per value of the group
+// predicate. With a label predicate, the groups, which are then nodes, are
+// fetched to read their label.
+func buildCountByGroupQuery(root, predicate, label string) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	if label == "" {
+		return fmt.Sprintf(`{
+	result(func: %s) @groupby(%s) {
+		count(uid)
+	}
+}`, root, predicate), nil
+	}
+
+	if err := validateName("predicate", label); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{
+	var(func: %s) @groupby(%s) {
+		c as count(uid)
+	}
+	result(func: uid(c)) {
+		uid
+		label: %s
+		count: val(c)
+	}
+}`, root, predicate, label), nil
+}
+
+// Turn a group value into a map key: strings as they are, other values as
+// their JSON text
+func groupKey(value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	return string(value)
+}
+
+// Reshape a grouped count result into a map from group value to count.
+// Labelled groups are keyed by their label, or by their uid when they have
+// none or share it with another group.
+func parseGroupCounts(data []byte, predicate string, labelled bool) (map[string]int, error) {
+	counts := map[string]int{}
+	if labelled {
+		var result struct {
+			Result []struct {
+				UID   string          `json:"uid"`
+				Label json.RawMessage `json:"label"`
+				Count int             `json:"count"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+		labels := map[string]int{}
+		for _, g := range result.Result {
+			if g.Label != nil {
+				labels[groupKey(g.Label)]++
+			}
+		}
+		for _, g := range result.Result {
+			key := g.UID
+			if g.Label != nil && labels[groupKey(g.Label)] == 1 {
+				key = groupKey(g.Label)
+			}
+			counts[key] = g.Count
+		}
+		return counts, nil
+	}
+
+	var result struct {
+		Result []struct {
+			Groups []map[string]json.RawMessage `json:"@groupby"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse query response: %v", err)
+	}
+	for _, r := range result.Result {
+		for _, g := range r.Groups {
+			var count int
+			if err := json.Unmarshal(g["count"], &count); err != nil {
+				return nil, fmt.Errorf("group without count in query response")
+			}
+			value, ok := g[predicate]
+			if !ok {
+				return nil, fmt.Errorf("group without %s in query response", predicate)
+			}
+			counts[groupKey(value)] = count
+		}
+	}
+	return counts, nil
+}
+
+// Create handler for the count by group tool
+func createCountByGroupHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		typeName, _ := request.Params.Arguments["type"].(string)
+		rootFunc, _ := request.Params.Arguments["func"].(string)
+		label, _ := request.Params.Arguments["label_predicate"].(string)
+
+		predicate, ok := request.Params.Arguments["group_predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("group_predicate must be a string")
+		}
+
+		root, err := aggregateRoot(typeName, rootFunc)
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := buildCountByGroupQuery(root, predicate, label)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkGroupPredicate(schema, predicate, label); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v%s", err, notIndexedHint(ctx, client, query, err))
+		}
+
+		counts, err := parseGroupCounts(resp.Json, predicate, label != "")
+		if err != nil {
+			return nil, err
+		}
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+
+		out, err := json.Marshal(struct {
+			GroupPredicate string         `json:"group_predicate"`
+			Groups         map[string]int `json:"groups"`
+			GroupCount     int            `json:"group_count"`
+			NodeCount      int            `json:"node_count"`
+		}{predicate, counts, len(counts), total})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckGroupPredicate(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "genre", Type: "uid"},
+		{Predicate: "rating", Type: "int"},
+		{Predicate: "tags", Type: "string", List: true},
+		{Predicate: "secret", Type: "password"},
+		{Predicate: "name", Type: "string"},
+	}}
+
+	tests := []struct {
+		predicate, label string
+		wantErr          bool
+	}{
+		{"genre", "", false},
+		{"genre", "name", false},
+		{"rating", "", false},
+		{"rating", "name", true},
+		{"genre", "title", true},
+		{"tags", "", true},
+		{"secret", "", true},
+		{"missing", "", true},
+	}
+	for _, tt := range tests {
+		if err := checkGroupPredicate(schema, tt.predicate, tt.label); (err != nil) != tt.wantErr {
+			t.Errorf("checkGroupPredicate(%q, %q) error = %v, wantErr %v", tt.predicate, tt.label, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildCountByGroupQuery(t *testing.T) {
+	query, err := buildCountByGroupQuery("type(Movie)", "genre", "")
+	if err != nil {
+		t.Fatalf("buildCountByGroupQuery() error = %v", err)
+	}
+	if !strings.Contains(query, "result(func: type(Movie)) @groupby(genre) {\n\t\tcount(uid)") {
+		t.Errorf("buildCountByGroupQuery() = %q", query)
+	}
+
+	query, err = buildCountByGroupQuery("has(genre)", "genre", "name")
+	if err != nil {
+		t.Fatalf("buildCountByGroupQuery() error = %v", err)
+	}
+	for _, want := range []string{"var(func: has(genre)) @groupby(genre)", "c as count(uid)", "result(func: uid(c))", "label: name", "count: val(c)"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("buildCountByGroupQuery() = %q, want it to contain %q", query, want)
+		}
+	}
+
+	if _, err := buildCountByGroupQuery("type(Movie)", "genre) { uid } #", ""); err == nil {
+		t.Errorf("buildCountByGroupQuery() expected error for an invalid predicate")
+	}
+}
+
+func TestParseGroupCounts(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		predicate string
+		labelled  bool
+		want      map[string]int
+	}{
+		{
+			name:      "strings",
+			data:      `{"result": [{"@groupby": [{"genre": "Comedy", "count": 2}, {"genre": "Drama", "count": 5}]}]}`,
+			predicate: "genre",
+			want:      map[string]int{"Comedy": 2, "Drama": 5},
+		},
+		{
+			name:      "numbers",
+			data:      `{"result": [{"@groupby": [{"rating": 4, "count": 1}, {"rating": 4.5, "count": 3}]}]}`,
+			predicate: "rating",
+			want:      map[string]int{"4": 1, "4.5": 3},
+		},
+		{
+			name:      "no nodes",
+			data:      `{"result": []}`,
+			predicate: "genre",
+			want:      map[string]int{},
+		},
+		{
+			name:     "labelled",
+			data:     `{"result": [{"uid": "0x1", "label": "Comedy", "count": 2}, {"uid": "0x2", "label": "Drama", "count": 5}, {"uid": "0x3", "label": "Drama", "count": 1}, {"uid": "0x4", "count": 7}]}`,
+			labelled: true,
+			want:     map[string]int{"Comedy": 2, "0x2": 5, "0x3": 1, "0x4": 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGroupCounts([]byte(tt.data), tt.predicate, tt.labelled)
+			if err != nil {
+				t.Fatalf("parseGroupCounts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseGroupCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseGroupCounts([]byte(`{"result": [{"@groupby": [{"count": 2}]}]}`), "genre", false); err == nil {
+		t.Errorf("parseGroupCounts() expected error for a group without its value")
+	}
+}
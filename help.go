@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// helpTopic is a section of the DQL cheat sheet
+type helpTopic struct {
+	Name  string
+	Title string
+	Body  string
+}
+
+// helpTopics is a concise DQL cheat sheet covering the syntax the tools
+// build on, served without touching the database
+var helpTopics = []helpTopic{
+	{
+		Name:  "functions",
+		Title: "Root and filter functions",
+		Body: "Functions select the root nodes with `func:` and filter nodes in `@filter(...)`. Most need an index on the predicate; `dgraph_index_audit` lists which.\n\n" +
+			"| Function | Matches | Index |\n" +
+			"|---|---|---|\n" +
+			"| `uid(0x1, 0x2)` / `uid(v)` | the given nodes or a variable | none |\n" +
+			"| `has(name)` | nodes with the predicate | none |\n" +
+			"| `type(Person)` | nodes whose `dgraph.type` is the type | none |\n" +
+			"| `eq(name, \"Alice\")`, `eq(age, [30, 31])` | equal values | a string index such as `exact` or `hash`, type index otherwise |\n" +
+			"| `lt`, `le`, `gt`, `ge` (`ge(age, 18)`) | ordered comparison | `exact` for strings, type index otherwise |\n" +
+			"| `between(age, 18, 30)` | inclusive range | as `lt` |\n" +
+			"| `allofterms(name, \"big apple\")` / `anyofterms` | all / any of the terms | `term` |\n" +
+			"| `alloftext(bio, \"running\")` / `anyoftext` | full-text with stemming | `fulltext` |\n" +
+			"| `regexp(name, /^Al.*$/i)` | regular expression | `trigram` |\n" +
+			"| `match(name, \"Alise\", 2)` | fuzzy match within a distance | `trigram` |\n" +
+			"| `near(loc, [-122.4, 37.8], 1000)` | within meters of a point | `geo` |\n" +
+			"| `within`, `contains`, `intersects` | polygon relations | `geo` |\n" +
+			"| `uid_in(friend, 0x2)` | nodes with an edge to the uid | none |\n\n" +
+			"Combine filters with `and`, `or` and `not`: `@filter(ge(age, 18) and not has(banned))`. `count(friend)` counts edges, and `eq(count(friend), 0)` filters on it, needing `@count` on the predicate.",
+	},
+	{
+		Name:  "directives",
+		Title: "Directives",
+		Body: "- `@filter(fn)` on a block or edge keeps the nodes matching `fn`: `friend @filter(ge(age, 18)) { name }`\n" +
+			"- `@cascade` drops nodes missing any selected predicate; `@cascade(name, email)` only requires those\n" +
+			"- `@normalize` flattens nested results into one object per result; only aliased fields (`n: name`) are kept\n" +
+			"- `@recurse(depth: 3, loop: false)` follows the selected edges repeatedly\n" +
+			"- `@groupby(genre) { count(uid) }` groups nodes by a non-list predicate\n" +
+			"- `@facets` returns the facets of an edge or value, `@facets(weight)` only that one; `@facets(eq(close, true))` filters by facet\n" +
+			"- `@if(eq(len(v), 0))` on an upsert mutation applies it only when the condition holds\n\n" +
+			"Directives go after the block or edge arguments and before its braces: `q(func: type(Person)) @filter(has(email)) @cascade { name email }`.",
+	},
+	{
+		Name:  "pagination",
+		Title: "Pagination and sorting",
+		Body: "Arguments go in the root function's parentheses or after an edge name:\n\n" +
+			"- `first: 10`, `offset: 20` page through results; `first: -1` returns the last node\n" +
+			"- `after: 0x2a` continues after a uid, which stays stable while data changes\n" +
+			"- `orderasc: name`, `orderdesc: age` sort; several orderings may be combined\n\n" +
+			"```\n{\n  q(func: type(Person), orderasc: name, first: 10, offset: 20) {\n    name\n    friend(orderdesc: age, first: 3) { name age }\n  }\n}\n```",
+	},
+	{
+		Name:  "variables",
+		Title: "Variables",
+		Body: "- Query variables are declared in the header and passed separately: `query q($name: string, $first: int = 10) { q(func: eq(name, $name), first: $first) { uid } }`\n" +
+			"- `v as uid` or `A as q(func: ...)` binds uids to a variable, used later with `uid(A)`\n" +
+			"- `a as age` binds values; read them with `val(a)`, aggregate with `min`, `max`, `sum` or `avg(val(a))`, and compute with `math(a * 2)`\n" +
+			"- `var(func: ...) { ... }` blocks only define variables and return nothing\n\n" +
+			"```\n{\n  adults as var(func: type(Person)) @filter(ge(age, 18))\n  q(func: uid(adults), orderdesc: age, first: 5) { name age }\n}\n```",
+	},
+	{
+		Name:  "examples",
+		Title: "Query skeletons",
+		Body: "Nodes of a type with some fields:\n```\n{ q(func: type(Person), first: 10) { uid name email } }\n```\n\n" +
+			"A node and its edges:\n```\n{ q(func: uid(0x1)) { name friend { uid name } } }\n```\n\n" +
+			"Reverse edge, needing `@reverse`:\n```\n{ q(func: uid(0x2)) { ~friend { uid name } } }\n```\n\n" +
+			"Counting:\n```\n{ total(func: type(Movie)) { count(uid) } }\n```\n\n" +
+			"Language-tagged values:\n```\n{ q(func: has(name)) { name@fr:en:. } }\n```\n\n" +
+			"Upsert, creating a node only if none has the email:\n```\nquery: { q(func: eq(email, \"a@b.c\")) { v as uid } }\nmutation: uid(v) <email> \"a@b.c\" .\ncond: eq(len(v), 0)\n```",
+	},
+}
+
+// Return the names of the help topics
+func helpTopicNames() []string {
+	names := make([]string, len(helpTopics))
+	for i, t := range helpTopics {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Render the cheat sheet as markdown, either a single topic or all of them
+func renderHelp(topic string) (string, error) {
+	var b strings.Builder
+	for _, t := range helpTopics {
+		if topic != "" && topic != t.Name {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s", t.Title, t.Body)
+	}
+	if b.Len() == 0 {
+		return "", fmt.Errorf("unknown topic %q; topics are %s", topic, strings.Join(helpTopicNames(), ", "))
+	}
+	return b.String(), nil
+}
+
+// Create handler for the help tool
+func createHelpHandler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		topic, ok := request.Params.Arguments["topic"].(string)
+		if !ok && request.Params.Arguments["topic"] != nil {
+			return nil, fmt.Errorf("topic must be a string")
+		}
+
+		help, err := renderHelp(topic)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(help), nil
+	}
+}
+
+// Create handler for the help resource
+func createHelpResourceHandler() func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		help, err := renderHelp("")
+		if err != nil {
+			return nil, err
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      "dgraph://help",
+				MIMEType: "text/markdown",
+				Text:     help,
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderHelp(t *testing.T) {
+	all, err := renderHelp("")
+	if err != nil {
+		t.Fatalf("renderHelp() error = %v", err)
+	}
+	for _, topic := range helpTopics {
+		got, err := renderHelp(topic.Name)
+		if err != nil {
+			t.Fatalf("renderHelp(%q) error = %v", topic.Name, err)
+		}
+		if !strings.HasPrefix(got, "## "+topic.Title+"\n") {
+			t.Errorf("renderHelp(%q) = %q, want it to start with the title", topic.Name, got)
+		}
+		if !strings.Contains(all, got) {
+			t.Errorf("renderHelp(\"\") is missing topic %q", topic.Name)
+		}
+	}
+
+	if _, err := renderHelp("mutations"); err == nil || !strings.Contains(err.Error(), "functions, directives") {
+		t.Errorf("renderHelp() error = %v, want it to list the topics", err)
+	}
+}
+
+func TestHelpQueriesParse(t *testing.T) {
+	// The query skeletons are meant to be copied, so they must be valid
+	for _, topic := range helpTopics {
+		blocks := strings.Split(topic.Body, "```\n")
+		for i := 1; i < len(blocks); i += 2 {
+			query := blocks[i]
+			if !strings.HasPrefix(query, "{") {
+				continue
+			}
+			if _, err := parseQueryBlocks(query); err != nil {
+				t.Errorf("topic %q: query %q doesn't parse: %v", topic.Name, query, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default number of N-Quads or JSON objects committed per transaction
+const defaultImportBatchSize = 1000
+
+// importStats counts the progress of a file import
+type importStats struct {
+	Batches int   // committed transactions
+	Records int   // N-Quads or JSON objects written
+	Nodes   int   // nodes created from blank nodes
+	Bytes   int64 // bytes read from the file
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Detect the format of an import file from its name: rdf for .rdf and .nq,
+// json for .json, each optionally gzip-compressed with .gz
+func importFormat(path string) (format string, gzipped bool, err error) {
+	name := strings.ToLower(filepath.Base(path))
+	if strings.HasSuffix(name, ".gz") {
+		gzipped = true
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	switch filepath.Ext(name) {
+	case ".rdf", ".nq":
+		return "rdf", gzipped, nil
+	case ".json":
+		return "json", gzipped, nil
+	}
+	return "", false, fmt.Errorf("unsupported file type %s; use .rdf, .nq or .json, optionally with .gz", filepath.Base(path))
+}
+
+// Replace "uid" values naming already assigned blank nodes (_:name) with
+// their uids, in nested objects too
+func rewriteJSONBlankNodes(value interface{}, uids map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if s, ok := item.(string); ok && key == "uid" && strings.HasPrefix(s, "_:") {
+				if uid, ok := uids[s[2:]]; ok {
+					v[key] = uid
+				}
+				continue
+			}
+			rewriteJSONBlankNodes(item, uids)
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteJSONBlankNodes(item, uids)
+		}
+	}
+}
+
+// importer writes records in batches through commit, which runs one
+// committed mutation and returns the uids assigned to its blank nodes.
+// Blank nodes keep their uid across batches, so a node may be referenced
+// after the batch creating it.
+type importer struct {
+	batchSize int
+	commit    func(mu *api.Mutation) (map[string]string, error)
+	progress  func(stats importStats)
+	input     *countingReader
+	uids      map[string]string
+	stats     importStats
+}
+
+// Commit one batch and record the blank nodes it created
+func (im *importer) flush(mu *api.Mutation, records int) error {
+	assigned, err := im.commit(mu)
+	if err != nil {
+		return fmt.Errorf("batch %d failed after %d committed batches: %v", im.stats.Batches+1, im.stats.Batches, err)
+	}
+	for name, uid := range assigned {
+		if _, ok := im.uids[name]; !ok {
+			im.uids[name] = uid
+			im.stats.Nodes++
+		}
+	}
+	im.stats.Batches++
+	im.stats.Records += records
+	im.stats.Bytes = im.input.n
+	if im.progress != nil {
+		im.progress(im.stats)
+	}
+	return nil
+}
+
+// Import N-Quads, one per line. Lines are checked before they are batched,
+// so a syntax error stops the import without sending the bad batch.
+func (im *importer) importRDF(r io.Reader) error {
+	br := bufio.NewReader(r)
+	var batch []string
+	send := func() error {
+		nquads := rewriteBlankNodes(strings.Join(batch, "\n"), im.uids)
+		if err := predicateAccess.checkNQuads(nquads); err != nil {
+			return err
+		}
+		err := im.flush(&api.Mutation{SetNquads: []byte(nquads), CommitNow: true}, len(batch))
+		batch = batch[:0]
+		return err
+	}
+
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read file: %v", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			if err := validateNQuadLine(line, false); err != nil {
+				err.(*nquadError).Line = n
+				return err
+			}
+			batch = append(batch, line)
+			if len(batch) == im.batchSize {
+				if err := send(); err != nil {
+					return err
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if len(batch) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// Import JSON objects from a top-level array, a single object or a stream
+// of objects
+func (im *importer) importJSON(r io.Reader) error {
+	br := bufio.NewReader(r)
+	dec := json.NewDecoder(br)
+	dec.UseNumber()
+
+	var batch []interface{}
+	send := func() error {
+		for _, obj := range batch {
+			rewriteJSONBlankNodes(obj, im.uids)
+		}
+		if err := predicateAccess.checkJSON(batch); err != nil {
+			return err
+		}
+		data, err := json.Marshal(batch)
+		if err != nil {
+			return fmt.Errorf("failed to encode batch: %v", err)
+		}
+		err = im.flush(&api.Mutation{SetJson: data, CommitNow: true}, len(batch))
+		batch = batch[:0]
+		return err
+	}
+	add := func(value interface{}) error {
+		if _, ok := value.(map[string]interface{}); !ok {
+			return fmt.Errorf("object %d: expected a JSON object", im.stats.Records+len(batch)+1)
+		}
+		batch = append(batch, value)
+		if len(batch) == im.batchSize {
+			return send()
+		}
+		return nil
+	}
+
+	// Stream the elements of a top-level array instead of decoding it whole
+	first, err := peekNonSpace(br)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %v", err)
+	}
+	if first == '[' {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+		for dec.More() {
+			var value interface{}
+			if err := dec.Decode(&value); err != nil {
+				return fmt.Errorf("invalid JSON in object %d: %v", im.stats.Records+len(batch)+1, err)
+			}
+			if err := add(value); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("invalid JSON: %v", err)
+		}
+	} else {
+		for {
+			var value interface{}
+			err := dec.Decode(&value)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("invalid JSON in object %d: %v", im.stats.Records+len(batch)+1, err)
+			}
+			if err := add(value); err != nil {
+				return err
+			}
+		}
+	}
+	if len(batch) > 0 {
+		return send()
+	}
+	return nil
+}
+
+// Return the first non-space byte of a reader without consuming it
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		c, err := br.Peek(1)
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		switch c[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+		default:
+			return c[0], nil
+		}
+	}
+}
+
+// Import a file in the given format, decompressing it first if gzipped
+func (im *importer) run(r io.Reader, format string, gzipped bool) error {
+	im.input = &countingReader{r: r}
+	im.uids = map[string]string{}
+	var in io.Reader = im.input
+	if gzipped {
+		zr, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip file: %v", err)
+		}
+		defer zr.Close()
+		in = zr
+	}
+
+	var err error
+	if format == "rdf" {
+		err = im.importRDF(in)
+	} else {
+		err = im.importJSON(in)
+	}
+	im.stats.Bytes = im.input.n
+	return err
+}
+
+// Create handler for the file import tool
+func createImportFileHandler(client *dgo.Dgraph, dirs []string, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		path, ok := request.Params.Arguments["path"].(string)
+		if !ok || strings.TrimSpace(path) == "" {
+			return nil, fmt.Errorf("path must be a non-empty string")
+		}
+		batchSize, err := intArgument(request, "batch_size", defaultImportBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if batchSize < 1 {
+			return nil, fmt.Errorf("batch_size must be at least 1")
+		}
+		format, gzipped, err := importFormat(path)
+		if err != nil {
+			return nil, err
+		}
+		resolved, err := resolveAllowedPath(path, dirs, "import file", "DGRAPH_IMPORT_DIR")
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open import file: %v", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open import file: %v", err)
+		}
+
+		im := &importer{
+			batchSize: batchSize,
+			commit: func(mu *api.Mutation) (map[string]string, error) {
+				var uids map[string]string
+				_, err := retry.do(ctx, func() error {
+					txn := activity.startTxn(client.NewTxn())
+					defer activity.finishTxn(ctx, txn)
+					resp, err := txn.Mutate(ctx, mu)
+					if err != nil {
+						return err
+					}
+					uids = resp.Uids
+					return nil
+				})
+				return uids, err
+			},
+			progress: func(stats importStats) {
+				notifyProgress(ctx, request, int(stats.Bytes), int(info.Size()))
+			},
+		}
+
+		start := time.Now()
+		err = im.run(f, format, gzipped)
+		elapsed := time.Since(start)
+		if im.stats.Batches > 0 {
+			// Committed batches change data and can add predicates
+			invalidateCaches()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("import of %s stopped after %d records and %d nodes: %v", filepath.Base(resolved), im.stats.Records, im.stats.Nodes, err)
+		}
+
+		seconds := elapsed.Seconds()
+		perSecond := 0.0
+		if seconds > 0 {
+			perSecond = float64(im.stats.Records) / seconds
+		}
+		slog.Info("Imported file", "path", resolved, "records", im.stats.Records, "nodes", im.stats.Nodes, "batches", im.stats.Batches, "duration", elapsed)
+
+		out, err := json.Marshal(map[string]interface{}{
+			"message":            fmt.Sprintf("Imported %d records from %s in %d batches, creating %d nodes", im.stats.Records, filepath.Base(resolved), im.stats.Batches, im.stats.Nodes),
+			"format":             format,
+			"gzip":               gzipped,
+			"records":            im.stats.Records,
+			"nodes_created":      im.stats.Nodes,
+			"batches":            im.stats.Batches,
+			"bytes":              im.stats.Bytes,
+			"seconds":            seconds,
+			"records_per_second": perSecond,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode import result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+)
+
+func TestImportFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		format  string
+		gzipped bool
+		wantErr bool
+	}{
+		{"data.rdf", "rdf", false, false},
+		{"dir/data.nq", "rdf", false, false},
+		{"DATA.RDF.GZ", "rdf", true, false},
+		{"data.json", "json", false, false},
+		{"data.json.gz", "json", true, false},
+		{"data.csv", "", false, true},
+		{"data.gz", "", false, true},
+	}
+	for _, tt := range tests {
+		format, gzipped, err := importFormat(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("importFormat(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			continue
+		}
+		if format != tt.format || gzipped != tt.gzipped {
+			t.Errorf("importFormat(%q) = %q, %v, want %q, %v", tt.path, format, gzipped, tt.format, tt.gzipped)
+		}
+	}
+}
+
+func TestRewriteJSONBlankNodes(t *testing.T) {
+	obj := map[string]interface{}{
+		"uid":     "_:b",
+		"name":    "_:a",
+		"friends": []interface{}{map[string]interface{}{"uid": "_:a"}, map[string]interface{}{"uid": "_:c"}},
+	}
+	rewriteJSONBlankNodes(obj, map[string]string{"a": "0x1", "b": "0x2"})
+	want := map[string]interface{}{
+		"uid":     "0x2",
+		"name":    "_:a",
+		"friends": []interface{}{map[string]interface{}{"uid": "0x1"}, map[string]interface{}{"uid": "_:c"}},
+	}
+	if !reflect.DeepEqual(obj, want) {
+		t.Errorf("rewriteJSONBlankNodes() = %v, want %v", obj, want)
+	}
+}
+
+var importBlankRe = regexp.MustCompile(`_:(\w+)`)
+
+// recordingImporter returns an importer whose commits assign a new uid to
+// every blank node left in a batch and record the batches
+func recordingImporter(batchSize int) (*importer, *[]*api.Mutation) {
+	var batches []*api.Mutation
+	next := 0
+	im := &importer{
+		batchSize: batchSize,
+		commit: func(mu *api.Mutation) (map[string]string, error) {
+			batches = append(batches, mu)
+			uids := map[string]string{}
+			for _, m := range importBlankRe.FindAllStringSubmatch(string(mu.SetNquads)+string(mu.SetJson), -1) {
+				if _, ok := uids[m[1]]; !ok {
+					next++
+					uids[m[1]] = fmt.Sprintf("0x%x", next)
+				}
+			}
+			return uids, nil
+		},
+	}
+	return im, &batches
+}
+
+func TestImportRDF(t *testing.T) {
+	input := "# movies\n_:a <name> \"A\" .\n\n_:b <name> \"B\" .\r\n_:b <friend> _:a .\n_:c <friend> _:b ."
+	im, batches := recordingImporter(2)
+	if err := im.run(strings.NewReader(input), "rdf", false); err != nil {
+		t.Fatalf("run() failed: %v", err)
+	}
+
+	want := []string{
+		"_:a <name> \"A\" .\n_:b <name> \"B\" .",
+		"<0x2> <friend> <0x1> .\n_:c <friend> <0x2> .",
+	}
+	if len(*batches) != len(want) {
+		t.Fatalf("got %d batches, want %d", len(*batches), len(want))
+	}
+	for i, mu := range *batches {
+		if string(mu.SetNquads) != want[i] || !mu.CommitNow {
+			t.Errorf("batch %d = %q (commit %v), want %q committed", i, mu.SetNquads, mu.CommitNow, want[i])
+		}
+	}
+	wantStats := importStats{Batches: 2, Records: 4, Nodes: 3, Bytes: int64(len(input))}
+	if im.stats != wantStats {
+		t.Errorf("stats = %+v, want %+v", im.stats, wantStats)
+	}
+}
+
+func TestImportRDFSyntaxError(t *testing.T) {
+	im, batches := recordingImporter(10)
+	err := im.run(strings.NewReader("_:a <name> \"A\" .\n_:a <name> \"B\"\n"), "rdf", false)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("run() error = %v, want an error at line 2", err)
+	}
+	if len(*batches) != 0 {
+		t.Errorf("got %d batches, want none", len(*batches))
+	}
+}
+
+func TestImportJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"array", `[{"uid": "_:a", "name": "A"}, {"uid": "_:b", "friend": {"uid": "_:a"}}, {"name": "C"}]`,
+			[]string{`[{"name":"A","uid":"_:a"},{"friend":{"uid":"_:a"},"uid":"_:b"}]`, `[{"name":"C"}]`}},
+		{"object stream", "{\"uid\": \"_:a\", \"n\": 1}\n{\"uid\": \"_:b\"}\n{\"friend\": {\"uid\": \"_:a\"}}",
+			[]string{`[{"n":1,"uid":"_:a"},{"uid":"_:b"}]`, `[{"friend":{"uid":"0x1"}}]`}},
+		{"single object", ` {"name": "A"}`, []string{`[{"name":"A"}]`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im, batches := recordingImporter(2)
+			if err := im.run(strings.NewReader(tt.input), "json", false); err != nil {
+				t.Fatalf("run() failed: %v", err)
+			}
+			var got []string
+			for _, mu := range *batches {
+				got = append(got, string(mu.SetJson))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("batches = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	im, _ := recordingImporter(2)
+	if err := im.run(strings.NewReader(`[{"a": 1}, 2]`), "json", false); err == nil {
+		t.Errorf("run() with a non-object succeeded, want error")
+	}
+}
+
+func TestImportGzip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte("_:a <name> \"A\" .\n"))
+	zw.Close()
+
+	im, batches := recordingImporter(10)
+	if err := im.run(bytes.NewReader(buf.Bytes()), "rdf", true); err != nil {
+		t.Fatalf("run() failed: %v", err)
+	}
+	if len(*batches) != 1 || string((*batches)[0].SetNquads) != "_:a <name> \"A\" ." {
+		t.Errorf("batches = %v, want the decompressed N-Quad", *batches)
+	}
+	if im.stats.Bytes != int64(buf.Len()) {
+		t.Errorf("bytes = %d, want the compressed size %d", im.stats.Bytes, buf.Len())
+	}
+
+	im, _ = recordingImporter(10)
+	if err := im.run(strings.NewReader("not gzip"), "rdf", true); err == nil {
+		t.Errorf("run() with invalid gzip succeeded, want error")
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// indexedFuncRe matches calls of functions that need an index on the
+// predicate given as their first argument
+var indexedFuncRe = regexp.MustCompile(`\b(eq|lt|le|gt|ge|allofterms|anyofterms|alloftext|anyoftext|regexp|match|near|within|contains|intersects)\s*\(\s*([A-Za-z_][\w.]*)\s*,`)
+
+// Tokenizers a function can use on a string predicate. Any index will do
+// for comparisons on other scalar types, which have a single tokenizer.
+var funcTokenizers = map[string][]string{
+	"eq":         {"exact", "hash", "term"},
+	"lt":         {"exact"},
+	"le":         {"exact"},
+	"gt":         {"exact"},
+	"ge":         {"exact"},
+	"allofterms": {"term"},
+	"anyofterms": {"term"},
+	"alloftext":  {"fulltext"},
+	"anyoftext":  {"fulltext"},
+	"regexp":     {"trigram"},
+	"match":      {"trigram"},
+	"near":       {"geo"},
+	"within":     {"geo"},
+	"contains":   {"geo"},
+	"intersects": {"geo"},
+}
+
+// Default tokenizers of non-string scalar types
+var defaultTypeTokenizers = map[string]string{
+	"int":      "int",
+	"float":    "float",
+	"bool":     "bool",
+	"datetime": "hour",
+	"geo":      "geo",
+}
+
+// indexUsage is an indexed predicate and its tokenizers
+type indexUsage struct {
+	Predicate  string   `json:"predicate"`
+	Type       string   `json:"type"`
+	Tokenizers []string `json:"tokenizers"`
+}
+
+// indexWarning is a function call in a query that needs a missing index
+type indexWarning struct {
+	Function   string `json:"function"`
+	Predicate  string `json:"predicate"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// indexAudit lists the indexes in the schema and the index problems of a query
+type indexAudit struct {
+	Indexes  []indexUsage   `json:"indexes"`
+	Warnings []indexWarning `json:"warnings"`
+}
+
+// List the indexed predicates of a schema
+func listIndexes(schema *schemaInfo) []indexUsage {
+	indexes := []indexUsage{}
+	for _, p := range schema.Predicates {
+		if !p.Index || isInternalName(p.Predicate) {
+			continue
+		}
+		indexes = append(indexes, indexUsage{Predicate: p.Predicate, Type: p.Type, Tokenizers: p.Tokenizer})
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Predicate < indexes[j].Predicate })
+	return indexes
+}
+
+// Find the function calls of a query needing an index their predicate lacks
+func auditQueryIndexes(schema *schemaInfo, query string) []indexWarning {
+	warnings := []indexWarning{}
+	seen := map[string]bool{}
+	for _, m := range indexedFuncRe.FindAllStringSubmatch(query, -1) {
+		fn, predicate := m[1], m[2]
+		if seen[fn+" "+predicate] {
+			continue
+		}
+		seen[fn+" "+predicate] = true
+
+		p, ok := schema.predicate(predicate)
+		if !ok {
+			warnings = append(warnings, indexWarning{
+				Function:  fn,
+				Predicate: predicate,
+				Message:   fmt.Sprintf("predicate %q is not in the schema", predicate),
+			})
+			continue
+		}
+
+		tokenizer, ok := missingTokenizer(p, fn)
+		if !ok {
+			continue
+		}
+		if tokenizer == "" {
+			warnings = append(warnings, indexWarning{
+				Function:  fn,
+				Predicate: predicate,
+				Message:   fmt.Sprintf("%s cannot be used on predicate %q of type %s", fn, predicate, p.Type),
+			})
+			continue
+		}
+
+		suggested := p
+		suggested.Index = true
+		suggested.Tokenizer = append(append([]string{}, p.Tokenizer...), tokenizer)
+		warnings = append(warnings, indexWarning{
+			Function:   fn,
+			Predicate:  predicate,
+			Message:    fmt.Sprintf("%s on %q needs a %s index", fn, predicate, tokenizer),
+			Suggestion: suggested.String(),
+		})
+	}
+	return warnings
+}
+
+// Find the tokenizer a predicate is missing for a function. Returns false if
+// nothing is missing, and an empty tokenizer if no index supports the
+// function on the predicate's type.
+func missingTokenizer(p predicateSchema, fn string) (string, bool) {
+	has := map[string]bool{}
+	for _, tok := range p.Tokenizer {
+		has[tok] = true
+	}
+
+	tokenizers := funcTokenizers[fn]
+	switch {
+	case tokenizers[0] == "geo":
+		if p.Type != "geo" {
+			return "", true
+		}
+	case p.Type == "string":
+		for _, tok := range tokenizers {
+			if has[tok] {
+				return "", false
+			}
+		}
+		return tokenizers[0], true
+	case fn == "eq" || fn == "lt" || fn == "le" || fn == "gt" || fn == "ge":
+		tok, ok := defaultTypeTokenizers[p.Type]
+		if !ok {
+			return "", true
+		}
+		if p.Index {
+			return "", false
+		}
+		return tok, true
+	default:
+		// Term, fulltext and trigram matching only work on strings
+		return "", true
+	}
+
+	if p.Index {
+		return "", false
+	}
+	return "geo", true
+}
+
+// Check that a predicate exists and has the index a function needs,
+// suggesting the schema line to add if it doesn't
+func checkIndexedPredicate(schema *schemaInfo, predicate, fn string) error {
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		return fmt.Errorf("predicate %q is not in the schema", predicate)
+	}
+	tokenizer, missing := missingTokenizer(p, fn)
+	if !missing {
+		return nil
+	}
+	if tokenizer == "" {
+		return fmt.Errorf("%s cannot be used on predicate %q of type %s", fn, predicate, p.Type)
+	}
+	suggested := p
+	suggested.Index = true
+	suggested.Tokenizer = append(append([]string{}, p.Tokenizer...), tokenizer)
+	return fmt.Errorf("predicate %q has no %s index, which %s needs; add it with dgraph_alter_schema, e.g. %s", predicate, tokenizer, fn, suggested.String())
+}
+
+// notIndexedRe matches the errors Dgraph returns for a function used on a
+// predicate without the index it needs, capturing the predicate
+var notIndexedRe = regexp.MustCompile(`(?:Attribute|Predicate) (\S+) (?:is not indexed|does not have (?:a valid tokenizer|trigram index))`)
+
+// indexSuggestion is the schema change fixing a "not indexed" query error
+type indexSuggestion struct {
+	Predicate string   `json:"predicate"`
+	Functions []string `json:"functions,omitempty"`
+	Index     []string `json:"index,omitempty"`
+	Schema    string   `json:"schema,omitempty"`
+	NextStep  string   `json:"next_step"`
+}
+
+// Work out the index a predicate needs for the functions a query uses on it
+func suggestIndex(schema *schemaInfo, query, predicate string) indexSuggestion {
+	s := indexSuggestion{Predicate: predicate}
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		s.NextStep = fmt.Sprintf("predicate %q is not in the schema; add it with an index using dgraph_alter_schema", predicate)
+		return s
+	}
+
+	suggested := p
+	for _, m := range indexedFuncRe.FindAllStringSubmatch(query, -1) {
+		fn := m[1]
+		if m[2] != predicate {
+			continue
+		}
+		tokenizer, missing := missingTokenizer(suggested, fn)
+		if !missing || tokenizer == "" {
+			continue
+		}
+		s.Functions = append(s.Functions, fn)
+		s.Index = append(s.Index, tokenizer)
+		suggested.Index = true
+		suggested.Tokenizer = append(append([]string{}, suggested.Tokenizer...), tokenizer)
+	}
+
+	if len(s.Index) == 0 {
+		s.NextStep = "run dgraph_index_audit with the query to find the index it needs"
+		return s
+	}
+	s.Schema = suggested.String()
+	s.NextStep = "apply the schema line with dgraph_alter_schema, then run the query again"
+	return s
+}
+
+// Turn a "not indexed" query error into a suggestion for the schema change
+// fixing it, to append to the error. Returns an empty string for other errors.
+func notIndexedHint(ctx context.Context, client *dgo.Dgraph, query string, err error) string {
+	m := notIndexedRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return ""
+	}
+	predicate := strings.TrimRight(m[1], ".:")
+
+	schema, schemaErr := fetchSchema(ctx, client, false)
+	if schemaErr != nil {
+		return ""
+	}
+	out, jsonErr := json.Marshal(suggestIndex(schema, query, predicate))
+	if jsonErr != nil {
+		return ""
+	}
+	return "\nSuggestion: " + string(out)
+}
+
+// Create handler for the index audit tool
+func createIndexAuditHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		audit := indexAudit{Indexes: listIndexes(schema), Warnings: []indexWarning{}}
+		if query, ok := request.Params.Arguments["query"].(string); ok && query != "" {
+			audit.Warnings = auditQueryIndexes(schema, query)
+		}
+
+		out, err := json.Marshal(audit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode audit: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
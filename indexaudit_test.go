@@ -0,0 +1,183 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuditQueryIndexes(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "bio", Type: "string"},
+		{Predicate: "age", Type: "int"},
+		{Predicate: "score", Type: "float", Index: true, Tokenizer: []string{"float"}},
+		{Predicate: "loc", Type: "geo"},
+		{Predicate: "friend", Type: "uid"},
+	}}
+
+	query := `{
+		q(func: eq(name, "Alice")) @filter(alloftext(bio, "graph") AND gt(age, 30) AND ge(score, 1.5)) {
+			friend @filter(regexp(name, /^A/)) { name }
+			near(loc, [1, 2], 10)
+			has(bio)
+			eq(friend, "x")
+			anyofterms(missing, "x")
+		}
+	}`
+	warnings := auditQueryIndexes(schema, query)
+
+	want := map[string]string{
+		"alloftext bio":      "bio: string @index(fulltext) .",
+		"gt age":             "age: int @index(int) .",
+		"regexp name":        "name: string @index(exact, trigram) .",
+		"near loc":           "loc: geo @index(geo) .",
+		"eq friend":          "",
+		"anyofterms missing": "",
+	}
+	got := map[string]string{}
+	for _, w := range warnings {
+		got[w.Function+" "+w.Predicate] = w.Suggestion
+	}
+	for key, suggestion := range want {
+		s, ok := got[key]
+		if !ok {
+			t.Errorf("missing warning for %s", key)
+			continue
+		}
+		if s != suggestion {
+			t.Errorf("suggestion for %s = %q, want %q", key, s, suggestion)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			t.Errorf("unexpected warning for %s", key)
+		}
+	}
+}
+
+func TestListIndexes(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "bio", Type: "string"},
+		{Predicate: "dgraph.xid", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "age", Type: "int", Index: true, Tokenizer: []string{"int"}},
+	}}
+	got := listIndexes(schema)
+	if len(got) != 2 || got[0].Predicate != "age" || got[1].Predicate != "name" {
+		t.Errorf("listIndexes = %+v", got)
+	}
+}
+
+func TestCheckIndexedPredicate(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "bio", Type: "string", Index: true, Tokenizer: []string{"fulltext"}},
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"term"}},
+		{Predicate: "age", Type: "int"},
+	}}
+
+	tests := []struct {
+		predicate string
+		fn        string
+		want      string
+	}{
+		{"bio", "alloftext", ""},
+		{"name", "anyofterms", ""},
+		{"name", "alloftext", "@index(term, fulltext)"},
+		{"bio", "anyofterms", "has no term index"},
+		{"age", "alloftext", "cannot be used on predicate"},
+		{"missing", "alloftext", "not in the schema"},
+	}
+	for _, tt := range tests {
+		err := checkIndexedPredicate(schema, tt.predicate, tt.fn)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("checkIndexedPredicate(%q, %q) failed: %v", tt.predicate, tt.fn, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("checkIndexedPredicate(%q, %q) = %v, want error containing %q", tt.predicate, tt.fn, err, tt.want)
+		}
+	}
+}
+
+func TestSuggestIndex(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "bio", Type: "string"},
+	}}
+
+	tests := []struct {
+		name      string
+		query     string
+		predicate string
+		want      indexSuggestion
+	}{
+		{
+			name:      "missing tokenizers",
+			query:     `{ q(func: anyofterms(name, "a")) @filter(regexp(name, /^A/) AND anyofterms(name, "b")) { name } }`,
+			predicate: "name",
+			want: indexSuggestion{
+				Predicate: "name",
+				Functions: []string{"anyofterms", "regexp"},
+				Index:     []string{"term", "trigram"},
+				Schema:    "name: string @index(exact, term, trigram) .",
+				NextStep:  "apply the schema line with dgraph_alter_schema, then run the query again",
+			},
+		},
+		{
+			name:      "unindexed predicate",
+			query:     `{ q(func: alloftext(bio, "graph")) { bio } }`,
+			predicate: "bio",
+			want: indexSuggestion{
+				Predicate: "bio",
+				Functions: []string{"alloftext"},
+				Index:     []string{"fulltext"},
+				Schema:    "bio: string @index(fulltext) .",
+				NextStep:  "apply the schema line with dgraph_alter_schema, then run the query again",
+			},
+		},
+		{
+			name:      "function not found",
+			query:     `{ q(func: has(bio), orderasc: bio) { bio } }`,
+			predicate: "bio",
+			want:      indexSuggestion{Predicate: "bio", NextStep: "run dgraph_index_audit with the query to find the index it needs"},
+		},
+		{
+			name:      "unknown predicate",
+			query:     `{ q(func: eq(age, 3)) { age } }`,
+			predicate: "age",
+			want:      indexSuggestion{Predicate: "age", NextStep: `predicate "age" is not in the schema; add it with an index using dgraph_alter_schema`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestIndex(schema, tt.query, tt.predicate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestIndex() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotIndexedRe(t *testing.T) {
+	tests := []struct {
+		err  string
+		want string
+	}{
+		{err: "rpc error: code = Unknown desc = : Predicate name is not indexed", want: "name"},
+		{err: "rpc error: code = Unknown desc = Attribute bio is not indexed.", want: "bio"},
+		{err: "Attribute dgraph.xid is not indexed with type fulltext", want: "dgraph.xid"},
+		{err: "Attribute name does not have trigram index for regex matching. Please add trigram index.", want: "name"},
+		{err: "Predicate age does not have a valid tokenizer.", want: "age"},
+		{err: "Some variables are declared but not used", want: ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if m := notIndexedRe.FindStringSubmatch(tt.err); m != nil {
+			got = m[1]
+		}
+		if got != tt.want {
+			t.Errorf("notIndexedRe on %q = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Prefix of the blank nodes given to objects without a uid
+const jsonArrayBlankPrefix = "json_array_item_"
+
+// Give every object without a uid a blank node, so the uid assigned to it
+// can be reported. Returns the uid or blank node reference of each object.
+func prepareJSONArray(objects []interface{}) ([]string, error) {
+	refs := make([]string, len(objects))
+	for i, item := range objects {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("element %d must be an object", i)
+		}
+
+		switch uid := obj["uid"].(type) {
+		case nil:
+			refs[i] = fmt.Sprintf("_:%s%d", jsonArrayBlankPrefix, i)
+			obj["uid"] = refs[i]
+		case string:
+			if strings.HasPrefix(uid, "_:") {
+				refs[i] = uid
+				continue
+			}
+			normalized, err := normalizeUID(uid)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %v", i, err)
+			}
+			obj["uid"] = normalized
+			refs[i] = normalized
+		default:
+			return nil, fmt.Errorf("element %d: uid must be a string", i)
+		}
+	}
+	return refs, nil
+}
+
+// Resolve the uid of each object from the uids Dgraph assigned to blank nodes
+func resolveJSONArrayUIDs(refs []string, assigned map[string]string) []string {
+	uids := make([]string, len(refs))
+	for i, ref := range refs {
+		if name, ok := strings.CutPrefix(ref, "_:"); ok {
+			uids[i] = assigned[name]
+		} else {
+			uids[i] = ref
+		}
+	}
+	return uids
+}
+
+// Create handler for the JSON array mutation tool
+func createJSONArrayMutationHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		objects, ok := request.Params.Arguments["objects"].([]interface{})
+		if !ok || len(objects) == 0 {
+			return nil, fmt.Errorf("objects must be a non-empty array of objects")
+		}
+
+		// Write untagged strings of @lang predicates in the given language
+		tag, langSchema, err := langTagArgument(ctx, client, request)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" && tagLangValues(langSchema, objects, tag) == 0 {
+			return nil, noLangPredicatesError(tag)
+		}
+
+		refs, err := prepareJSONArray(objects)
+		if err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkJSON(objects); err != nil {
+			return nil, err
+		}
+
+		setJSON, err := json.Marshal(objects)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode objects: %v", err)
+		}
+
+		// Create transaction
+		txn := activity.startTxn(client.NewTxn())
+		defer activity.finishTxn(ctx, txn)
+
+		// Insert all objects in a single committed mutation
+		resp, err := txn.Mutate(ctx, &api.Mutation{
+			SetJson:   setJSON,
+			CommitNow: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mutation failed: %v", err)
+		}
+
+		// Mutations change data and can add predicates to the schema
+		invalidateCaches()
+
+		// Report the blank nodes named by the caller, not the generated ones
+		blankNodes := map[string]string{}
+		for name, uid := range resp.Uids {
+			if !strings.HasPrefix(name, jsonArrayBlankPrefix) {
+				blankNodes[name] = uid
+			}
+		}
+
+		out, err := json.Marshal(map[string]interface{}{
+			"message":     fmt.Sprintf("Inserted %d objects", len(objects)),
+			"uids":        resolveJSONArrayUIDs(refs, resp.Uids),
+			"blank_nodes": blankNodes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode mutation response: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
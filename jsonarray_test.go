@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrepareJSONArray(t *testing.T) {
+	objects := []interface{}{
+		map[string]interface{}{"name": "Alice"},
+		map[string]interface{}{"uid": "_:bob", "name": "Bob"},
+		map[string]interface{}{"uid": "31", "name": "Carol"},
+	}
+	refs, err := prepareJSONArray(objects)
+	if err != nil {
+		t.Fatalf("prepareJSONArray failed: %v", err)
+	}
+	want := []string{"_:json_array_item_0", "_:bob", "0x1f"}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("prepareJSONArray refs = %v, want %v", refs, want)
+	}
+	if uid := objects[0].(map[string]interface{})["uid"]; uid != "_:json_array_item_0" {
+		t.Errorf("object without uid got uid %v", uid)
+	}
+	if uid := objects[2].(map[string]interface{})["uid"]; uid != "0x1f" {
+		t.Errorf("object uid was not normalized: %v", uid)
+	}
+
+	uids := resolveJSONArrayUIDs(refs, map[string]string{"json_array_item_0": "0x1", "bob": "0x2"})
+	if !reflect.DeepEqual(uids, []string{"0x1", "0x2", "0x1f"}) {
+		t.Errorf("resolveJSONArrayUIDs = %v", uids)
+	}
+}
+
+func TestPrepareJSONArrayInvalid(t *testing.T) {
+	for _, objects := range [][]interface{}{
+		{"not an object"},
+		{map[string]interface{}{"uid": 5}},
+		{map[string]interface{}{"uid": "nope"}},
+	} {
+		if _, err := prepareJSONArray(objects); err == nil {
+			t.Errorf("prepareJSONArray(%v) succeeded, want error", objects)
+		}
+	}
+}
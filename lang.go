@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// langOption asks a query tool to read language-tagged strings
+var langOption = mcp.WithString("lang",
+	mcp.Description("Language to read string predicates with @lang in, e.g. fr, or a preference list such as fr:en:. ending in . to fall back to any language. Those fields come back keyed by the tagged name, e.g. name@fr:en:."),
+)
+
+// langTagOption asks a mutation tool to tag the strings it writes
+var langTagOption = mcp.WithString("lang",
+	mcp.Description("Language tag, e.g. fr or pt-BR, to write untagged strings of predicates with @lang in"),
+)
+
+// langTagRe matches a BCP 47 style language tag such as en, fr or zh-Hant-TW
+var langTagRe = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// Check that a language tag is well-formed
+func validateLangTag(tag string) error {
+	if !langTagRe.MatchString(tag) {
+		return fmt.Errorf("invalid language tag %q, expected e.g. en, fr or pt-BR", tag)
+	}
+	return nil
+}
+
+// Check a language preference list such as fr:en:. where the final .
+// stands for any language
+func validateLangPreference(lang string) error {
+	tags := strings.Split(lang, ":")
+	for i, tag := range tags {
+		if tag == "." && i == len(tags)-1 {
+			continue
+		}
+		if err := validateLangTag(tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check a field name with an optional language suffix, e.g. name@fr:en:.
+func validateField(kind, field string) error {
+	name, lang, tagged := strings.Cut(field, "@")
+	if err := validateName(kind, name); err != nil {
+		return err
+	}
+	if tagged {
+		if err := validateLangPreference(lang); err != nil {
+			return fmt.Errorf("%s %q: %v", kind, field, err)
+		}
+	}
+	return nil
+}
+
+// Check whether a predicate stores language-tagged strings
+func isLangPredicate(schema *schemaInfo, name string) bool {
+	p, ok := schema.predicate(name)
+	return ok && p.Type == "string" && p.Lang
+}
+
+// Append a language preference to the fields that have @lang and no tag yet
+func tagLangFields(schema *schemaInfo, fields []string, lang string) []string {
+	tagged := make([]string, len(fields))
+	for i, f := range fields {
+		tagged[i] = f
+		if !strings.Contains(f, "@") && isLangPredicate(schema, f) {
+			tagged[i] = f + "@" + lang
+		}
+	}
+	return tagged
+}
+
+// Apply the lang argument of a query tool to the fields it fetches, named
+// by param in errors. Fields are left alone without a lang argument.
+func langFields(ctx context.Context, client *dgo.Dgraph, request mcp.CallToolRequest, fields []string, param string) ([]string, error) {
+	lang, ok := request.Params.Arguments["lang"].(string)
+	if !ok && request.Params.Arguments["lang"] != nil {
+		return nil, fmt.Errorf("lang must be a string")
+	}
+	if lang == "" {
+		return fields, nil
+	}
+	if err := validateLangPreference(lang); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("lang needs %s listing the predicates to read", param)
+	}
+
+	refresh, err := boolArgument(request, "refresh", false)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := fetchSchema(ctx, client, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return tagLangFields(schema, fields, lang), nil
+}
+
+// Read the lang argument of a mutation tool, returning the schema to tag
+// values with, or nil without a lang argument
+func langTagArgument(ctx context.Context, client *dgo.Dgraph, request mcp.CallToolRequest) (string, *schemaInfo, error) {
+	tag, ok := request.Params.Arguments["lang"].(string)
+	if !ok && request.Params.Arguments["lang"] != nil {
+		return "", nil, fmt.Errorf("lang must be a string")
+	}
+	if tag == "" {
+		return "", nil, nil
+	}
+	if err := validateLangTag(tag); err != nil {
+		return "", nil, err
+	}
+	schema, err := fetchSchema(ctx, client, false)
+	if err != nil {
+		return "", nil, err
+	}
+	return tag, schema, nil
+}
+
+// Tag the string values of @lang predicates in JSON mutation objects by
+// renaming their keys, e.g. name to name@fr. Nested objects are tagged too.
+// Returns how many values were tagged.
+func tagLangValues(schema *schemaInfo, value interface{}, tag string) int {
+	count := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if _, isString := v[key].(string); isString && !strings.Contains(key, "@") && isLangPredicate(schema, key) {
+				v[key+"@"+tag] = v[key]
+				delete(v, key)
+				count++
+				continue
+			}
+			count += tagLangValues(schema, v[key], tag)
+		}
+	case []interface{}:
+		for _, item := range v {
+			count += tagLangValues(schema, item, tag)
+		}
+	}
+	return count
+}
+
+// Tag the untagged string literals of @lang predicates in N-Quads, e.g.
+// <0x1> <name> "Paris" . becomes <0x1> <name> "Paris"@fr . Returns the
+// rewritten N-Quads and how many literals were tagged.
+func tagLangNQuads(schema *schemaInfo, nquads, tag string) (string, int) {
+	lines := strings.Split(nquads, "\n")
+	count := 0
+	for n, line := range lines {
+		s := &nquadScanner{line: line}
+		s.skipSpace()
+		if s.pos == len(line) || line[s.pos] == '#' || s.term("subject", false) != nil {
+			continue
+		}
+		s.skipSpace()
+		start := s.pos
+		if s.term("predicate", false) != nil {
+			continue
+		}
+		predicate := strings.Trim(line[start:s.pos], "<>")
+
+		s.skipSpace()
+		if s.pos >= len(line) || line[s.pos] != '"' || !isLangPredicate(schema, predicate) {
+			continue
+		}
+		end, err := skipString(line, s.pos)
+		if err != nil || end+1 < len(line) && (line[end+1] == '@' || line[end+1] == '^') {
+			continue
+		}
+		lines[n] = line[:end+1] + "@" + tag + line[end+1:]
+		count++
+	}
+	return strings.Join(lines, "\n"), count
+}
+
+// Explain why a language tag had no effect on a mutation
+func noLangPredicatesError(tag string) error {
+	return fmt.Errorf("lang %q was given but no string written belongs to a predicate with @lang; add it with dgraph_alter_schema, e.g. name: string @lang .", tag)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var langTestSchema = &schemaInfo{Predicates: []predicateSchema{
+	{Predicate: "name", Type: "string", Lang: true},
+	{Predicate: "bio", Type: "string", Lang: true},
+	{Predicate: "email", Type: "string"},
+	{Predicate: "age", Type: "int"},
+}}
+
+func TestValidateField(t *testing.T) {
+	tests := []struct {
+		field   string
+		wantErr bool
+	}{
+		{field: "name"},
+		{field: "name@en"},
+		{field: "name@pt-BR"},
+		{field: "name@zh-Hant-TW"},
+		{field: "name@fr:en:."},
+		{field: "name@."},
+		{field: "name@", wantErr: true},
+		{field: "name@e", wantErr: true},
+		{field: "name@.:en", wantErr: true},
+		{field: "name@en fr", wantErr: true},
+		{field: "name@en-", wantErr: true},
+		{field: "na me@en", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if err := validateField("field", tt.field); (err != nil) != tt.wantErr {
+				t.Errorf("validateField(%q) error = %v, wantErr %v", tt.field, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTagLangFields(t *testing.T) {
+	got := tagLangFields(langTestSchema, []string{"name", "bio@de", "email", "age", "missing"}, "fr:en:.")
+	want := []string{"name@fr:en:.", "bio@de", "email", "age", "missing"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tagLangFields() = %v, want %v", got, want)
+	}
+}
+
+func TestTagLangValues(t *testing.T) {
+	var objects []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{"name": "Paris", "email": "a@example.com", "age": 3, "bio@en": "Hi", "friend": {"name": "Lyon", "bio": 1}},
+		{"uid": "_:x", "bio": "Salut"}
+	]`), &objects); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := tagLangValues(langTestSchema, objects, "fr"); n != 3 {
+		t.Errorf("tagLangValues() tagged %d values, want 3", n)
+	}
+	got, err := json.Marshal(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"age":3,"bio@en":"Hi","email":"a@example.com","friend":{"bio":1,"name@fr":"Lyon"},"name@fr":"Paris"},{"bio@fr":"Salut","uid":"_:x"}]`
+	if string(got) != want {
+		t.Errorf("tagLangValues() = %s, want %s", got, want)
+	}
+}
+
+func TestTagLangNQuads(t *testing.T) {
+	nquads := `<0x1> <name> "Paris" .
+<0x1> <name> "London"@en .
+_:x <bio> "Say \"hi\"" .
+<0x1> <email> "a@example.com" .
+<0x1> <age> "3"^^<xs:int> .
+# <0x1> <name> "comment" .`
+
+	got, n := tagLangNQuads(langTestSchema, nquads, "fr")
+	want := `<0x1> <name> "Paris"@fr .
+<0x1> <name> "London"@en .
+_:x <bio> "Say \"hi\""@fr .
+<0x1> <email> "a@example.com" .
+<0x1> <age> "3"^^<xs:int> .
+# <0x1> <name> "comment" .`
+	if got != want || n != 2 {
+		t.Errorf("tagLangNQuads() = %d tagged\n%s\nwant 2 tagged\n%s", n, got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Create a logger writing to stderr, since stdout carries the MCP protocol
+func newLogger(level, format string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q, must be text or json", format)
+}
+
+// Log an error and exit
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// Tool handler middleware logging every call. Arguments are only logged at
+// debug level, with sensitive values redacted.
+func logToolCalls(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		slog.Debug("Tool call started", "tool", request.Params.Name, "arguments", redactArguments(request.Params.Arguments))
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		attrs := []any{
+			"tool", request.Params.Name,
+			"duration", time.Since(start),
+			"error", err != nil || (result != nil && result.IsError),
+		}
+		if err != nil {
+			attrs = append(attrs, "reason", err.Error())
+		}
+		slog.Info("Tool call finished", attrs...)
+
+		return result, err
+	}
+}
+
+// Attach a diagnostic value to a tool result's metadata when debug logging
+// is enabled
+func addDebugMeta(ctx context.Context, result *mcp.CallToolResult, key string, value interface{}) *mcp.CallToolResult {
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return result
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta[key] = value
+	return result
+}
+
+// Replace values of arguments that look like secrets
+func redactArguments(args map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if isSensitiveKey(key) {
+			redacted[key] = "[REDACTED]"
+		} else {
+			redacted[key] = redactValue(value)
+		}
+	}
+	return redacted
+}
+
+// Redact secrets in objects nested in an argument value, such as the
+// operations of a batch
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactArguments(v)
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, elem := range v {
+			redacted[i] = redactValue(elem)
+		}
+		return redacted
+	}
+	return value
+}
+
+// Check whether an argument name suggests a secret value
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range []string{"password", "secret", "token", "authorization"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
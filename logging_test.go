@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestRedactArguments(t *testing.T) {
+	args := map[string]interface{}{
+		"query":    "{ q(func: has(name)) { name } }",
+		"password": "hunter2",
+		"headers": map[string]interface{}{
+			"Authorization": "Bearer abc",
+			"X-Trace":       "1",
+		},
+		"operations": []interface{}{
+			map[string]interface{}{"name": "first", "token": "abc"},
+			[]interface{}{map[string]interface{}{"secret": "xyz"}},
+			"plain",
+		},
+	}
+
+	redacted := redactArguments(args)
+
+	if redacted["query"] != args["query"] {
+		t.Errorf("query was altered: %v", redacted["query"])
+	}
+	if redacted["password"] != "[REDACTED]" {
+		t.Errorf("password was not redacted: %v", redacted["password"])
+	}
+	headers := redacted["headers"].(map[string]interface{})
+	if headers["Authorization"] != "[REDACTED]" || headers["X-Trace"] != "1" {
+		t.Errorf("nested arguments not redacted correctly: %v", headers)
+	}
+	ops := redacted["operations"].([]interface{})
+	if op := ops[0].(map[string]interface{}); op["token"] != "[REDACTED]" || op["name"] != "first" {
+		t.Errorf("object in list not redacted correctly: %v", op)
+	}
+	if op := ops[1].([]interface{})[0].(map[string]interface{}); op["secret"] != "[REDACTED]" {
+		t.Errorf("object in nested list not redacted: %v", op)
+	}
+	if ops[2] != "plain" {
+		t.Errorf("list element was altered: %v", ops[2])
+	}
+	if args["password"] != "hunter2" {
+		t.Error("original arguments were modified")
+	}
+	if op := args["operations"].([]interface{})[0].(map[string]interface{}); op["token"] != "abc" {
+		t.Error("original list elements were modified")
+	}
+}
@@ -2,77 +2,1334 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/dgraph-io/dgo/v2"
 	"github.com/dgraph-io/dgo/v2/protos/api"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 )
 
 // Default Dgraph connection settings
 const (
-	defaultDgraphHost = "localhost:9080"
+	defaultDgraphHost     = "localhost:9080"
+	defaultConnectTimeout = 30 * time.Second
+	initialConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 5 * time.Second
+	defaultMaxMsgSize     = 64 << 20 // 64MB
+	defaultConnPoolSize   = 1
+	defaultSSEAddr        = ":8080"
+
+	// Dgraph uses the gRPC server's default keepalive enforcement, which
+	// closes connections pinging more often than every 5 minutes
+	defaultKeepaliveTime    = 5 * time.Minute
+	defaultKeepaliveTimeout = 20 * time.Second
 )
 
-func main() {
-	// Get Dgraph connection settings from environment or use defaults
-	dgraphHost := getEnv("DGRAPH_HOST", defaultDgraphHost)
+func main() {
+	// Set up logging before anything else is logged
+	logger, err := newLogger(getEnv("LOG_LEVEL", "info"), getEnv("LOG_FORMAT", "text"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid logging configuration: %v\n", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger)
+
+	// Get Dgraph connection settings from environment or use defaults
+	dgraphHost := getEnv("DGRAPH_HOST", defaultDgraphHost)
+
+	maxRecvMsgSize, err := getEnvInt("DGRAPH_MAX_RECV_MSG_SIZE", defaultMaxMsgSize)
+	if err != nil {
+		fatal("Invalid DGRAPH_MAX_RECV_MSG_SIZE", "error", err)
+	}
+	maxSendMsgSize, err := getEnvInt("DGRAPH_MAX_SEND_MSG_SIZE", defaultMaxMsgSize)
+	if err != nil {
+		fatal("Invalid DGRAPH_MAX_SEND_MSG_SIZE", "error", err)
+	}
+
+	var keepaliveParams keepalive.ClientParameters
+	if keepaliveParams.Time, err = time.ParseDuration(getEnv("DGRAPH_KEEPALIVE_TIME", defaultKeepaliveTime.String())); err != nil {
+		fatal("Invalid DGRAPH_KEEPALIVE_TIME", "error", err)
+	}
+	if keepaliveParams.Timeout, err = time.ParseDuration(getEnv("DGRAPH_KEEPALIVE_TIMEOUT", defaultKeepaliveTimeout.String())); err != nil {
+		fatal("Invalid DGRAPH_KEEPALIVE_TIMEOUT", "error", err)
+	}
+	if keepaliveParams.PermitWithoutStream, err = strconv.ParseBool(getEnv("DGRAPH_KEEPALIVE_PERMIT_WITHOUT_STREAM", "false")); err != nil {
+		fatal("Invalid DGRAPH_KEEPALIVE_PERMIT_WITHOUT_STREAM", "error", err)
+	}
+	if keepaliveParams.Time > 0 && keepaliveParams.Time < defaultKeepaliveTime {
+		slog.Warn("DGRAPH_KEEPALIVE_TIME is below the gRPC server default minimum, Dgraph may close the connection for pinging too often", "time", keepaliveParams.Time, "minimum", defaultKeepaliveTime)
+	}
+
+	compression, err := strconv.ParseBool(getEnv("DGRAPH_COMPRESSION", "false"))
+	if err != nil {
+		fatal("Invalid DGRAPH_COMPRESSION", "error", err)
+	}
+
+	// Connect to every Alpha, waiting for them to become reachable but
+	// starting regardless so that orchestrated deploys don't depend on
+	// startup ordering
+	hosts, err := parseHosts(dgraphHost)
+	if err != nil {
+		fatal("Invalid DGRAPH_HOST", "error", err)
+	}
+	connectTimeout, err := time.ParseDuration(getEnv("DGRAPH_CONNECT_TIMEOUT", defaultConnectTimeout.String()))
+	if err != nil {
+		fatal("Invalid DGRAPH_CONNECT_TIMEOUT", "error", err)
+	}
+	reconnectTimeout, err := time.ParseDuration(getEnv("DGRAPH_RECONNECT_TIMEOUT", defaultReconnectTimeout.String()))
+	if err != nil {
+		fatal("Invalid DGRAPH_RECONNECT_TIMEOUT", "error", err)
+	}
+
+	// Reconnect lost connections when a call fails on them
+	reconnects := newReconnector(reconnectTimeout)
+
+	// Trace tool calls and the Dgraph calls they make when an OTLP
+	// collector is configured
+	var tracer *spanTracer
+	interceptors := []grpc.UnaryClientInterceptor{reconnects.lazyReconnect}
+	if endpoint := getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", ""); endpoint != "" {
+		if tracer, err = newOTLPTracer(context.Background(), getEnv("OTEL_SERVICE_NAME", defaultServiceName)); err != nil {
+			fatal("Failed to set up tracing", "error", err)
+		}
+		// Outermost, so that a span covers a call's reconnect
+		interceptors = append(tracer.unaryInterceptors(), interceptors...)
+		slog.Info("Exporting traces", "endpoint", endpoint)
+	}
+
+	dial := func(host string, compression bool) (*grpc.ClientConn, error) {
+		return dialDgraph(host, maxRecvMsgSize, maxSendMsgSize, keepaliveParams, compression, interceptors)
+	}
+	connected := connectAlphas(hosts, dial, compression, connectTimeout)
+	alphas, reachable := usableAlphas(connected)
+	if len(alphas) == 0 {
+		fatal("Failed to connect to Dgraph", "host", dgraphHost, "error", connected[0].err)
+	}
+
+	// Skip the unreachable Alphas, unless none is reachable
+	for _, alpha := range connected {
+		switch {
+		case alpha.conn == nil:
+			slog.Warn("Failed to connect to Dgraph, skipping it", "host", alpha.host, "error", alpha.err)
+		case alpha.err != nil && reachable:
+			slog.Warn("Dgraph is not reachable, skipping it", "host", alpha.host, "error", alpha.err)
+			alpha.conn.Close()
+		case alpha.err != nil:
+			slog.Warn("Dgraph is not reachable, starting anyway", "host", alpha.host, "error", alpha.err)
+		default:
+			slog.Info("Connected to Dgraph", "host", alpha.host)
+		}
+	}
+
+	// Spread concurrent requests over several connections to each Alpha
+	poolSize, err := getEnvInt("DGRAPH_CONN_POOL_SIZE", defaultConnPoolSize)
+	if err != nil {
+		fatal("Invalid DGRAPH_CONN_POOL_SIZE", "error", err)
+	}
+	if poolSize < 1 {
+		fatal("Invalid DGRAPH_CONN_POOL_SIZE, must be at least 1", "size", poolSize)
+	}
+	for i := range alphas {
+		if err := alphas[i].openPool(poolSize, dial); err != nil {
+			slog.Warn("Failed to open pooled Dgraph connection", "host", alphas[i].host, "error", err)
+		}
+	}
+	if poolSize > 1 {
+		slog.Info("Opened Dgraph connection pool", "connections_per_alpha", poolSize)
+	}
+
+	conns := alphaClients(alphas)
+	dgraphClient := dgo.NewDgraphClient(conns...)
+
+	// Log in through the namespace client pool when ACL credentials are set
+	var pool *namespaceClientPool
+	if user := getEnv("DGRAPH_USER", ""); user != "" {
+		loginTTL, err := time.ParseDuration(getEnv("DGRAPH_LOGIN_TTL", defaultLoginTTL.String()))
+		if err != nil {
+			fatal("Invalid DGRAPH_LOGIN_TTL", "error", err)
+		}
+
+		pool = newNamespaceClientPool(conns, loginTTL, aclLogin(user, getEnv("DGRAPH_PASSWORD", "")))
+		client, err := pool.get(context.Background(), defaultNamespace)
+		switch {
+		case err == nil:
+			dgraphClient = client
+			slog.Info("Logged in to Dgraph", "user", user)
+		case reachable:
+			fatal("Failed to log in to Dgraph", "user", user, "error", err)
+		default:
+			slog.Warn("Failed to log in to Dgraph, retrying on the next tool call", "user", user, "error", err)
+		}
+	}
+
+	// Without ACL, tool calls go through the shared client, which leaves out
+	// connections that are down
+	if pool == nil {
+		reconnects.track(alphas)
+	}
+	setClient(dgraphClient)
+
+	// Make sure the predicates and indexes the deployment relies on exist
+	// before serving
+	if path := getEnv("DGRAPH_BOOTSTRAP_SCHEMA", ""); path != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		applied, err := bootstrapSchema(ctx, dgraphClient, path)
+		cancel()
+		if err != nil {
+			fatal("Failed to apply DGRAPH_BOOTSTRAP_SCHEMA", "path", path, "error", err)
+		}
+		if !applied {
+			slog.Info("Bootstrap schema is already applied", "path", path)
+		}
+	}
+
+	maxRecurseDepth, err := getEnvInt("DGRAPH_MAX_RECURSE_DEPTH", defaultMaxRecurseDepth)
+	if err != nil {
+		fatal("Invalid DGRAPH_MAX_RECURSE_DEPTH", "error", err)
+	}
+
+	limits := queryLimits{
+		MaxLength: defaultMaxQueryLength,
+		MaxDepth:  defaultMaxQueryDepth,
+		MaxBlocks: defaultMaxQueryBlocks,
+	}
+	if limits.MaxLength, err = getEnvInt("DGRAPH_MAX_QUERY_LENGTH", limits.MaxLength); err != nil {
+		fatal("Invalid DGRAPH_MAX_QUERY_LENGTH", "error", err)
+	}
+	if limits.MaxDepth, err = getEnvInt("DGRAPH_MAX_QUERY_DEPTH", limits.MaxDepth); err != nil {
+		fatal("Invalid DGRAPH_MAX_QUERY_DEPTH", "error", err)
+	}
+	if limits.MaxBlocks, err = getEnvInt("DGRAPH_MAX_QUERY_BLOCKS", limits.MaxBlocks); err != nil {
+		fatal("Invalid DGRAPH_MAX_QUERY_BLOCKS", "error", err)
+	}
+	if limits.DefaultFirst, err = getEnvInt("DGRAPH_DEFAULT_FIRST", 0); err != nil || limits.DefaultFirst < 0 {
+		fatal("Invalid DGRAPH_DEFAULT_FIRST", "value", getEnv("DGRAPH_DEFAULT_FIRST", ""), "error", err)
+	}
+
+	predicateAccess = newPredicatePolicy(getEnv("DGRAPH_ALLOWED_PREDICATES", ""), getEnv("DGRAPH_DENIED_PREDICATES", ""))
+	if predicateAccess.active() {
+		slog.Info("Predicate access is restricted", "allowed", sortedKeys(predicateAccess.allowed), "denied", sortedKeys(predicateAccess.denied))
+	}
+
+	defaultCommit, err := strconv.ParseBool(getEnv("DGRAPH_DEFAULT_COMMIT", "true"))
+	if err != nil {
+		fatal("Invalid DGRAPH_DEFAULT_COMMIT", "error", err)
+	}
+
+	txnTTL, err := time.ParseDuration(getEnv("DGRAPH_TXN_TTL", defaultTxnTTL.String()))
+	if err != nil {
+		fatal("Invalid DGRAPH_TXN_TTL", "error", err)
+	}
+	txns := newTxnRegistry(txnTTL)
+
+	upsertRetries, err := getEnvInt("DGRAPH_UPSERT_RETRIES", defaultUpsertRetries)
+	if err != nil || upsertRetries < 0 {
+		fatal("Invalid DGRAPH_UPSERT_RETRIES", "value", getEnv("DGRAPH_UPSERT_RETRIES", ""), "error", err)
+	}
+	upsertRetry := newRetryPolicy(upsertRetries)
+
+	shutdownTimeout, err := time.ParseDuration(getEnv("MCP_SHUTDOWN_TIMEOUT", defaultShutdownTimeout.String()))
+	if err != nil {
+		fatal("Invalid MCP_SHUTDOWN_TIMEOUT", "error", err)
+	}
+
+	queryCacheSize, err := getEnvInt("MCP_QUERY_CACHE_SIZE", 0)
+	if err != nil {
+		fatal("Invalid MCP_QUERY_CACHE_SIZE", "error", err)
+	}
+	queryCacheTTL, err := time.ParseDuration(getEnv("MCP_QUERY_CACHE_TTL", defaultQueryCacheTTL.String()))
+	if err != nil {
+		fatal("Invalid MCP_QUERY_CACHE_TTL", "error", err)
+	}
+	queryResults = newQueryCache(queryCacheSize, queryCacheTTL)
+
+	streamThreshold, err := getEnvInt("MCP_STREAM_THRESHOLD", defaultStreamThreshold)
+	if err != nil {
+		fatal("Invalid MCP_STREAM_THRESHOLD", "error", err)
+	}
+
+	readOnly, err := strconv.ParseBool(getEnv("DGRAPH_READONLY", "false"))
+	if err != nil {
+		fatal("Invalid DGRAPH_READONLY", "error", err)
+	}
+	if readOnly {
+		slog.Warn("READ-ONLY MODE: mutating tools are disabled and queries run in read-only transactions")
+	}
+
+	adminEnabled, err := strconv.ParseBool(getEnv("DGRAPH_ADMIN_ENABLED", "false"))
+	if err != nil {
+		fatal("Invalid DGRAPH_ADMIN_ENABLED", "error", err)
+	}
+	adminEndpoint := getEnv("DGRAPH_ADMIN_ENDPOINT", defaultAdminEndpoint)
+	adminAuthToken := getEnv("DGRAPH_ADMIN_AUTH_TOKEN", "")
+
+	// Load the query templates curated by the operator
+	var templates *templateRegistry
+	if path := getEnv("MCP_QUERY_TEMPLATES", ""); path != "" {
+		if templates, err = loadQueryTemplates(path); err != nil {
+			fatal("Invalid MCP_QUERY_TEMPLATES", "error", err)
+		}
+		slog.Info("Loaded query templates", "templates", templates.names())
+	}
+
+	serverOptions := []server.ServerOption{
+		server.WithToolHandlerMiddleware(activity.toolMiddleware),
+		server.WithToolHandlerMiddleware(logToolCalls),
+		server.WithToolHandlerMiddleware(namespaceMiddleware(pool)),
+	}
+
+	// Split large query results for SSE clients only
+	transport := getEnv("MCP_TRANSPORT", "stdio")
+	if transport == "sse" {
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(chunkLargeResults(streamThreshold)))
+	}
+
+	if tracer != nil {
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(tracer.toolMiddleware))
+	}
+
+	// Expose tool metrics for Prometheus when an address is configured
+	if metricsAddr := getEnv("MCP_METRICS_ADDR", ""); metricsAddr != "" {
+		metrics := newToolMetrics()
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(metrics.toolMiddleware))
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		go func() {
+			if err := http.ListenAndServe(metricsAddr, mux); err != nil {
+				slog.Error("Metrics server error", "error", err)
+			}
+		}()
+		slog.Info("Serving metrics", "addr", metricsAddr, "path", "/metrics")
+	}
+
+	// Create MCP server
+	s := server.NewMCPServer(
+		"Dgraph MCP Server",
+		"1.0.0",
+		serverOptions...,
+	)
+
+	// Add query tool
+	queryTool := mcp.NewTool("dgraph_query",
+		mcp.WithDescription("Execute a DQL query against Dgraph"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The DQL query to execute"),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Variables for the query, passed safely outside the query text. The query must declare them, e.g. query q($name: string) { ... } (optional)"),
+		),
+		mcp.WithBoolean("exists_only",
+			mcp.Description("Only check whether the query matches anything, returning {\"exists\": true|false} (default: false)"),
+		),
+		mcp.WithString("response_format",
+			mcp.Description("The result format: json, rdf for N-Quads (requires uid in every block), or csv with one row per top-level node and one column per predicate (default: json)"),
+			mcp.Enum("json", "rdf", "csv"),
+		),
+		mcp.WithString("txn_id",
+			mcp.Description("Run the query inside a transaction opened with dgraph_begin_txn (optional)"),
+		),
+		txnContextOption,
+		mcp.WithNumber("read_ts",
+			mcp.Description("Read the same database version as an earlier query, passing the read_ts from its result's _meta. Snapshots are kept for DGRAPH_TXN_TTL after their last use (optional)"),
+		),
+		mcp.WithBoolean("expand_all",
+			mcp.Description("Fetch all predicates of the nodes in each result block with expand(_all_). Nodes need a dgraph.type for this to work (default: false)"),
+		),
+		mcp.WithBoolean("normalize",
+			mcp.Description("Flatten nested results with @normalize. Only aliased fields (alias: predicate) are returned (default: false)"),
+		),
+		mcp.WithBoolean("cascade",
+			mcp.Description("Drop nodes missing any requested predicate with @cascade. Pass a list of predicates instead of true to only require those, via @cascade(pred1, pred2) (default: false)"),
+			// Accept a list of predicates as well as a boolean
+			func(schema map[string]interface{}) {
+				schema["type"] = []string{"boolean", "array"}
+				schema["items"] = map[string]interface{}{"type": "string"}
+			},
+		),
+		mcp.WithBoolean("omit_uids",
+			mcp.Description("Remove uid fields from the result to save space. Keep them if you need to refer to the nodes later (default: false)"),
+		),
+		mcp.WithBoolean("omit_types",
+			mcp.Description("Remove dgraph.type fields from the result (default: false)"),
+		),
+		mcp.WithBoolean("markdown",
+			mcp.Description("Also return a markdown table of the top-level results, with nested objects summarized, as a second content block (default: false)"),
+		),
+		mcp.WithBoolean("force",
+			mcp.Description("Run the query even if it is likely to scan the entire database, e.g. has() or type() without first: (default: false)"),
+		),
+		mcp.WithBoolean("pretty",
+			mcp.Description("Indent the JSON result for reading, at the cost of more tokens (default: false)"),
+		),
+		mcp.WithBoolean("lenient",
+			mcp.Description("Accept a bare selection such as \"name age friend { name }\" as the query and wrap it into a block selecting the nodes matched by func. Full queries are run unchanged (default: false)"),
+		),
+		mcp.WithString("func",
+			mcp.Description("The root function a bare selection is wrapped with in lenient mode, e.g. has(name) or eq(name, \"Alice\")"),
+		),
+		namespaceOption,
+	)
+
+	// Add mutation tool
+	mutationTool := mcp.NewTool("dgraph_mutate",
+		mcp.WithDescription("Execute a mutation against Dgraph"),
+		mcp.WithString("mutation",
+			mcp.Description("The RDF mutation to execute: N-Quads to set. At least one of mutation and delete is required"),
+		),
+		mcp.WithString("delete",
+			mcp.Description("N-Quads to delete, applied atomically with mutation, e.g. <0x1> <name> * . (optional)"),
+		),
+		mcp.WithBoolean("commit",
+			mcp.Description(fmt.Sprintf("Whether to commit the transaction. Without a commit nothing is saved; the mutation is kept in an open transaction whose txn_id is returned for dgraph_commit_txn (default: %t)", defaultCommit)),
+		),
+		mcp.WithString("type",
+			mcp.Description("Give every blank node in mutation without a dgraph.type this type, so the new nodes can be found with type() and expand(_all_) (optional)"),
+		),
+		mcp.WithString("session",
+			mcp.Description("Optional session name. Blank nodes assigned by earlier committed mutations in the same session are replaced with their uids"),
+		),
+		mcp.WithString("txn_id",
+			mcp.Description("Run the mutation inside a transaction opened with dgraph_begin_txn. It is not committed until dgraph_commit_txn is called (optional)"),
+		),
+		txnContextOption,
+		langTagOption,
+		namespaceOption,
+	)
+
+	// Add schema tool
+	schemaTool := mcp.NewTool("dgraph_alter_schema",
+		mcp.WithDescription("Alter the Dgraph schema"),
+		mcp.WithString("schema",
+			mcp.Required(),
+			mcp.Description("The schema definition to apply"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Validate the schema and report what would change, including changes that trigger reindexing, without applying it (default: false)"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add paginated query tool
+	paginatedQueryTool := mcp.NewTool("dgraph_paginated_query",
+		mcp.WithDescription("Fetch one page of results together with pagination metadata (current page, page size, total matches, total pages and a cursor for the next page)"),
+		mcp.WithString("func",
+			mcp.Required(),
+			mcp.Description("The root function selecting nodes, e.g. type(Movie) or has(name)"),
+		),
+		mcp.WithString("fields",
+			mcp.Required(),
+			mcp.Description("The predicates to return for each node, e.g. \"uid name age\""),
+		),
+		mcp.WithNumber("page",
+			mcp.Description("The 1-based page number to fetch (default: 1)"),
+		),
+		mcp.WithNumber("page_size",
+			mcp.Description("The number of results per page (default: 10)"),
+		),
+		mcp.WithString("after",
+			mcp.Description("A cursor: fetch the page after this uid, as returned in next_cursor. Stable under concurrent writes, unlike page. Cannot be combined with page (optional)"),
+		),
+		filterOption,
+		namespaceOption,
+	)
+
+	// Add schema diff tool
+	schemaDiffTool := mcp.NewTool("dgraph_schema_diff",
+		mcp.WithDescription("Compare a proposed schema against the live schema without applying it, listing added, removed and changed predicates and types"),
+		mcp.WithString("schema",
+			mcp.Required(),
+			mcp.Description("The proposed schema definition"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add uid normalization tool
+	normalizeUIDTool := mcp.NewTool("dgraph_normalize_uid",
+		mcp.WithDescription("Validate a uid given as hex (0x1) or decimal (1) and return it in Dgraph's canonical 0x form"),
+		mcp.WithString("uid",
+			mcp.Required(),
+			mcp.Description("The uid to normalize"),
+		),
+	)
+
+	// Add query by type tool
+	typeQueryTool := mcp.NewTool("dgraph_query_by_type",
+		mcp.WithDescription("Fetch all nodes of a type (func: type(...)) without writing DQL"),
+		mcp.WithString("type",
+			mcp.Required(),
+			mcp.Description("The type name, e.g. Movie"),
+		),
+		mcp.WithArray("predicates",
+			mcp.Description("The predicates to return (default: all predicates via expand(_all_))"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("The number of nodes to skip (optional)"),
+		),
+		mcp.WithString("after",
+			mcp.Description("A cursor: return the nodes after this uid, as given in next_cursor of the result's _meta field. Cannot be combined with offset (optional)"),
+		),
+		filterOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add ping tool
+	pingTool := mcp.NewTool("dgraph_ping",
+		mcp.WithDescription("Check connectivity to every Dgraph Alpha, returning the cluster version and each Alpha's health and round-trip latency. Useful as a first call to verify the database is reachable"),
+	)
+
+	// Add recurse tool
+	recurseTool := mcp.NewTool("dgraph_recurse",
+		mcp.WithDescription("Find everything reachable from a node within a number of hops along an edge predicate, using @recurse"),
+		mcp.WithString("uid",
+			mcp.Required(),
+			mcp.Description("The uid of the starting node"),
+		),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The edge predicate to follow, e.g. friend"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The maximum number of hops to follow (1 to %d)", maxRecurseDepth)),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Scalar predicates to return for each visited node, e.g. [\"name\"] (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		namespaceOption,
+	)
+
+	// Add geo query tool
+	geoQueryTool := mcp.NewTool("dgraph_geo_query",
+		mcp.WithDescription("Find nodes by location using Dgraph's geo functions (near, within, contains, intersects). The predicate needs a geo index"),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The geo predicate to search, e.g. location"),
+		),
+		mcp.WithString("mode",
+			mcp.Description("The geo function: near (point and radius_meters), within or intersects (polygon), contains (point or polygon) (default: near)"),
+			mcp.Enum(geoModes...),
+		),
+		mcp.WithArray("point",
+			mcp.Description("A point as [longitude, latitude], e.g. [-122.42, 37.77]"),
+			mcp.Items(map[string]interface{}{"type": "number"}),
+		),
+		mcp.WithNumber("radius_meters",
+			mcp.Description("The search radius in meters for near"),
+		),
+		mcp.WithArray("polygon",
+			mcp.Description("A polygon as a list of [longitude, latitude] points. It is closed automatically if the last point differs from the first"),
+			mcp.Items(map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}}),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Predicates to return for each node, e.g. [\"name\"] (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		refreshOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add get node tool
+	getNodeTool := mcp.NewTool("dgraph_get_node",
+		mcp.WithDescription("Fetch everything about a node: all its predicates via expand(_all_), optionally with linked nodes and reverse edges"),
+		mcp.WithString("uid",
+			mcp.Required(),
+			mcp.Description("The uid of the node"),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("How many levels of linked nodes to expand (0 to %d); 0 returns only scalar predicates (default: 1)", maxGetNodeDepth)),
+		),
+		mcp.WithBoolean("include_reverse",
+			mcp.Description("Also return nodes pointing at this one through @reverse predicates (default: false)"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Only return these predicates, e.g. [\"name\", \"friend\"], instead of all of them. Edges come back as the uids and types of the linked nodes. Cannot be combined with depth (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add reverse query tool
+	reverseQueryTool := mcp.NewTool("dgraph_reverse_query",
+		mcp.WithDescription("List the nodes pointing at a node through a predicate with @reverse, i.e. who points at this node"),
+		mcp.WithString("uid",
+			mcp.Required(),
+			mcp.Description("The uid of the node being pointed at"),
+		),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The edge predicate to follow backwards, e.g. friend"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Predicates to return for each node (default: expand(_all_))"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		refreshOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add aggregate tool
+	aggregateTool := mcp.NewTool("dgraph_aggregate",
+		mcp.WithDescription("Compute min, max, sum, avg or count over a predicate of the nodes of a type or matching a root function"),
+		mcp.WithString("type",
+			mcp.Description("The type of the nodes to aggregate over. Either type or func is required"),
+		),
+		mcp.WithString("func",
+			mcp.Description("A root function selecting the nodes to aggregate over, e.g. has(age)"),
+		),
+		mcp.WithString("predicate",
+			mcp.Description("The predicate to aggregate. Required except for count, which counts the nodes having it, or all nodes without it"),
+		),
+		mcp.WithString("aggregation",
+			mcp.Required(),
+			mcp.Description("The aggregation to compute"),
+			mcp.Enum(aggregations...),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add count by group tool
+	countByGroupTool := mcp.NewTool("dgraph_count_by_group",
+		mcp.WithDescription("Count the nodes of a type or matching a root function per value of a predicate with @groupby, e.g. movies per genre. Returns a map from each value to its count"),
+		mcp.WithString("type",
+			mcp.Description("The type of the nodes to count. Either type or func is required"),
+		),
+		mcp.WithString("func",
+			mcp.Description("A root function selecting the nodes to count, e.g. has(genre)"),
+		),
+		mcp.WithString("group_predicate",
+			mcp.Required(),
+			mcp.Description("The predicate to group by. It must not be a list"),
+		),
+		mcp.WithString("label_predicate",
+			mcp.Description("For a uid group predicate, a predicate of the linked nodes to key the groups by instead of their uid, e.g. name (optional)"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add var query tool
+	varQueryTool := mcp.NewTool("dgraph_var_query",
+		mcp.WithDescription("Run a multi-stage query: var blocks compute variables (A as var(...)) used by later blocks, e.g. func: uid(A), and named values that result blocks can sort by and return with val(). Only the result blocks are returned"),
+		mcp.WithArray("blocks",
+			mcp.Required(),
+			mcp.Description("The query blocks in order. Each has func (required), name (required unless var is true), var, as, filter, first, fields, values and project"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":    map[string]interface{}{"type": "string", "description": "The result block name"},
+					"var":     map[string]interface{}{"type": "boolean", "description": "Whether this is a var block that is not returned"},
+					"as":      map[string]interface{}{"type": "string", "description": "A variable bound to the matched uids"},
+					"func":    map[string]interface{}{"type": "string", "description": "The root function, e.g. type(Person) or uid(A)"},
+					"filter":  map[string]interface{}{"type": "string", "description": "An optional @filter expression"},
+					"first":   map[string]interface{}{"type": "number", "description": "An optional result limit"},
+					"fields":  map[string]interface{}{"type": "string", "description": "The fields to select, which may define value variables, e.g. f as friend (default: uid)"},
+					"values":  map[string]interface{}{"type": "object", "description": "Value variables to define, by name, e.g. {\"score\": \"math(likes + 2 * shares)\"}", "additionalProperties": map[string]interface{}{"type": "string"}},
+					"project": map[string]interface{}{"type": "object", "description": "Value variables to return, by result key, e.g. {\"score\": \"score\"} returns score: val(score)", "additionalProperties": map[string]interface{}{"type": "string"}},
+				},
+				"required": []string{"func"},
+			}),
+		),
+		namespaceOption,
+	)
+
+	// Add alter schema from source tool
+	schemaFromSourceTool := mcp.NewTool("dgraph_alter_schema_from_source",
+		mcp.WithDescription("Apply a schema read from a file in DGRAPH_SCHEMA_DIR or downloaded from a URL on a host in DGRAPH_SCHEMA_URL_HOSTS"),
+		mcp.WithString("path",
+			mcp.Description("The schema file, absolute or relative to the first DGRAPH_SCHEMA_DIR directory. Either path or url is required"),
+		),
+		mcp.WithString("url",
+			mcp.Description("An http or https URL to download the schema from, on a host allowed by DGRAPH_SCHEMA_URL_HOSTS"),
+		),
+		namespaceOption,
+	)
+
+	// Add shortest path tool
+	shortestPathTool := mcp.NewTool("dgraph_shortest_path",
+		mcp.WithDescription("Find the shortest path between two nodes along an edge predicate, optionally weighted by an edge facet"),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("The uid of the start node"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("The uid of the end node"),
+		),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The edge predicate to follow, e.g. friend"),
+		),
+		mcp.WithNumber("max_depth",
+			mcp.Description("The maximum number of hops to search (optional)"),
+		),
+		mcp.WithString("weight_facet",
+			mcp.Description("An edge facet holding the weight of each hop, e.g. distance. Without it every hop weighs 1 (optional)"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("Predicates to return for each node on the path, e.g. [\"name\"] (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		namespaceOption,
+	)
+
+	// Add index audit tool
+	indexAuditTool := mcp.NewTool("dgraph_index_audit",
+		mcp.WithDescription("List the indexes in the schema and, given a query, warn about functions needing an index their predicate lacks, with @index suggestions"),
+		mcp.WithString("query",
+			mcp.Description("A query to check for missing indexes (optional)"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add explain schema tool
+	explainSchemaTool := mcp.NewTool("dgraph_explain_schema",
+		mcp.WithDescription("Summarize the data model in prose: the node types with their fields and edges, which predicates are searchable with which functions, and example queries for the most connected type. A good first call to learn an unfamiliar database"),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add mutation preview tool
+	mutatePreviewTool := mcp.NewTool("dgraph_mutate_preview",
+		mcp.WithDescription("Preview the effect of a mutation without saving it: the mutation runs in a transaction that is rolled back, and the touched predicates of each affected node are returned with their values before and after"),
+		mcp.WithString("mutation",
+			mcp.Description("RDF N-Quads to set, with subjects given as <uid> or _:blank"),
+		),
+		mcp.WithString("delete",
+			mcp.Description("RDF N-Quads to delete; * may be used as predicate or object"),
+		),
+		namespaceOption,
+	)
+
+	// Add batch query tool
+	batchQueryTool := mcp.NewTool("dgraph_batch_query",
+		mcp.WithDescription("Run several independent DQL queries in one call, returning each result under its name. Set consistent to read all of them from the same database version"),
+		mcp.WithArray("queries",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The queries to run, at most %d. Each has a unique name, a query and optional variables", maxBatchQueries)),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":      map[string]interface{}{"type": "string", "description": "The key of the query's result"},
+					"query":     map[string]interface{}{"type": "string", "description": "The DQL query"},
+					"variables": map[string]interface{}{"type": "object", "description": "Variables declared by the query, e.g. {\"$name\": \"Alice\"}"},
+				},
+				"required": []string{"name", "query"},
+			}),
+		),
+		mcp.WithBoolean("consistent",
+			mcp.Description("Run all queries in one read-only transaction so they see the same snapshot, one after another. Otherwise they run concurrently, each in its own transaction (default: false)"),
+		),
+		namespaceOption,
+	)
+
+	// Add rename predicate tool
+	renamePredicateTool := mcp.NewTool("dgraph_rename_predicate",
+		mcp.WithDescription("Rename a predicate: create the new predicate with the old one's schema, copy every value or edge over, add it to the types listing the old one and optionally drop the old predicate. Facets are not copied"),
+		mcp.WithString("from",
+			mcp.Required(),
+			mcp.Description("The predicate to rename"),
+		),
+		mcp.WithString("to",
+			mcp.Required(),
+			mcp.Description("The new predicate name. If it exists already its schema must match the old one"),
+		),
+		mcp.WithBoolean("drop_old",
+			mcp.Description("Drop the old predicate and remove it from its types once every node has the new one (default: false)"),
+		),
+		namespaceOption,
+	)
+
+	// Add help tool
+	helpTool := mcp.NewTool("dgraph_help",
+		mcp.WithDescription("Get a concise DQL cheat sheet: functions and the indexes they need, directives, pagination, variables and query skeletons. Read it before writing DQL by hand"),
+		mcp.WithString("topic",
+			mcp.Description("The topic to return (default: all topics)"),
+			mcp.Enum(helpTopicNames()...),
+		),
+	)
+
+	// Add delete by query tool
+	deleteByQueryTool := mcp.NewTool("dgraph_delete_by_query",
+		mcp.WithDescription("Delete every node a query matches: all predicates of the nodes bound to a variable are deleted with uid(v) * * . in one atomic upsert. Without confirm it only counts the matching nodes"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The DQL query binding the nodes to delete to a variable, e.g. { q(func: type(Session)) @filter(lt(expires, \"2024-01-01\")) { v as uid } }"),
+		),
+		mcp.WithString("var",
+			mcp.Description("The variable holding the nodes to delete (default: v)"),
+		),
+		mcp.WithBoolean("confirm",
+			mcp.Description("Must be true to delete the nodes. Otherwise only the number of matching nodes is returned (default: false)"),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Variables for the query, e.g. {\"$type\": \"Session\"}. The query must declare them (optional)"),
+		),
+		namespaceOption,
+	)
+
+	// Add math query tool
+	mathQueryTool := mcp.NewTool("dgraph_query_with_math",
+		mcp.WithDescription("Compute a math() expression over value variables for each node, e.g. a weighted score, and return the nodes with their computed value, ranked by it"),
+		mcp.WithString("func",
+			mcp.Required(),
+			mcp.Description("The root function selecting the nodes, e.g. type(Movie)"),
+		),
+		mcp.WithString("filter",
+			mcp.Description("An @filter expression on the nodes (optional)"),
+		),
+		mcp.WithObject("vars",
+			mcp.Required(),
+			mcp.Description("The variables the expression uses, each defined as a predicate or count(edge), e.g. {\"r\": \"rating\", \"f\": \"count(fans)\"}"),
+		),
+		mcp.WithString("math",
+			mcp.Required(),
+			mcp.Description("The expression, e.g. r * 0.7 + ln(f + 1). Supports + - * / %, comparisons and min, max, ln, exp, pow, logbase, sqrt, floor, ceil, since and cond"),
+		),
+		mcp.WithString("as",
+			mcp.Description("The name of the computed value in each result (default: score)"),
+		),
+		mcp.WithString("fields",
+			mcp.Description("The fields to return for each node, e.g. \"uid title\" (default: uid)"),
+		),
+		mcp.WithString("order",
+			mcp.Description("Sort the nodes by the computed value (default: desc)"),
+			mcp.Enum("desc", "asc", "none"),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		namespaceOption,
+	)
+
+	// Add schema existence tools
+	predicateExistsTool := mcp.NewTool("dgraph_predicate_exists",
+		mcp.WithDescription("Check whether a predicate exists in the schema, returning its type, indexes and the functions it can be searched with if it does. Use it before building a query on a predicate"),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The predicate name, e.g. name"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+	typeExistsTool := mcp.NewTool("dgraph_type_exists",
+		mcp.WithDescription("Check whether a type exists in the schema, returning its fields if it does"),
+		mcp.WithString("type",
+			mcp.Required(),
+			mcp.Description("The type name, e.g. Person"),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add raw request tool
+	doTool := mcp.NewTool("dgraph_do",
+		mcp.WithDescription("Advanced: send one request in Dgraph's native shape, a query and any number of mutations with optional conditions, run as a single txn.Do call. Prefer the dedicated tools; use this when a request needs several of their features at once"),
+		mcp.WithString("query",
+			mcp.Description("The DQL query. Mutations may use the variables it defines via uid(v) and val(v) (optional)"),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Variables for the query, e.g. {\"$name\": \"Alice\"}. The query must declare them (optional)"),
+		),
+		mcp.WithArray("mutations",
+			mcp.Description("The mutations, applied in order after the query. Each has set_nquads, del_nquads, set_json or delete_json, and an optional cond (optional)"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"set_nquads":  map[string]interface{}{"type": "string", "description": "N-Quads to set"},
+					"del_nquads":  map[string]interface{}{"type": "string", "description": "N-Quads to delete, which may use * as predicate or object"},
+					"set_json":    map[string]interface{}{"type": "object", "description": "A JSON object or list of objects to set"},
+					"delete_json": map[string]interface{}{"type": "object", "description": "A JSON object or list of objects to delete"},
+					"cond":        map[string]interface{}{"type": "string", "description": "Apply the mutation only if this condition on the query's variables holds, e.g. eq(len(v), 0)"},
+				},
+			}),
+		),
+		mcp.WithBoolean("read_only",
+			mcp.Description("Run the query in a read-only transaction; no mutations allowed (default: false)"),
+		),
+		mcp.WithBoolean("best_effort",
+			mcp.Description("Let a read_only query use a possibly slightly stale timestamp for lower latency (default: false)"),
+		),
+		mcp.WithBoolean("commit_now",
+			mcp.Description("Commit the mutations with the request (default: true, or false with txn_id)"),
+		),
+		mcp.WithString("txn_id",
+			mcp.Description("Run the request inside a transaction opened with dgraph_begin_txn (optional)"),
+		),
+		namespaceOption,
+	)
+
+	importFileTool := mcp.NewTool("dgraph_import_file",
+		mcp.WithDescription("Import an RDF (.rdf, .nq) or JSON file from a DGRAPH_IMPORT_DIR directory in batches of committed transactions, optionally gzip-compressed (.gz). Blank nodes keep their uid across batches"),
+		mcp.WithString("path",
+			mcp.Required(),
+			mcp.Description("The file, absolute or relative to the first DGRAPH_IMPORT_DIR directory"),
+		),
+		mcp.WithNumber("batch_size",
+			mcp.Description("N-Quads or JSON objects committed per transaction (default: 1000)"),
+		),
+		namespaceOption,
+	)
+
+	subgraphExportTool := mcp.NewTool("dgraph_subgraph_export",
+		mcp.WithDescription("Export the subgraph reachable from some nodes within a depth as N-Quads or JSON on blank nodes, ready to import into another Dgraph instance without uid collisions"),
+		mcp.WithArray("uids",
+			mcp.Required(),
+			mcp.Description("The uids of the root nodes, e.g. [\"0x1\"]"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("depth",
+			mcp.Description(fmt.Sprintf("The maximum number of hops to follow from the roots (1 to %d, default: 3)", maxRecurseDepth)),
+		),
+		mcp.WithString("format",
+			mcp.Description("nquads or json (default: nquads)"),
+			mcp.Enum("nquads", "json"),
+		),
+		mcp.WithArray("edges",
+			mcp.Description("Only follow these edge predicates (optional; all edges by default)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithBoolean("refresh",
+			mcp.Description("Reload the schema instead of using the cached copy (default: false)"),
+		),
+		namespaceOption,
+	)
+
+	// Add add predicate tool
+	addPredicateTool := mcp.NewTool("dgraph_add_predicate",
+		mcp.WithDescription("Add a predicate to the schema, or add indexes and directives to an existing one, from its parts instead of a hand-written schema line. Tokenizers and directives are checked against the type and merged with the ones the predicate already has"),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The predicate name"),
+		),
+		mcp.WithString("type",
+			mcp.Required(),
+			mcp.Description("The value type. It must match the type of an existing predicate"),
+			mcp.Enum("default", "int", "float", "string", "bool", "datetime", "geo", "password", "uid"),
+		),
+		mcp.WithBoolean("list",
+			mcp.Description("Hold a list of values, e.g. [string] (default: false)"),
+		),
+		mcp.WithArray("index",
+			mcp.Description("Index tokenizers, e.g. [\"exact\", \"term\"]: exact, hash, term, fulltext or trigram for strings, year, month, day or hour for datetimes, and int, float, bool or geo for those types (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithArray("directives",
+			mcp.Description("Directives to set: upsert (needs an index), count (uid or list predicates), lang (strings) or reverse (uid predicates) (optional)"),
+			mcp.Items(map[string]interface{}{"type": "string", "enum": predicateDirectives}),
+		),
+		namespaceOption,
+	)
+
+	// Add atomic batch mutation tool
+	atomicBatchTool := mcp.NewTool("dgraph_mutate_batch_atomic",
+		mcp.WithDescription("Apply several mutation blocks in one transaction committed together, so either all of them succeed or none do. Blank nodes are shared between blocks, so a block can link to a node another block creates. Returns the uids assigned to all blank nodes"),
+		mcp.WithArray("mutations",
+			mcp.Required(),
+			mcp.Description("The mutation blocks, applied in order. Each has set_nquads, del_nquads, set_json or delete_json, or several of them"),
+			mcp.Items(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"set_nquads":  map[string]interface{}{"type": "string", "description": "N-Quads to set"},
+					"del_nquads":  map[string]interface{}{"type": "string", "description": "N-Quads to delete, which may use * as predicate or object"},
+					"set_json":    map[string]interface{}{"type": "object", "description": "A JSON object or list of objects to set"},
+					"delete_json": map[string]interface{}{"type": "object", "description": "A JSON object or list of objects to delete"},
+				},
+			}),
+		),
+		namespaceOption,
+	)
+
+	// Add regular expression search tool
+	regexSearchTool := mcp.NewTool("dgraph_regex_search",
+		mcp.WithDescription("Search a string predicate for values matching a regular expression, via regexp(predicate, /pattern/). The predicate needs a trigram index"),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The predicate to search. It must have a trigram index"),
+		),
+		mcp.WithString("pattern",
+			mcp.Required(),
+			mcp.Description("The regular expression in Go RE2 syntax, without surrounding slashes, e.g. ^Star.*Wars$. It needs at least three literal characters in a row for the trigram index to narrow the search"),
+		),
+		mcp.WithBoolean("case_insensitive",
+			mcp.Description("Match regardless of case, with the i flag (default: false)"),
+		),
+		mcp.WithString("type",
+			mcp.Description("Only return nodes of this type (optional)"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("The predicates to return for each match (default: the searched predicate)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("The number of nodes to skip (optional)"),
+		),
+		refreshOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add node degree tool
+	nodeDegreeTool := mcp.NewTool("dgraph_node_degree",
+		mcp.WithDescription("Count the edges of a node per predicate, outbound and inbound, to see how connected it is, e.g. to find hub nodes. Inbound edges are counted through predicates with @reverse"),
+		mcp.WithString("uid",
+			mcp.Required(),
+			mcp.Description("The uid of the node"),
+		),
+		mcp.WithArray("predicates",
+			mcp.Description("The edge predicates to count (default: every uid predicate in the schema)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add merge nodes tool
+	mergeNodesTool := mcp.NewTool("dgraph_merge_nodes",
+		mcp.WithDescription("Merge duplicate nodes of the same entity into a canonical node in one transaction: their values, types and edges are copied onto it, edges pointing at them are pointed at it, and the duplicates are deleted. Single-valued predicates the canonical node already has keep its value. Returns the counts of copied values and rewritten edges"),
+		mcp.WithString("canonical",
+			mcp.Required(),
+			mcp.Description("The uid of the node to keep"),
+		),
+		mcp.WithArray("duplicates",
+			mcp.Required(),
+			mcp.Description("The uids of the duplicates to merge into it and delete"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		namespaceOption,
+	)
+
+	// Add schema history tool
+	schemaHistoryTool := mcp.NewTool("dgraph_schema_history",
+		mcp.WithDescription("List the schema versions snapshotted before each schema alteration by this server, newest first, with the time and the tool that altered it. Give a version to see its full schema"),
+		mcp.WithNumber("version",
+			mcp.Description("A version to return in full, with its schema"),
+		),
+		namespaceOption,
+	)
 
-	// Connect to Dgraph
-	dgraphClient, err := connectToDgraph(dgraphHost)
-	if err != nil {
-		log.Fatalf("Failed to connect to Dgraph: %v", err)
-	}
-	log.Printf("Connected to Dgraph at %s", dgraphHost)
+	// Add schema rollback tool
+	schemaRollbackTool := mcp.NewTool("dgraph_schema_rollback",
+		mcp.WithDescription("Reapply a schema version from dgraph_schema_history. Predicates dropped since are recreated empty, as their data can't be recovered, and predicates and types added since stay. The schema replaced is snapshotted first, so a rollback can be undone"),
+		mcp.WithNumber("version",
+			mcp.Required(),
+			mcp.Description("The version to roll back to"),
+		),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Report what the rollback would change, without applying it (default: false)"),
+		),
+		namespaceOption,
+	)
 
-	// Create MCP server
-	s := server.NewMCPServer(
-		"Dgraph MCP Server",
-		"1.0.0",
+	// Add JSON array mutation tool
+	jsonArrayMutationTool := mcp.NewTool("dgraph_mutate_json_array",
+		mcp.WithDescription("Insert a list of JSON objects in one committed transaction, returning the uid assigned to each object"),
+		mcp.WithArray("objects",
+			mcp.Required(),
+			mcp.Description("The objects to insert. An object may set uid to a blank node (_:name) to be referenced by others, or to an existing uid to update that node"),
+			mcp.Items(map[string]interface{}{"type": "object"}),
+		),
+		langTagOption,
+		namespaceOption,
 	)
 
-	// Add query tool
-	queryTool := mcp.NewTool("dgraph_query",
-		mcp.WithDescription("Execute a DQL query against Dgraph"),
+	// Add full-text search tool
+	fulltextSearchTool := mcp.NewTool("dgraph_fulltext_search",
+		mcp.WithDescription("Search a predicate with a full-text index (alloftext/anyoftext), optionally scoped to a type"),
+		mcp.WithString("predicate",
+			mcp.Required(),
+			mcp.Description("The predicate to search. It must have a fulltext index"),
+		),
+		mcp.WithString("term",
+			mcp.Required(),
+			mcp.Description("The search text. Matching uses stemming and stop words for the text's language"),
+		),
+		mcp.WithString("match",
+			mcp.Description("Whether nodes must match all terms (alloftext) or any term (anyoftext) (default: all)"),
+			mcp.Enum(fulltextMatches...),
+		),
+		mcp.WithString("type",
+			mcp.Description("Only return nodes of this type (optional)"),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("The predicates to return for each match (default: the searched predicate)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description("The maximum number of nodes to return (optional)"),
+		),
+		mcp.WithNumber("offset",
+			mcp.Description("The number of nodes to skip (optional)"),
+		),
+		refreshOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add data audit tool
+	dataAuditTool := mcp.NewTool("dgraph_data_audit",
+		mcp.WithDescription("Check data quality: count and sample nodes without a dgraph.type, uid references to nodes with no data, and predicates with data that no type declares"),
+		mcp.WithNumber("sample_size",
+			mcp.Description(fmt.Sprintf("The number of offending uids to return per finding, at most %d (default: %d)", maxAuditSampleSize, defaultAuditSampleSize)),
+		),
+		refreshOption,
+		namespaceOption,
+	)
+
+	// Add GraphQL tool
+	graphQLTool := mcp.NewTool("dgraph_graphql",
+		mcp.WithDescription("Run a query or mutation against Dgraph's typed GraphQL API (/graphql), generated from a GraphQL schema. This is standard GraphQL, not DQL: use dgraph_query for DQL"),
 		mcp.WithString("query",
 			mcp.Required(),
-			mcp.Description("The DQL query to execute"),
+			mcp.Description("The GraphQL query or mutation, e.g. query { queryUser(first: 10) { name } }"),
 		),
 		mcp.WithObject("variables",
-			mcp.Description("Variables for the query (optional)"),
+			mcp.Description("Variables for the GraphQL operation (optional)"),
 		),
 	)
 
-	// Add mutation tool
-	mutationTool := mcp.NewTool("dgraph_mutate",
-		mcp.WithDescription("Execute a mutation against Dgraph"),
+	// Add upsert tool
+	upsertTool := mcp.NewTool("dgraph_upsert",
+		mcp.WithDescription("Run an upsert block: a query defining variables and a mutation using them via uid(v) and val(v), committed atomically. Retried automatically when a conflicting transaction aborts it"),
+		mcp.WithString("query",
+			mcp.Required(),
+			mcp.Description("The DQL query, defining variables, e.g. query { q(func: eq(email, \"a@b.c\")) { v as uid } }"),
+		),
 		mcp.WithString("mutation",
+			mcp.Description("N-Quads to set, which may use uid(v) and val(v). At least one of mutation and delete is required"),
+		),
+		mcp.WithString("delete",
+			mcp.Description("N-Quads to delete, which may use uid(v) (optional)"),
+		),
+		mcp.WithString("cond",
+			mcp.Description("Condition on the query's variables for applying the mutation, e.g. eq(len(v), 0) to insert only if nothing was found. Supports eq, le, lt, ge and gt on len(v), combined with and, or and not (optional)"),
+		),
+		mcp.WithObject("variables",
+			mcp.Description("Variables for the query, e.g. {\"email\": \"a@b.c\"}. The query must declare them (optional)"),
+		),
+		namespaceOption,
+	)
+
+	// Add upsert by xid tool
+	upsertByXIDTool := mcp.NewTool("dgraph_upsert_by_xid",
+		mcp.WithDescription("Find a node by an external id predicate and set predicates on it, creating the node if none exists, in a single atomic upsert"),
+		mcp.WithString("xid_predicate",
 			mcp.Required(),
-			mcp.Description("The RDF mutation to execute"),
+			mcp.Description("The string predicate holding the external id, e.g. xid. It needs an exact or hash index, and should have @upsert"),
 		),
-		mcp.WithBoolean("commit",
-			mcp.Description("Whether to commit the transaction (default: true)"),
+		mcp.WithString("xid_value",
+			mcp.Required(),
+			mcp.Description("The external id of the node"),
+		),
+		mcp.WithObject("set",
+			mcp.Description("The predicates to set, as a JSON object, e.g. {\"name\": \"Alice\", \"age\": 30} (optional)"),
+		),
+		mcp.WithString("type",
+			mcp.Description("The dgraph.type to give the node (optional)"),
 		),
+		refreshOption,
+		langTagOption,
+		namespaceOption,
 	)
 
-	// Add schema tool
-	schemaTool := mcp.NewTool("dgraph_alter_schema",
-		mcp.WithDescription("Alter the Dgraph schema"),
-		mcp.WithString("schema",
+	// Add tasks tool
+	tasksTool := mcp.NewTool("dgraph_tasks",
+		mcp.WithDescription("Show the background work keeping Dgraph busy, such as predicates being reindexed after a schema change, or the status of a task like an export or backup"),
+		mcp.WithString("task_id",
+			mcp.Description("The id of a task returned by an export or backup, to get its kind, status and last update (optional; without it, ongoing operations and indexing are listed)"),
+		),
+	)
+
+	// Add similar text tool
+	similarTextTool := mcp.NewTool("dgraph_similar_text",
+		mcp.WithDescription("Find nodes whose text shares the most terms with a given text, ranked by the number of matching terms. A simple \"more like this\" using the term index, without embeddings"),
+		mcp.WithString("predicate",
 			mcp.Required(),
-			mcp.Description("The schema definition to apply"),
+			mcp.Description("The string predicate to compare. It must have a term index"),
+		),
+		mcp.WithString("text",
+			mcp.Required(),
+			mcp.Description(fmt.Sprintf("The text to find similar nodes for, with at most %d distinct terms", maxSimilarTerms)),
+		),
+		mcp.WithArray("fields",
+			mcp.Description("The predicates to return for each node (default: the compared predicate)"),
+			mcp.Items(map[string]interface{}{"type": "string"}),
+		),
+		mcp.WithNumber("first",
+			mcp.Description(fmt.Sprintf("The number of most similar nodes to return (default: %d)", defaultSimilarFirst)),
+		),
+		refreshOption,
+		langOption,
+		namespaceOption,
+	)
+
+	// Add N-Quad validation tool
+	validateNQuadsTool := mcp.NewTool("dgraph_validate_nquads",
+		mcp.WithDescription("Check the syntax of N-Quads without sending them to Dgraph, reporting the line and column of the first error. Use it to check hand-written mutations before running them"),
+		mcp.WithString("nquads",
+			mcp.Required(),
+			mcp.Description("The N-Quads to check, one per line"),
+		),
+		mcp.WithBoolean("delete",
+			mcp.Description("Check them as deletions, which allow * as predicate and object (default: false)"),
+		),
+	)
+
+	// Add transaction tools
+	beginTxnTool := mcp.NewTool("dgraph_begin_txn",
+		mcp.WithDescription("Open a transaction spanning multiple dgraph_query and dgraph_mutate calls, returning its txn_id"),
+		namespaceOption,
+	)
+	commitTxnTool := mcp.NewTool("dgraph_commit_txn",
+		mcp.WithDescription("Commit a transaction opened with dgraph_begin_txn"),
+		mcp.WithString("txn_id",
+			mcp.Required(),
+			mcp.Description("The id returned by dgraph_begin_txn"),
+		),
+	)
+	discardTxnTool := mcp.NewTool("dgraph_discard_txn",
+		mcp.WithDescription("Discard a transaction opened with dgraph_begin_txn, dropping its mutations"),
+		mcp.WithString("txn_id",
+			mcp.Required(),
+			mcp.Description("The id returned by dgraph_begin_txn"),
 		),
 	)
 
-	// Add tools with their handlers
-	s.AddTool(queryTool, createQueryHandler(dgraphClient))
-	s.AddTool(mutationTool, createMutationHandler(dgraphClient))
-	s.AddTool(schemaTool, createSchemaHandler(dgraphClient))
+	// Add tools with their handlers, skipping those the configuration excludes
+	tools := newToolFilter(getEnv("MCP_ENABLED_TOOLS", ""), getEnv("MCP_DISABLED_TOOLS", ""), readOnly)
+	addTool := func(tool mcp.Tool, handler server.ToolHandlerFunc) {
+		if tools.allow(tool.Name) {
+			s.AddTool(tool, handler)
+		} else {
+			slog.Debug("Tool disabled by configuration", "tool", tool.Name)
+		}
+	}
+	addTool(queryTool, createQueryHandler(dgraphClient, txns, newSnapshotRegistry(txnTTL), limits, readOnly))
+	addTool(mutationTool, createMutationHandler(dgraphClient, newBlankNodeSessions(), txns, defaultCommit))
+	addTool(schemaTool, createSchemaHandler(dgraphClient))
+	addTool(paginatedQueryTool, createPaginatedQueryHandler(dgraphClient, limits))
+	addTool(schemaDiffTool, createSchemaDiffHandler(dgraphClient))
+	addTool(normalizeUIDTool, createNormalizeUIDHandler())
+	addTool(typeQueryTool, createTypeQueryHandler(dgraphClient))
+	addTool(pingTool, createPingHandler(alphas))
+	addTool(recurseTool, createRecurseHandler(dgraphClient, maxRecurseDepth))
+	addTool(geoQueryTool, createGeoQueryHandler(dgraphClient))
+	addTool(getNodeTool, createGetNodeHandler(dgraphClient))
+	addTool(reverseQueryTool, createReverseQueryHandler(dgraphClient))
+	addTool(aggregateTool, createAggregateHandler(dgraphClient))
+	addTool(countByGroupTool, createCountByGroupHandler(dgraphClient))
+	addTool(varQueryTool, createVarQueryHandler(dgraphClient, limits))
+	addTool(schemaFromSourceTool, createSchemaFromSourceHandler(dgraphClient, schemaDirs(getEnv("DGRAPH_SCHEMA_DIR", "")), schemaURLHosts(getEnv("DGRAPH_SCHEMA_URL_HOSTS", ""))))
+	addTool(shortestPathTool, createShortestPathHandler(dgraphClient))
+	addTool(indexAuditTool, createIndexAuditHandler(dgraphClient))
+	addTool(explainSchemaTool, createExplainSchemaHandler(dgraphClient))
+	addTool(mutatePreviewTool, createMutatePreviewHandler(dgraphClient))
+	addTool(batchQueryTool, createBatchQueryHandler(dgraphClient, limits))
+	addTool(renamePredicateTool, createRenamePredicateHandler(dgraphClient, upsertRetry))
+	addTool(helpTool, createHelpHandler())
+	addTool(deleteByQueryTool, createDeleteByQueryHandler(dgraphClient, limits, upsertRetry))
+	addTool(mathQueryTool, createMathQueryHandler(dgraphClient, limits))
+	addTool(predicateExistsTool, createSchemaExistsHandler(dgraphClient, "predicate", predicateExistence))
+	addTool(typeExistsTool, createSchemaExistsHandler(dgraphClient, "type", typeExistence))
+	addTool(doTool, createDoHandler(dgraphClient, txns, limits))
+	addTool(importFileTool, createImportFileHandler(dgraphClient, schemaDirs(getEnv("DGRAPH_IMPORT_DIR", "")), upsertRetry))
+	addTool(subgraphExportTool, createSubgraphExportHandler(dgraphClient, maxRecurseDepth))
+	addTool(addPredicateTool, createAddPredicateHandler(dgraphClient))
+	addTool(atomicBatchTool, createAtomicBatchHandler(dgraphClient, upsertRetry))
+	addTool(regexSearchTool, createRegexSearchHandler(dgraphClient))
+	addTool(nodeDegreeTool, createNodeDegreeHandler(dgraphClient))
+	addTool(mergeNodesTool, createMergeNodesHandler(dgraphClient, upsertRetry))
+	addTool(schemaHistoryTool, createSchemaHistoryHandler(dgraphClient))
+	addTool(schemaRollbackTool, createSchemaRollbackHandler(dgraphClient))
+	addTool(jsonArrayMutationTool, createJSONArrayMutationHandler(dgraphClient))
+	addTool(fulltextSearchTool, createFulltextSearchHandler(dgraphClient))
+	addTool(dataAuditTool, createDataAuditHandler(dgraphClient))
+	addTool(graphQLTool, createGraphQLHandler(getEnv("DGRAPH_GRAPHQL_ENDPOINT", defaultGraphQLEndpoint)))
+	addTool(upsertTool, createUpsertHandler(dgraphClient, limits, upsertRetry))
+	addTool(upsertByXIDTool, createUpsertByXIDHandler(dgraphClient, upsertRetry))
+	addTool(tasksTool, createTasksHandler(adminEndpoint, adminAuthToken))
+	addTool(similarTextTool, createSimilarTextHandler(dgraphClient))
+	addTool(validateNQuadsTool, createValidateNQuadsHandler())
+	addTool(beginTxnTool, createBeginTxnHandler(dgraphClient, txns))
+	addTool(commitTxnTool, createFinishTxnHandler(txns, true))
+	addTool(discardTxnTool, createFinishTxnHandler(txns, false))
+
+	// Query templates are only exposed when configured
+	if templates != nil {
+		runTemplateTool := mcp.NewTool("dgraph_run_template",
+			mcp.WithDescription(templates.toolDescription()),
+			mcp.WithString("name",
+				mcp.Required(),
+				mcp.Description("The name of the template to run"),
+			),
+			mcp.WithObject("params",
+				mcp.Description("The template parameters by name, e.g. {\"name\": \"Alice\"}. Parameters without a default are required"),
+			),
+			namespaceOption,
+		)
+		addTool(runTemplateTool, createRunTemplateHandler(dgraphClient, templates))
+	}
+
+	// The admin endpoint can take backups, shut down and reconfigure the
+	// cluster, so it is only exposed when explicitly enabled
+	if adminEnabled {
+		adminTool := mcp.NewTool("dgraph_admin",
+			mcp.WithDescription("Run a GraphQL query or mutation against Dgraph's /admin endpoint, for cluster administration such as backups, draining and configuration. This is GraphQL, not DQL"),
+			mcp.WithString("query",
+				mcp.Required(),
+				mcp.Description("The GraphQL query or mutation, e.g. mutation { backup(input: {destination: \"/backups\"}) { response { message } } }"),
+			),
+			mcp.WithObject("variables",
+				mcp.Description("Variables for the GraphQL operation (optional)"),
+			),
+		)
+		addTool(adminTool, createAdminHandler(adminEndpoint, adminAuthToken))
+		slog.Warn("The dgraph_admin tool is enabled; clients can run any admin operation")
+	}
+
+	if unknown := tools.unknown(); len(unknown) > 0 {
+		slog.Warn("MCP_ENABLED_TOOLS or MCP_DISABLED_TOOLS names unknown tools", "tools", unknown)
+	}
 
 	// Add schema resource
 	schemaResource := mcp.NewResource(
@@ -82,14 +1339,109 @@ func main() {
 		mcp.WithMIMEType("text/plain"),
 	)
 
-	// Add resource with its handler
+	// Add predicates resource
+	predicatesResource := mcp.NewResource(
+		"dgraph://predicates",
+		"Dgraph Predicates",
+		mcp.WithResourceDescription("A flat list of all predicates with their types and indexes, for query autocompletion"),
+		mcp.WithMIMEType("application/json"),
+	)
+
+	// Add help resource
+	helpResource := mcp.NewResource(
+		"dgraph://help",
+		"DQL Cheat Sheet",
+		mcp.WithResourceDescription("Concise DQL syntax help: functions, directives, pagination, variables and query skeletons"),
+		mcp.WithMIMEType("text/markdown"),
+	)
+
+	// Add resources with their handlers
 	s.AddResource(schemaResource, createSchemaResourceHandler(dgraphClient))
+	s.AddResource(predicatesResource, createPredicatesResourceHandler(dgraphClient))
+	s.AddResource(helpResource, createHelpResourceHandler())
+	if templates != nil {
+		templatesResource := mcp.NewResource(
+			"dgraph://templates",
+			"Query Templates",
+			mcp.WithResourceDescription("The query templates dgraph_run_template can run, with their parameters"),
+			mcp.WithMIMEType("application/json"),
+		)
+		s.AddResource(templatesResource, createTemplatesResourceHandler(templates))
+	}
+
+	// Start the server on the configured transport
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Discard idle transactions even while no tool calls are made
+	go txns.expireEvery(ctx, min(txnTTL, txnExpiryInterval))
 
-	// Start the stdio server
-	log.Println("Starting Dgraph MCP Server...")
-	if err := server.ServeStdio(s); err != nil {
-		log.Fatalf("Server error: %v", err)
+	serveErr := make(chan error, 1)
+	var sseServer *server.SSEServer
+	switch transport {
+	case "stdio":
+		slog.Info("Starting Dgraph MCP Server", "transport", transport)
+		go func() {
+			stdioServer := server.NewStdioServer(s)
+			stdioServer.SetErrorLogger(slog.NewLogLogger(logger.Handler(), slog.LevelError))
+			serveErr <- stdioServer.Listen(ctx, os.Stdin, os.Stdout)
+		}()
+	case "sse":
+		sseAddr := getEnv("MCP_SSE_ADDR", defaultSSEAddr)
+		var sseOptions []server.SSEOption
+		if baseURL := getEnv("MCP_SSE_BASE_URL", ""); baseURL != "" {
+			sseOptions = append(sseOptions, server.WithBaseURL(baseURL))
+		}
+		if tracer != nil {
+			sseOptions = append(sseOptions, server.WithSSEContextFunc(traceparentContext))
+		}
+		sseServer = server.NewSSEServer(s, sseOptions...)
+		slog.Info("Starting Dgraph MCP Server", "transport", transport, "addr", sseAddr)
+		go func() {
+			if err := sseServer.Start(sseAddr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+			}
+		}()
+	default:
+		fatal("Invalid MCP_TRANSPORT, must be stdio or sse", "transport", transport)
+	}
+
+	// Wait for a shutdown signal or for the client to go away
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case sig := <-sigChan:
+		slog.Info("Shutting down", "signal", sig.String())
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("Server error", "error", err)
+		}
+	}
+
+	// Let in-flight tool calls finish before tearing down the connection
+	drained, pending := activity.drain(shutdownTimeout)
+	cancel()
+	if sseServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), discardTimeout)
+		if err := sseServer.Shutdown(shutdownCtx); err != nil {
+			slog.Warn("Failed to shut down SSE server", "error", err)
+		}
+		cancelShutdown()
 	}
+	discarded := activity.discardOpenTxns()
+	for _, alpha := range alphas {
+		if err := alpha.close(); err != nil {
+			slog.Warn("Failed to close Dgraph connection", "host", alpha.host, "error", err)
+		}
+	}
+	if tracer != nil {
+		exportCtx, cancelExport := context.WithTimeout(context.Background(), spanExportTimeout)
+		if err := tracer.shutdown(exportCtx); err != nil {
+			slog.Warn("Failed to export spans", "error", err)
+		}
+		cancelExport()
+	}
+	slog.Info("Shutdown complete", "drained_calls", drained, "pending_calls", pending, "discarded_txns", discarded)
 }
 
 // Helper function to get environment variable with default fallback
@@ -100,93 +1452,608 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// Helper function to get an integer environment variable with default fallback
+func getEnvInt(key string, fallback int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
+
 // Connect to Dgraph
-func connectToDgraph(host string) (*dgo.Dgraph, error) {
-	conn, err := grpc.Dial(host, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		return nil, err
+func dialDgraph(host string, maxRecvMsgSize, maxSendMsgSize int, keepaliveParams keepalive.ClientParameters, compression bool, interceptors []grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(maxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(maxSendMsgSize),
+		),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}
+	// A zero time disables keepalive pings
+	if keepaliveParams.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepaliveParams))
 	}
+	if compression {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	return grpc.Dial(host, opts...)
+}
+
+// Check whether a call failed because the server has no decompressor for
+// the compression the client used
+func isCompressionUnsupported(err error) bool {
+	s, ok := status.FromError(err)
+	return err != nil && ok && s.Code() == codes.Unimplemented && strings.Contains(s.Message(), "grpc-encoding")
+}
+
+// Wait until Dgraph answers a version check, retrying with exponential backoff
+func waitForDgraph(ctx context.Context, conn api.DgraphClient, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := initialConnectBackoff
+	for attempt := 1; ; attempt++ {
+		_, err := conn.CheckVersion(ctx, &api.Check{})
+		if err == nil || isCompressionUnsupported(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up after %d attempts: %v", attempt, err)
+		case <-time.After(backoff):
+		}
 
-	return dgo.NewDgraphClient(
-		api.NewDgraphClient(conn),
-	), nil
+		slog.Info("Waiting for Dgraph", "attempt", attempt, "error", err)
+		backoff *= 2
+		if backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
 }
 
 // Create handler for the query tool
-func createQueryHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func createQueryHandler(client *dgo.Dgraph, txns *txnRegistry, snapshots *snapshotRegistry, limits queryLimits, readOnly bool) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
 		query, ok := request.Params.Arguments["query"].(string)
 		if !ok {
 			return nil, fmt.Errorf("query must be a string")
 		}
 
-		// Create transaction
-		txn := client.NewTxn()
-		defer txn.Discard(ctx)
+		// Wrap a bare selection into a full query in lenient mode
+		lenient, err := boolArgument(request, "lenient", false)
+		if err != nil {
+			return nil, err
+		}
+		rootFunc, _ := request.Params.Arguments["func"].(string)
+		if rootFunc != "" && !lenient {
+			return nil, fmt.Errorf("func only applies in lenient mode, to wrap a bare selection")
+		}
+		wrapped := ""
+		if lenient {
+			var ok bool
+			if query, ok, err = autoWrapQuery(query, rootFunc); err != nil {
+				return nil, err
+			}
+			if ok {
+				wrapped = query
+			}
+		}
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+
+		// Rewrite existence checks to fetch a single uid per block
+		existsOnly, err := boolArgument(request, "exists_only", false)
+		if err != nil {
+			return nil, err
+		}
+
+		responseFormat := "json"
+		if formatArg, ok := request.Params.Arguments["response_format"].(string); ok {
+			responseFormat = formatArg
+		}
+		if responseFormat != "json" && responseFormat != "rdf" && responseFormat != "csv" {
+			return nil, fmt.Errorf("response_format must be json, rdf or csv")
+		}
+
+		expandAll, err := boolArgument(request, "expand_all", false)
+		if err != nil {
+			return nil, err
+		}
+		if existsOnly && expandAll {
+			return nil, fmt.Errorf("exists_only and expand_all cannot be combined")
+		}
+
+		normalize, err := boolArgument(request, "normalize", false)
+		if err != nil {
+			return nil, err
+		}
+		if normalize && (existsOnly || expandAll) {
+			return nil, fmt.Errorf("normalize cannot be combined with exists_only or expand_all")
+		}
+		if normalize {
+			if query, err = rewriteNormalize(query); err != nil {
+				return nil, fmt.Errorf("failed to rewrite query for normalize: %v", err)
+			}
+		}
+
+		cascade, cascadePredicates, err := boolOrStringsArgument(request, "cascade")
+		if err != nil {
+			return nil, err
+		}
+		if cascade && existsOnly {
+			return nil, fmt.Errorf("cascade cannot be combined with exists_only")
+		}
+		if cascade {
+			if query, err = rewriteCascade(query, cascadePredicates); err != nil {
+				return nil, fmt.Errorf("failed to rewrite query for cascade: %v", err)
+			}
+		}
+
+		var resultBlocks []string
+		if existsOnly {
+			query, resultBlocks, err = rewriteExistsOnly(query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewrite query for exists_only: %v", err)
+			}
+		}
+		if expandAll {
+			query, resultBlocks, err = rewriteExpandAll(query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewrite query for expand_all: %v", err)
+			}
+		}
+
+		// Bound result blocks the query left unbounded
+		query, limitedBlocks, err := applyDefaultFirst(query, limits.DefaultFirst)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %v", err)
+		}
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Refuse queries likely to scan the whole database unless forced
+		cost, err := estimateQueryCost(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %v", err)
+		}
+		force, err := boolArgument(request, "force", false)
+		if err != nil {
+			return nil, err
+		}
+		if err := cost.check(force); err != nil {
+			return nil, err
+		}
+
+		// Internal fields to strip from the result
+		omit := map[string]bool{}
+		for arg, key := range map[string]string{"omit_uids": "uid", "omit_types": "dgraph.type"} {
+			enabled, err := boolArgument(request, arg, false)
+			if err != nil {
+				return nil, err
+			}
+			if enabled {
+				omit[key] = true
+			}
+		}
+		if omit["uid"] && responseFormat == "rdf" {
+			return nil, fmt.Errorf("omit_uids cannot be combined with response_format rdf, which needs uids")
+		}
+		pretty, err := boolArgument(request, "pretty", false)
+		if err != nil {
+			return nil, err
+		}
+		if pretty && responseFormat != "json" {
+			return nil, fmt.Errorf("pretty only applies to response_format json")
+		}
+		markdown, err := boolArgument(request, "markdown", false)
+		if err != nil {
+			return nil, err
+		}
+		if markdown && (responseFormat != "json" || existsOnly) {
+			return nil, fmt.Errorf("markdown cannot be combined with response_format rdf or csv, or with exists_only")
+		}
+
+		vars, err := queryVarsArgument(request, "variables")
+		if err != nil {
+			return nil, err
+		}
+
+		// Queries in an open transaction see its uncommitted mutations, and
+		// pinned queries read an older version, so only other queries are cached
+		txnID, _ := request.Params.Arguments["txn_id"].(string)
+		readTs, err := intArgument(request, "read_ts", 0)
+		if err != nil {
+			return nil, err
+		}
+		if readTs < 0 {
+			return nil, fmt.Errorf("read_ts must be a positive timestamp")
+		}
+		if readTs > 0 && txnID != "" {
+			return nil, fmt.Errorf("read_ts cannot be combined with txn_id")
+		}
+		txnCtx, err := txnContextArgument(request)
+		if err != nil {
+			return nil, err
+		}
+		if txnCtx != nil && (txnID != "" || readTs > 0) {
+			return nil, fmt.Errorf("txn_context cannot be combined with txn_id or read_ts")
+		}
+		cacheKey := newQueryCacheKey(client, query, vars)
+		var (
+			cached     []byte
+			generation uint64
+			cacheHit   bool
+			// Whether the result came from another request's identical query
+			sharedFlight bool
+		)
+		if txnID == "" && readTs == 0 && txnCtx == nil && queryResults.enabled() {
+			cached, generation, cacheHit = queryResults.get(cacheKey)
+		}
+
+		// Execute query, inside an open transaction if one was given
+		var resp *api.Response
+		runQuery := func(txn *dgo.Txn) error {
+			resp, err = txn.QueryWithVars(ctx, query, vars)
+			return err
+		}
+		switch {
+		case txnID != "" && readOnly:
+			return nil, fmt.Errorf("transactions are disabled in read-only mode")
+		case txnCtx != nil && readOnly:
+			// An external transaction isn't read-only, and its coordinator
+			// may commit writes made in it elsewhere
+			return nil, fmt.Errorf("txn_context is disabled in read-only mode")
+		case cacheHit:
+			resp = &api.Response{Json: cached}
+		case txnID != "":
+			err = txns.use(txnID, runQuery)
+		case readTs > 0:
+			err = snapshots.use(client, uint64(readTs), runQuery)
+		case txnCtx != nil:
+			// Never discarded: the coordinator commits or aborts it
+			resp, err = client.NewTxn().QueryWithVars(withExternalTxn(ctx, txnCtx), query, vars)
+		default:
+			// Identical queries in flight share one read. Keep the read-only
+			// transaction so that later queries can pass its read timestamp
+			// to read the same snapshot.
+			resp, sharedFlight, err = inflightQueries.do(ctx, cacheKey, func(ctx context.Context) (*api.Response, error) {
+				txn := client.NewReadOnlyTxn()
+				resp, err := txn.QueryWithVars(ctx, query, vars)
+				if err == nil {
+					snapshots.add(client, txn, readTimestamp(resp))
+				}
+				return resp, err
+			})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v%s", err, notIndexedHint(ctx, client, query, err))
+		}
+		if txnID == "" && readTs == 0 && txnCtx == nil && !cacheHit {
+			queryResults.put(cacheKey, generation, resp.Json)
+		}
+
+		if existsOnly {
+			exists, err := resultHasData(resp.Json, resultBlocks)
+			if err != nil {
+				return nil, err
+			}
+			result := withQueryCost(withReadTs(ctx, mcp.NewToolResultText(fmt.Sprintf(`{"exists": %t}`, exists)), resp), cost)
+			return addDebugMeta(ctx, result, "cache_hit", cacheHit), nil
+		}
+
+		var result *mcp.CallToolResult
+		switch responseFormat {
+		case "rdf":
+			rdf, err := jsonToNQuads(resp.Json)
+			if err != nil {
+				return nil, err
+			}
+			result = mcp.NewToolResultText(rdf)
+		case "csv":
+			data, err := stripJSONKeys(resp.Json, omit)
+			if err != nil {
+				return nil, err
+			}
+			table, err := resultToCSV(data)
+			if err != nil {
+				return nil, err
+			}
+			result = mcp.NewToolResultText(table)
+		default:
+			data, err := stripJSONKeys(resp.Json, omit)
+			if err != nil {
+				return nil, err
+			}
+			summary := ""
+			if markdown {
+				if summary, err = renderMarkdownSummary(data); err != nil {
+					return nil, err
+				}
+			}
+			if pretty {
+				data = prettyJSON(data)
+			}
+			// Return the JSON result, followed by its summary if requested
+			result = mcp.NewToolResultText(string(data))
+			if summary != "" {
+				result.Content = append(result.Content, mcp.NewTextContent(summary))
+			}
+		}
 
-		// Execute query
-		resp, err := txn.Query(ctx, query)
+		// An empty result is easy to mistake for a malformed one
+		empty, err := resultIsEmpty(resp.Json)
 		if err != nil {
-			return nil, fmt.Errorf("query failed: %v", err)
+			return nil, err
+		}
+		if empty {
+			result.Content = append(result.Content, mcp.NewTextContent("No results: the query matched no nodes."))
+		}
+		if wrapped != "" {
+			result.Content = append(result.Content, mcp.NewTextContent("Note: the bare selection was wrapped into the query:\n"+wrapped))
+		}
+		if len(limitedBlocks) > 0 {
+			result.Content = append(result.Content, mcp.NewTextContent(defaultFirstNote(limits.DefaultFirst, limitedBlocks)))
 		}
 
-		// Return the JSON result
-		return mcp.NewToolResultText(string(resp.Json)), nil
+		// expand(_all_) silently returns nothing for nodes without a type
+		if expandAll {
+			untyped, total, err := countUntypedNodes(resp.Json, resultBlocks)
+			if err != nil {
+				return nil, err
+			}
+			if untyped > 0 {
+				warning := fmt.Sprintf("Warning: %d of %d nodes have no dgraph.type, so expand(_all_) returned none of their predicates. List the predicates explicitly or set dgraph.type on the nodes.", untyped, total)
+				slog.Warn("expand_all matched untyped nodes", "untyped", untyped, "total", total)
+				result.Content = append(result.Content, mcp.NewTextContent(warning))
+			}
+		}
+		result = addDebugMeta(ctx, withQueryCost(withReadTs(ctx, result, resp), cost), "cache_hit", cacheHit)
+		return addDebugMeta(ctx, result, "shared_flight", sharedFlight), nil
 	}
 }
 
 // Create handler for the mutation tool
-func createMutationHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func createMutationHandler(client *dgo.Dgraph, sessions *blankNodeSessions, txns *txnRegistry, defaultCommit bool) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
 		mutation, ok := request.Params.Arguments["mutation"].(string)
-		if !ok {
+		if !ok && request.Params.Arguments["mutation"] != nil {
 			return nil, fmt.Errorf("mutation must be a string")
 		}
+		deletion, ok := request.Params.Arguments["delete"].(string)
+		if !ok && request.Params.Arguments["delete"] != nil {
+			return nil, fmt.Errorf("delete must be a string")
+		}
+		if strings.TrimSpace(mutation) == "" && strings.TrimSpace(deletion) == "" {
+			return nil, fmt.Errorf("mutation or delete must be given")
+		}
+
+		// Default to committing the transaction unless configured otherwise
+		commit, err := boolArgument(request, "commit", defaultCommit)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reuse the uids of blank nodes assigned earlier in the session
+		session, _ := request.Params.Arguments["session"].(string)
+		if session != "" {
+			assigned := sessions.get(session)
+			mutation = rewriteBlankNodes(mutation, assigned)
+			deletion = rewriteBlankNodes(deletion, assigned)
+		}
+
+		// Type the nodes the mutation creates
+		if typeName, ok := request.Params.Arguments["type"].(string); ok && typeName != "" {
+			if err := validateName("type", typeName); err != nil {
+				return nil, err
+			}
+			mutation = addBlankNodeTypes(mutation, typeName)
+		}
+
+		// Write untagged strings of @lang predicates in the given language
+		tag, langSchema, err := langTagArgument(ctx, client, request)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" {
+			tagged := 0
+			if mutation, tagged = tagLangNQuads(langSchema, mutation, tag); tagged == 0 {
+				return nil, noLangPredicatesError(tag)
+			}
+		}
+
+		// Catch syntax errors before they abort a transaction
+		if err := validateNQuads(mutation, false); err != nil {
+			return nil, fmt.Errorf("mutation: %v", err)
+		}
+		if err := validateNQuads(deletion, true); err != nil {
+			return nil, fmt.Errorf("delete: %v", err)
+		}
+		if err := predicateAccess.checkNQuads(mutation); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkNQuads(deletion); err != nil {
+			return nil, err
+		}
 
-		// Default to committing the transaction
-		commit := true
-		if commitArg, ok := request.Params.Arguments["commit"].(bool); ok {
-			commit = commitArg
+		// Mutations inside an open transaction are committed by dgraph_commit_txn
+		txnID, _ := request.Params.Arguments["txn_id"].(string)
+		if txnID != "" {
+			commit = false
 		}
 
-		// Create transaction
-		txn := client.NewTxn()
-		defer txn.Discard(ctx)
+		// Mutations joining an external transaction are committed by its coordinator
+		txnCtx, err := txnContextArgument(request)
+		if err != nil {
+			return nil, err
+		}
+		if txnCtx != nil {
+			if txnID != "" {
+				return nil, fmt.Errorf("txn_context cannot be combined with txn_id")
+			}
+			if explicit, _ := boolArgument(request, "commit", false); explicit {
+				return nil, fmt.Errorf("mutations in an external transaction can't be committed here; its coordinator commits it")
+			}
+			commit = false
+		}
 
-		// Create mutation
+		// Create mutation, setting and deleting in one atomic request
 		mu := &api.Mutation{
-			SetNquads: []byte(mutation),
 			CommitNow: commit,
 		}
+		if mutation != "" {
+			mu.SetNquads = []byte(mutation)
+		}
+		if deletion != "" {
+			mu.DelNquads = []byte(deletion)
+		}
+
+		// Keep an uncommitted mutation in a new open transaction instead of
+		// rolling it back, so that it can still be committed later
+		staged := false
+		if !commit && txnID == "" && txnCtx == nil {
+			if txnID, err = txns.begin(client.NewTxn()); err != nil {
+				return nil, err
+			}
+			staged = true
+		}
 
 		// Execute mutation
-		resp, err := txn.Mutate(ctx, mu)
+		var resp *api.Response
+		runMutation := func(txn *dgo.Txn) error {
+			resp, err = txn.Mutate(ctx, mu)
+			return err
+		}
+		switch {
+		case txnID != "":
+			err = txns.use(txnID, runMutation)
+		case txnCtx != nil:
+			// Never discarded: the coordinator commits or aborts it
+			resp, err = client.NewTxn().Mutate(withExternalTxn(ctx, txnCtx), mu)
+		default:
+			txn := activity.startTxn(client.NewTxn())
+			defer activity.finishTxn(ctx, txn)
+			err = runMutation(txn)
+		}
 		if err != nil {
+			if staged {
+				txns.finish(ctx, txnID, false)
+			}
 			return nil, fmt.Errorf("mutation failed: %v", err)
 		}
 
-		// Return the JSON result
-		return mcp.NewToolResultText(fmt.Sprintf("Mutation successful. Response: %v", resp)), nil
+		// Mutations change data and can add predicates to the schema
+		invalidateCaches()
+
+		uids := resp.Uids
+		if uids == nil {
+			uids = map[string]string{}
+		}
+		if session != "" && commit {
+			sessions.record(session, uids)
+		}
+
+		// Don't claim success for a write that isn't saved yet
+		message := "Mutation successful"
+		switch {
+		case staged:
+			message = "Mutation was not committed and nothing is saved yet. It is kept in transaction " + txnID + ": call dgraph_commit_txn to save it or dgraph_discard_txn to drop it"
+		case txnID != "":
+			message = "Mutation applied to transaction " + txnID + "; it is saved when dgraph_commit_txn is called"
+		case txnCtx != nil:
+			message = "Mutation applied to the external transaction; it is saved when its coordinator commits it with the returned txn_context"
+		}
+
+		// Return the assigned uids keyed by blank node name
+		response := map[string]interface{}{
+			"message":   message,
+			"committed": commit,
+			"uids":      uids,
+		}
+		if txnID != "" {
+			response["txn_id"] = txnID
+		}
+		if txnCtx != nil {
+			response["txn_context"] = mergeTxnContext(txnCtx, resp.Txn)
+		}
+		result, err := json.Marshal(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode mutation response: %v", err)
+		}
+		return mcp.NewToolResultText(string(result)), nil
 	}
 }
 
 // Create handler for the schema tool
 func createSchemaHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
 		schema, ok := request.Params.Arguments["schema"].(string)
 		if !ok {
 			return nil, fmt.Errorf("schema must be a string")
 		}
 
-		// Create operation
-		op := &api.Operation{
-			Schema: schema,
+		dryRun, err := boolArgument(request, "dry_run", false)
+		if err != nil {
+			return nil, err
 		}
 
-		// Execute alter operation
-		err := client.Alter(ctx, op)
-		if err != nil {
-			return nil, fmt.Errorf("schema alteration failed: %v", err)
+		// Preview the changes instead of applying them
+		if dryRun {
+			proposed, err := parseSchema(schema)
+			if err != nil {
+				return nil, err
+			}
+
+			refresh, err := boolArgument(request, "refresh", false)
+			if err != nil {
+				return nil, err
+			}
+
+			current, err := fetchSchema(ctx, client, refresh)
+			if err != nil {
+				return nil, err
+			}
+
+			// Alter never drops what the schema leaves out
+			diff, unaffected := diffSchema(current, proposed).withoutRemovals()
+			out, err := json.Marshal(struct {
+				Applied    bool       `json:"applied"`
+				Reindex    []string   `json:"reindex"`
+				Unaffected []string   `json:"unaffected"`
+				Diff       schemaDiff `json:"diff"`
+			}{false, diff.reindexChanges(), unaffected, diff})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode diff: %v", err)
+			}
+
+			return mcp.NewToolResultText(string(out)), nil
+		}
+
+		// Execute alter operation, keeping the schema it replaces
+		if err := snapshotSchema(ctx, client, "dgraph_alter_schema"); err != nil {
+			return nil, err
+		}
+		if err := alterSchema(ctx, client, schema); err != nil {
+			return nil, err
 		}
 
 		return mcp.NewToolResultText("Schema updated successfully"), nil
@@ -196,22 +2063,21 @@ func createSchemaHandler(client *dgo.Dgraph) func(ctx context.Context, request m
 // Create handler for the schema resource
 func createSchemaResourceHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
 	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
-		// Create operation to get schema
-		op := &api.Operation{
-			Schema: "",
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
 		}
 
-		// Execute operation
-		resp, err := client.NewTxn().Query(ctx, "schema {}")
+		schema, err := schemas.get(ctx, client, false)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get schema: %v", err)
+			return nil, err
 		}
 
 		return []mcp.ResourceContents{
 			mcp.TextResourceContents{
 				URI:      "dgraph://schema",
 				MIMEType: "text/plain",
-				Text:     string(resp.Json),
+				Text:     string(schema.raw),
 			},
 		}, nil
 	}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Limits keeping markdown summaries of query results readable
+const (
+	markdownMaxRows     = 20
+	markdownMaxColumns  = 8
+	markdownMaxCellSize = 60
+	markdownMaxSummary  = 3
+)
+
+// Render the top-level blocks of a JSON query result as markdown tables,
+// one row per node. Nested objects and lists are summarized in their cell.
+func renderMarkdownSummary(data []byte) (string, error) {
+	var result map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode query result: %v", err)
+	}
+
+	var b strings.Builder
+	for i, name := range sortedKeys(result) {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		nodes, ok := result[name].([]interface{})
+		if !ok {
+			fmt.Fprintf(&b, "**%s**: %s\n", name, markdownCell(result[name]))
+			continue
+		}
+		fmt.Fprintf(&b, "**%s** (%d %s)\n\n", name, len(nodes), plural(len(nodes), "result", "results"))
+		if len(nodes) == 0 {
+			b.WriteString("_No results_\n")
+			continue
+		}
+		writeMarkdownTable(&b, nodes)
+	}
+	return b.String(), nil
+}
+
+// Write nodes as a table whose columns are the fields of all nodes, uid
+// first. Values that aren't objects get a single value column.
+func writeMarkdownTable(b *strings.Builder, nodes []interface{}) {
+	seen := map[string]bool{}
+	var columns []string
+	for _, node := range nodes {
+		if obj, ok := node.(map[string]interface{}); ok {
+			for key := range obj {
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		if (columns[i] == "uid") != (columns[j] == "uid") {
+			return columns[i] == "uid"
+		}
+		return columns[i] < columns[j]
+	})
+	hidden := 0
+	if len(columns) > markdownMaxColumns {
+		hidden = len(columns) - markdownMaxColumns
+		columns = columns[:markdownMaxColumns]
+	}
+	if len(columns) == 0 {
+		columns = []string{"value"}
+	}
+
+	b.WriteString("| " + strings.Join(escapeMarkdownCells(columns), " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for i, node := range nodes {
+		if i == markdownMaxRows {
+			fmt.Fprintf(b, "\n_%d more %s not shown_\n", len(nodes)-i, plural(len(nodes)-i, "row", "rows"))
+			break
+		}
+		cells := make([]string, len(columns))
+		obj, ok := node.(map[string]interface{})
+		for j, column := range columns {
+			switch {
+			case ok:
+				if value, present := obj[column]; present {
+					cells[j] = markdownCell(value)
+				}
+			case j == 0:
+				cells[j] = markdownCell(node)
+			}
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	if hidden > 0 {
+		fmt.Fprintf(b, "\n_%d more %s not shown_\n", hidden, plural(hidden, "column", "columns"))
+	}
+}
+
+// Format a value for a table cell: scalars as they are, objects as a few of
+// their fields and lists as their items or their length
+func markdownCell(value interface{}) string {
+	return escapeMarkdownCell(truncateCell(summarizeValue(value)))
+}
+
+// Summarize a value in a single line
+func summarizeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}:
+		return summarizeObject(v)
+	case []interface{}:
+		if len(v) == 1 {
+			return summarizeValue(v[0])
+		}
+		scalars := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Sprintf("%d %s", len(v), plural(len(v), "item", "items"))
+			}
+			scalars = append(scalars, summarizeValue(item))
+		}
+		return strings.Join(scalars, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Summarize an object by its first few fields, nested values by their kind
+func summarizeObject(obj map[string]interface{}) string {
+	keys := sortedKeys(obj)
+	parts := make([]string, 0, markdownMaxSummary+1)
+	for _, key := range keys {
+		if len(parts) == markdownMaxSummary {
+			parts = append(parts, "…")
+			break
+		}
+		switch v := obj[key].(type) {
+		case map[string]interface{}:
+			parts = append(parts, key+": {…}")
+		case []interface{}:
+			parts = append(parts, fmt.Sprintf("%s: [%d]", key, len(v)))
+		default:
+			parts = append(parts, key+": "+summarizeValue(v))
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// Shorten a cell to markdownMaxCellSize characters
+func truncateCell(s string) string {
+	if runes := []rune(s); len(runes) > markdownMaxCellSize {
+		return string(runes[:markdownMaxCellSize-1]) + "…"
+	}
+	return s
+}
+
+// Keep a cell on one line and its pipes from ending it
+func escapeMarkdownCell(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func escapeMarkdownCells(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = escapeMarkdownCell(cell)
+	}
+	return escaped
+}
+
+// Choose the singular or plural form of a word for n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownSummary(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			"table",
+			`{"people": [{"uid": "0x1", "name": "Alice", "age": 30}, {"uid": "0x2", "name": "Bob | Jr.\nII"}]}`,
+			"**people** (2 results)\n\n| uid | age | name |\n| --- | --- | --- |\n| 0x1 | 30 | Alice |\n| 0x2 |  | Bob \\| Jr. II |\n",
+		},
+		{
+			"nested values",
+			`{"q": [{"name": "Alice", "friend": [{"name": "Bob"}, {"name": "Carol"}], "boss": {"name": "Dan", "age": 50, "email": "d@x", "team": {"n": 1}}, "tags": ["a", "b"], "pet": [{"name": "Rex", "owners": [1, 2]}]}]}`,
+			"**q** (1 result)\n\n| boss | friend | name | pet | tags |\n| --- | --- | --- | --- | --- |\n| {age: 50, email: d@x, name: Dan, …} | 2 items | Alice | {name: Rex, owners: [2]} | a, b |\n",
+		},
+		{
+			"empty and scalar blocks",
+			`{"a": [], "b": [1, 2]}`,
+			"**a** (0 results)\n\n_No results_\n\n**b** (2 results)\n\n| value |\n| --- |\n| 1 |\n| 2 |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := renderMarkdownSummary([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("renderMarkdownSummary() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("renderMarkdownSummary() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := renderMarkdownSummary([]byte("not json")); err == nil {
+		t.Errorf("renderMarkdownSummary() of invalid JSON succeeded, want error")
+	}
+}
+
+func TestRenderMarkdownSummaryLimits(t *testing.T) {
+	var nodes, fields []string
+	for i := 0; i < markdownMaxColumns+2; i++ {
+		fields = append(fields, fmt.Sprintf(`"f%d": %d`, i, i))
+	}
+	for i := 0; i < markdownMaxRows+5; i++ {
+		nodes = append(nodes, "{"+strings.Join(fields, ", ")+"}")
+	}
+	got, err := renderMarkdownSummary([]byte(`{"q": [` + strings.Join(nodes, ", ") + `]}`))
+	if err != nil {
+		t.Fatalf("renderMarkdownSummary() failed: %v", err)
+	}
+	for _, want := range []string{"_5 more rows not shown_", "_2 more columns not shown_"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("renderMarkdownSummary() = %q, want it to contain %q", got, want)
+		}
+	}
+	if rows := strings.Count(got, "\n| "); rows != markdownMaxRows+2 {
+		t.Errorf("got %d table lines, want a header, a separator and %d rows", rows, markdownMaxRows)
+	}
+
+	long := strings.Repeat("x", markdownMaxCellSize+10)
+	if cell := markdownCell(long); len([]rune(cell)) != markdownMaxCellSize || !strings.HasSuffix(cell, "…") {
+		t.Errorf("markdownCell() = %q, want it cut to %d characters", cell, markdownMaxCellSize)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mathFunctions are the functions Dgraph accepts in math() expressions
+var mathFunctions = map[string]bool{
+	"min": true, "max": true, "ln": true, "exp": true, "pow": true,
+	"logbase": true, "sqrt": true, "floor": true, "ceil": true,
+	"since": true, "cond": true,
+}
+
+var (
+	// mathTokenRe matches one token of a math() expression
+	mathTokenRe = regexp.MustCompile(`^(?:\s+|[A-Za-z_]\w*|\d+(?:\.\d*)?(?:[eE][+-]?\d+)?|\.\d+|==|!=|<=|>=|[-+*/%<>(),])`)
+	// mathIdentRe matches a variable or function name in a math() expression
+	mathIdentRe = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+	// countVarRe matches a variable defined as the count of an edge
+	countVarRe = regexp.MustCompile(`^count\(\s*([^()\s]+)\s*\)$`)
+)
+
+// mathQuery describes a query computing a math() expression for each node
+type mathQuery struct {
+	Func   string            // the root function selecting the nodes
+	Filter string            // an optional @filter expression
+	Vars   map[string]string // value variables by name: a predicate or count(edge)
+	Math   string            // the expression over the variables
+	As     string            // the variable, and result key, of the computed value
+	Fields string            // the fields to return for each node
+	Order  string            // asc, desc or none
+	First  int               // an optional result limit
+}
+
+// Check a math() expression: it may only use numbers, operators, the
+// functions Dgraph supports and the given variables
+func checkMathExpr(expr string, vars map[string]bool) error {
+	if strings.TrimSpace(expr) == "" {
+		return fmt.Errorf("math must be a non-empty expression")
+	}
+	depth := 0
+	for rest := expr; rest != ""; {
+		token := mathTokenRe.FindString(rest)
+		if token == "" {
+			return fmt.Errorf("math: unexpected %q", rest[:1])
+		}
+		rest = rest[len(token):]
+		switch {
+		case token == "(":
+			depth++
+		case token == ")":
+			if depth--; depth < 0 {
+				return fmt.Errorf("math: unbalanced parentheses")
+			}
+		case mathIdentRe.MatchString(token):
+			if strings.HasPrefix(strings.TrimSpace(rest), "(") {
+				if !mathFunctions[token] {
+					return fmt.Errorf("math: unknown function %s", token)
+				}
+			} else if !vars[token] {
+				return fmt.Errorf("math: variable %s is not defined; define it in vars", token)
+			}
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("math: unbalanced parentheses")
+	}
+	return nil
+}
+
+// Build a query defining the variables and the math() expression over the
+// nodes the root function selects, and returning each node with its
+// computed value
+func buildMathQuery(q mathQuery) (string, error) {
+	if strings.TrimSpace(q.Func) == "" {
+		return "", fmt.Errorf("func must be a non-empty string")
+	}
+	if len(q.Vars) == 0 {
+		return "", fmt.Errorf("vars must define at least one variable")
+	}
+	as := q.As
+	if as == "" {
+		as = "score"
+	}
+	if err := validateName("variable", as); err != nil {
+		return "", err
+	}
+	if _, ok := q.Vars[as]; ok {
+		return "", fmt.Errorf("variable %s is already defined in vars", as)
+	}
+	if q.First < 0 {
+		return "", fmt.Errorf("first must not be negative")
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n\tvar(func: " + q.Func + ")")
+	if q.Filter != "" {
+		b.WriteString(" @filter(" + q.Filter + ")")
+	}
+	b.WriteString(" {")
+	defined := map[string]bool{}
+	names := make([]string, 0, len(q.Vars))
+	for name := range q.Vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := validateName("variable", name); err != nil {
+			return "", err
+		}
+		source := strings.TrimSpace(q.Vars[name])
+		if m := countVarRe.FindStringSubmatch(source); m != nil {
+			if err := validateName("predicate", strings.TrimPrefix(m[1], "~")); err != nil {
+				return "", err
+			}
+			source = "count(" + m[1] + ")"
+		} else if err := validateField("predicate", source); err != nil {
+			return "", fmt.Errorf("variable %s must be a predicate or count(edge): %v", name, err)
+		}
+		defined[name] = true
+		fmt.Fprintf(&b, "\n\t\t%s as %s", name, source)
+	}
+	if err := checkMathExpr(q.Math, defined); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(&b, "\n\t\t%s as math(%s)\n\t}", as, strings.TrimSpace(q.Math))
+
+	args := "func: uid(" + as + ")"
+	switch q.Order {
+	case "", "desc":
+		args += ", orderdesc: val(" + as + ")"
+	case "asc":
+		args += ", orderasc: val(" + as + ")"
+	case "none":
+	default:
+		return "", fmt.Errorf("order must be asc, desc or none")
+	}
+	if q.First > 0 {
+		args += fmt.Sprintf(", first: %d", q.First)
+	}
+	fields := strings.TrimSpace(q.Fields)
+	if fields == "" {
+		fields = "uid"
+	}
+	fmt.Fprintf(&b, "\n\tresult(%s) {\n\t\t%s\n\t\t%s: val(%s)\n\t}\n}", args, fields, as, as)
+
+	// Catch unbalanced fragments before they can change the block structure
+	query := b.String()
+	parsed, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", err
+	}
+	if len(parsed) != 2 {
+		return "", fmt.Errorf("func, filter and fields must not contain unbalanced braces")
+	}
+	return query, nil
+}
+
+// Create handler for the math query tool
+func createMathQueryHandler(client *dgo.Dgraph, limits queryLimits) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		var q mathQuery
+		for name, dst := range map[string]*string{"func": &q.Func, "filter": &q.Filter, "math": &q.Math, "as": &q.As, "fields": &q.Fields, "order": &q.Order} {
+			value, ok := request.Params.Arguments[name].(string)
+			if !ok && request.Params.Arguments[name] != nil {
+				return nil, fmt.Errorf("%s must be a string", name)
+			}
+			*dst = value
+		}
+		vars, ok := request.Params.Arguments["vars"].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("vars must be an object mapping variable names to predicates")
+		}
+		q.Vars = make(map[string]string, len(vars))
+		for name, value := range vars {
+			if q.Vars[name], ok = value.(string); !ok {
+				return nil, fmt.Errorf("variable %s must be a predicate or count(edge)", name)
+			}
+		}
+		if q.First, err = intArgument(request, "first", 0); err != nil {
+			return nil, err
+		}
+
+		query, err := buildMathQuery(q)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v\nGenerated query:\n%s%s", err, query, notIndexedHint(ctx, client, query, err))
+		}
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMathExpr(t *testing.T) {
+	vars := map[string]bool{"r": true, "votes": true, "born": true}
+	tests := []struct {
+		expr    string
+		wantErr bool
+	}{
+		{"r * 0.7 + votes / 1000", false},
+		{"ln(votes + 1) * r", false},
+		{"max(r, 2.5e1) - .5", false},
+		{"cond(r >= 4, 1, 0) + since(born) / 86400", false},
+		{"(r + votes) % 3 == 0", false},
+		{"", true},
+		{"r * weight", true},
+		{"drop(r)", true},
+		{"(r + 1", true},
+		{"r + 1)", true},
+		{"r } result(func: has(x)) { x", true},
+		{`r + "1"`, true},
+	}
+	for _, tt := range tests {
+		if err := checkMathExpr(tt.expr, vars); (err != nil) != tt.wantErr {
+			t.Errorf("checkMathExpr(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBuildMathQuery(t *testing.T) {
+	query, err := buildMathQuery(mathQuery{
+		Func:   "type(Movie)",
+		Filter: "has(rating)",
+		Vars:   map[string]string{"r": "rating", "f": "count(fans)"},
+		Math:   "r * 0.7 + f",
+		Fields: "uid title",
+		First:  5,
+	})
+	if err != nil {
+		t.Fatalf("buildMathQuery() error = %v", err)
+	}
+	for _, want := range []string{
+		"var(func: type(Movie)) @filter(has(rating)) {",
+		"f as count(fans)",
+		"r as rating",
+		"score as math(r * 0.7 + f)",
+		"result(func: uid(score), orderdesc: val(score), first: 5) {",
+		"uid title",
+		"score: val(score)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+
+	query, err = buildMathQuery(mathQuery{Func: "has(age)", Vars: map[string]string{"a": "age"}, Math: "a * 2", As: "double", Order: "none"})
+	if err != nil {
+		t.Fatalf("buildMathQuery() error = %v", err)
+	}
+	if !strings.Contains(query, "result(func: uid(double)) {\n\t\tuid\n\t\tdouble: val(double)") {
+		t.Errorf("unexpected query:\n%s", query)
+	}
+
+	for _, q := range []mathQuery{
+		{Vars: map[string]string{"a": "age"}, Math: "a"},
+		{Func: "has(age)", Math: "1"},
+		{Func: "has(age)", Vars: map[string]string{"a": "age"}, Math: "b"},
+		{Func: "has(age)", Vars: map[string]string{"a": "age) { x"}, Math: "a"},
+		{Func: "has(age)", Vars: map[string]string{"score": "age"}, Math: "score"},
+		{Func: "has(age)", Vars: map[string]string{"a": "age"}, Math: "a", Order: "random"},
+		{Func: "has(age)", Vars: map[string]string{"a": "age"}, Math: "a", Fields: "name } q(func: has(x)) {"},
+		{Func: "has(age)", Vars: map[string]string{"a": "age"}, Math: "a", First: -1},
+	} {
+		if _, err := buildMathQuery(q); err == nil {
+			t.Errorf("buildMathQuery(%+v) expected error", q)
+		}
+	}
+}
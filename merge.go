@@ -0,0 +1,360 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// mergePlan is the mutation merging duplicate nodes into a canonical one
+type mergePlan struct {
+	Set    []map[string]interface{} // objects to set, the canonical node first
+	Delete []map[string]interface{} // edges and predicates to delete
+
+	CopiedValues   int      // values copied onto the canonical node
+	CopiedEdges    int      // outgoing edges copied onto the canonical node
+	RewrittenEdges int      // incoming edges pointed at the canonical node
+	Kept           []string // single-valued predicates the canonical node kept its own value of
+}
+
+// Pick the predicates to move from the duplicates: every predicate the
+// server may read, except passwords, whose values can't be read back
+func mergePredicates(schema *schemaInfo) ([]predicateSchema, error) {
+	var preds []predicateSchema
+	for _, p := range schema.Predicates {
+		if isInternalName(p.Predicate) || p.Type == "password" || predicateAccess.check(p.Predicate) != nil {
+			continue
+		}
+		if err := validateName("predicate", p.Predicate); err != nil {
+			return nil, err
+		}
+		preds = append(preds, p)
+	}
+	return preds, nil
+}
+
+// Build the query reading the canonical node and the duplicates with all
+// their values and edges, and the edges pointing at the duplicates. Incoming
+// edges are read through @reverse where the predicate has it and found by
+// scanning the predicate with uid_in otherwise, aliased in<i> either way.
+func buildMergeQuery(canonical string, duplicates []string, preds []predicateSchema) string {
+	var fields, scans strings.Builder
+	for i, p := range preds {
+		switch {
+		case p.Type == "uid":
+			fmt.Fprintf(&fields, "\n\t\t<%s> { uid }", p.Predicate)
+			if p.Reverse {
+				fmt.Fprintf(&fields, "\n\t\tin%d: ~<%s> { uid }", i, p.Predicate)
+				continue
+			}
+			filters := make([]string, len(duplicates))
+			for j, d := range duplicates {
+				filters[j] = fmt.Sprintf("uid_in(<%s>, %s)", p.Predicate, d)
+			}
+			fmt.Fprintf(&scans, "\n\tin%d(func: has(<%s>)) @filter(%s) {\n\t\tuid\n\t\t<%s> { uid }\n\t}",
+				i, p.Predicate, strings.Join(filters, " OR "), p.Predicate)
+		case p.Lang:
+			fmt.Fprintf(&fields, "\n\t\t<%s>@*", p.Predicate)
+		default:
+			fmt.Fprintf(&fields, "\n\t\t<%s>", p.Predicate)
+		}
+	}
+
+	uids := append([]string{canonical}, duplicates...)
+	return fmt.Sprintf("{\n\tnodes(func: uid(%s)) {\n\t\tuid\n\t\tdgraph.type%s\n\t}%s\n}",
+		strings.Join(uids, ", "), fields.String(), scans.String())
+}
+
+// Read the uids of an edge, which Dgraph returns as a list, or as a single
+// object for predicates that aren't lists
+func edgeUIDs(raw json.RawMessage) []string {
+	var nodes []uidNode
+	if err := json.Unmarshal(raw, &nodes); err != nil {
+		var node uidNode
+		if json.Unmarshal(raw, &node) != nil || node.UID == "" {
+			return nil
+		}
+		nodes = []uidNode{node}
+	}
+	return resultUIDs(nodes)
+}
+
+// Plan the merge from the result of buildMergeQuery: values, types and
+// outgoing edges of the duplicates are copied onto the canonical node,
+// edges pointing at a duplicate are pointed at the canonical node instead,
+// and every predicate of the duplicates is deleted. Single-valued
+// predicates the canonical node already has keep its value, as does the
+// first duplicate setting one. Edges between the merged nodes would become
+// loops on the canonical node and are dropped.
+func planMerge(data []byte, canonical string, duplicates []string, preds []predicateSchema) (mergePlan, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return mergePlan{}, fmt.Errorf("failed to parse query response: %v", err)
+	}
+	var nodes []map[string]json.RawMessage
+	if err := json.Unmarshal(result["nodes"], &nodes); err != nil && result["nodes"] != nil {
+		return mergePlan{}, fmt.Errorf("failed to parse query response: %v", err)
+	}
+	byUID := make(map[string]map[string]json.RawMessage, len(nodes))
+	for _, node := range nodes {
+		var uid string
+		if err := json.Unmarshal(node["uid"], &uid); err != nil {
+			return mergePlan{}, fmt.Errorf("node without uid in query response")
+		}
+		byUID[uid] = node
+	}
+
+	merged := append([]string{canonical}, duplicates...)
+	for _, uid := range merged {
+		// A uid function returns any uid, so a node with only a uid is missing
+		if len(byUID[uid]) < 2 {
+			return mergePlan{}, fmt.Errorf("node %s has no predicates", uid)
+		}
+	}
+	target := byUID[canonical]
+
+	var (
+		plan     mergePlan
+		set      = map[string]interface{}{"uid": canonical}
+		kept     = map[string]bool{}
+		types    []string
+		lists    = map[string][]interface{}{}
+		rewrites []map[string]interface{}
+		deletes  []map[string]interface{}
+	)
+	predicates := make(map[string]predicateSchema, len(preds))
+	for _, p := range preds {
+		predicates[p.Predicate] = p
+	}
+
+	// Point an edge src -p-> dup at the canonical node. An edge from the
+	// canonical node would become a loop, so it's only deleted; edges from
+	// the other duplicates go with their predicates.
+	rewrite := func(src, predicate, dup string) {
+		if src == canonical {
+			deletes = append(deletes, map[string]interface{}{"uid": src, predicate: map[string]interface{}{"uid": dup}})
+			return
+		}
+		if slices.Contains(merged, src) {
+			return
+		}
+		rewrites = append(rewrites, map[string]interface{}{"uid": src, predicate: map[string]interface{}{"uid": canonical}})
+		deletes = append(deletes, map[string]interface{}{"uid": src, predicate: map[string]interface{}{"uid": dup}})
+		plan.RewrittenEdges++
+	}
+
+	for _, dup := range duplicates {
+		node := byUID[dup]
+		remove := map[string]interface{}{"uid": dup}
+		for _, key := range sortedKeys(node) {
+			name, _, _ := strings.Cut(key, "@")
+			if name == "dgraph.type" {
+				var dupTypes []string
+				if err := json.Unmarshal(node[key], &dupTypes); err != nil {
+					return mergePlan{}, fmt.Errorf("failed to parse dgraph.type of %s: %v", dup, err)
+				}
+				types = append(types, dupTypes...)
+				remove[name] = nil
+				continue
+			}
+			p, ok := predicates[name]
+			if !ok {
+				// uid, or the incoming edges of a reverse predicate
+				continue
+			}
+			remove[name] = nil
+
+			switch {
+			case p.Type == "uid":
+				for _, uid := range edgeUIDs(node[key]) {
+					if slices.Contains(merged, uid) {
+						continue
+					}
+					if !p.List {
+						if _, ok := target[key]; ok || set[key] != nil {
+							kept[key] = true
+							break
+						}
+						set[key] = map[string]interface{}{"uid": uid}
+					} else {
+						lists[key] = append(lists[key], map[string]interface{}{"uid": uid})
+					}
+					plan.CopiedEdges++
+				}
+			case p.List:
+				var values []json.RawMessage
+				if err := json.Unmarshal(node[key], &values); err != nil {
+					return mergePlan{}, fmt.Errorf("failed to parse %s of %s: %v", key, dup, err)
+				}
+				for _, v := range values {
+					lists[key] = append(lists[key], v)
+				}
+				plan.CopiedValues += len(values)
+			default:
+				if _, ok := target[key]; ok || set[key] != nil {
+					kept[key] = true
+					continue
+				}
+				set[key] = node[key]
+				plan.CopiedValues++
+			}
+		}
+		deletes = append(deletes, remove)
+
+		// Incoming edges read through @reverse
+		for i, p := range preds {
+			for _, src := range edgeUIDs(node[fmt.Sprintf("in%d", i)]) {
+				rewrite(src, p.Predicate, dup)
+			}
+		}
+	}
+
+	// Incoming edges found by scanning predicates without @reverse
+	for i, p := range preds {
+		raw, ok := result[fmt.Sprintf("in%d", i)]
+		if !ok {
+			continue
+		}
+		var sources []map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &sources); err != nil {
+			return mergePlan{}, fmt.Errorf("failed to parse query response: %v", err)
+		}
+		for _, source := range sources {
+			var src string
+			if err := json.Unmarshal(source["uid"], &src); err != nil {
+				return mergePlan{}, fmt.Errorf("node without uid in query response")
+			}
+			for _, uid := range edgeUIDs(source[p.Predicate]) {
+				if slices.Contains(duplicates, uid) {
+					rewrite(src, p.Predicate, uid)
+				}
+			}
+		}
+	}
+
+	var targetTypes []string
+	if raw, ok := target["dgraph.type"]; ok {
+		if err := json.Unmarshal(raw, &targetTypes); err != nil {
+			return mergePlan{}, fmt.Errorf("failed to parse dgraph.type of %s: %v", canonical, err)
+		}
+	}
+	var newTypes []string
+	for _, t := range types {
+		if !slices.Contains(targetTypes, t) && !slices.Contains(newTypes, t) {
+			newTypes = append(newTypes, t)
+		}
+	}
+	if len(newTypes) > 0 {
+		set["dgraph.type"] = newTypes
+	}
+	for key, values := range lists {
+		set[key] = values
+	}
+
+	if len(set) > 1 {
+		plan.Set = append(plan.Set, set)
+	}
+	plan.Set = append(plan.Set, rewrites...)
+	plan.Delete = deletes
+	plan.Kept = sortedKeys(kept)
+	return plan, nil
+}
+
+// Create handler for the merge nodes tool
+func createMergeNodesHandler(client *dgo.Dgraph, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		canonical, err := uidArgument(request, "canonical")
+		if err != nil {
+			return nil, err
+		}
+		values, err := stringsArgument(request, "duplicates")
+		if err != nil {
+			return nil, err
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("duplicates must list at least one uid")
+		}
+		var duplicates []string
+		for _, v := range values {
+			uid, err := normalizeUID(v)
+			if err != nil {
+				return nil, err
+			}
+			if uid == canonical {
+				return nil, fmt.Errorf("the canonical node %s can't also be a duplicate", uid)
+			}
+			if slices.Contains(duplicates, uid) {
+				return nil, fmt.Errorf("duplicate %s is listed twice", uid)
+			}
+			duplicates = append(duplicates, uid)
+		}
+
+		// Read the predicates from the live schema, so none is left behind
+		schema, err := fetchSchema(ctx, client, true)
+		if err != nil {
+			return nil, err
+		}
+		preds, err := mergePredicates(schema)
+		if err != nil {
+			return nil, err
+		}
+		query := buildMergeQuery(canonical, duplicates, preds)
+
+		// Read and write in one transaction, so a concurrent change to the
+		// nodes aborts the merge, which is then planned again
+		var plan mergePlan
+		attempts, err := retry.do(ctx, func() error {
+			txn := activity.startTxn(client.NewTxn())
+			defer activity.finishTxn(ctx, txn)
+
+			resp, err := txn.Query(ctx, query)
+			if err != nil {
+				return fmt.Errorf("failed to read nodes: %v", err)
+			}
+			if plan, err = planMerge(resp.Json, canonical, duplicates, preds); err != nil {
+				return err
+			}
+
+			mu := &api.Mutation{CommitNow: true}
+			if len(plan.Set) > 0 {
+				if mu.SetJson, err = json.Marshal(plan.Set); err != nil {
+					return fmt.Errorf("failed to encode mutation: %v", err)
+				}
+			}
+			if mu.DeleteJson, err = json.Marshal(plan.Delete); err != nil {
+				return fmt.Errorf("failed to encode mutation: %v", err)
+			}
+			_, err = txn.Mutate(ctx, mu)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("merge failed after %d attempts: %v", attempts, err)
+		}
+		invalidateCaches()
+
+		out, err := json.Marshal(map[string]interface{}{
+			"canonical":       canonical,
+			"merged":          duplicates,
+			"copied_values":   plan.CopiedValues,
+			"copied_edges":    plan.CopiedEdges,
+			"rewritten_edges": plan.RewrittenEdges,
+			"kept":            plan.Kept,
+			"attempts":        attempts,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var mergeTestPredicates = []predicateSchema{
+	{Predicate: "name", Type: "string"},
+	{Predicate: "alias", Type: "string", List: true},
+	{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+	{Predicate: "owner", Type: "uid"},
+}
+
+func TestMergePredicates(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string"},
+		{Predicate: "secret", Type: "password"},
+		{Predicate: "dgraph.type", Type: "string", List: true},
+	}}
+	preds, err := mergePredicates(schema)
+	if err != nil {
+		t.Fatalf("mergePredicates() failed: %v", err)
+	}
+	if want := schema.Predicates[:1]; !reflect.DeepEqual(preds, want) {
+		t.Errorf("mergePredicates() = %v, want %v", preds, want)
+	}
+}
+
+func TestBuildMergeQuery(t *testing.T) {
+	got := buildMergeQuery("0x1", []string{"0x2", "0x3"}, mergeTestPredicates)
+	want := `{
+	nodes(func: uid(0x1, 0x2, 0x3)) {
+		uid
+		dgraph.type
+		<name>
+		<alias>
+		<friend> { uid }
+		in2: ~<friend> { uid }
+		<owner> { uid }
+	}
+	in3(func: has(<owner>)) @filter(uid_in(<owner>, 0x2) OR uid_in(<owner>, 0x3)) {
+		uid
+		<owner> { uid }
+	}
+}`
+	if got != want {
+		t.Errorf("buildMergeQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPlanMerge(t *testing.T) {
+	data := []byte(`{
+		"nodes": [
+			{"uid": "0x1", "dgraph.type": ["Person"], "name": "Alice"},
+			{"uid": "0x2", "dgraph.type": ["Person", "Author"], "name": "alice", "alias": ["Al"],
+			 "friend": [{"uid": "0x9"}, {"uid": "0x1"}], "in2": [{"uid": "0x7"}, {"uid": "0x3"}],
+			 "owner": {"uid": "0x8"}}
+		],
+		"in3": [{"uid": "0x5", "owner": {"uid": "0x2"}}]
+	}`)
+	plan, err := planMerge(data, "0x1", []string{"0x2"}, mergeTestPredicates)
+	if err != nil {
+		t.Fatalf("planMerge() failed: %v", err)
+	}
+
+	got, _ := json.Marshal(plan.Set)
+	want := `[{"alias":["Al"],"dgraph.type":["Author"],"friend":[{"uid":"0x9"}],"owner":{"uid":"0x8"},"uid":"0x1"},` +
+		`{"friend":{"uid":"0x1"},"uid":"0x7"},{"friend":{"uid":"0x1"},"uid":"0x3"},{"owner":{"uid":"0x1"},"uid":"0x5"}]`
+	if string(got) != want {
+		t.Errorf("planMerge() set =\n%s\nwant\n%s", got, want)
+	}
+
+	got, _ = json.Marshal(plan.Delete)
+	want = `[{"alias":null,"dgraph.type":null,"friend":null,"name":null,"owner":null,"uid":"0x2"},` +
+		`{"friend":{"uid":"0x2"},"uid":"0x7"},{"friend":{"uid":"0x2"},"uid":"0x3"},{"owner":{"uid":"0x2"},"uid":"0x5"}]`
+	if string(got) != want {
+		t.Errorf("planMerge() delete =\n%s\nwant\n%s", got, want)
+	}
+
+	if plan.CopiedValues != 1 || plan.CopiedEdges != 2 || plan.RewrittenEdges != 3 {
+		t.Errorf("planMerge() counts = %d values, %d edges, %d rewritten; want 1, 2, 3", plan.CopiedValues, plan.CopiedEdges, plan.RewrittenEdges)
+	}
+	if !reflect.DeepEqual(plan.Kept, []string{"name"}) {
+		t.Errorf("planMerge() kept = %v, want [name]", plan.Kept)
+	}
+}
+
+func TestPlanMergeCanonicalEdges(t *testing.T) {
+	// The canonical node points at the duplicate through a reverse and a
+	// scanned predicate
+	data := []byte(`{
+		"nodes": [
+			{"uid": "0x1", "name": "Alice", "friend": [{"uid": "0x2"}], "owner": {"uid": "0x2"}},
+			{"uid": "0x2", "name": "alice", "in2": [{"uid": "0x1"}]}
+		],
+		"in3": [{"uid": "0x1", "owner": {"uid": "0x2"}}]
+	}`)
+	plan, err := planMerge(data, "0x1", []string{"0x2"}, mergeTestPredicates)
+	if err != nil {
+		t.Fatalf("planMerge() failed: %v", err)
+	}
+
+	if len(plan.Set) != 0 {
+		t.Errorf("planMerge() set = %v, want nothing", plan.Set)
+	}
+	got, _ := json.Marshal(plan.Delete)
+	want := `[{"name":null,"uid":"0x2"},{"friend":{"uid":"0x2"},"uid":"0x1"},{"owner":{"uid":"0x2"},"uid":"0x1"}]`
+	if string(got) != want {
+		t.Errorf("planMerge() delete =\n%s\nwant\n%s", got, want)
+	}
+	if plan.RewrittenEdges != 0 {
+		t.Errorf("planMerge() rewritten = %d, want 0", plan.RewrittenEdges)
+	}
+}
+
+func TestPlanMergeMissingNode(t *testing.T) {
+	data := []byte(`{"nodes": [{"uid": "0x1", "name": "Alice"}, {"uid": "0x2"}]}`)
+	if _, err := planMerge(data, "0x1", []string{"0x2"}, mergeTestPredicates); err == nil || !strings.Contains(err.Error(), "node 0x2 has no predicates") {
+		t.Errorf("planMerge() error = %v, want a missing node error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Upper bounds of the tool latency histogram buckets, in seconds
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// toolStats holds the counters and latency histogram of a single tool
+type toolStats struct {
+	calls   uint64
+	errors  uint64
+	buckets []uint64
+	sum     float64
+}
+
+// toolMetrics records tool invocations and exposes them in the Prometheus
+// text exposition format
+type toolMetrics struct {
+	mu    sync.Mutex
+	tools map[string]*toolStats
+}
+
+// Create an empty metrics registry
+func newToolMetrics() *toolMetrics {
+	return &toolMetrics{tools: make(map[string]*toolStats)}
+}
+
+// Record the outcome and latency of a tool call
+func (m *toolMetrics) observe(tool string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.tools[tool]
+	if !ok {
+		stats = &toolStats{buckets: make([]uint64, len(latencyBuckets))}
+		m.tools[tool] = stats
+	}
+
+	seconds := duration.Seconds()
+	stats.calls++
+	if failed {
+		stats.errors++
+	}
+	stats.sum += seconds
+	for i, bound := range latencyBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// Tool handler middleware recording every call
+func (m *toolMetrics) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		m.observe(request.Params.Name, time.Since(start), err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+// Serve the metrics in the Prometheus text exposition format
+func (m *toolMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	names := make([]string, 0, len(m.tools))
+	for name := range m.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP mcp_tool_calls_total Total number of tool invocations.")
+	fmt.Fprintln(w, "# TYPE mcp_tool_calls_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "mcp_tool_calls_total{tool=%q} %d\n", name, m.tools[name].calls)
+	}
+
+	fmt.Fprintln(w, "# HELP mcp_tool_errors_total Total number of failed tool invocations.")
+	fmt.Fprintln(w, "# TYPE mcp_tool_errors_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "mcp_tool_errors_total{tool=%q} %d\n", name, m.tools[name].errors)
+	}
+
+	fmt.Fprintln(w, "# HELP mcp_tool_duration_seconds Tool invocation latency.")
+	fmt.Fprintln(w, "# TYPE mcp_tool_duration_seconds histogram")
+	for _, name := range names {
+		stats := m.tools[name]
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(w, "mcp_tool_duration_seconds_bucket{tool=%q,le=%q} %d\n", name, strconv.FormatFloat(bound, 'g', -1, 64), stats.buckets[i])
+		}
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_bucket{tool=%q,le=\"+Inf\"} %d\n", name, stats.calls)
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_sum{tool=%q} %g\n", name, stats.sum)
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_count{tool=%q} %d\n", name, stats.calls)
+	}
+}
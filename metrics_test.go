@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestToolMetrics(t *testing.T) {
+	metrics := newToolMetrics()
+
+	ok := metrics.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	fail := metrics.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, errors.New("boom")
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "dgraph_query"
+	ok(context.Background(), request)
+	ok(context.Background(), request)
+	fail(context.Background(), request)
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`mcp_tool_calls_total{tool="dgraph_query"} 3`,
+		`mcp_tool_errors_total{tool="dgraph_query"} 1`,
+		`mcp_tool_duration_seconds_bucket{tool="dgraph_query",le="+Inf"} 3`,
+		`mcp_tool_duration_seconds_bucket{tool="dgraph_query",le="10"} 3`,
+		`mcp_tool_duration_seconds_count{tool="dgraph_query"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
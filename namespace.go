@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// namespaceOption adds the namespace parameter to a tool definition
+var namespaceOption = mcp.WithNumber("namespace",
+	mcp.Description("The namespace (tenant) to operate in (default: 0). Requires ACL login"),
+)
+
+// namespaceContextKey is the context key of the namespace a tool call targets
+type namespaceContextKey struct{}
+
+// namespaceTarget is the namespace a tool call targets and the pool to get
+// its client from
+type namespaceTarget struct {
+	pool      *namespaceClientPool
+	namespace uint64
+}
+
+// Read the namespace argument of a tool call
+func namespaceArgument(request mcp.CallToolRequest) (uint64, error) {
+	ns, err := intArgument(request, "namespace", defaultNamespace)
+	if err != nil {
+		return 0, err
+	}
+	if ns < 0 {
+		return 0, fmt.Errorf("namespace must not be negative")
+	}
+	return uint64(ns), nil
+}
+
+// Tool handler middleware recording the namespace a call targets. Without a
+// pool, i.e. without ACL credentials, only the default namespace can be used.
+func namespaceMiddleware(pool *namespaceClientPool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ns, err := namespaceArgument(request)
+			if err != nil {
+				return nil, err
+			}
+			if pool == nil && ns != defaultNamespace {
+				return nil, fmt.Errorf("namespace %d requires ACL login; set DGRAPH_USER and DGRAPH_PASSWORD", ns)
+			}
+			return next(context.WithValue(ctx, namespaceContextKey{}, namespaceTarget{pool, ns}), request)
+		}
+	}
+}
+
+// Get the logged-in client for the namespace the current tool call targets.
+// Logging in happens lazily here, so tools that don't talk to Dgraph never
+// wait for a login. Without ACL the shared client is returned, or the
+// fallback client the handler was created with until one is set.
+func clientFromContext(ctx context.Context, fallback *dgo.Dgraph) (*dgo.Dgraph, error) {
+	target, ok := ctx.Value(namespaceContextKey{}).(namespaceTarget)
+	if !ok || target.pool == nil {
+		if client := getClient(); client != nil {
+			return client, nil
+		}
+		return fallback, nil
+	}
+	return target.pool.get(ctx, target.namespace)
+}
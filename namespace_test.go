@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Run a request through the namespace middleware, returning the client the
+// handler resolved
+func resolveClient(pool *namespaceClientPool, fallback *dgo.Dgraph, args map[string]interface{}) (*dgo.Dgraph, error) {
+	var resolved *dgo.Dgraph
+	handler := namespaceMiddleware(pool)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, fallback)
+		resolved = client
+		return nil, err
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Arguments = args
+	_, err := handler(context.Background(), request)
+	return resolved, err
+}
+
+func TestNamespaceMiddlewareWithoutPool(t *testing.T) {
+	fallback := dgo.NewDgraphClient()
+
+	client, err := resolveClient(nil, fallback, map[string]interface{}{})
+	if err != nil || client != fallback {
+		t.Errorf("default namespace = %p, %v, want the fallback client", client, err)
+	}
+	if _, err := resolveClient(nil, fallback, map[string]interface{}{"namespace": float64(2)}); err == nil {
+		t.Errorf("namespace 2 without a pool succeeded, want error")
+	}
+}
+
+func TestNamespaceArgument(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	pool := newNamespaceClientPool(nil, time.Hour, logins.login)
+	fallback := dgo.NewDgraphClient()
+
+	tests := []struct {
+		name    string
+		value   interface{}
+		wantErr bool
+	}{
+		{"negative", float64(-1), true},
+		{"fractional", 1.5, true},
+		{"string", "2", true},
+	}
+	for _, tt := range tests {
+		if _, err := resolveClient(pool, fallback, map[string]interface{}{"namespace": tt.value}); (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+	if len(logins.counts) != 0 {
+		t.Errorf("invalid namespaces logged in: %v", logins.counts)
+	}
+}
+
+func TestNamespaceMiddlewareWithPool(t *testing.T) {
+	logins := &countingLogin{counts: map[uint64]int{}}
+	pool := newNamespaceClientPool(nil, time.Hour, logins.login)
+	fallback := dgo.NewDgraphClient()
+
+	first, err := resolveClient(pool, fallback, map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("default namespace failed: %v", err)
+	}
+	if first == fallback {
+		t.Errorf("default namespace resolved to the fallback client")
+	}
+	again, _ := resolveClient(pool, fallback, map[string]interface{}{"namespace": float64(0)})
+	if again != first {
+		t.Errorf("default namespace did not reuse the pooled client")
+	}
+
+	tenant, err := resolveClient(pool, fallback, map[string]interface{}{"namespace": float64(3)})
+	if err != nil {
+		t.Fatalf("namespace 3 failed: %v", err)
+	}
+	if tenant == first || tenant == fallback {
+		t.Errorf("namespace 3 resolved to the client of another namespace")
+	}
+	if again, _ := resolveClient(pool, fallback, map[string]interface{}{"namespace": float64(3)}); again != tenant {
+		t.Errorf("namespace 3 did not reuse the pooled client")
+	}
+	if logins.counts[defaultNamespace] != 1 || logins.counts[3] != 1 {
+		t.Errorf("logins = %v, want one per namespace", logins.counts)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// nquadError locates a syntax error in N-Quads
+type nquadError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *nquadError) Error() string {
+	return fmt.Sprintf("invalid N-Quad at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// nquadScanner reads the terms of a single N-Quad line
+type nquadScanner struct {
+	line string
+	pos  int
+}
+
+// Skip spaces and tabs
+func (s *nquadScanner) skipSpace() {
+	for s.pos < len(s.line) && (s.line[s.pos] == ' ' || s.line[s.pos] == '\t' || s.line[s.pos] == '\r') {
+		s.pos++
+	}
+}
+
+// Build an error at the current position
+func (s *nquadScanner) errorf(format string, args ...interface{}) error {
+	return &nquadError{Column: s.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Read an IRI such as <name> or <0x1>
+func (s *nquadScanner) iri() error {
+	end := strings.IndexByte(s.line[s.pos:], '>')
+	if end < 0 {
+		return s.errorf("unterminated IRI, missing >")
+	}
+	if end == 1 {
+		return s.errorf("empty IRI <>")
+	}
+	if i := strings.IndexAny(s.line[s.pos+1:s.pos+end], " \t\"<"); i >= 0 {
+		s.pos += 1 + i
+		return s.errorf("invalid character %q in IRI", s.line[s.pos])
+	}
+	s.pos += end + 1
+	return nil
+}
+
+// Read a blank node such as _:alice
+func (s *nquadScanner) blankNode() error {
+	start := s.pos
+	s.pos += 2
+	for s.pos < len(s.line) && isBlankNodeChar(s.line[s.pos]) {
+		s.pos++
+	}
+	if s.pos == start+2 {
+		return s.errorf("blank node _: has no name")
+	}
+	return nil
+}
+
+// Read a variable reference such as uid(v) or val(v)
+func (s *nquadScanner) varRef(fn string) error {
+	s.pos += len(fn) + 1
+	end := strings.IndexByte(s.line[s.pos:], ')')
+	if end < 0 {
+		return s.errorf("unterminated %s(, missing )", fn)
+	}
+	if strings.TrimSpace(s.line[s.pos:s.pos+end]) == "" {
+		return s.errorf("%s() has no variable", fn)
+	}
+	s.pos += end + 1
+	return nil
+}
+
+// Read a string literal with an optional language tag or type
+func (s *nquadScanner) literal() error {
+	end, err := skipString(s.line, s.pos)
+	if err != nil {
+		return s.errorf("unterminated string literal, missing closing quote")
+	}
+	s.pos = end + 1
+	switch {
+	case strings.HasPrefix(s.line[s.pos:], "@"):
+		s.pos++
+		start := s.pos
+		for s.pos < len(s.line) && (isBlankNodeChar(s.line[s.pos]) && s.line[s.pos] != '.') {
+			s.pos++
+		}
+		if s.pos == start {
+			return s.errorf("empty language tag")
+		}
+	case strings.HasPrefix(s.line[s.pos:], "^^"):
+		s.pos += 2
+		if s.pos >= len(s.line) || s.line[s.pos] != '<' {
+			return s.errorf("expected a type IRI such as <xs:int> after ^^")
+		}
+		return s.iri()
+	}
+	return nil
+}
+
+// Read a term of the given role: subject, predicate or object
+func (s *nquadScanner) term(role string, wildcard bool) error {
+	s.skipSpace()
+	if s.pos >= len(s.line) {
+		return s.errorf("missing %s", role)
+	}
+	rest := s.line[s.pos:]
+	switch {
+	case rest[0] == '<':
+		return s.iri()
+	case rest[0] == '*' && wildcard && role != "subject":
+		s.pos++
+		return nil
+	case strings.HasPrefix(rest, "_:") && role != "predicate":
+		return s.blankNode()
+	case strings.HasPrefix(rest, "uid(") && role != "predicate":
+		return s.varRef("uid")
+	case strings.HasPrefix(rest, "val(") && role == "object":
+		return s.varRef("val")
+	case rest[0] == '"' && role == "object":
+		return s.literal()
+	case rest[0] == '.' && role == "object":
+		return s.errorf("missing object before .")
+	}
+	switch role {
+	case "subject":
+		return s.errorf("subject must be <uid>, _:blank or uid(var)")
+	case "predicate":
+		if wildcard {
+			return s.errorf("predicate must be <name> or *")
+		}
+		return s.errorf("predicate must be <name>")
+	}
+	return s.errorf("object must be <uid>, _:blank, uid(var), val(var) or a \"literal\"")
+}
+
+// Check the syntax of N-Quads line by line, reporting the line and column
+// of the first error. Wildcards (*) are allowed as predicate and object in
+// deletions. Facets and graph labels are accepted but not checked in depth.
+func validateNQuads(nquads string, wildcard bool) error {
+	for n, line := range strings.Split(nquads, "\n") {
+		if err := validateNQuadLine(line, wildcard); err != nil {
+			err.(*nquadError).Line = n + 1
+			return err
+		}
+	}
+	return nil
+}
+
+// Check the syntax of a single N-Quad line
+func validateNQuadLine(line string, wildcard bool) error {
+	s := &nquadScanner{line: line}
+	s.skipSpace()
+	if s.pos == len(line) || line[s.pos] == '#' {
+		return nil
+	}
+
+	for _, role := range []string{"subject", "predicate", "object"} {
+		if err := s.term(role, wildcard); err != nil {
+			return err
+		}
+	}
+
+	// Optional graph label and facets
+	s.skipSpace()
+	if s.pos < len(line) && line[s.pos] == '<' {
+		if err := s.iri(); err != nil {
+			return err
+		}
+		s.skipSpace()
+	}
+	if s.pos < len(line) && line[s.pos] == '(' {
+		end, err := matchingDelim(line, s.pos)
+		if err != nil {
+			return s.errorf("unterminated facets, missing )")
+		}
+		s.pos = end + 1
+		s.skipSpace()
+	}
+
+	if s.pos >= len(line) || line[s.pos] != '.' {
+		if s.pos >= len(line) {
+			return s.errorf("missing terminating .")
+		}
+		return s.errorf("expected terminating . but found %q", line[s.pos])
+	}
+	s.pos++
+	s.skipSpace()
+	if s.pos < len(line) && line[s.pos] != '#' {
+		return s.errorf("unexpected %q after terminating .; write one N-Quad per line", line[s.pos])
+	}
+	return nil
+}
+
+// Create handler for the N-Quad validation tool
+func createValidateNQuadsHandler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		nquads, ok := request.Params.Arguments["nquads"].(string)
+		if !ok {
+			return nil, fmt.Errorf("nquads must be a string")
+		}
+
+		deletion, err := boolArgument(request, "delete", false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validateNQuads(nquads, deletion); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("N-Quads are valid"), nil
+	}
+}
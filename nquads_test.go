@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateNQuads(t *testing.T) {
+	valid := `# people
+_:alice <name> "Alice" .
+_:alice <name> "Alicia"@es .
+_:alice <age> "30"^^<xs:int> .
+_:alice <friend> _:bob (since=2006-01-02T15:04:05, close=true) .
+<0x1> <friend> <0x2> .
+uid(v) <score> val(s) .
+
+_:bob <bio> "says \"hi\" . bye" . # trailing comment`
+	if err := validateNQuads(valid, false); err != nil {
+		t.Errorf("validateNQuads(valid) = %v", err)
+	}
+	if err := validateNQuads("<0x1> <name> * .\n<0x1> * * .", true); err != nil {
+		t.Errorf("validateNQuads with wildcards = %v", err)
+	}
+}
+
+func TestValidateNQuadsErrors(t *testing.T) {
+	tests := []struct {
+		nquads   string
+		wildcard bool
+		line     int
+		column   int
+		msg      string
+	}{
+		{`_:a <name> "Alice"`, false, 1, 19, "missing terminating ."},
+		{"_:a <name> \"A\" .\n_:b <na me> \"B\" .", false, 2, 8, "invalid character"},
+		{"_:a <name> \"A\" .\n_:b <name \"B\" .", false, 2, 5, "unterminated IRI"},
+		{`_:a <name> "Alice .`, false, 1, 12, "unterminated string"},
+		{`_:a <name> .`, false, 1, 12, "missing object"},
+		{`"a" <name> "b" .`, false, 1, 1, "subject must be"},
+		{`_:a name "b" .`, false, 1, 5, "predicate must be <name>"},
+		{`_:a <name> "b" . _:b <name> "c" .`, false, 1, 18, "one N-Quad per line"},
+		{`<0x1> <name> * .`, false, 1, 14, "object must be"},
+		{`_: <name> "b" .`, false, 1, 3, "has no name"},
+		{`_:a <age> "1"^^xs:int .`, false, 1, 16, "type IRI"},
+	}
+	for _, tt := range tests {
+		err := validateNQuads(tt.nquads, tt.wildcard)
+		e, ok := err.(*nquadError)
+		if !ok {
+			t.Errorf("validateNQuads(%q) = %v, want nquadError", tt.nquads, err)
+			continue
+		}
+		if e.Line != tt.line || e.Column != tt.column || !strings.Contains(e.Msg, tt.msg) {
+			t.Errorf("validateNQuads(%q) = %v, want line %d, column %d, %q", tt.nquads, err, tt.line, tt.column, tt.msg)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default pagination settings
+const (
+	defaultPage     = 1
+	defaultPageSize = 10
+)
+
+// pageInfo describes where a page sits within the full result set. Pages
+// fetched by cursor have no page number.
+type pageInfo struct {
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	TotalCount int    `json:"total_count"`
+	TotalPages int    `json:"total_pages"`
+	After      string `json:"after,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Compute pagination metadata from the total number of matches
+func newPageInfo(page, pageSize, totalCount int) pageInfo {
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = (totalCount + pageSize - 1) / pageSize
+	}
+
+	return pageInfo{
+		Page:       page,
+		PageSize:   pageSize,
+		TotalCount: totalCount,
+		TotalPages: totalPages,
+	}
+}
+
+// Build a query with a count block for the total, a block for the requested
+// page and a block listing the page's uids for the next cursor. With after
+// the page starts after that uid instead of at an offset. The filter
+// applies to both the total and the page.
+func buildPaginatedQuery(rootFunc, fields string, page, pageSize int, after string, filter compiledFilter) string {
+	position := fmt.Sprintf("offset: %d", (page-1)*pageSize)
+	if after != "" {
+		position = "after: " + after
+	}
+
+	directive := filter.directive()
+	return filter.withHeader(fmt.Sprintf(`{
+	total(func: %s)%s {
+		count(uid)
+	}
+	page_uids as page(func: %s, first: %d, %s)%s {
+		%s
+	}
+	cursor(func: uid(page_uids)) {
+		uid
+	}
+}`, rootFunc, directive, rootFunc, pageSize, position, directive, fields))
+}
+
+// Return the cursor continuing after a page of nodes in uid order: the last
+// uid of a full page, or "" when the page is the last one
+func nextCursor(uids []string, pageSize int) string {
+	if pageSize < 1 || len(uids) < pageSize {
+		return ""
+	}
+	return uids[len(uids)-1]
+}
+
+// uidNode is a result node of which only the uid is read
+type uidNode struct {
+	UID string `json:"uid"`
+}
+
+// Return the uids of a list of result nodes
+func resultUIDs(nodes []uidNode) []string {
+	uids := make([]string, len(nodes))
+	for i, n := range nodes {
+		uids[i] = n.UID
+	}
+	return uids
+}
+
+// Create handler for the paginated query tool
+func createPaginatedQueryHandler(client *dgo.Dgraph, limits queryLimits) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		rootFunc, ok := request.Params.Arguments["func"].(string)
+		if !ok {
+			return nil, fmt.Errorf("func must be a string")
+		}
+
+		fields, ok := request.Params.Arguments["fields"].(string)
+		if !ok {
+			return nil, fmt.Errorf("fields must be a string")
+		}
+
+		page, err := intArgument(request, "page", defaultPage)
+		if err != nil {
+			return nil, err
+		}
+		if page < 1 {
+			return nil, fmt.Errorf("page must be at least 1")
+		}
+
+		pageSize, err := intArgument(request, "page_size", defaultPageSize)
+		if err != nil {
+			return nil, err
+		}
+		if pageSize < 1 {
+			return nil, fmt.Errorf("page_size must be at least 1")
+		}
+
+		// A cursor continues after the last uid of the previous page
+		var after string
+		if request.Params.Arguments["after"] != nil {
+			if request.Params.Arguments["page"] != nil {
+				return nil, fmt.Errorf("page cannot be combined with after")
+			}
+			if after, err = uidArgument(request, "after"); err != nil {
+				return nil, err
+			}
+		}
+
+		filter, err := filterArgument(request, "filter")
+		if err != nil {
+			return nil, err
+		}
+
+		query := buildPaginatedQuery(rootFunc, fields, page, pageSize, after, filter)
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.QueryWithVars(ctx, query, filter.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		var result struct {
+			Total []struct {
+				Count int `json:"count"`
+			} `json:"total"`
+			Page   json.RawMessage `json:"page"`
+			Cursor []uidNode       `json:"cursor"`
+		}
+		if err := json.Unmarshal(resp.Json, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+
+		totalCount := 0
+		if len(result.Total) > 0 {
+			totalCount = result.Total[0].Count
+		}
+
+		results := result.Page
+		if results == nil {
+			results = json.RawMessage("[]")
+		}
+
+		info := newPageInfo(page, pageSize, totalCount)
+		if after != "" {
+			info.Page = 0
+			info.After = after
+		}
+		info.NextCursor = nextCursor(resultUIDs(result.Cursor), pageSize)
+
+		out, err := json.Marshal(struct {
+			Results    json.RawMessage `json:"results"`
+			Pagination pageInfo        `json:"pagination"`
+		}{results, info})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
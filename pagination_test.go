@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPageInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       int
+		pageSize   int
+		totalCount int
+		want       pageInfo
+	}{
+		{"empty result set", 1, 10, 0, pageInfo{Page: 1, PageSize: 10, TotalCount: 0, TotalPages: 0}},
+		{"exact multiple", 2, 10, 30, pageInfo{Page: 2, PageSize: 10, TotalCount: 30, TotalPages: 3}},
+		{"partial last page", 3, 10, 25, pageInfo{Page: 3, PageSize: 10, TotalCount: 25, TotalPages: 3}},
+		{"single item", 1, 10, 1, pageInfo{Page: 1, PageSize: 10, TotalCount: 1, TotalPages: 1}},
+		{"page beyond end", 5, 10, 25, pageInfo{Page: 5, PageSize: 10, TotalCount: 25, TotalPages: 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newPageInfo(tt.page, tt.pageSize, tt.totalCount); got != tt.want {
+				t.Errorf("newPageInfo(%d, %d, %d) = %+v, want %+v", tt.page, tt.pageSize, tt.totalCount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildPaginatedQuery(t *testing.T) {
+	query := buildPaginatedQuery("type(Movie)", "uid title", 3, 20, "", compiledFilter{})
+
+	for _, want := range []string{
+		"total(func: type(Movie))",
+		"count(uid)",
+		"page(func: type(Movie), first: 20, offset: 40)",
+		"uid title",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+}
+
+func TestBuildPaginatedQueryAfter(t *testing.T) {
+	query := buildPaginatedQuery("type(Movie)", "title", 1, 20, "0x2a", compiledFilter{})
+
+	for _, want := range []string{
+		"page_uids as page(func: type(Movie), first: 20, after: 0x2a)",
+		"cursor(func: uid(page_uids))",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query missing %q:\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, "offset") {
+		t.Errorf("cursor query uses an offset:\n%s", query)
+	}
+	if _, err := parseQueryBlocks(query); err != nil {
+		t.Errorf("parseQueryBlocks() error = %v", err)
+	}
+}
+
+func TestNextCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		uids     []string
+		pageSize int
+		want     string
+	}{
+		{"full page", []string{"0x1", "0x5", "0x9"}, 3, "0x9"},
+		{"last page", []string{"0x1", "0x5"}, 3, ""},
+		{"empty page", nil, 3, ""},
+		{"no page size", []string{"0x1"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextCursor(tt.uids, tt.pageSize); got != tt.want {
+				t.Errorf("nextCursor(%v, %d) = %q, want %q", tt.uids, tt.pageSize, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// alphaHealth is the result of pinging one Alpha
+type alphaHealth struct {
+	Host      string  `json:"host"`
+	Status    string  `json:"status"`
+	Version   string  `json:"version,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Ping an Alpha, measuring the round trip of a version check
+func pingAlpha(ctx context.Context, host string, conn api.DgraphClient) alphaHealth {
+	start := time.Now()
+	version, err := conn.CheckVersion(ctx, &api.Check{})
+	latency := time.Since(start)
+
+	health := alphaHealth{Host: host, Status: "ok", LatencyMs: float64(latency.Microseconds()) / 1000}
+	if err != nil {
+		health.Status, health.Error = "unreachable", err.Error()
+	} else {
+		health.Version = version.Tag
+	}
+	return health
+}
+
+// Create handler for the ping tool. Every Alpha is pinged concurrently, so
+// an unreachable one doesn't delay the others. The cluster is ok when every
+// Alpha answers and degraded when only some do; the ping fails when none
+// does.
+func createPingHandler(alphas []alphaConn) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		health := make([]alphaHealth, len(alphas))
+		var wg sync.WaitGroup
+		for i, alpha := range alphas {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				health[i] = pingAlpha(ctx, alpha.host, alpha.client)
+			}()
+		}
+		wg.Wait()
+
+		var version string
+		var failed []string
+		for _, h := range health {
+			if h.Error != "" {
+				failed = append(failed, fmt.Sprintf("%s after %vms: %s", h.Host, h.LatencyMs, h.Error))
+			} else if version == "" {
+				version = h.Version
+			}
+		}
+		if len(failed) == len(health) {
+			return nil, fmt.Errorf("ping failed: %s", strings.Join(failed, "; "))
+		}
+
+		status := "ok"
+		if len(failed) > 0 {
+			status = "degraded"
+		}
+		out, err := json.Marshal(struct {
+			Status  string        `json:"status"`
+			Version string        `json:"version"`
+			Alphas  []alphaHealth `json:"alphas"`
+		}{status, version, health})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
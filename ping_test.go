@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+)
+
+// versionClient answers version checks with a fixed version or error
+type versionClient struct {
+	api.DgraphClient
+	tag string
+	err error
+}
+
+func (c versionClient) CheckVersion(ctx context.Context, in *api.Check, opts ...grpc.CallOption) (*api.Version, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &api.Version{Tag: c.tag}, nil
+}
+
+func TestPingHandler(t *testing.T) {
+	healthy := alphaConn{host: "alpha1:9080", client: versionClient{tag: "v21.03.0"}}
+	down := alphaConn{host: "alpha2:9080", client: versionClient{err: errors.New("connection refused")}}
+
+	tests := []struct {
+		name     string
+		alphas   []alphaConn
+		status   string
+		statuses []string
+	}{
+		{"single", []alphaConn{healthy}, "ok", []string{"ok"}},
+		{"all healthy", []alphaConn{healthy, healthy}, "ok", []string{"ok", "ok"}},
+		{"one down", []alphaConn{down, healthy}, "degraded", []string{"unreachable", "ok"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := createPingHandler(tt.alphas)(context.Background(), mcp.CallToolRequest{})
+			if err != nil {
+				t.Fatalf("ping failed: %v", err)
+			}
+			var got struct {
+				Status  string        `json:"status"`
+				Version string        `json:"version"`
+				Alphas  []alphaHealth `json:"alphas"`
+			}
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &got); err != nil {
+				t.Fatalf("failed to parse result: %v", err)
+			}
+			if got.Status != tt.status || got.Version != "v21.03.0" || len(got.Alphas) != len(tt.statuses) {
+				t.Fatalf("ping = %+v, want status %s, version v21.03.0 and %d Alphas", got, tt.status, len(tt.statuses))
+			}
+			for i, h := range got.Alphas {
+				if h.Host != tt.alphas[i].host || h.Status != tt.statuses[i] {
+					t.Errorf("alpha %d = %+v, want %s %s", i, h, tt.alphas[i].host, tt.statuses[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPingHandlerAllDown(t *testing.T) {
+	alphas := []alphaConn{
+		{host: "alpha1:9080", client: versionClient{err: errors.New("connection refused")}},
+		{host: "alpha2:9080", client: versionClient{err: errors.New("deadline exceeded")}},
+	}
+	_, err := createPingHandler(alphas)(context.Background(), mcp.CallToolRequest{})
+	if err == nil || !strings.Contains(err.Error(), "alpha1:9080") || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("ping error = %v, want one naming every Alpha", err)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// predicatePolicy restricts which predicates tools may read or write, from
+// the comma-separated DGRAPH_ALLOWED_PREDICATES and DGRAPH_DENIED_PREDICATES
+// lists. An empty allowed list allows every predicate that isn't denied.
+type predicatePolicy struct {
+	allowed map[string]bool
+	denied  map[string]bool
+}
+
+// predicateAccess is the policy applied to every query and mutation,
+// configured at startup
+var predicateAccess predicatePolicy
+
+// Create a predicate policy from the allowed and denied predicate lists.
+// dgraph.type is allowed along with an allowed list unless it is denied,
+// since type() and typed mutations need it.
+func newPredicatePolicy(allowed, denied string) predicatePolicy {
+	p := predicatePolicy{
+		allowed: parseToolList(allowed),
+		denied:  parseToolList(denied),
+	}
+	if len(p.allowed) > 0 {
+		p.allowed["dgraph.type"] = true
+	}
+	return p
+}
+
+// Check whether the policy restricts anything
+func (p predicatePolicy) active() bool {
+	return len(p.allowed) > 0 || len(p.denied) > 0
+}
+
+// Check a single predicate, which may have a ~ reverse prefix or an @lang
+// suffix
+func (p predicatePolicy) check(predicate string) error {
+	name := strings.TrimPrefix(predicate, "~")
+	name, _, _ = strings.Cut(name, "@")
+	if p.denied[name] {
+		return fmt.Errorf("access to predicate %q is denied by DGRAPH_DENIED_PREDICATES", name)
+	}
+	if len(p.allowed) > 0 && !p.allowed[name] {
+		return fmt.Errorf("access to predicate %q is denied: it is not in DGRAPH_ALLOWED_PREDICATES", name)
+	}
+	return nil
+}
+
+// Check every predicate a DQL query reads. expand() is rejected while the
+// policy is active, since it reads predicates the query doesn't name.
+func (p predicatePolicy) checkQuery(query string) error {
+	if !p.active() {
+		return nil
+	}
+	predicates, expands, err := queryPredicates(query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %v", err)
+	}
+	for _, name := range predicates {
+		if err := p.check(name); err != nil {
+			return err
+		}
+	}
+	if expands {
+		return fmt.Errorf("expand() is not allowed while predicate access is restricted; list the predicates to read instead")
+	}
+	return nil
+}
+
+// Check every predicate N-Quads write or delete. A * predicate is rejected
+// while the policy is active, since it deletes every predicate of a node.
+func (p predicatePolicy) checkNQuads(nquads string) error {
+	if !p.active() {
+		return nil
+	}
+	for _, line := range strings.Split(nquads, "\n") {
+		s := &nquadScanner{line: line}
+		s.skipSpace()
+		if s.pos == len(line) || line[s.pos] == '#' || s.term("subject", false) != nil {
+			continue
+		}
+		s.skipSpace()
+		if strings.HasPrefix(line[s.pos:], "*") {
+			return fmt.Errorf("deleting * is not allowed while predicate access is restricted; delete the predicates one by one")
+		}
+		start := s.pos
+		if s.term("predicate", false) != nil {
+			continue
+		}
+		if err := p.check(strings.Trim(line[start:s.pos], "<>")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Check every predicate JSON mutation objects write, including those of
+// nested objects. Keys may carry an @lang suffix or a |facet.
+func (p predicatePolicy) checkJSON(value interface{}) error {
+	if !p.active() {
+		return nil
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			name, _, _ := strings.Cut(key, "|")
+			if name != "uid" {
+				if err := p.check(name); err != nil {
+					return err
+				}
+			}
+			if err := p.checkJSON(v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := p.checkJSON(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	// Keywords that may stand where a predicate does
+	queryKeywords = map[string]bool{"uid": true, "as": true, "and": true, "or": true, "not": true, "true": true, "false": true}
+	// Functions whose arguments are variables, types or facets rather
+	// than predicates
+	nonPredicateArgs = map[string]bool{"uid": true, "val": true, "len": true, "type": true, "math": true, "expand": true, "@facets": true}
+	// Functions that may appear in a selection next to predicates
+	selectionFunctions = map[string]bool{"count": true, "val": true, "min": true, "max": true, "sum": true, "avg": true, "expand": true, "math": true, "uid": true, "checkpwd": true}
+)
+
+// List the predicates a DQL query reads, in its selections, functions,
+// filters and ordering, and whether it uses expand(). Variables, aliases,
+// arguments and facets are skipped. schema queries read no predicates.
+func queryPredicates(query string) ([]string, bool, error) {
+	if strings.HasPrefix(strings.TrimSpace(query), "schema") {
+		return nil, false, nil
+	}
+	s, err := blankLiterals(query)
+	if err != nil {
+		return nil, false, err
+	}
+	// Skip a `query name($var: type)` header
+	if i := strings.IndexByte(s, '{'); i >= 0 {
+		s = s[i:]
+	}
+
+	var (
+		predicates []string
+		expands    bool
+		parens     []string // the function opening each open parenthesis
+		braces     int
+		last       string // the word before an opening parenthesis
+	)
+	skipped := func() bool {
+		for _, fn := range parens {
+			if nonPredicateArgs[fn] {
+				return true
+			}
+		}
+		return false
+	}
+	next := func(i int) (byte, string) {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+			i++
+		}
+		if i == len(s) {
+			return 0, ""
+		}
+		j := i
+		for j < len(s) && isWordChar(s[j]) {
+			j++
+		}
+		return s[i], s[i:j]
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '{':
+			braces++
+			last = ""
+		case c == '}':
+			braces--
+			last = ""
+		case c == '(':
+			if last == "expand" {
+				expands = true
+			}
+			parens = append(parens, last)
+			last = ""
+		case c == ')':
+			if len(parens) > 0 {
+				parens = parens[:len(parens)-1]
+			}
+			last = ""
+		case c == '$' || c == '@' || c >= '0' && c <= '9' || c == '-':
+			// Variables, directives and numbers
+			start := i
+			for i+1 < len(s) && isWordChar(s[i+1]) {
+				i++
+			}
+			last = ""
+			if c == '@' {
+				last = s[start : i+1]
+			}
+		case c == '<' && skipped():
+			// A comparison in math()
+		case c == '<' || c == '~' || isWordChar(c):
+			start := i
+			if c == '~' {
+				i++
+			}
+			if i < len(s) && s[i] == '<' {
+				end := strings.IndexByte(s[i:], '>')
+				if end < 0 {
+					return nil, false, fmt.Errorf("unterminated <predicate>")
+				}
+				i += end
+			} else {
+				for i+1 < len(s) && isWordChar(s[i+1]) {
+					i++
+				}
+			}
+			// A language suffix, e.g. name@en:fr
+			if i+1 < len(s) && s[i+1] == '@' {
+				i++
+				for i+1 < len(s) && (isWordChar(s[i+1]) || s[i+1] == ':' || s[i+1] == '-') {
+					i++
+				}
+			}
+			word := s[start : i+1]
+			name := strings.ReplaceAll(strings.ReplaceAll(word, "<", ""), ">", "")
+			last = name
+
+			after, following := next(i + 1)
+			switch {
+			case skipped(), queryKeywords[strings.ToLower(name)], following == "as":
+			case after == ':':
+				// An alias or argument name
+			case after == '(' && (len(parens) > 0 || braces <= 1 || selectionFunctions[name]):
+				// A function or a block
+			default:
+				predicates = append(predicates, name)
+			}
+		}
+	}
+	return predicates, expands, nil
+}
+
+// Check whether a byte may be part of a predicate or variable name
+func isWordChar(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
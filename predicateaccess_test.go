@@ -0,0 +1,116 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestQueryPredicates(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		want        []string
+		wantExpands bool
+	}{
+		{
+			name:  "selection and function",
+			query: `{ q(func: eq(name, "password_hash")) { uid name friend(first: 2) { ~friend name@en:. } } }`,
+			want:  []string{"name", "name", "friend", "~friend", "name@en:."},
+		},
+		{
+			name: "variables, aliases and filters",
+			query: `query q($email: string) {
+	v as var(func: has(<email>)) @filter(uid_in(owner, 0x1) AND NOT type(Person)) {
+		c as count(friend)
+	}
+	me(func: uid(v), orderdesc: val(c), first: $first) @cascade {
+		n: full_name
+		total: sum(val(c))
+		weight as math(c * 2)
+		friend @facets(close) @groupby(age) { count(uid) }
+	}
+}`,
+			want: []string{"email", "owner", "friend", "full_name", "friend", "age"},
+		},
+		{
+			name:        "expand",
+			query:       `{ q(func: type(Person)) { expand(_all_) { name } } }`,
+			want:        []string{"name"},
+			wantExpands: true,
+		},
+		{
+			name:  "comments and strings",
+			query: "{ q(func: has(title)) { # secret\n title } }",
+			want:  []string{"title", "title"},
+		},
+		{
+			name:  "schema",
+			query: `schema(pred: [secret]) { type }`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, expands, err := queryPredicates(tt.query)
+			if err != nil {
+				t.Fatalf("queryPredicates() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("queryPredicates() = %q, want %q", got, tt.want)
+			}
+			if expands != tt.wantExpands {
+				t.Errorf("queryPredicates() expands = %v, want %v", expands, tt.wantExpands)
+			}
+		})
+	}
+}
+
+func TestPredicatePolicy(t *testing.T) {
+	denied := newPredicatePolicy("", "password_hash, ssn")
+	allowed := newPredicatePolicy("name,friend", "")
+	open := newPredicatePolicy("", "")
+
+	tests := []struct {
+		name    string
+		policy  predicatePolicy
+		run     func(p predicatePolicy) error
+		wantErr string
+	}{
+		{"open query", open, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: has(ssn)) { expand(_all_) } }`) }, ""},
+		{"denied query", denied, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: has(name)) { password_hash } }`) }, `"password_hash" is denied`},
+		{"denied reverse", denied, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: uid(0x1)) { ~ssn { uid } } }`) }, `"ssn"`},
+		{"string value", denied, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: eq(name, "ssn")) { name } }`) }, ""},
+		{"expand", denied, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: uid(0x1)) { expand(_all_) } }`) }, "expand()"},
+		{"not allowed", allowed, func(p predicatePolicy) error { return p.checkQuery(`{ q(func: has(name)) { age } }`) }, `"age" is denied`},
+		{"allowed with type", allowed, func(p predicatePolicy) error {
+			return p.checkQuery(`{ q(func: type(Person)) { name dgraph.type friend { name@fr } } }`)
+		}, ""},
+		{"denied nquad", denied, func(p predicatePolicy) error {
+			return p.checkNQuads("<0x1> <name> \"Alice\" .\nuid(v) <ssn> \"123\" .")
+		}, `"ssn"`},
+		{"wildcard delete", denied, func(p predicatePolicy) error { return p.checkNQuads("<0x1> * * .") }, "deleting *"},
+		{"allowed nquads", allowed, func(p predicatePolicy) error {
+			return p.checkNQuads("_:a <name> \"A\"@en .\n_:a <friend> <0x1> (since=2020) .\n_:a <dgraph.type> \"Person\" .")
+		}, ""},
+		{"denied json", denied, func(p predicatePolicy) error {
+			return p.checkJSON([]interface{}{map[string]interface{}{"uid": "_:a", "friend": map[string]interface{}{"ssn": "1"}}})
+		}, `"ssn"`},
+		{"allowed json", allowed, func(p predicatePolicy) error {
+			return p.checkJSON(map[string]interface{}{"uid": "_:a", "name@en": "A", "friend|close": true})
+		}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.run(tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// previewTarget is a node touched by a mutation and the predicates it
+// changes, mapped to whether they are edges to other nodes
+type previewTarget struct {
+	Subject    string // a uid, or a blank node such as _:alice
+	Predicates map[string]bool
+}
+
+// valueChange is the value of a predicate before and after a mutation
+type valueChange struct {
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// nodePreview is the effect of a mutation on a node
+type nodePreview struct {
+	UID       string                 `json:"uid"`
+	BlankNode string                 `json:"blank_node,omitempty"`
+	Created   bool                   `json:"created"`
+	Changes   map[string]valueChange `json:"changes"`
+}
+
+// Collect the nodes and predicates touched by N-Quads, in order of first
+// appearance. Language-tagged literals touch the tagged predicate, e.g.
+// name@en. Subjects given as uid(var) can't be resolved without running
+// an upsert and are rejected.
+func collectPreviewTargets(targets []*previewTarget, nquads string, wildcard bool) ([]*previewTarget, error) {
+	for n, line := range strings.Split(nquads, "\n") {
+		s := &nquadScanner{line: line}
+		s.skipSpace()
+		if s.pos == len(line) || line[s.pos] == '#' {
+			continue
+		}
+
+		start := s.pos
+		if err := s.term("subject", wildcard); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		subject := line[start:s.pos]
+		switch {
+		case strings.HasPrefix(subject, "<"):
+			uid, err := normalizeUID(subject[1 : len(subject)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			subject = uid
+		case strings.HasPrefix(subject, "uid("):
+			return nil, fmt.Errorf("line %d: subject %s can't be previewed; use a uid or blank node", n+1, subject)
+		}
+
+		s.skipSpace()
+		start = s.pos
+		if err := s.term("predicate", wildcard); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		predicate := strings.Trim(line[start:s.pos], "<>")
+		if predicate != "*" {
+			if err := validateName("predicate", predicate); err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+		}
+
+		s.skipSpace()
+		start = s.pos
+		if err := s.term("object", wildcard); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		object := line[start:s.pos]
+		edge := strings.HasPrefix(object, "<") || strings.HasPrefix(object, "_:") || strings.HasPrefix(object, "uid(")
+		if strings.HasPrefix(object, `"`) {
+			if end, err := skipString(line, start); err == nil && end+1 < s.pos && line[end+1] == '@' {
+				predicate += line[end+1 : s.pos]
+			}
+		}
+
+		var target *previewTarget
+		for _, t := range targets {
+			if t.Subject == subject {
+				target = t
+			}
+		}
+		if target == nil {
+			target = &previewTarget{Subject: subject, Predicates: map[string]bool{}}
+			targets = append(targets, target)
+		}
+		target.Predicates[predicate] = target.Predicates[predicate] || edge
+	}
+	return targets, nil
+}
+
+// Build a query fetching the touched predicates of the given nodes, one
+// block per node named after its index. A * predicate fetches all of them.
+func buildPreviewQuery(targets []*previewTarget, uids []string) string {
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, t := range targets {
+		if uids[i] == "" {
+			continue
+		}
+		fields := []string{"uid"}
+		for _, p := range sortedKeys(t.Predicates) {
+			switch {
+			case p == "*":
+				fields = append(fields, "dgraph.type", "expand(_all_) { uid }")
+			case t.Predicates[p]:
+				fields = append(fields, p+" { uid }")
+			default:
+				fields = append(fields, p)
+			}
+		}
+		fmt.Fprintf(&b, "\tn%d(func: uid(%s)) { %s }\n", i, uids[i], strings.Join(fields, " "))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// Parse the nodes returned by a preview query, keyed by block name
+func parsePreviewNodes(data []byte) (map[string]map[string]json.RawMessage, error) {
+	var result map[string][]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse query response: %v", err)
+	}
+	nodes := map[string]map[string]json.RawMessage{}
+	for name, list := range result {
+		if len(list) > 0 {
+			nodes[name] = list[0]
+		}
+	}
+	return nodes, nil
+}
+
+// Compare the predicates of a node before and after a mutation
+func diffNode(before, after map[string]json.RawMessage) map[string]valueChange {
+	changes := map[string]valueChange{}
+	keys := map[string]bool{}
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	for k := range keys {
+		if k == "uid" {
+			continue
+		}
+		b, a := compactJSON(before[k]), compactJSON(after[k])
+		if !bytes.Equal(b, a) {
+			changes[k] = valueChange{Before: b, After: a}
+		}
+	}
+	return changes
+}
+
+// Compact a JSON value for comparison, turning a missing value into null
+func compactJSON(raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return json.RawMessage("null")
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return raw
+	}
+	return b.Bytes()
+}
+
+// Create handler for the mutation preview tool
+func createMutatePreviewHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		mutation, ok := request.Params.Arguments["mutation"].(string)
+		if !ok && request.Params.Arguments["mutation"] != nil {
+			return nil, fmt.Errorf("mutation must be a string")
+		}
+		deletion, ok := request.Params.Arguments["delete"].(string)
+		if !ok && request.Params.Arguments["delete"] != nil {
+			return nil, fmt.Errorf("delete must be a string")
+		}
+		if strings.TrimSpace(mutation) == "" && strings.TrimSpace(deletion) == "" {
+			return nil, fmt.Errorf("mutation or delete must be given")
+		}
+		if err := validateNQuads(mutation, false); err != nil {
+			return nil, fmt.Errorf("mutation: %v", err)
+		}
+		if err := validateNQuads(deletion, true); err != nil {
+			return nil, fmt.Errorf("delete: %v", err)
+		}
+		if err := predicateAccess.checkNQuads(mutation); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkNQuads(deletion); err != nil {
+			return nil, err
+		}
+
+		targets, err := collectPreviewTargets(nil, mutation, false)
+		if err != nil {
+			return nil, fmt.Errorf("mutation: %v", err)
+		}
+		if targets, err = collectPreviewTargets(targets, deletion, true); err != nil {
+			return nil, fmt.Errorf("delete: %v", err)
+		}
+
+		// Read, mutate and read again in one transaction that is never
+		// committed, so the second read sees the uncommitted writes
+		txn := activity.startTxn(client.NewTxn())
+		defer activity.finishTxn(ctx, txn)
+
+		uids := make([]string, len(targets))
+		for i, t := range targets {
+			if !strings.HasPrefix(t.Subject, "_:") {
+				uids[i] = t.Subject
+			}
+		}
+		before := map[string]map[string]json.RawMessage{}
+		if query := buildPreviewQuery(targets, uids); query != "{\n}" {
+			resp, err := txn.Query(ctx, query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read nodes before the mutation: %v", err)
+			}
+			if before, err = parsePreviewNodes(resp.Json); err != nil {
+				return nil, err
+			}
+		}
+
+		mu := &api.Mutation{}
+		if mutation != "" {
+			mu.SetNquads = []byte(mutation)
+		}
+		if deletion != "" {
+			mu.DelNquads = []byte(deletion)
+		}
+		resp, err := txn.Mutate(ctx, mu)
+		if err != nil {
+			return nil, fmt.Errorf("mutation failed: %v", err)
+		}
+		// Dgraph adds new predicates to the schema when the mutation runs,
+		// even though it is never committed
+		schemas.invalidate()
+
+		for i, t := range targets {
+			if name, ok := strings.CutPrefix(t.Subject, "_:"); ok {
+				uids[i] = resp.Uids[name]
+			}
+		}
+		resp, err = txn.Query(ctx, buildPreviewQuery(targets, uids))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read nodes after the mutation: %v", err)
+		}
+		after, err := parsePreviewNodes(resp.Json)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes := []nodePreview{}
+		for i, t := range targets {
+			block := fmt.Sprintf("n%d", i)
+			node := nodePreview{UID: uids[i], Changes: diffNode(before[block], after[block])}
+			if name, ok := strings.CutPrefix(t.Subject, "_:"); ok {
+				node.BlankNode, node.Created = name, true
+			}
+			nodes = append(nodes, node)
+		}
+
+		out, err := json.Marshal(map[string]interface{}{
+			"message": "Preview only: the mutation was rolled back and nothing is saved",
+			"nodes":   nodes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode preview: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCollectPreviewTargets(t *testing.T) {
+	targets, err := collectPreviewTargets(nil, `<0x01> <name> "Alice" .
+_:bob <name> "Bob"@en .
+_:bob <friend> <0x1> .
+# comment
+<0x1> <age> "30"^^<xs:int> .`, false)
+	if err != nil {
+		t.Fatalf("collectPreviewTargets() error = %v", err)
+	}
+	if targets, err = collectPreviewTargets(targets, "<0x2> * * .", true); err != nil {
+		t.Fatalf("collectPreviewTargets() error = %v", err)
+	}
+
+	want := []previewTarget{
+		{Subject: "0x1", Predicates: map[string]bool{"name": false, "age": false}},
+		{Subject: "_:bob", Predicates: map[string]bool{"name@en": false, "friend": true}},
+		{Subject: "0x2", Predicates: map[string]bool{"*": false}},
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("collectPreviewTargets() = %d targets, want %d", len(targets), len(want))
+	}
+	for i, target := range targets {
+		if !reflect.DeepEqual(*target, want[i]) {
+			t.Errorf("target %d = %+v, want %+v", i, *target, want[i])
+		}
+	}
+
+	for _, nquads := range []string{
+		`uid(v) <name> "Alice" .`,
+		`<0x1> <bad-name> "Alice" .`,
+		`<alice> <name> "Alice" .`,
+	} {
+		if _, err := collectPreviewTargets(nil, nquads, false); err == nil {
+			t.Errorf("collectPreviewTargets(%q) succeeded, want an error", nquads)
+		}
+	}
+}
+
+func TestBuildPreviewQuery(t *testing.T) {
+	targets := []*previewTarget{
+		{Subject: "0x1", Predicates: map[string]bool{"name": false, "friend": true}},
+		{Subject: "_:bob", Predicates: map[string]bool{"name": false}},
+		{Subject: "0x2", Predicates: map[string]bool{"*": false}},
+	}
+
+	got := buildPreviewQuery(targets, []string{"0x1", "", "0x2"})
+	want := "{\n" +
+		"\tn0(func: uid(0x1)) { uid friend { uid } name }\n" +
+		"\tn2(func: uid(0x2)) { uid dgraph.type expand(_all_) { uid } }\n" +
+		"}"
+	if got != want {
+		t.Errorf("buildPreviewQuery() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffNode(t *testing.T) {
+	before := map[string]json.RawMessage{
+		"uid":  json.RawMessage(`"0x1"`),
+		"name": json.RawMessage(`"Alice"`),
+		"age":  json.RawMessage(`30`),
+		"tags": json.RawMessage(`["a", "b"]`),
+	}
+	after := map[string]json.RawMessage{
+		"uid":   json.RawMessage(`"0x1"`),
+		"name":  json.RawMessage(`"Alicia"`),
+		"tags":  json.RawMessage(`["a","b"]`),
+		"email": json.RawMessage(`"a@example.com"`),
+	}
+
+	got, err := json.Marshal(diffNode(before, after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"age":{"before":30,"after":null},"email":{"before":null,"after":"a@example.com"},"name":{"before":"Alice","after":"Alicia"}}`
+	if string(got) != want {
+		t.Errorf("diffNode() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+// Default lifetime of cached query results
+const defaultQueryCacheTTL = 30 * time.Second
+
+// queryCacheKey identifies a query result. Results are cached per client,
+// since clients logged into different namespaces see different data.
+type queryCacheKey struct {
+	client *dgo.Dgraph
+	query  string
+	vars   string
+}
+
+// queryCacheEntry is a cached query result
+type queryCacheEntry struct {
+	key     queryCacheKey
+	data    []byte
+	expires time.Time
+}
+
+// queryCache is an LRU cache of query results with a TTL. Every operation
+// of this server that writes data or changes the schema invalidates it;
+// writes made by other Dgraph clients are only seen once entries expire.
+type queryCache struct {
+	mu         sync.Mutex
+	size       int
+	ttl        time.Duration
+	now        func() time.Time
+	generation uint64
+	order      *list.List // most recently used first
+	entries    map[queryCacheKey]*list.Element
+}
+
+// queryResults caches dgraph_query results. It is disabled until main
+// configures a size.
+var queryResults = newQueryCache(0, defaultQueryCacheTTL)
+
+// Create a query cache holding up to size results. A size of 0 disables it.
+func newQueryCache(size int, ttl time.Duration) *queryCache {
+	return &queryCache{
+		size:    size,
+		ttl:     ttl,
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[queryCacheKey]*list.Element),
+	}
+}
+
+// Build the cache key of a query run by a client with variables
+func newQueryCacheKey(client *dgo.Dgraph, query string, vars map[string]string) queryCacheKey {
+	var b strings.Builder
+	for _, name := range sortedKeys(vars) {
+		b.WriteString(name + "=" + vars[name] + "\x00")
+	}
+	return queryCacheKey{client: client, query: query, vars: b.String()}
+}
+
+// Check whether the cache stores anything
+func (c *queryCache) enabled() bool {
+	return c.size > 0 && c.ttl > 0
+}
+
+// Get a cached result. The generation returned must be passed to put when
+// storing a result fetched after a miss.
+func (c *queryCache) get(key queryCacheKey) ([]byte, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, c.generation, false
+	}
+	entry := elem.Value.(*queryCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, c.generation, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.data, c.generation, true
+}
+
+// Store a result, evicting the least recently used one if the cache is full
+func (c *queryCache) put(key queryCacheKey, generation uint64, data []byte) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Don't cache a result fetched before an invalidation, it may be stale
+	if c.generation != generation {
+		return
+	}
+
+	entry := &queryCacheEntry{key: key, data: data, expires: c.now().Add(c.ttl)}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*queryCacheEntry).key)
+	}
+}
+
+// Drop all cached results
+func (c *queryCache) invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.order.Init()
+	c.entries = make(map[queryCacheKey]*list.Element)
+	c.mu.Unlock()
+}
+
+// Drop everything cached about the data and schema after a write
+func invalidateCaches() {
+	schemas.invalidate()
+	queryResults.invalidate()
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueryCache(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newQueryCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	a := newQueryCacheKey(nil, "{ a() {} }", nil)
+	b := newQueryCacheKey(nil, "{ b() {} }", map[string]string{"$x": "1"})
+	d := newQueryCacheKey(nil, "{ b() {} }", map[string]string{"$x": "2"})
+
+	_, gen, ok := c.get(a)
+	if ok {
+		t.Fatalf("empty cache returned a hit")
+	}
+	c.put(a, gen, []byte("A"))
+	c.put(b, gen, []byte("B"))
+	if data, _, ok := c.get(a); !ok || string(data) != "A" {
+		t.Errorf("get(a) = %q, %t, want A", data, ok)
+	}
+
+	// b is now least recently used and is evicted
+	c.put(d, gen, []byte("D"))
+	if _, _, ok := c.get(b); ok {
+		t.Errorf("get(b) hit after eviction")
+	}
+	if data, _, ok := c.get(d); !ok || string(data) != "D" {
+		t.Errorf("get(d) = %q, %t, want D", data, ok)
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	if _, _, ok := c.get(a); ok {
+		t.Errorf("get(a) hit after expiry")
+	}
+}
+
+func TestQueryCacheInvalidate(t *testing.T) {
+	c := newQueryCache(10, time.Minute)
+	key := newQueryCacheKey(nil, "{ a() {} }", nil)
+
+	_, gen, _ := c.get(key)
+	c.put(key, gen, []byte("A"))
+	c.invalidate()
+	if _, _, ok := c.get(key); ok {
+		t.Errorf("get hit after invalidate")
+	}
+
+	// A result fetched before an invalidation isn't cached
+	_, gen, _ = c.get(key)
+	c.invalidate()
+	c.put(key, gen, []byte("stale"))
+	if _, _, ok := c.get(key); ok {
+		t.Errorf("stale result was cached")
+	}
+}
+
+func TestQueryCacheDisabled(t *testing.T) {
+	c := newQueryCache(0, time.Minute)
+	key := newQueryCacheKey(nil, "{ a() {} }", nil)
+	_, gen, _ := c.get(key)
+	c.put(key, gen, []byte("A"))
+	if _, _, ok := c.get(key); ok {
+		t.Errorf("disabled cache returned a hit")
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Risk levels of a query's estimated cost
+const (
+	costLow    = "low"
+	costMedium = "medium"
+	costHigh   = "high"
+)
+
+// Largest first: limit on a scan that is not considered risky
+const maxCheapScanFirst = 10000
+
+var (
+	// rootFuncRe matches the root function of a block's arguments
+	rootFuncRe = regexp.MustCompile(`\bfunc\s*:\s*(\w+)\s*\(`)
+	// firstValueRe matches a first: limit, given literally or as a variable
+	firstValueRe = regexp.MustCompile(`\bfirst\s*:\s*(-?\d+|\$\w+)`)
+	// recurseRe matches an @recurse directive and its arguments, if any
+	recurseRe = regexp.MustCompile(`@recurse\s*(\([^)]*\))?`)
+	// countUIDRe matches a block body holding only count(uid)
+	countUIDRe = regexp.MustCompile(`^\s*(?:[\w.]+\s*:\s*)?count\(\s*uid\s*\)\s*$`)
+)
+
+// scanFunctions are the root functions visiting every node with a predicate
+// or type, as opposed to the index lookups of the other functions
+var scanFunctions = map[string]bool{"has": true, "type": true}
+
+// queryCost is the estimated risk of running a query, with a reason for
+// every block contributing to it
+type queryCost struct {
+	Risk    string   `json:"risk"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Raise the risk to level, recording why
+func (c *queryCost) add(level, reason string) {
+	if level == costHigh || c.Risk == costLow {
+		c.Risk = level
+	}
+	c.Reasons = append(c.Reasons, reason)
+}
+
+// Estimate the cost of a query from its text alone. Blocks scanning every
+// node with has() or type() are risky without a first: limit, since they
+// read the whole predicate or type, and so is @recurse without a depth.
+// Blocks only counting the nodes they scan are a lesser risk. Schema
+// queries are always a low risk.
+func estimateQueryCost(query string) (queryCost, error) {
+	// Schema queries read the schema only
+	if isSchemaQuery(query) {
+		return queryCost{Risk: costLow}, nil
+	}
+	blocks, err := parseQueryBlocks(query)
+	if err != nil {
+		return queryCost{}, err
+	}
+
+	cost := queryCost{Risk: costLow}
+	for _, b := range blocks {
+		if m := rootFuncRe.FindStringSubmatch(b.Args); m != nil && scanFunctions[m[1]] {
+			first := firstValueRe.FindStringSubmatch(b.Args)
+			switch {
+			case first == nil && countOnly(query[b.bodyStart+1:b.bodyEnd]):
+				cost.add(costMedium, fmt.Sprintf("block %s counts every node with %s()", b.Name, m[1]))
+			case first == nil:
+				cost.add(costHigh, fmt.Sprintf("block %s scans every node with %s() and has no first: limit", b.Name, m[1]))
+			case !strings.HasPrefix(first[1], "$"):
+				if n, err := strconv.Atoi(first[1]); err == nil && n > maxCheapScanFirst {
+					cost.add(costMedium, fmt.Sprintf("block %s scans with %s() and a large first: %d", b.Name, m[1], n))
+				}
+			}
+		}
+
+		if b.argsStart >= 0 {
+			directives := query[b.argsEnd+1 : b.bodyStart]
+			if m := recurseRe.FindStringSubmatch(directives); m != nil && !strings.Contains(m[1], "depth") {
+				cost.add(costMedium, fmt.Sprintf("block %s uses @recurse without a depth", b.Name))
+			}
+		}
+	}
+	return cost, nil
+}
+
+// Check whether a block body only counts the nodes, which reads their uids
+// but none of their values
+func countOnly(body string) bool {
+	return countUIDRe.MatchString(body)
+}
+
+// Check whether a query may run: high-risk queries need force
+func (c queryCost) check(force bool) error {
+	if c.Risk != costHigh || force {
+		return nil
+	}
+	return fmt.Errorf("query is likely to scan the entire database (risk: %s): %s. Add a first: limit or a more selective root function, or set force to run it anyway", c.Risk, strings.Join(c.Reasons, "; "))
+}
+
+// Report the estimated cost of a risky query in the result's metadata and
+// as a warning
+func withQueryCost(result *mcp.CallToolResult, cost queryCost) *mcp.CallToolResult {
+	if cost.Risk == costLow {
+		return result
+	}
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta["query_cost"] = cost
+	warning := fmt.Sprintf("Warning: query cost risk is %s: %s.", cost.Risk, strings.Join(cost.Reasons, "; "))
+	result.Content = append(result.Content, mcp.NewTextContent(warning))
+	return result
+}
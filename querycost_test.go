@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestEstimateQueryCost(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		risk    string
+		reasons int
+	}{
+		{"index lookup", `{ q(func: eq(name, "Alice")) { name } }`, costLow, 0},
+		{"uid", `{ q(func: uid(0x1)) { name } }`, costLow, 0},
+		{"has with first", `{ q(func: has(name), first: 10) { name } }`, costLow, 0},
+		{"type with variable first", `query q($n: int) { q(func: type(Person), first: $n) { name } }`, costLow, 0},
+		{"has without first", `{ q(func: has(name)) { name } }`, costHigh, 1},
+		{"type in var block", `{ v as var(func: type(Person)) @filter(ge(age, 18)) q(func: uid(v), first: 5) { name } }`, costHigh, 1},
+		{"large first", `{ q(func: has(name), first: 50000) { name } }`, costMedium, 1},
+		{"count only", `{ q(func: type(Person)) { total: count(uid) } }`, costMedium, 1},
+		{"recurse without depth", `{ q(func: uid(0x1)) @recurse { friend } }`, costMedium, 1},
+		{"recurse with depth", `{ q(func: uid(0x1)) @recurse(depth: 3) { friend } }`, costLow, 0},
+		{"high wins", `{ a(func: has(name), first: 50000) { name } b(func: has(age)) { age } }`, costHigh, 2},
+		{"schema", `schema {}`, costLow, 0},
+		{"schema of predicates", `schema(pred: [name]) { type index }`, costLow, 0},
+		{"first inside a string", `{ q(func: has(name)) @filter(eq(note, "first: 1")) { name } }`, costHigh, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := estimateQueryCost(tt.query)
+			if err != nil {
+				t.Fatalf("estimateQueryCost() failed: %v", err)
+			}
+			if cost.Risk != tt.risk || len(cost.Reasons) != tt.reasons {
+				t.Errorf("estimateQueryCost() = %+v, want risk %s with %d reasons", cost, tt.risk, tt.reasons)
+			}
+		})
+	}
+
+	if _, err := estimateQueryCost("{ q(func: has(name) { name } }"); err == nil {
+		t.Errorf("estimateQueryCost() of an unbalanced query succeeded, want error")
+	}
+}
+
+func TestQueryCostCheck(t *testing.T) {
+	high := queryCost{Risk: costHigh, Reasons: []string{"block q scans every node with has() and has no first: limit"}}
+	if err := high.check(false); err == nil || !strings.Contains(err.Error(), "block q scans") {
+		t.Errorf("check() = %v, want an error naming the reason", err)
+	}
+	if err := high.check(true); err != nil {
+		t.Errorf("check(force) = %v, want nil", err)
+	}
+	if err := (queryCost{Risk: costMedium}).check(false); err != nil {
+		t.Errorf("check() of a medium risk = %v, want nil", err)
+	}
+}
+
+func TestWithQueryCost(t *testing.T) {
+	low := withQueryCost(mcp.NewToolResultText("{}"), queryCost{Risk: costLow})
+	if low.Meta != nil || len(low.Content) != 1 {
+		t.Errorf("withQueryCost() of a low risk changed the result: %+v", low)
+	}
+
+	cost := queryCost{Risk: costMedium, Reasons: []string{"block q uses @recurse without a depth"}}
+	result := withQueryCost(mcp.NewToolResultText("{}"), cost)
+	if !reflect.DeepEqual(result.Meta["query_cost"], cost) {
+		t.Errorf("query_cost = %v, want %v", result.Meta["query_cost"], cost)
+	}
+	if len(result.Content) != 2 {
+		t.Errorf("got %d content blocks, want the result and a warning", len(result.Content))
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+)
+
+// flightCall is a query in flight, shared by every request for it
+type flightCall struct {
+	done     chan struct{}
+	resp     *api.Response
+	err      error
+	ctx      context.Context
+	cancel   context.CancelFunc
+	deadline time.Time
+	timer    *time.Timer
+	waiters  int
+}
+
+// Shorten the call's deadline to d if d is earlier, so the call never
+// outlives the most impatient request waiting for it
+func (c *flightCall) shortenDeadline(d time.Time) {
+	if !c.deadline.IsZero() && !d.Before(c.deadline) {
+		return
+	}
+	c.deadline = d
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(time.Until(d), c.cancel)
+}
+
+// queryFlights runs identical concurrent queries once and hands the result
+// to every request waiting for it. Only queries in flight are shared;
+// results, and errors in particular, are forgotten once the query returns.
+type queryFlights struct {
+	mu    sync.Mutex
+	calls map[queryCacheKey]*flightCall
+}
+
+// inflightQueries deduplicates the dgraph_query reads not pinned to a
+// transaction or snapshot
+var inflightQueries = newQueryFlights()
+
+func newQueryFlights() *queryFlights {
+	return &queryFlights{calls: make(map[queryCacheKey]*flightCall)}
+}
+
+// Run fn for key, or wait for the run already in flight. fn gets a context
+// carrying the values of the first request, ending at the earliest deadline
+// of the requests waiting, and canceled once none of them waits anymore.
+// Each request stops waiting when its own context ends. Reports whether
+// the result was shared with another request.
+func (f *queryFlights) do(ctx context.Context, key queryCacheKey, fn func(ctx context.Context) (*api.Response, error)) (*api.Response, bool, error) {
+	f.mu.Lock()
+	call, shared := f.calls[key]
+	if !shared {
+		call = &flightCall{done: make(chan struct{})}
+		call.ctx, call.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		f.calls[key] = call
+		go f.run(key, call, fn)
+	}
+	call.waiters++
+	if d, ok := ctx.Deadline(); ok {
+		call.shortenDeadline(d)
+	}
+	f.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil && ctx.Err() != nil {
+			// The call likely failed because this request's deadline ended it
+			return nil, shared, ctx.Err()
+		}
+		return call.resp, shared, call.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		if call.waiters--; call.waiters == 0 {
+			// Nobody needs the result: stop the query, and let later
+			// requests start a new one instead of joining a canceled one
+			call.cancel()
+			f.forget(key, call)
+		}
+		f.mu.Unlock()
+		return nil, shared, ctx.Err()
+	}
+}
+
+// Run a call and release its waiters
+func (f *queryFlights) run(key queryCacheKey, call *flightCall, fn func(ctx context.Context) (*api.Response, error)) {
+	call.resp, call.err = fn(call.ctx)
+
+	f.mu.Lock()
+	f.forget(key, call)
+	if call.timer != nil {
+		call.timer.Stop()
+	}
+	f.mu.Unlock()
+	call.cancel()
+	close(call.done)
+}
+
+// Remove a call from the calls in flight, unless a newer one replaced it.
+// f.mu must be held.
+func (f *queryFlights) forget(key queryCacheKey, call *flightCall) {
+	if f.calls[key] == call {
+		delete(f.calls, key)
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+)
+
+func TestQueryFlightsShareResult(t *testing.T) {
+	f := newQueryFlights()
+	key := queryCacheKey{query: "{ q(func: uid(0x1)) { name } }"}
+	release := make(chan struct{})
+	var calls atomic.Int32
+	fn := func(ctx context.Context) (*api.Response, error) {
+		calls.Add(1)
+		<-release
+		return &api.Response{Json: []byte(`{"q":[]}`)}, nil
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	var sharedCount atomic.Int32
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, shared, err := f.do(context.Background(), key, fn)
+			if err != nil || string(resp.Json) != `{"q":[]}` {
+				t.Errorf("do() = %v, %v, want the shared response", resp, err)
+			}
+			if shared {
+				sharedCount.Add(1)
+			}
+		}()
+	}
+	// Wait until every request joined the call before releasing it
+	for {
+		f.mu.Lock()
+		call := f.calls[key]
+		joined := call != nil && call.waiters == requests
+		f.mu.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("query ran %d times, want once", calls.Load())
+	}
+	if sharedCount.Load() != requests-1 {
+		t.Errorf("%d requests shared the result, want %d", sharedCount.Load(), requests-1)
+	}
+	if len(f.calls) != 0 {
+		t.Errorf("%d calls left in flight, want none", len(f.calls))
+	}
+}
+
+func TestQueryFlightsDontKeepErrors(t *testing.T) {
+	f := newQueryFlights()
+	key := queryCacheKey{query: "q"}
+	calls := 0
+	fn := func(ctx context.Context) (*api.Response, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return &api.Response{}, nil
+	}
+
+	if _, _, err := f.do(context.Background(), key, fn); err == nil {
+		t.Fatalf("first do() succeeded, want error")
+	}
+	if _, shared, err := f.do(context.Background(), key, fn); err != nil || shared {
+		t.Errorf("second do() = shared %v, %v, want a new successful run", shared, err)
+	}
+	if calls != 2 {
+		t.Errorf("query ran %d times, want twice", calls)
+	}
+}
+
+func TestQueryFlightsShortestDeadline(t *testing.T) {
+	f := newQueryFlights()
+	key := queryCacheKey{query: "q"}
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	fn := func(ctx context.Context) (*api.Response, error) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return nil, ctx.Err()
+	}
+
+	// The first request has no deadline, the second a short one
+	go f.do(context.Background(), key, fn)
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, shared, err := f.do(ctx, key, fn); !shared || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("do() = shared %v, %v, want a shared call ending at the deadline", shared, err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("shared query kept running past the shortest deadline")
+	}
+}
+
+func TestQueryFlightsCancelWithoutWaiters(t *testing.T) {
+	f := newQueryFlights()
+	key := queryCacheKey{query: "q"}
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	fn := func(ctx context.Context) (*api.Response, error) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, _, err := f.do(ctx, key, fn)
+		done <- err
+	}()
+	<-started
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("do() = %v, want context.Canceled", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("query kept running after its only request left")
+	}
+
+	// A later request starts a new query instead of joining the canceled one
+	resp, shared, err := f.do(context.Background(), key, func(ctx context.Context) (*api.Response, error) {
+		return &api.Response{}, ctx.Err()
+	})
+	if err != nil || shared || resp == nil {
+		t.Errorf("do() after cancellation = %v, shared %v, %v, want a new run", resp, shared, err)
+	}
+}
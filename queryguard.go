@@ -0,0 +1,80 @@
+package main
+
+import "fmt"
+
+// Default query complexity limits
+const (
+	defaultMaxQueryLength = 64 << 10
+	defaultMaxQueryDepth  = 16
+	defaultMaxQueryBlocks = 128
+)
+
+// queryLimits bounds the size and complexity of queries accepted from
+// clients, so they are rejected before reaching Dgraph. A zero limit is
+// not enforced.
+type queryLimits struct {
+	MaxLength    int // maximum length of the query text in bytes
+	MaxDepth     int // maximum nesting depth of blocks below the query body
+	MaxBlocks    int // maximum number of blocks, counting nested ones
+	DefaultFirst int // first: limit added to result blocks without one
+}
+
+// Measure how deeply blocks are nested and how many there are. The braces
+// enclosing the query body itself are not counted.
+func queryComplexity(query string) (depth, blocks int, err error) {
+	level := 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '"':
+			if i, err = skipString(query, i); err != nil {
+				return 0, 0, err
+			}
+		case '/':
+			if isRegexpStart(query, i) {
+				if i, err = skipRegexp(query, i); err != nil {
+					return 0, 0, err
+				}
+			}
+		case '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case '{':
+			level++
+			if level > 1 {
+				blocks++
+			}
+			if level-1 > depth {
+				depth = level - 1
+			}
+		case '}':
+			level--
+			if level < 0 {
+				return 0, 0, fmt.Errorf("unbalanced '}' at offset %d", i)
+			}
+		}
+	}
+	if level != 0 {
+		return 0, 0, fmt.Errorf("unbalanced '{' in query")
+	}
+	return depth, blocks, nil
+}
+
+// Check a query against the limits, naming the limit it exceeds
+func (l queryLimits) check(query string) error {
+	if l.MaxLength > 0 && len(query) > l.MaxLength {
+		return fmt.Errorf("query is %d bytes long, exceeding the limit of %d bytes", len(query), l.MaxLength)
+	}
+
+	depth, blocks, err := queryComplexity(query)
+	if err != nil {
+		return fmt.Errorf("invalid query: %v", err)
+	}
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("query nests blocks %d levels deep, exceeding the limit of %d", depth, l.MaxDepth)
+	}
+	if l.MaxBlocks > 0 && blocks > l.MaxBlocks {
+		return fmt.Errorf("query has %d blocks, exceeding the limit of %d", blocks, l.MaxBlocks)
+	}
+	return nil
+}
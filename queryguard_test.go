@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryComplexity(t *testing.T) {
+	tests := []struct {
+		query  string
+		depth  int
+		blocks int
+	}{
+		{`{ me(func: has(name)) { name } }`, 1, 1},
+		{`{ me(func: has(name)) { name friend { name friend { name } } } }`, 3, 3},
+		{`{ a(func: has(x)) { x } b(func: has(y)) { y { z } } }`, 2, 3},
+		{`query q($n: string) { me(func: eq(name, $n)) { name } }`, 1, 1},
+		{`{ me(func: eq(name, "{{{")) { name } # {{{
+		}`, 1, 1},
+		{`{ me(func: regexp(name, /^{{#/)) { name } }`, 1, 1},
+		{`{ me(func: has(tag)) @filter(regexp(tag, /a\/}/)) { tag { x } } }`, 2, 2},
+	}
+	for _, tt := range tests {
+		depth, blocks, err := queryComplexity(tt.query)
+		if err != nil {
+			t.Errorf("queryComplexity(%q) failed: %v", tt.query, err)
+			continue
+		}
+		if depth != tt.depth || blocks != tt.blocks {
+			t.Errorf("queryComplexity(%q) = %d, %d, want %d, %d", tt.query, depth, blocks, tt.depth, tt.blocks)
+		}
+	}
+}
+
+func TestQueryLimitsCheck(t *testing.T) {
+	limits := queryLimits{MaxLength: 200, MaxDepth: 2, MaxBlocks: 3}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ me(func: has(name)) { name friend { name } } }`, ""},
+		{`{ me(func: has(name)) { ` + strings.Repeat("name ", 50) + `} }`, "bytes long"},
+		{`{ me(func: has(name)) { friend { friend { name } } } }`, "levels deep"},
+		{`{ a(func: has(x)) { x } b(func: has(x)) { x } c(func: has(x)) { x } d(func: has(x)) { x } }`, "4 blocks"},
+		{`{ me(func: has(name)) { name }`, "unbalanced"},
+	}
+	for _, tt := range tests {
+		err := limits.check(tt.query)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("check(%q) failed: %v", tt.query, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("check(%q) = %v, want error containing %q", tt.query, err, tt.want)
+		}
+	}
+
+	if err := (queryLimits{}).check(`{ a { b { c { d } } } }`); err != nil {
+		t.Errorf("zero limits rejected a query: %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Convert a JSON query result into N-Quads. The dgo v2 client can't ask
+// Dgraph for RDF responses, so the triples are rebuilt from the JSON nodes.
+// Every node must select `uid` to be expressible as a triple subject; keys
+// that aren't plain predicates (aliases with functions, facets) are skipped.
+func jsonToNQuads(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var result map[string]interface{}
+	if err := decoder.Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	var b strings.Builder
+	for _, block := range sortedKeys(result) {
+		nodes, ok := result[block].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, node := range nodes {
+			if obj, ok := node.(map[string]interface{}); ok {
+				if err := writeNodeNQuads(&b, obj); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+// Write the triples of a node and its children
+func writeNodeNQuads(b *strings.Builder, node map[string]interface{}) error {
+	uid, ok := node["uid"].(string)
+	if !ok {
+		return fmt.Errorf("cannot convert node without uid to RDF, select uid in every block")
+	}
+
+	for _, key := range sortedKeys(node) {
+		if key == "uid" || strings.ContainsAny(key, "(|") {
+			continue
+		}
+		predicate, lang, _ := strings.Cut(key, "@")
+
+		values, ok := node[key].([]interface{})
+		if !ok {
+			values = []interface{}{node[key]}
+		}
+		for _, value := range values {
+			if child, ok := value.(map[string]interface{}); ok {
+				childUID, ok := child["uid"].(string)
+				if !ok {
+					return fmt.Errorf("cannot convert node without uid to RDF, select uid in every block")
+				}
+				fmt.Fprintf(b, "<%s> <%s> <%s> .\n", uid, predicate, childUID)
+				if err := writeNodeNQuads(b, child); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Fprintf(b, "<%s> <%s> %s .\n", uid, predicate, formatRDFLiteral(value, lang))
+		}
+	}
+	return nil
+}
+
+// Format a JSON scalar as an RDF literal
+func formatRDFLiteral(value interface{}, lang string) string {
+	switch v := value.(type) {
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return fmt.Sprintf("%q^^<xs:int>", v.String())
+		}
+		return fmt.Sprintf("%q^^<xs:float>", v.String())
+	case bool:
+		return fmt.Sprintf("%q^^<xs:boolean>", strconv.FormatBool(v))
+	case string:
+		if lang != "" {
+			return strconv.Quote(v) + "@" + lang
+		}
+		return strconv.Quote(v)
+	default:
+		encoded, _ := json.Marshal(v)
+		return strconv.Quote(string(encoded))
+	}
+}
+
+// Return the keys of a map in sorted order for deterministic output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestJSONToNQuads(t *testing.T) {
+	data := []byte(`{"people":[{"uid":"0x1","name":"Alice","name@fr":"Alice","age":30,"score":1.5,"active":true,"friend":[{"uid":"0x2","name":"Bob \"B\""}]}]}`)
+
+	got, err := jsonToNQuads(data)
+	if err != nil {
+		t.Fatalf("jsonToNQuads failed: %v", err)
+	}
+
+	want := `<0x1> <active> "true"^^<xs:boolean> .
+<0x1> <age> "30"^^<xs:int> .
+<0x1> <friend> <0x2> .
+<0x2> <name> "Bob \"B\"" .
+<0x1> <name> "Alice" .
+<0x1> <name> "Alice"@fr .
+<0x1> <score> "1.5"^^<xs:float> .
+`
+	if got != want {
+		t.Errorf("jsonToNQuads =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSONToNQuadsRequiresUID(t *testing.T) {
+	if _, err := jsonToNQuads([]byte(`{"people":[{"name":"Alice"}]}`)); err == nil {
+		t.Error("expected error for node without uid")
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// Default time a call waits for a lost connection to come back
+const defaultReconnectTimeout = 5 * time.Second
+
+// Reconnect a connection right away, skipping the backoff gRPC waits
+// between attempts, and wait until it is ready or ctx ends
+func reconnect(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.ResetConnectBackoff()
+	conn.Connect()
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		if !conn.WaitForStateChange(ctx, state) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Check whether a failed call can be sent again without applying a write
+// twice. A call made while the connection was down never reached Dgraph;
+// over a ready connection only reads are safe, since a connection lost
+// mid-call leaves unknown whether Dgraph applied it.
+func retryableCall(method string, req interface{}, before connectivity.State) bool {
+	if before != connectivity.Ready {
+		return true
+	}
+	switch method {
+	case "/api.Dgraph/CheckVersion", "/api.Dgraph/Login":
+		return true
+	case "/api.Dgraph/Query":
+		r, ok := req.(*api.Request)
+		return ok && len(r.Mutations) == 0 && !r.CommitNow
+	}
+	return false
+}
+
+// reconnector reconnects lost connections lazily and keeps the shared
+// client on the connections that are up. A connection that doesn't come back
+// within the timeout is left out of a fresh client swapped in for later
+// calls, so they don't each wait on an Alpha that is down, and is put back
+// once gRPC, reconnecting in the background, has it ready again.
+type reconnector struct {
+	timeout time.Duration
+
+	mu    sync.Mutex
+	conns []*grpc.ClientConn
+	down  map[*grpc.ClientConn]bool
+}
+
+// Create a reconnector waiting up to timeout for a lost connection. A
+// timeout of 0 disables reconnecting at call time.
+func newReconnector(timeout time.Duration) *reconnector {
+	return &reconnector{timeout: timeout, down: make(map[*grpc.ClientConn]bool)}
+}
+
+// Track the connections to the Alphas the shared client sends calls over
+func (r *reconnector) track(alphas []alphaConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, alpha := range alphas {
+		r.conns = append(r.conns, alpha.conn)
+		r.conns = append(r.conns, alpha.pool...)
+	}
+}
+
+// Swap in a shared client on the connections that are up, or on all of
+// them when none is, so that calls keep reconnecting. The schema history
+// recorded with the replaced client carries over, as both reach the same
+// cluster. Must be called with r.mu held.
+func (r *reconnector) renew() {
+	var up, all []api.DgraphClient
+	for _, conn := range r.conns {
+		client := externalTxnClient{api.NewDgraphClient(conn)}
+		all = append(all, client)
+		if !r.down[conn] {
+			up = append(up, client)
+		}
+	}
+	if len(up) == 0 {
+		up = all
+	}
+
+	client := dgo.NewDgraphClient(up...)
+	if old := getClient(); old != nil {
+		schemaVersions.transfer(old, client)
+	}
+	setClient(client)
+}
+
+// Leave a tracked connection that didn't come back out of the shared client
+// until it is ready again
+func (r *reconnector) markDown(conn *grpc.ClientConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down[conn] || !slices.Contains(r.conns, conn) {
+		return
+	}
+	slog.Warn("Dgraph connection is down, sending calls over the others", "target", conn.Target())
+	r.down[conn] = true
+	r.renew()
+	go r.watch(conn)
+}
+
+// Put a connection left out back into the shared client
+func (r *reconnector) markUp(conn *grpc.ClientConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.down[conn] {
+		return
+	}
+	slog.Info("Dgraph connection is back", "target", conn.Target())
+	delete(r.down, conn)
+	r.renew()
+}
+
+// Wait for a connection left out to be ready again, asking gRPC to
+// reconnect whenever it gives up, then put it back. Closing the connection
+// on shutdown ends the wait.
+func (r *reconnector) watch(conn *grpc.ClientConn) {
+	for state := conn.GetState(); state != connectivity.Ready; state = conn.GetState() {
+		switch state {
+		case connectivity.Shutdown:
+			return
+		case connectivity.Idle:
+			conn.Connect()
+		}
+		conn.WaitForStateChange(context.Background(), state)
+	}
+	r.markUp(conn)
+}
+
+// Client interceptor reconnecting lazily: a call failing because the
+// connection to Dgraph is down, e.g. the first one after a restart,
+// reconnects once, waiting up to the timeout, and is sent again if that is
+// safe. A connection not back by then is left out of the shared client.
+func (r *reconnector) lazyReconnect(ctx context.Context, method string, req, reply interface{}, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	before := conn.GetState()
+	call := func() error { return invoker(ctx, method, req, reply, conn, opts...) }
+	reconnectConn := func(rctx context.Context) error {
+		start := time.Now()
+		if err := reconnect(rctx, conn); err != nil {
+			slog.Debug("Reconnecting to Dgraph failed", "target", conn.Target(), "error", err)
+			// Only the timeout, not the call being canceled, tells the
+			// connection is down
+			if ctx.Err() == nil {
+				r.markDown(conn)
+			}
+			return err
+		}
+		slog.Info("Reconnected to Dgraph", "target", conn.Target(), "method", method, "duration", time.Since(start))
+		r.markUp(conn)
+		return nil
+	}
+	return retryAfterReconnect(ctx, method, req, before, r.timeout, reconnectConn, call)
+}
+
+// Make a call and, if it fails with the connection unavailable and may be
+// sent again, reconnect within timeout and make it once more. A failed
+// reconnect returns the call's original error.
+func retryAfterReconnect(ctx context.Context, method string, req interface{}, before connectivity.State, timeout time.Duration, reconnect func(context.Context) error, call func() error) error {
+	err := call()
+	if timeout <= 0 || status.Code(err) != codes.Unavailable || ctx.Err() != nil || !retryableCall(method, req, before) {
+		return err
+	}
+
+	rctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if reconnect(rctx) != nil {
+		return err
+	}
+	return call()
+}
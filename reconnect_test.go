@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryableCall(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		before connectivity.State
+		want   bool
+	}{
+		{"connection down", "/api.Dgraph/CommitOrAbort", &api.TxnContext{}, connectivity.TransientFailure, true},
+		{"idle connection", "/api.Dgraph/Query", &api.Request{Mutations: []*api.Mutation{{}}}, connectivity.Idle, true},
+		{"read", "/api.Dgraph/Query", &api.Request{Query: "{ q(func: uid(0x1)) { uid } }"}, connectivity.Ready, true},
+		{"version check", "/api.Dgraph/CheckVersion", &api.Check{}, connectivity.Ready, true},
+		{"mutation", "/api.Dgraph/Query", &api.Request{Mutations: []*api.Mutation{{}}, CommitNow: true}, connectivity.Ready, false},
+		{"commit", "/api.Dgraph/CommitOrAbort", &api.TxnContext{}, connectivity.Ready, false},
+		{"alter", "/api.Dgraph/Alter", &api.Operation{}, connectivity.Ready, false},
+	}
+	for _, tt := range tests {
+		if got := retryableCall(tt.method, tt.req, tt.before); got != tt.want {
+			t.Errorf("%s: retryableCall() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfterReconnect(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	write := &api.Request{Mutations: []*api.Mutation{{}}, CommitNow: true}
+
+	tests := []struct {
+		name         string
+		timeout      time.Duration
+		req          interface{}
+		errs         []error // the results of successive calls
+		reconnectErr error
+		calls        int
+		reconnects   int
+		wantErr      bool
+	}{
+		{"retried read", time.Second, &api.Request{}, []error{unavailable, nil}, nil, 2, 1, false},
+		{"failing twice", time.Second, &api.Request{}, []error{unavailable, unavailable}, nil, 2, 1, true},
+		{"reconnect failing", time.Second, &api.Request{}, []error{unavailable}, context.DeadlineExceeded, 1, 1, true},
+		{"other error", time.Second, &api.Request{}, []error{status.Error(codes.Internal, "boom")}, nil, 1, 0, true},
+		{"disabled", 0, &api.Request{}, []error{unavailable}, nil, 1, 0, true},
+		{"write over a ready connection", time.Second, write, []error{unavailable}, nil, 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, reconnects := 0, 0
+			call := func() error {
+				err := tt.errs[calls]
+				calls++
+				return err
+			}
+			reconnect := func(ctx context.Context) error {
+				reconnects++
+				if _, ok := ctx.Deadline(); !ok {
+					t.Errorf("reconnect without a deadline")
+				}
+				return tt.reconnectErr
+			}
+			err := retryAfterReconnect(context.Background(), "/api.Dgraph/Query", tt.req, connectivity.Ready, tt.timeout, reconnect, call)
+			if (err != nil) != tt.wantErr || calls != tt.calls || reconnects != tt.reconnects {
+				t.Errorf("retryAfterReconnect() = %v after %d calls and %d reconnects, want error %v after %d and %d",
+					err, calls, reconnects, tt.wantErr, tt.calls, tt.reconnects)
+			}
+		})
+	}
+}
+
+// Dial a connection that never connects, as nothing listens on port 1, and
+// answers every query itself with a schema naming the Alpha
+func answeringConn(t *testing.T, name string) *grpc.ClientConn {
+	answer := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if r, ok := reply.(*api.Response); ok {
+			r.Json = []byte(fmt.Sprintf(`{"schema":[{"predicate":%q,"type":"string"}]}`, name))
+			r.Txn = &api.TxnContext{}
+		}
+		return nil
+	}
+	conn, err := grpc.Dial("127.0.0.1:1", grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithUnaryInterceptor(answer))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Track two Alphas with a reconnector and start the shared client on them
+func trackedAlphas(t *testing.T, timeout time.Duration) (*reconnector, *grpc.ClientConn, *grpc.ClientConn) {
+	history := schemaVersions
+	schemaVersions = newSchemaHistory(maxSchemaVersions)
+	t.Cleanup(func() {
+		setClient(nil)
+		schemaVersions = history
+		schemas.invalidate()
+	})
+
+	a, b := answeringConn(t, "alpha1"), answeringConn(t, "alpha2")
+	r := newReconnector(timeout)
+	r.track([]alphaConn{{host: "alpha1", conn: a}, {host: "alpha2", conn: b}})
+	r.mu.Lock()
+	r.renew()
+	r.mu.Unlock()
+	return r, a, b
+}
+
+func TestReconnectorSwapsSharedClient(t *testing.T) {
+	r, a, _ := trackedAlphas(t, time.Second)
+	initial := getClient()
+	schemaVersions.add(initial, "dgraph_alter_schema", &schemaInfo{})
+
+	// Read the schema resource repeatedly, reporting the Alphas that answered
+	resource := createSchemaResourceHandler(nil)
+	answered := func() map[string]bool {
+		seen := map[string]bool{}
+		for i := 0; i < 20; i++ {
+			schemas.invalidate()
+			contents, err := resource(context.Background(), mcp.ReadResourceRequest{})
+			if err != nil {
+				t.Fatalf("schema resource failed: %v", err)
+			}
+			for _, name := range []string{"alpha1", "alpha2"} {
+				if strings.Contains(contents[0].(mcp.TextResourceContents).Text, name) {
+					seen[name] = true
+				}
+			}
+		}
+		return seen
+	}
+
+	r.markDown(a)
+	down := getClient()
+	if down == initial {
+		t.Fatalf("shared client not replaced after a connection went down")
+	}
+	if seen := answered(); seen["alpha1"] || !seen["alpha2"] {
+		t.Errorf("calls with alpha1 down were answered by %v, want alpha2 only", seen)
+	}
+	if versions := schemaVersions.list(down); len(versions) != 1 {
+		t.Errorf("replacing client has %d schema versions, want the 1 of the replaced one", len(versions))
+	}
+
+	r.markUp(a)
+	if getClient() == down {
+		t.Fatalf("shared client not replaced after the connection came back")
+	}
+	if seen := answered(); !seen["alpha1"] || !seen["alpha2"] {
+		t.Errorf("calls with both Alphas up were answered by %v, want both", seen)
+	}
+}
+
+func TestLazyReconnectLeavesOutLostConnection(t *testing.T) {
+	r, a, _ := trackedAlphas(t, 20*time.Millisecond)
+	initial := getClient()
+
+	unavailable := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "connection refused")
+	}
+	err := r.lazyReconnect(context.Background(), "/api.Dgraph/Query", &api.Request{}, &api.Response{}, a, unavailable)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("lazyReconnect() = %v, want the call's error", err)
+	}
+
+	r.mu.Lock()
+	down := r.down[a]
+	r.mu.Unlock()
+	if !down || getClient() == initial {
+		t.Errorf("connection that didn't come back: down %v, client replaced %v; want both", down, getClient() != initial)
+	}
+
+	// A call canceled by its caller says nothing about the connection
+	r2, other, _ := trackedAlphas(t, time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r2.lazyReconnect(ctx, "/api.Dgraph/Query", &api.Request{}, &api.Response{}, other, unavailable)
+	if r2.down[other] {
+		t.Errorf("canceled call left its connection out")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Default maximum traversal depth for the recurse tool
+const defaultMaxRecurseDepth = 10
+
+// Build a depth-limited traversal from a node along an edge predicate
+func buildRecurseQuery(uid, predicate string, depth int, fields []string) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		if err := validateName("field", f); err != nil {
+			return "", err
+		}
+	}
+
+	selection := append([]string{"uid"}, fields...)
+	selection = append(selection, predicate)
+
+	return fmt.Sprintf(`{
+	q(func: uid(%s)) @recurse(depth: %d, loop: false) {
+		%s
+	}
+}`, uid, depth, strings.Join(selection, "\n\t\t")), nil
+}
+
+// Create handler for the recurse tool
+func createRecurseHandler(client *dgo.Dgraph, maxDepth int) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := uidArgument(request, "uid")
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+
+		depth, err := intArgument(request, "depth", 0)
+		if err != nil {
+			return nil, err
+		}
+		if depth < 1 {
+			return nil, fmt.Errorf("depth must be a positive integer")
+		}
+		if depth > maxDepth {
+			return nil, fmt.Errorf("depth %d exceeds the maximum of %d", depth, maxDepth)
+		}
+
+		fields, err := stringsArgument(request, "fields")
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := buildRecurseQuery(uid, predicate, depth, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
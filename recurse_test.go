@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBuildRecurseQuery(t *testing.T) {
+	query, err := buildRecurseQuery("0x1", "friend", 3, []string{"name", "age"})
+	if err != nil {
+		t.Fatalf("buildRecurseQuery() failed: %v", err)
+	}
+	want := "{\n\tq(func: uid(0x1)) @recurse(depth: 3, loop: false) {\n\t\tuid\n\t\tname\n\t\tage\n\t\tfriend\n\t}\n}"
+	if query != want {
+		t.Errorf("buildRecurseQuery() =\n%s\nwant\n%s", query, want)
+	}
+
+	// The depth bounds the traversal, so the query isn't a costly one
+	cost, err := estimateQueryCost(query)
+	if err != nil || cost.Risk != costLow {
+		t.Errorf("estimateQueryCost() = %+v, %v; want a low risk", cost, err)
+	}
+
+	// Cycles are never followed back, whatever the fields
+	bare, err := buildRecurseQuery("0x2", "parent", 1, nil)
+	if err != nil {
+		t.Fatalf("buildRecurseQuery() failed: %v", err)
+	}
+	if !strings.Contains(bare, "@recurse(depth: 1, loop: false)") || !strings.Contains(bare, "uid\n\t\tparent\n") {
+		t.Errorf("buildRecurseQuery() without fields = %s", bare)
+	}
+}
+
+func TestBuildRecurseQueryErrors(t *testing.T) {
+	if _, err := buildRecurseQuery("0x1", "friend } q2(func: has(password)) {", 2, nil); err == nil || !strings.Contains(err.Error(), "invalid predicate") {
+		t.Errorf("buildRecurseQuery() with an invalid predicate error = %v", err)
+	}
+	if _, err := buildRecurseQuery("0x1", "friend", 2, []string{"name", "password }"}); err == nil || !strings.Contains(err.Error(), "invalid field") {
+		t.Errorf("buildRecurseQuery() with an invalid field error = %v", err)
+	}
+}
+
+func TestRecurseHandlerArguments(t *testing.T) {
+	handler := createRecurseHandler(dgo.NewDgraphClient(), 5)
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing uid", map[string]interface{}{"predicate": "friend", "depth": float64(2)}, "uid"},
+		{"missing predicate", map[string]interface{}{"uid": "0x1", "depth": float64(2)}, "predicate must be a string"},
+		{"missing depth", map[string]interface{}{"uid": "0x1", "predicate": "friend"}, "depth must be a positive integer"},
+		{"zero depth", map[string]interface{}{"uid": "0x1", "predicate": "friend", "depth": float64(0)}, "depth must be a positive integer"},
+		{"fractional depth", map[string]interface{}{"uid": "0x1", "predicate": "friend", "depth": 2.5}, "depth"},
+		{"depth over the maximum", map[string]interface{}{"uid": "0x1", "predicate": "friend", "depth": float64(6)}, "exceeds the maximum of 5"},
+		{"invalid field", map[string]interface{}{"uid": "0x1", "predicate": "friend", "depth": float64(2), "fields": []interface{}{"name)"}}, "invalid field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = tt.args
+			_, err := handler(context.Background(), request)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("handler error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Format a regular expression as a DQL regex literal, /pattern/ or
+// /pattern/i. Dgraph matches with Go's RE2 syntax, so the pattern is
+// checked by compiling it. Unescaped slashes are escaped so that they
+// can't end the literal.
+func regexLiteral(pattern string, caseInsensitive bool) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("pattern must not be empty")
+	}
+	if strings.ContainsAny(pattern, "\r\n") {
+		return "", fmt.Errorf(`pattern must be on one line; match line breaks with \n`)
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", fmt.Errorf("invalid pattern: %v", err)
+	}
+
+	var b strings.Builder
+	b.WriteByte('/')
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '\\':
+			// Compiling succeeded, so a backslash is never the last byte
+			b.WriteByte(c)
+			i++
+			b.WriteByte(pattern[i])
+		case '/':
+			b.WriteString(`\/`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('/')
+	if caseInsensitive {
+		b.WriteByte('i')
+	}
+	return b.String(), nil
+}
+
+// Build a regular expression search query. Without fields the searched
+// predicate is returned.
+func buildRegexSearchQuery(predicate, pattern string, caseInsensitive bool, typeName string, fields []string, first, offset int) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	if typeName != "" {
+		if err := validateName("type", typeName); err != nil {
+			return "", err
+		}
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", err
+		}
+	}
+	literal, err := regexLiteral(pattern, caseInsensitive)
+	if err != nil {
+		return "", err
+	}
+
+	args := fmt.Sprintf("func: regexp(%s, %s)", predicate, literal)
+	if first > 0 {
+		args += fmt.Sprintf(", first: %d", first)
+	}
+	if offset > 0 {
+		args += fmt.Sprintf(", offset: %d", offset)
+	}
+	filter := ""
+	if typeName != "" {
+		filter = fmt.Sprintf(" @filter(type(%s))", typeName)
+	}
+	if len(fields) == 0 {
+		fields = []string{predicate}
+	}
+
+	return fmt.Sprintf(`{
+	q(%s)%s {
+		uid
+		%s
+	}
+}`, args, filter, strings.Join(fields, "\n\t\t")), nil
+}
+
+// Create handler for the regular expression search tool
+func createRegexSearchHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+		pattern, ok := request.Params.Arguments["pattern"].(string)
+		if !ok {
+			return nil, fmt.Errorf("pattern must be a string")
+		}
+		caseInsensitive, err := boolArgument(request, "case_insensitive", false)
+		if err != nil {
+			return nil, err
+		}
+
+		typeName := ""
+		if v, ok := request.Params.Arguments["type"]; ok {
+			if typeName, ok = v.(string); !ok {
+				return nil, fmt.Errorf("type must be a string")
+			}
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			fields = []string{predicate}
+		}
+		if fields, err = langFields(ctx, client, request, fields, "fields"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", 0)
+		if err != nil {
+			return nil, err
+		}
+		offset, err := intArgument(request, "offset", 0)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 || offset < 0 {
+			return nil, fmt.Errorf("first and offset must not be negative")
+		}
+
+		query, err := buildRegexSearchQuery(predicate, pattern, caseInsensitive, typeName, fields, first, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		// regexp needs a trigram index, name the schema line adding it
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIndexedPredicate(schema, predicate, "regexp"); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexLiteral(t *testing.T) {
+	tests := []struct {
+		name            string
+		pattern         string
+		caseInsensitive bool
+		want            string
+		wantErr         string
+	}{
+		{"plain", "^Star.*Wars$", false, "/^Star.*Wars$/", ""},
+		{"case insensitive", "wars", true, "/wars/i", ""},
+		{"slash", "AC/DC", false, `/AC\/DC/`, ""},
+		{"escaped slash", `AC\/DC`, false, `/AC\/DC/`, ""},
+		{"escapes", `\d+\.\d+`, false, `/\d+\.\d+/`, ""},
+		{"escaped backslash then slash", `a\\/b`, false, `/a\\\/b/`, ""},
+		{"empty", "", false, "", "must not be empty"},
+		{"invalid", "(abc", false, "", "invalid pattern"},
+		{"trailing backslash", `abc\`, false, "", "invalid pattern"},
+		{"line break", "abc\ndef", false, "", "one line"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := regexLiteral(tt.pattern, tt.caseInsensitive)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("regexLiteral() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("regexLiteral() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildRegexSearchQuery(t *testing.T) {
+	query, err := buildRegexSearchQuery("title", "^star wars", true, "Movie", []string{"title", "release_year"}, 10, 0)
+	if err != nil {
+		t.Fatalf("buildRegexSearchQuery() failed: %v", err)
+	}
+	want := "{\n\tq(func: regexp(title, /^star wars/i), first: 10) @filter(type(Movie)) {\n\t\tuid\n\t\ttitle\n\t\trelease_year\n\t}\n}"
+	if query != want {
+		t.Errorf("buildRegexSearchQuery() =\n%s\nwant\n%s", query, want)
+	}
+
+	if _, err := buildRegexSearchQuery("title) { uid } x(func: has(a)", "abc", false, "", nil, 0, 0); err == nil {
+		t.Errorf("buildRegexSearchQuery() accepted an invalid predicate")
+	}
+}
+
+func TestRegexIndexCheck(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "title", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"trigram"}},
+		{Predicate: "age", Type: "int"},
+	}}
+	if err := checkIndexedPredicate(schema, "name", "regexp"); err != nil {
+		t.Errorf("checkIndexedPredicate(name) = %v, want nil", err)
+	}
+	if err := checkIndexedPredicate(schema, "title", "regexp"); err == nil || !strings.Contains(err.Error(), "title: string @index(exact, trigram) .") {
+		t.Errorf("checkIndexedPredicate(title) = %v, want a schema suggestion", err)
+	}
+	if err := checkIndexedPredicate(schema, "age", "regexp"); err == nil || !strings.Contains(err.Error(), "cannot be used") {
+		t.Errorf("checkIndexedPredicate(age) = %v, want a type error", err)
+	}
+}
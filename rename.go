@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// renameBatchSize is the number of nodes copied per transaction when a
+// predicate can't be copied by a single upsert
+const renameBatchSize = 1000
+
+// Plan the schema of a renamed predicate: the new predicate's definition,
+// copied from the old one, and the types listing the old predicate with the
+// new one added, or replacing it if the old one is dropped
+func planRename(schema *schemaInfo, from, to string, drop bool) (string, []string, error) {
+	if err := validateName("predicate", from); err != nil {
+		return "", nil, err
+	}
+	if err := validateName("predicate", to); err != nil {
+		return "", nil, err
+	}
+	if from == to {
+		return "", nil, fmt.Errorf("from and to are the same predicate")
+	}
+	if isInternalName(from) || isInternalName(to) {
+		return "", nil, fmt.Errorf("Dgraph's own predicates can't be renamed")
+	}
+
+	p, ok := schema.predicate(from)
+	if !ok {
+		return "", nil, fmt.Errorf("predicate %q is not in the schema", from)
+	}
+	if p.Type == "password" {
+		return "", nil, fmt.Errorf("predicate %q has type password, whose values can't be read to copy them", from)
+	}
+	renamed := p
+	renamed.Predicate = to
+	if existing, ok := schema.predicate(to); ok && existing.String() != renamed.String() {
+		return "", nil, fmt.Errorf("predicate %q already exists as %s, which doesn't match %s", to, existing.String(), p.String())
+	}
+
+	var types []string
+	for _, t := range schema.Types {
+		var fields []string
+		found := false
+		for _, f := range t.Fields {
+			switch f.Name {
+			case from:
+				found = true
+				if !drop {
+					fields = append(fields, from)
+				}
+				fields = append(fields, to)
+			case to:
+				// Already listed next to from
+			default:
+				fields = append(fields, f.Name)
+			}
+		}
+		if found {
+			types = append(types, fmt.Sprintf("type %s {\n\t%s\n}", t.Name, strings.Join(fields, "\n\t")))
+		}
+	}
+	return renamed.String(), types, nil
+}
+
+// Check whether a predicate can be copied by one upsert with a value
+// variable, which only carries a single untagged scalar per node
+func copiesByUpsert(p predicateSchema) bool {
+	return p.Type != "uid" && !p.List && !p.Lang
+}
+
+// Build the upsert copying a single-valued scalar predicate
+func buildRenameUpsert(from, to string) *api.Request {
+	return &api.Request{
+		Query: fmt.Sprintf(`{
+	q(func: has(<%s>)) {
+		v as uid
+		f as <%s>
+	}
+}`, from, from),
+		Mutations: []*api.Mutation{{SetNquads: []byte(fmt.Sprintf("uid(v) <%s> val(f) .", to))}},
+		CommitNow: true,
+	}
+}
+
+// Build the query reading a page of nodes with the old predicate
+func buildRenamePageQuery(p predicateSchema, after string) string {
+	selection := "<" + p.Predicate + ">"
+	switch {
+	case p.Type == "uid":
+		selection += " { uid }"
+	case p.Lang:
+		selection += "@*"
+	}
+	args := fmt.Sprintf("first: %d", renameBatchSize)
+	if after != "" {
+		args += ", after: " + after
+	}
+	return fmt.Sprintf(`{
+	q(func: has(<%s>), %s) {
+		uid
+		%s
+	}
+}`, p.Predicate, args, selection)
+}
+
+// Turn a page of nodes into JSON mutation objects setting the values of
+// the old predicate on the new one. Language-tagged keys such as name@en
+// keep their tag. Returns the objects and the uid of the last node.
+func renamePageObjects(data []byte, from, to string) ([]map[string]interface{}, string, error) {
+	var page struct {
+		Q []map[string]json.RawMessage `json:"q"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, "", fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	objects := make([]map[string]interface{}, 0, len(page.Q))
+	last := ""
+	for _, node := range page.Q {
+		var uid string
+		if err := json.Unmarshal(node["uid"], &uid); err != nil {
+			return nil, "", fmt.Errorf("node without uid in query response")
+		}
+		last = uid
+
+		obj := map[string]interface{}{"uid": uid}
+		for key, value := range node {
+			name, lang, _ := strings.Cut(key, "@")
+			if name != from {
+				continue
+			}
+			if lang != "" {
+				obj[to+"@"+lang] = value
+			} else {
+				obj[to] = value
+			}
+		}
+		objects = append(objects, obj)
+	}
+	return objects, last, nil
+}
+
+// Copy a predicate page by page, each page in its own transaction. Returns
+// the number of nodes copied.
+func copyPredicatePages(ctx context.Context, client *dgo.Dgraph, p predicateSchema, to string) (int, error) {
+	copied := 0
+	after := ""
+	for {
+		txn := client.NewReadOnlyTxn()
+		resp, err := txn.Query(ctx, buildRenamePageQuery(p, after))
+		txn.Discard(ctx)
+		if err != nil {
+			return copied, fmt.Errorf("failed to read %s: %v", p.Predicate, err)
+		}
+		objects, last, err := renamePageObjects(resp.Json, p.Predicate, to)
+		if err != nil {
+			return copied, err
+		}
+		if len(objects) == 0 {
+			return copied, nil
+		}
+
+		setJSON, err := json.Marshal(objects)
+		if err != nil {
+			return copied, fmt.Errorf("failed to encode mutation: %v", err)
+		}
+		err = func() error {
+			txn := activity.startTxn(client.NewTxn())
+			defer activity.finishTxn(ctx, txn)
+			_, err := txn.Mutate(ctx, &api.Mutation{SetJson: setJSON, CommitNow: true})
+			return err
+		}()
+		if err != nil {
+			return copied, fmt.Errorf("failed to copy %s after %d nodes: %v", p.Predicate, copied, err)
+		}
+		copied += len(objects)
+		after = last
+	}
+}
+
+// Count the nodes having each of two predicates
+func countPredicates(ctx context.Context, client *dgo.Dgraph, a, b string) (int, int, error) {
+	txn := client.NewReadOnlyTxn()
+	defer txn.Discard(ctx)
+
+	resp, err := txn.Query(ctx, fmt.Sprintf(`{
+	a(func: has(<%s>)) { count(uid) }
+	b(func: has(<%s>)) { count(uid) }
+}`, a, b))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count nodes: %v", err)
+	}
+	var counts struct {
+		A []struct {
+			Count int `json:"count"`
+		} `json:"a"`
+		B []struct {
+			Count int `json:"count"`
+		} `json:"b"`
+	}
+	if err := json.Unmarshal(resp.Json, &counts); err != nil || len(counts.A) == 0 || len(counts.B) == 0 {
+		return 0, 0, fmt.Errorf("failed to parse counts: %s", resp.Json)
+	}
+	return counts.A[0].Count, counts.B[0].Count, nil
+}
+
+// Create handler for the rename predicate tool
+func createRenamePredicateHandler(client *dgo.Dgraph, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		from, ok := request.Params.Arguments["from"].(string)
+		if !ok {
+			return nil, fmt.Errorf("from must be a string")
+		}
+		to, ok := request.Params.Arguments["to"].(string)
+		if !ok {
+			return nil, fmt.Errorf("to must be a string")
+		}
+		drop, err := boolArgument(request, "drop_old", false)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range []string{from, to} {
+			if err := predicateAccess.check(name); err != nil {
+				return nil, err
+			}
+		}
+
+		// Plan against the live schema, not a cached copy
+		schema, err := fetchSchema(ctx, client, true)
+		if err != nil {
+			return nil, err
+		}
+		definition, types, err := planRename(schema, from, to, drop)
+		if err != nil {
+			return nil, err
+		}
+		p, _ := schema.predicate(from)
+
+		// Create the new predicate with the old one's type and indexes,
+		// snapshotting the schema before each alteration
+		if err := snapshotSchema(ctx, client, "dgraph_rename_predicate"); err != nil {
+			return nil, err
+		}
+		if err := alterSchema(ctx, client, definition); err != nil {
+			return nil, err
+		}
+
+		method := "batches"
+		if copiesByUpsert(p) {
+			method = "upsert"
+			if _, attempts, err := runUpsert(ctx, client, retry, buildRenameUpsert(from, to)); err != nil {
+				return nil, fmt.Errorf("copying %s failed after %d attempts: %v", from, attempts, err)
+			}
+		} else if _, err := copyPredicatePages(ctx, client, p, to); err != nil {
+			invalidateCaches()
+			return nil, err
+		}
+		invalidateCaches()
+
+		// Only drop the old predicate once every node has the new one
+		fromCount, toCount, err := countPredicates(ctx, client, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if toCount < fromCount {
+			return nil, fmt.Errorf("copied %s to %s but only %d of %d nodes have %s; %s was kept", from, to, toCount, fromCount, to, from)
+		}
+
+		// List the new predicate in the types listing the old one
+		if len(types) > 0 {
+			if err := snapshotSchema(ctx, client, "dgraph_rename_predicate"); err != nil {
+				return nil, err
+			}
+			if err := alterSchema(ctx, client, strings.Join(types, "\n")); err != nil {
+				return nil, err
+			}
+		}
+		if drop {
+			if err := snapshotSchema(ctx, client, "dgraph_rename_predicate"); err != nil {
+				return nil, err
+			}
+			err := client.Alter(ctx, &api.Operation{DropAttr: from})
+			invalidateCaches()
+			if err != nil {
+				return nil, fmt.Errorf("copied %s to %s but failed to drop %s: %v", from, to, from, err)
+			}
+		}
+
+		out, err := json.Marshal(map[string]interface{}{
+			"message": fmt.Sprintf("Copied %s to %s on %d nodes", from, to, toCount),
+			"copied":  toCount,
+			"method":  method,
+			"schema":  definition,
+			"types":   types,
+			"dropped": drop,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
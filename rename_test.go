@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanRename(t *testing.T) {
+	schema := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "fullname", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+			{Predicate: "name", Type: "string"},
+			{Predicate: "age", Type: "int"},
+			{Predicate: "secret", Type: "password"},
+			{Predicate: "dgraph.type", Type: "string"},
+		},
+		Types: []typeSchema{
+			{Name: "Person", Fields: []typeField{{Name: "fullname"}, {Name: "age"}}},
+			{Name: "Pet", Fields: []typeField{{Name: "age"}}},
+		},
+	}
+
+	definition, types, err := planRename(schema, "fullname", "title", false)
+	if err != nil {
+		t.Fatalf("planRename() error = %v", err)
+	}
+	if definition != "title: string @index(exact) ." {
+		t.Errorf("planRename() definition = %q", definition)
+	}
+	if want := []string{"type Person {\n\tfullname\n\ttitle\n\tage\n}"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("planRename() types = %q, want %q", types, want)
+	}
+
+	if _, types, err = planRename(schema, "fullname", "title", true); err != nil {
+		t.Fatalf("planRename() error = %v", err)
+	}
+	if want := []string{"type Person {\n\ttitle\n\tage\n}"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("planRename() types = %q, want %q", types, want)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		from, to string
+	}{
+		{"same", "age", "age"},
+		{"missing", "nickname", "title"},
+		{"password", "secret", "pin"},
+		{"mismatched target", "fullname", "name"},
+		{"internal", "dgraph.type", "kind"},
+		{"invalid name", "age", "bad name"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := planRename(schema, tt.from, tt.to, false); err == nil {
+				t.Errorf("planRename(%q, %q) expected error", tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestCopiesByUpsert(t *testing.T) {
+	tests := []struct {
+		p    predicateSchema
+		want bool
+	}{
+		{predicateSchema{Type: "string"}, true},
+		{predicateSchema{Type: "int", Index: true}, true},
+		{predicateSchema{Type: "uid"}, false},
+		{predicateSchema{Type: "string", List: true}, false},
+		{predicateSchema{Type: "string", Lang: true}, false},
+	}
+	for _, tt := range tests {
+		if got := copiesByUpsert(tt.p); got != tt.want {
+			t.Errorf("copiesByUpsert(%+v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestBuildRenamePageQuery(t *testing.T) {
+	tests := []struct {
+		p     predicateSchema
+		after string
+		want  []string
+	}{
+		{predicateSchema{Predicate: "friend", Type: "uid", List: true}, "", []string{"has(<friend>), first: 1000)", "<friend> { uid }"}},
+		{predicateSchema{Predicate: "name", Type: "string", Lang: true}, "0x2a", []string{"first: 1000, after: 0x2a", "<name>@*"}},
+		{predicateSchema{Predicate: "tags", Type: "string", List: true}, "", []string{"\t\t<tags>\n"}},
+	}
+	for _, tt := range tests {
+		got := buildRenamePageQuery(tt.p, tt.after)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("buildRenamePageQuery(%s) = %q, want it to contain %q", tt.p.Predicate, got, want)
+			}
+		}
+	}
+}
+
+func TestRenamePageObjects(t *testing.T) {
+	data := []byte(`{"q": [
+		{"uid": "0x1", "name@en": "Paris", "name@fr": "Paris", "name": "Paris"},
+		{"uid": "0x2", "friend": [{"uid": "0x3"}], "nickname": "ignored"}
+	]}`)
+	objects, last, err := renamePageObjects(data, "name", "title")
+	if err != nil {
+		t.Fatalf("renamePageObjects() error = %v", err)
+	}
+	if last != "0x2" {
+		t.Errorf("renamePageObjects() last = %q, want 0x2", last)
+	}
+	got, _ := json.Marshal(objects)
+	want := `[{"title":"Paris","title@en":"Paris","title@fr":"Paris","uid":"0x1"},{"uid":"0x2"}]`
+	if string(got) != want {
+		t.Errorf("renamePageObjects() = %s, want %s", got, want)
+	}
+
+	if objects, _, err = renamePageObjects(data, "friend", "knows"); err != nil {
+		t.Fatalf("renamePageObjects() error = %v", err)
+	}
+	got, _ = json.Marshal(objects[1])
+	if want := `{"knows":[{"uid":"0x3"}],"uid":"0x2"}`; string(got) != want {
+		t.Errorf("renamePageObjects() = %s, want %s", got, want)
+	}
+
+	if _, _, err := renamePageObjects([]byte(`{"q": [{"name": "x"}]}`), "name", "title"); err == nil {
+		t.Errorf("renamePageObjects() expected error for a node without uid")
+	}
+}
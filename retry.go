@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+// Default retry settings for upserts aborted by conflicting transactions
+const (
+	defaultUpsertRetries = 5
+	upsertRetryBase      = 50 * time.Millisecond
+	upsertRetryMax       = 2 * time.Second
+)
+
+// retryPolicy re-runs operations Dgraph aborts because of a conflicting
+// transaction, waiting a random delay of up to base*2^n between attempts
+// so that clients contending for the same nodes spread out
+type retryPolicy struct {
+	retries int
+	base    time.Duration
+	max     time.Duration
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// Create a retry policy allowing retries attempts after the first one
+func newRetryPolicy(retries int) retryPolicy {
+	return retryPolicy{
+		retries: retries,
+		base:    upsertRetryBase,
+		max:     upsertRetryMax,
+		sleep:   sleepContext,
+	}
+}
+
+// Run fn until it succeeds, fails with an error other than an abort, or
+// runs out of retries. fn must start a new transaction on every call.
+// Returns the number of attempts made.
+func (p retryPolicy) do(ctx context.Context, fn func() error) (int, error) {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !errors.Is(err, dgo.ErrAborted) || attempt > p.retries {
+			return attempt, err
+		}
+
+		delay := p.base << (attempt - 1)
+		if delay > p.max || delay <= 0 {
+			delay = p.max
+		}
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+		slog.Debug("Transaction aborted, retrying", "attempt", attempt, "delay", delay)
+		if err := p.sleep(ctx, delay); err != nil {
+			return attempt, err
+		}
+	}
+}
+
+// Wait for a duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
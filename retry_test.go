@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	var delays []time.Duration
+	p := newRetryPolicy(3)
+	p.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	tests := []struct {
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{0, nil, 1, false},
+		{2, dgo.ErrAborted, 3, false},
+		{10, dgo.ErrAborted, 4, true},
+		{10, fmt.Errorf("upsert failed: %w", dgo.ErrAborted), 4, true},
+		{10, errors.New("syntax error"), 1, true},
+	}
+	for _, tt := range tests {
+		delays = nil
+		calls := 0
+		attempts, err := p.do(context.Background(), func() error {
+			calls++
+			if calls <= tt.failures {
+				return tt.err
+			}
+			return nil
+		})
+		if attempts != tt.wantAttempts || attempts != calls || (err != nil) != tt.wantErr {
+			t.Errorf("failures %d, err %v: attempts = %d, calls = %d, err = %v", tt.failures, tt.err, attempts, calls, err)
+		}
+		for i, d := range delays {
+			if max := upsertRetryBase << i; d < 0 || d > max {
+				t.Errorf("delay %d = %v, want at most %v", i, d, max)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts, err := newRetryPolicy(5).do(ctx, func() error { return dgo.ErrAborted })
+	if attempts != 1 || !errors.Is(err, context.Canceled) {
+		t.Errorf("do with cancelled context = %d, %v, want 1, context.Canceled", attempts, err)
+	}
+}
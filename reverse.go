@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Build a query listing the nodes pointing at a node through a predicate
+func buildReverseQuery(uid, predicate string, fields []string, first int) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", err
+		}
+	}
+
+	selection := "uid dgraph.type expand(_all_)"
+	if len(fields) > 0 {
+		selection = "uid " + strings.Join(fields, " ")
+	}
+	args := ""
+	if first > 0 {
+		args = fmt.Sprintf("(first: %d)", first)
+	}
+
+	return fmt.Sprintf(`{
+	q(func: uid(%s)) {
+		uid
+		~%s%s {
+			%s
+		}
+	}
+}`, uid, predicate, args, selection), nil
+}
+
+// Check that a predicate exists and can be traversed in reverse
+func checkReversePredicate(schema *schemaInfo, predicate string) error {
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		return fmt.Errorf("predicate %q is not in the schema", predicate)
+	}
+	if p.Type != "uid" {
+		return fmt.Errorf("predicate %q has type %s; only uid predicates can be traversed in reverse", predicate, p.Type)
+	}
+	if !p.Reverse {
+		return fmt.Errorf("predicate %q has no @reverse index; add it with dgraph_alter_schema, e.g. %s: [uid] @reverse .", predicate, predicate)
+	}
+	return nil
+}
+
+// Create handler for the reverse query tool
+func createReverseQueryHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		uid, err := uidArgument(request, "uid")
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if fields, err = langFields(ctx, client, request, fields, "fields"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", 0)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 {
+			return nil, fmt.Errorf("first must not be negative")
+		}
+
+		query, err := buildReverseQuery(uid, predicate, fields, first)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkReversePredicate(schema, predicate); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildReverseQuery(t *testing.T) {
+	got, err := buildReverseQuery("0x1", "friend", nil, 0)
+	if err != nil {
+		t.Fatalf("buildReverseQuery failed: %v", err)
+	}
+	if !strings.Contains(got, "uid(0x1)") || !strings.Contains(got, "~friend {") || !strings.Contains(got, "expand(_all_)") {
+		t.Errorf("buildReverseQuery = %s", got)
+	}
+
+	got, err = buildReverseQuery("0x1", "friend", []string{"name"}, 10)
+	if err != nil {
+		t.Fatalf("buildReverseQuery failed: %v", err)
+	}
+	if !strings.Contains(got, "~friend(first: 10) {") || !strings.Contains(got, "uid name") || strings.Contains(got, "expand") {
+		t.Errorf("buildReverseQuery with fields = %s", got)
+	}
+
+	if _, err := buildReverseQuery("0x1", "friend }", nil, 0); err == nil {
+		t.Errorf("buildReverseQuery with invalid predicate succeeded, want error")
+	}
+}
+
+func TestCheckReversePredicate(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "friend", Type: "uid", Reverse: true},
+		{Predicate: "owner", Type: "uid"},
+		{Predicate: "name", Type: "string"},
+	}}
+
+	tests := []struct {
+		predicate string
+		want      string
+	}{
+		{"friend", ""},
+		{"owner", "no @reverse"},
+		{"name", "only uid predicates"},
+		{"missing", "not in the schema"},
+	}
+	for _, tt := range tests {
+		err := checkReversePredicate(schema, tt.predicate)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("checkReversePredicate(%q) failed: %v", tt.predicate, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("checkReversePredicate(%q) = %v, want error containing %q", tt.predicate, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// predicateSchema is a single predicate definition as reported by `schema {}`
+type predicateSchema struct {
+	Predicate string   `json:"predicate"`
+	Type      string   `json:"type"`
+	Index     bool     `json:"index,omitempty"`
+	Tokenizer []string `json:"tokenizer,omitempty"`
+	Reverse   bool     `json:"reverse,omitempty"`
+	Count     bool     `json:"count,omitempty"`
+	List      bool     `json:"list,omitempty"`
+	Upsert    bool     `json:"upsert,omitempty"`
+	Lang      bool     `json:"lang,omitempty"`
+}
+
+// typeField is a field of a type definition
+type typeField struct {
+	Name string `json:"name"`
+}
+
+// typeSchema is a type definition as reported by `schema {}`
+type typeSchema struct {
+	Name   string      `json:"name"`
+	Fields []typeField `json:"fields"`
+}
+
+// schemaInfo is the full schema as reported by `schema {}`
+type schemaInfo struct {
+	Predicates []predicateSchema `json:"schema"`
+	Types      []typeSchema      `json:"types"`
+}
+
+// Look up a predicate by name
+func (s *schemaInfo) predicate(name string) (predicateSchema, bool) {
+	for _, p := range s.Predicates {
+		if p.Predicate == name {
+			return p, true
+		}
+	}
+	return predicateSchema{}, false
+}
+
+// Look up a type by name
+func (s *schemaInfo) typeDef(name string) (typeSchema, bool) {
+	for _, t := range s.Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return typeSchema{}, false
+}
+
+// Load the current schema via introspection, returning both the raw JSON
+// response and its parsed form
+func loadSchema(ctx context.Context, client *dgo.Dgraph) ([]byte, *schemaInfo, error) {
+	txn := client.NewReadOnlyTxn()
+	defer txn.Discard(ctx)
+
+	resp, err := txn.Query(ctx, "schema {}")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get schema: %v", err)
+	}
+
+	var schema schemaInfo
+	if err := json.Unmarshal(resp.Json, &schema); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse schema: %v", err)
+	}
+	return resp.Json, &schema, nil
+}
+
+// Format a predicate as a schema definition line
+func (p predicateSchema) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: ", p.Predicate)
+	if p.List {
+		fmt.Fprintf(&b, "[%s]", p.Type)
+	} else {
+		b.WriteString(p.Type)
+	}
+	if p.Index {
+		fmt.Fprintf(&b, " @index(%s)", strings.Join(p.Tokenizer, ", "))
+	}
+	if p.Reverse {
+		b.WriteString(" @reverse")
+	}
+	if p.Count {
+		b.WriteString(" @count")
+	}
+	if p.Upsert {
+		b.WriteString(" @upsert")
+	}
+	if p.Lang {
+		b.WriteString(" @lang")
+	}
+	b.WriteString(" .")
+
+	return b.String()
+}
+
+var (
+	schemaCommentRe   = regexp.MustCompile(`#[^\n]*`)
+	schemaTypeRe      = regexp.MustCompile(`(?s)type\s+<?([\w.]+)>?\s*\{(.*?)\}`)
+	schemaFieldTypeRe = regexp.MustCompile(`:\s*\[?\s*\w+\s*\]?`)
+	schemaDirectiveRe = regexp.MustCompile(`@(\w+)(?:\(([^)]*)\))?`)
+)
+
+// Parse a DQL schema definition into the same shape introspection returns
+func parseSchema(text string) (*schemaInfo, error) {
+	schema := &schemaInfo{}
+	text = schemaCommentRe.ReplaceAllString(text, "")
+
+	// Extract type blocks first, they may span multiple lines
+	for _, m := range schemaTypeRe.FindAllStringSubmatch(text, -1) {
+		t := typeSchema{Name: m[1]}
+		// Old-style type fields are written as `name: type`, drop the type
+		for _, field := range strings.Fields(schemaFieldTypeRe.ReplaceAllString(m[2], "")) {
+			t.Fields = append(t.Fields, typeField{Name: strings.Trim(field, "<>")})
+		}
+		schema.Types = append(schema.Types, t)
+	}
+	text = schemaTypeRe.ReplaceAllString(text, "")
+
+	// Every remaining statement is a predicate definition terminated by " ."
+	for _, stmt := range splitSchemaStatements(text) {
+		p, err := parsePredicate(stmt)
+		if err != nil {
+			return nil, err
+		}
+		schema.Predicates = append(schema.Predicates, p)
+	}
+
+	return schema, nil
+}
+
+// Split predicate definitions on their terminating dot
+func splitSchemaStatements(text string) []string {
+	var stmts []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		for _, stmt := range strings.SplitAfter(line, " .") {
+			stmt = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(stmt), "."))
+			if stmt != "" {
+				stmts = append(stmts, stmt)
+			}
+		}
+	}
+	return stmts
+}
+
+// Parse a single predicate definition without its terminating dot
+func parsePredicate(stmt string) (predicateSchema, error) {
+	name, rest, ok := strings.Cut(stmt, ":")
+	if !ok {
+		return predicateSchema{}, fmt.Errorf("invalid predicate definition %q: missing ':'", stmt)
+	}
+
+	p := predicateSchema{Predicate: strings.Trim(strings.TrimSpace(name), "<>")}
+	if p.Predicate == "" {
+		return predicateSchema{}, fmt.Errorf("invalid predicate definition %q: missing name", stmt)
+	}
+
+	rest = strings.TrimSpace(rest)
+	typ := rest
+	if i := strings.Index(rest, "@"); i >= 0 {
+		typ = strings.TrimSpace(rest[:i])
+		rest = rest[i:]
+	} else {
+		rest = ""
+	}
+	if strings.HasPrefix(typ, "[") && strings.HasSuffix(typ, "]") {
+		p.List = true
+		typ = strings.TrimSpace(typ[1 : len(typ)-1])
+	}
+	if !isSchemaTypeName(typ) {
+		return predicateSchema{}, fmt.Errorf("invalid predicate definition %q: unknown type %q", stmt, typ)
+	}
+	p.Type = typ
+
+	for _, m := range schemaDirectiveRe.FindAllStringSubmatch(rest, -1) {
+		switch m[1] {
+		case "index":
+			p.Index = true
+			for _, tok := range strings.Split(m[2], ",") {
+				if tok = strings.TrimSpace(tok); tok != "" {
+					p.Tokenizer = append(p.Tokenizer, tok)
+				}
+			}
+		case "reverse":
+			p.Reverse = true
+		case "count":
+			p.Count = true
+		case "upsert":
+			p.Upsert = true
+		case "lang":
+			p.Lang = true
+		default:
+			return predicateSchema{}, fmt.Errorf("invalid predicate definition %q: unknown directive @%s", stmt, m[1])
+		}
+	}
+
+	return p, nil
+}
+
+// Check whether a name is one of Dgraph's scalar or uid types
+func isSchemaTypeName(name string) bool {
+	switch name {
+	case "default", "int", "float", "string", "bool", "datetime", "geo", "password", "uid":
+		return true
+	}
+	return false
+}
+
+// predicateChange describes how a predicate definition would change
+type predicateChange struct {
+	Predicate string   `json:"predicate"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Changes   []string `json:"changes"`
+	Reindex   bool     `json:"reindex"`
+}
+
+// typeChange describes how a type definition would change
+type typeChange struct {
+	Name          string   `json:"name"`
+	AddedFields   []string `json:"added_fields,omitempty"`
+	RemovedFields []string `json:"removed_fields,omitempty"`
+}
+
+// schemaDiff is the difference between a live and a proposed schema
+type schemaDiff struct {
+	AddedPredicates   []string          `json:"added_predicates"`
+	RemovedPredicates []string          `json:"removed_predicates"`
+	ChangedPredicates []predicateChange `json:"changed_predicates"`
+	AddedTypes        []string          `json:"added_types"`
+	RemovedTypes      []string          `json:"removed_types"`
+	ChangedTypes      []typeChange      `json:"changed_types"`
+}
+
+// Compare the live schema against a proposed one. Dgraph's internal
+// predicates and types are ignored since users never define them.
+func diffSchema(current, proposed *schemaInfo) schemaDiff {
+	diff := schemaDiff{
+		AddedPredicates:   []string{},
+		RemovedPredicates: []string{},
+		ChangedPredicates: []predicateChange{},
+		AddedTypes:        []string{},
+		RemovedTypes:      []string{},
+		ChangedTypes:      []typeChange{},
+	}
+
+	for _, p := range proposed.Predicates {
+		old, ok := current.predicate(p.Predicate)
+		if !ok {
+			diff.AddedPredicates = append(diff.AddedPredicates, p.Predicate)
+			continue
+		}
+		if change, changed := comparePredicates(old, p); changed {
+			diff.ChangedPredicates = append(diff.ChangedPredicates, change)
+		}
+	}
+	for _, p := range current.Predicates {
+		if isInternalName(p.Predicate) {
+			continue
+		}
+		if _, ok := proposed.predicate(p.Predicate); !ok {
+			diff.RemovedPredicates = append(diff.RemovedPredicates, p.Predicate)
+		}
+	}
+
+	for _, t := range proposed.Types {
+		old, ok := current.typeDef(t.Name)
+		if !ok {
+			diff.AddedTypes = append(diff.AddedTypes, t.Name)
+			continue
+		}
+		added, removed := diffFields(old.Fields, t.Fields)
+		if len(added) > 0 || len(removed) > 0 {
+			diff.ChangedTypes = append(diff.ChangedTypes, typeChange{Name: t.Name, AddedFields: added, RemovedFields: removed})
+		}
+	}
+	for _, t := range current.Types {
+		if isInternalName(t.Name) {
+			continue
+		}
+		if _, ok := proposed.typeDef(t.Name); !ok {
+			diff.RemovedTypes = append(diff.RemovedTypes, t.Name)
+		}
+	}
+
+	return diff
+}
+
+// Split off the removals of a diff, which an alteration never makes: the
+// predicates and types a proposed schema leaves out stay as they are.
+// Returns the diff without them and their names, types labeled as such.
+func (d schemaDiff) withoutRemovals() (schemaDiff, []string) {
+	unaffected := append(append([]string{}, d.RemovedPredicates...), typeNames(d.RemovedTypes)...)
+	d.RemovedPredicates, d.RemovedTypes = []string{}, []string{}
+	return d, unaffected
+}
+
+// List the changes that make Dgraph rebuild or drop an index
+func (d schemaDiff) reindexChanges() []string {
+	changes := []string{}
+	for _, c := range d.ChangedPredicates {
+		if !c.Reindex {
+			continue
+		}
+		for _, change := range c.Changes {
+			changes = append(changes, fmt.Sprintf("%s: %s", c.Predicate, change))
+		}
+	}
+	return changes
+}
+
+// Compare two definitions of the same predicate
+func comparePredicates(old, new predicateSchema) (predicateChange, bool) {
+	change := predicateChange{Predicate: new.Predicate, From: old.String(), To: new.String()}
+
+	if old.Type != new.Type || old.List != new.List {
+		change.Changes = append(change.Changes, "type changed")
+		change.Reindex = old.Index || new.Index
+	}
+	addedTok, removedTok := diffStrings(old.Tokenizer, new.Tokenizer)
+	for _, tok := range addedTok {
+		change.Changes = append(change.Changes, fmt.Sprintf("index %s added", tok))
+		change.Reindex = true
+	}
+	for _, tok := range removedTok {
+		change.Changes = append(change.Changes, fmt.Sprintf("index %s dropped", tok))
+		change.Reindex = true
+	}
+	for _, d := range []struct {
+		name     string
+		old, new bool
+	}{
+		{"reverse", old.Reverse, new.Reverse},
+		{"count", old.Count, new.Count},
+		{"upsert", old.Upsert, new.Upsert},
+		{"lang", old.Lang, new.Lang},
+	} {
+		switch {
+		case !d.old && d.new:
+			change.Changes = append(change.Changes, fmt.Sprintf("@%s added", d.name))
+		case d.old && !d.new:
+			change.Changes = append(change.Changes, fmt.Sprintf("@%s removed", d.name))
+		default:
+			continue
+		}
+		if d.name == "reverse" || d.name == "count" {
+			change.Reindex = true
+		}
+	}
+
+	return change, len(change.Changes) > 0
+}
+
+// Compare the field lists of two type definitions
+func diffFields(old, new []typeField) (added, removed []string) {
+	var oldNames, newNames []string
+	for _, f := range old {
+		oldNames = append(oldNames, f.Name)
+	}
+	for _, f := range new {
+		newNames = append(newNames, f.Name)
+	}
+	return diffStrings(oldNames, newNames)
+}
+
+// Return the sorted values only in new and only in old
+func diffStrings(old, new []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(old))
+	for _, v := range old {
+		oldSet[v] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, v := range new {
+		newSet[v] = true
+		if !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range old {
+		if !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// Check whether a predicate or type name belongs to Dgraph itself
+func isInternalName(name string) bool {
+	return strings.HasPrefix(name, "dgraph.")
+}
+
+// Create handler for the schema diff tool
+func createSchemaDiffHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, ok := request.Params.Arguments["schema"].(string)
+		if !ok {
+			return nil, fmt.Errorf("schema must be a string")
+		}
+
+		proposed, err := parseSchema(schema)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		current, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := json.Marshal(diffSchema(current, proposed))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode diff: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// predicateListing is a flat predicate entry for query autocompletion
+type predicateListing struct {
+	Predicate string   `json:"predicate"`
+	Type      string   `json:"type"`
+	List      bool     `json:"list"`
+	Edge      bool     `json:"edge"`
+	Index     []string `json:"index"`
+	Reverse   bool     `json:"reverse"`
+}
+
+// Create handler for the predicates resource
+func createPredicatesResourceHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := fetchSchema(ctx, client, false)
+		if err != nil {
+			return nil, err
+		}
+
+		listing := make([]predicateListing, 0, len(schema.Predicates))
+		for _, p := range schema.Predicates {
+			index := p.Tokenizer
+			if index == nil {
+				index = []string{}
+			}
+			listing = append(listing, predicateListing{
+				Predicate: p.Predicate,
+				Type:      p.Type,
+				List:      p.List,
+				Edge:      p.Type == "uid",
+				Index:     index,
+				Reverse:   p.Reverse,
+			})
+		}
+
+		out, err := json.Marshal(listing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode predicates: %v", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      "dgraph://predicates",
+				MIMEType: "application/json",
+				Text:     string(out),
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSchema(t *testing.T) {
+	schema, err := parseSchema(`
+		# people
+		name: string @index(exact, term) @upsert .
+		friend: [uid] @reverse @count .
+		<age>: int .
+		type Person {
+			name
+			friend
+			age
+		}
+	`)
+	if err != nil {
+		t.Fatalf("parseSchema failed: %v", err)
+	}
+
+	wantPreds := []predicateSchema{
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}, Upsert: true},
+		{Predicate: "friend", Type: "uid", List: true, Reverse: true, Count: true},
+		{Predicate: "age", Type: "int"},
+	}
+	if !reflect.DeepEqual(schema.Predicates, wantPreds) {
+		t.Errorf("predicates = %+v, want %+v", schema.Predicates, wantPreds)
+	}
+
+	wantTypes := []typeSchema{{Name: "Person", Fields: []typeField{{"name"}, {"friend"}, {"age"}}}}
+	if !reflect.DeepEqual(schema.Types, wantTypes) {
+		t.Errorf("types = %+v, want %+v", schema.Types, wantTypes)
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, text := range []string{
+		"name string .",
+		"name: text .",
+		"name: string @bogus .",
+	} {
+		if _, err := parseSchema(text); err == nil {
+			t.Errorf("parseSchema(%q) succeeded, want error", text)
+		}
+	}
+}
+
+func TestDiffSchema(t *testing.T) {
+	current := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "dgraph.type", Type: "string", List: true, Index: true, Tokenizer: []string{"exact"}},
+			{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}},
+			{Predicate: "age", Type: "int"},
+			{Predicate: "email", Type: "string"},
+		},
+		Types: []typeSchema{{Name: "Person", Fields: []typeField{{"name"}, {"age"}}}},
+	}
+	proposed, err := parseSchema(`
+		name: string @index(exact) .
+		age: int .
+		friend: [uid] .
+		type Person { name friend }
+		type Pet { name }
+	`)
+	if err != nil {
+		t.Fatalf("parseSchema failed: %v", err)
+	}
+
+	diff := diffSchema(current, proposed)
+
+	if !reflect.DeepEqual(diff.AddedPredicates, []string{"friend"}) {
+		t.Errorf("added predicates = %v", diff.AddedPredicates)
+	}
+	if !reflect.DeepEqual(diff.RemovedPredicates, []string{"email"}) {
+		t.Errorf("removed predicates = %v", diff.RemovedPredicates)
+	}
+	if len(diff.ChangedPredicates) != 1 {
+		t.Fatalf("changed predicates = %+v", diff.ChangedPredicates)
+	}
+	change := diff.ChangedPredicates[0]
+	if change.Predicate != "name" || !change.Reindex || !reflect.DeepEqual(change.Changes, []string{"index term dropped"}) {
+		t.Errorf("unexpected change %+v", change)
+	}
+	if !reflect.DeepEqual(diff.AddedTypes, []string{"Pet"}) {
+		t.Errorf("added types = %v", diff.AddedTypes)
+	}
+	wantType := typeChange{Name: "Person", AddedFields: []string{"friend"}, RemovedFields: []string{"age"}}
+	if len(diff.ChangedTypes) != 1 || !reflect.DeepEqual(diff.ChangedTypes[0], wantType) {
+		t.Errorf("changed types = %+v", diff.ChangedTypes)
+	}
+}
+
+func TestSchemaDiffWithoutRemovals(t *testing.T) {
+	current, err := parseSchema(`
+		name: string .
+		email: string .
+		type Person { name }
+		type Account { email }
+	`)
+	if err != nil {
+		t.Fatalf("parseSchema failed: %v", err)
+	}
+	proposed, err := parseSchema(`
+		name: string @index(exact) .
+		type Person { name }
+	`)
+	if err != nil {
+		t.Fatalf("parseSchema failed: %v", err)
+	}
+
+	diff, unaffected := diffSchema(current, proposed).withoutRemovals()
+	if !reflect.DeepEqual(unaffected, []string{"email", "type Account"}) {
+		t.Errorf("unaffected = %v, want [email type Account]", unaffected)
+	}
+	if len(diff.RemovedPredicates) != 0 || len(diff.RemovedTypes) != 0 {
+		t.Errorf("diff still has removals: %+v", diff)
+	}
+	if len(diff.ChangedPredicates) != 1 || diff.ChangedPredicates[0].Predicate != "name" {
+		t.Errorf("changed predicates = %+v, want name", diff.ChangedPredicates)
+	}
+}
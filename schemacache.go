@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// refreshOption adds the refresh parameter to tools reading the schema
+var refreshOption = mcp.WithBoolean("refresh",
+	mcp.Description("Reload the schema from Dgraph instead of using the cached copy (default: false)"),
+)
+
+// cachedSchema is a schema as loaded from Dgraph
+type cachedSchema struct {
+	raw  []byte
+	info *schemaInfo
+}
+
+// schemaCache keeps the schema in memory once loaded. Every operation of
+// this server that can change the schema invalidates it. Schemas are cached
+// per client, since clients logged into different namespaces see different
+// schemas.
+type schemaCache struct {
+	mu         sync.Mutex
+	generation uint64
+	load       func(ctx context.Context, client *dgo.Dgraph) ([]byte, *schemaInfo, error)
+	entries    map[*dgo.Dgraph]cachedSchema
+}
+
+// schemas caches the schema for all tools and resources
+var schemas = newSchemaCache()
+
+// Create an empty schema cache
+func newSchemaCache() *schemaCache {
+	return &schemaCache{
+		load:    loadSchema,
+		entries: make(map[*dgo.Dgraph]cachedSchema),
+	}
+}
+
+// Get the schema seen by a client, loading it if it isn't cached or refresh is set
+func (c *schemaCache) get(ctx context.Context, client *dgo.Dgraph, refresh bool) (cachedSchema, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[client]
+	generation := c.generation
+	c.mu.Unlock()
+	if ok && !refresh {
+		return entry, nil
+	}
+
+	raw, info, err := c.load(ctx, client)
+	if err != nil {
+		return cachedSchema{}, err
+	}
+	entry = cachedSchema{raw: raw, info: info}
+
+	// Don't cache a schema loaded before an invalidation, it may be stale
+	c.mu.Lock()
+	if c.generation == generation {
+		c.entries[client] = entry
+	}
+	c.mu.Unlock()
+	return entry, nil
+}
+
+// Drop all cached schemas
+func (c *schemaCache) invalidate() {
+	c.mu.Lock()
+	c.generation++
+	c.entries = make(map[*dgo.Dgraph]cachedSchema)
+	c.mu.Unlock()
+}
+
+// Fetch the parsed schema seen by a client through the cache
+func fetchSchema(ctx context.Context, client *dgo.Dgraph, refresh bool) (*schemaInfo, error) {
+	entry, err := schemas.get(ctx, client, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info, nil
+}
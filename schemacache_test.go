@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+// Create a schema cache counting how often the schema is loaded
+func newCountingSchemaCache(loads *int) *schemaCache {
+	c := newSchemaCache()
+	c.load = func(ctx context.Context, client *dgo.Dgraph) ([]byte, *schemaInfo, error) {
+		*loads++
+		return []byte(`{"schema":[]}`), &schemaInfo{}, nil
+	}
+	return c
+}
+
+func TestSchemaCache(t *testing.T) {
+	loads := 0
+	c := newCountingSchemaCache(&loads)
+	ctx := context.Background()
+	client := dgo.NewDgraphClient()
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.get(ctx, client, false); err != nil {
+			t.Fatalf("get failed: %v", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loaded %d times, want 1", loads)
+	}
+
+	c.get(ctx, client, true)
+	if loads != 2 {
+		t.Errorf("refresh did not reload the schema")
+	}
+
+	c.invalidate()
+	c.get(ctx, client, false)
+	c.get(ctx, client, false)
+	if loads != 3 {
+		t.Errorf("loaded %d times after invalidate, want 3", loads)
+	}
+
+	// Each client has its own entry
+	c.get(ctx, dgo.NewDgraphClient(), false)
+	if loads != 4 {
+		t.Errorf("loaded %d times for a second client, want 4", loads)
+	}
+}
+
+func TestSchemaCacheInvalidateDuringLoad(t *testing.T) {
+	c := newSchemaCache()
+	client := dgo.NewDgraphClient()
+	loads := 0
+	c.load = func(ctx context.Context, client *dgo.Dgraph) ([]byte, *schemaInfo, error) {
+		loads++
+		if loads == 1 {
+			// An alter completes while the schema is being loaded
+			c.invalidate()
+		}
+		return nil, &schemaInfo{}, nil
+	}
+
+	c.get(context.Background(), client, false)
+	c.get(context.Background(), client, false)
+	if loads != 2 {
+		t.Errorf("schema loaded before an invalidation was cached")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Find the names differing from name only in case, which usually means the
+// name was misspelled
+func caseInsensitiveMatches(name string, names []string) []string {
+	matches := []string{}
+	for _, n := range names {
+		if n != name && strings.EqualFold(n, name) {
+			matches = append(matches, n)
+		}
+	}
+	return matches
+}
+
+// Describe whether a predicate exists and, if it does, its definition and
+// the functions its indexes support
+func predicateExistence(schema *schemaInfo, name string) map[string]interface{} {
+	p, ok := schema.predicate(name)
+	if !ok {
+		var names []string
+		for _, p := range schema.Predicates {
+			if predicateAccess.check(p.Predicate) == nil {
+				names = append(names, p.Predicate)
+			}
+		}
+		return map[string]interface{}{
+			"exists":       false,
+			"predicate":    name,
+			"did_you_mean": caseInsensitiveMatches(name, names),
+		}
+	}
+
+	functions := searchFunctions(p)
+	if functions == nil {
+		functions = []string{}
+	}
+	return map[string]interface{}{
+		"exists":     true,
+		"predicate":  p.Predicate,
+		"type":       p.Type,
+		"list":       p.List,
+		"index":      p.Index,
+		"tokenizers": append([]string{}, p.Tokenizer...),
+		"reverse":    p.Reverse,
+		"count":      p.Count,
+		"lang":       p.Lang,
+		"upsert":     p.Upsert,
+		"functions":  functions,
+		"schema":     p.String(),
+	}
+}
+
+// Describe whether a type exists and, if it does, its fields. Fields hidden
+// by the predicate access lists are left out.
+func typeExistence(schema *schemaInfo, name string) map[string]interface{} {
+	t, ok := schema.typeDef(name)
+	if !ok {
+		names := make([]string, len(schema.Types))
+		for i, t := range schema.Types {
+			names[i] = t.Name
+		}
+		return map[string]interface{}{
+			"exists":       false,
+			"type":         name,
+			"did_you_mean": caseInsensitiveMatches(name, names),
+		}
+	}
+
+	fields := []string{}
+	for _, f := range t.Fields {
+		if predicateAccess.check(f.Name) == nil {
+			fields = append(fields, f.Name)
+		}
+	}
+	return map[string]interface{}{
+		"exists": true,
+		"type":   t.Name,
+		"fields": fields,
+	}
+}
+
+// Create handler for the predicate and type existence tools. arg names both
+// the argument and the kind of schema entry checked.
+func createSchemaExistsHandler(client *dgo.Dgraph, arg string, describe func(schema *schemaInfo, name string) map[string]interface{}) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := request.Params.Arguments[arg].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a string", arg)
+		}
+		name = strings.Trim(strings.TrimSpace(name), "<>")
+		if err := validateName(arg, name); err != nil {
+			return nil, err
+		}
+		if arg == "predicate" {
+			if err := predicateAccess.check(name); err != nil {
+				return nil, err
+			}
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := json.Marshal(describe(schema, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
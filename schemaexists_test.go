@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPredicateExistence(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}, Lang: true},
+		{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+	}}
+
+	got := predicateExistence(schema, "name")
+	if got["exists"] != true || got["type"] != "string" || got["lang"] != true {
+		t.Errorf("predicateExistence(name) = %v", got)
+	}
+	if fns := got["functions"].([]string); !reflect.DeepEqual(fns, []string{"eq", "lt", "le", "gt", "ge", "allofterms", "anyofterms"}) {
+		t.Errorf("functions = %v", fns)
+	}
+	if got["schema"] != "name: string @index(exact, term) @lang ." {
+		t.Errorf("schema = %v", got["schema"])
+	}
+
+	got = predicateExistence(schema, "friend")
+	if got["exists"] != true || got["list"] != true || !reflect.DeepEqual(got["functions"], []string{}) {
+		t.Errorf("predicateExistence(friend) = %v", got)
+	}
+
+	got = predicateExistence(schema, "Name")
+	if got["exists"] != false || !reflect.DeepEqual(got["did_you_mean"], []string{"name"}) {
+		t.Errorf("predicateExistence(Name) = %v", got)
+	}
+}
+
+func TestTypeExistence(t *testing.T) {
+	schema := &schemaInfo{Types: []typeSchema{
+		{Name: "Person", Fields: []typeField{{Name: "name"}, {Name: "friend"}}},
+	}}
+
+	got := typeExistence(schema, "Person")
+	if got["exists"] != true || !reflect.DeepEqual(got["fields"], []string{"name", "friend"}) {
+		t.Errorf("typeExistence(Person) = %v", got)
+	}
+
+	got = typeExistence(schema, "person")
+	if got["exists"] != false || !reflect.DeepEqual(got["did_you_mean"], []string{"Person"}) {
+		t.Errorf("typeExistence(person) = %v", got)
+	}
+
+	got = typeExistence(schema, "Movie")
+	if got["exists"] != false || !reflect.DeepEqual(got["did_you_mean"], []string{}) {
+		t.Errorf("typeExistence(Movie) = %v", got)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxSchemaVersions is the number of schema snapshots kept per client;
+// older ones are dropped first
+const maxSchemaVersions = 50
+
+// schemaVersion is a snapshot of the schema taken before a tool altered it
+type schemaVersion struct {
+	Version    int       `json:"version"`
+	Time       time.Time `json:"time"`
+	Tool       string    `json:"tool"`
+	Predicates int       `json:"predicates"`
+	Types      int       `json:"types"`
+
+	schema string // the snapshot as a schema definition
+	client *dgo.Dgraph
+}
+
+// schemaHistory keeps the snapshots in memory, so they are lost when the
+// server restarts. Snapshots are kept per client, as clients logged into
+// different namespaces alter different schemas, but numbered across all
+// of them.
+type schemaHistory struct {
+	mu       sync.Mutex
+	max      int
+	next     int
+	versions []schemaVersion
+	now      func() time.Time
+}
+
+// schemaVersions records the schema before every alteration by a tool
+var schemaVersions = newSchemaHistory(maxSchemaVersions)
+
+// Create an empty history keeping up to max snapshots per client
+func newSchemaHistory(max int) *schemaHistory {
+	return &schemaHistory{max: max, next: 1, now: time.Now}
+}
+
+// Format the user-defined part of a schema as a definition that Dgraph
+// accepts back. Dgraph's own predicates and types are left out, as they
+// can't be altered.
+func formatSchema(s *schemaInfo) string {
+	var lines []string
+	for _, p := range s.Predicates {
+		if !isInternalName(p.Predicate) {
+			lines = append(lines, p.String())
+		}
+	}
+	for _, t := range s.Types {
+		if isInternalName(t.Name) {
+			continue
+		}
+		fields := make([]string, len(t.Fields))
+		for i, f := range t.Fields {
+			fields[i] = "\n\t" + f.Name
+		}
+		lines = append(lines, fmt.Sprintf("type %s {%s\n}", t.Name, strings.Join(fields, "")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Record a snapshot of a schema, returning its version number
+func (h *schemaHistory) add(client *dgo.Dgraph, tool string, s *schemaInfo) int {
+	v := schemaVersion{Tool: tool, schema: formatSchema(s), client: client}
+	for _, p := range s.Predicates {
+		if !isInternalName(p.Predicate) {
+			v.Predicates++
+		}
+	}
+	for _, t := range s.Types {
+		if !isInternalName(t.Name) {
+			v.Types++
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v.Version, v.Time = h.next, h.now()
+	h.next++
+	h.versions = append(h.versions, v)
+
+	// Drop the client's oldest snapshot once it has too many
+	count := 0
+	for _, existing := range h.versions {
+		if existing.client == client {
+			count++
+		}
+	}
+	if count > h.max {
+		for i, existing := range h.versions {
+			if existing.client == client {
+				h.versions = append(h.versions[:i:i], h.versions[i+1:]...)
+				break
+			}
+		}
+	}
+	return v.Version
+}
+
+// Hand the snapshots of a client over to the client replacing it
+func (h *schemaHistory) transfer(from, to *dgo.Dgraph) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.versions {
+		if h.versions[i].client == from {
+			h.versions[i].client = to
+		}
+	}
+}
+
+// List the snapshots of a client, newest first
+func (h *schemaHistory) list(client *dgo.Dgraph) []schemaVersion {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var versions []schemaVersion
+	for i := len(h.versions) - 1; i >= 0; i-- {
+		if h.versions[i].client == client {
+			versions = append(versions, h.versions[i])
+		}
+	}
+	return versions
+}
+
+// Look up a snapshot of a client by version number
+func (h *schemaHistory) get(client *dgo.Dgraph, version int) (schemaVersion, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, v := range h.versions {
+		if v.Version == version && v.client == client {
+			return v, nil
+		}
+	}
+	return schemaVersion{}, fmt.Errorf("schema version %d not found; list the versions with dgraph_schema_history", version)
+}
+
+// Snapshot the live schema before a tool alters it, so the alteration can
+// be rolled back
+func snapshotSchema(ctx context.Context, client *dgo.Dgraph, tool string) error {
+	current, err := fetchSchema(ctx, client, true)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot the schema before altering it: %v", err)
+	}
+	schemaVersions.add(client, tool, current)
+	return nil
+}
+
+// schemaRollback describes what rolling back to a snapshot changes
+type schemaRollback struct {
+	Version int    `json:"version"`
+	Applied bool   `json:"applied"`
+	Schema  string `json:"schema"`
+	// Predicates and types the snapshot has but the live schema doesn't.
+	// Rolling back recreates them empty.
+	Recreated []string `json:"recreated"`
+	// Predicates and types added after the snapshot. An alteration can't
+	// remove them, so they stay.
+	LeftInPlace []string   `json:"left_in_place"`
+	Reindex     []string   `json:"reindex"`
+	Diff        schemaDiff `json:"diff"`
+	Warnings    []string   `json:"warnings,omitempty"`
+}
+
+// Plan rolling back from the live schema to a snapshot
+func planSchemaRollback(current *schemaInfo, v schemaVersion) (schemaRollback, error) {
+	snapshot, err := parseSchema(v.schema)
+	if err != nil {
+		return schemaRollback{}, fmt.Errorf("failed to parse schema version %d: %v", v.Version, err)
+	}
+	diff := diffSchema(current, snapshot)
+	plan := schemaRollback{
+		Version:     v.Version,
+		Schema:      v.schema,
+		Recreated:   append(append([]string{}, diff.AddedPredicates...), typeNames(diff.AddedTypes)...),
+		LeftInPlace: append(append([]string{}, diff.RemovedPredicates...), typeNames(diff.RemovedTypes)...),
+		Reindex:     diff.reindexChanges(),
+		Diff:        diff,
+	}
+
+	if len(diff.AddedPredicates) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s %s dropped since version %d and will be recreated empty: rolling back the schema can't recover data lost to dropped predicates",
+			plural(len(diff.AddedPredicates), "Predicate", "Predicates"), strings.Join(diff.AddedPredicates, ", "), v.Version))
+	}
+	if len(plan.LeftInPlace) > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s added after version %d and stay, as a schema alteration can't remove them",
+			strings.Join(plan.LeftInPlace, ", "), v.Version))
+	}
+	if len(plan.Reindex) > 0 {
+		plan.Warnings = append(plan.Warnings, "Rolling back rebuilds or drops indexes, which can take a while on large predicates")
+	}
+	return plan, nil
+}
+
+// Label type names so they can't be mistaken for predicates
+func typeNames(names []string) []string {
+	labeled := make([]string, len(names))
+	for i, name := range names {
+		labeled[i] = "type " + name
+	}
+	return labeled
+}
+
+// Create handler for the schema history tool
+func createSchemaHistoryHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		// Show a single version in full
+		if request.Params.Arguments["version"] != nil {
+			version, err := intArgument(request, "version", 0)
+			if err != nil {
+				return nil, err
+			}
+			v, err := schemaVersions.get(client, version)
+			if err != nil {
+				return nil, err
+			}
+			out, err := json.Marshal(struct {
+				schemaVersion
+				Schema string `json:"schema"`
+			}{v, v.schema})
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode result: %v", err)
+			}
+			return mcp.NewToolResultText(string(out)), nil
+		}
+
+		versions := schemaVersions.list(client)
+		if versions == nil {
+			versions = []schemaVersion{}
+		}
+		out, err := json.Marshal(struct {
+			Versions []schemaVersion `json:"versions"`
+		}{versions})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
+
+// Create handler for the schema rollback tool
+func createSchemaRollbackHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		if request.Params.Arguments["version"] == nil {
+			return nil, fmt.Errorf("version is required")
+		}
+		version, err := intArgument(request, "version", 0)
+		if err != nil {
+			return nil, err
+		}
+		dryRun, err := boolArgument(request, "dry_run", false)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := schemaVersions.get(client, version)
+		if err != nil {
+			return nil, err
+		}
+		if v.schema == "" {
+			return nil, fmt.Errorf("schema version %d defines no predicates or types, so there is nothing to reapply", version)
+		}
+		current, err := fetchSchema(ctx, client, true)
+		if err != nil {
+			return nil, err
+		}
+		plan, err := planSchemaRollback(current, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if !dryRun {
+			// The rollback is itself a version, so it can be undone too
+			schemaVersions.add(client, "dgraph_schema_rollback", current)
+			if err := alterSchema(ctx, client, v.schema); err != nil {
+				return nil, err
+			}
+			plan.Applied = true
+		}
+
+		out, err := json.Marshal(plan)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+)
+
+func TestFormatSchema(t *testing.T) {
+	s := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "dgraph.type", Type: "string", List: true, Index: true, Tokenizer: []string{"exact"}},
+			{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact"}},
+			{Predicate: "friend", Type: "uid", List: true, Reverse: true},
+		},
+		Types: []typeSchema{
+			{Name: "dgraph.graphql", Fields: []typeField{{Name: "dgraph.graphql.schema"}}},
+			{Name: "Person", Fields: []typeField{{Name: "name"}, {Name: "friend"}}},
+		},
+	}
+	want := "name: string @index(exact) .\nfriend: [uid] @reverse .\ntype Person {\n\tname\n\tfriend\n}"
+	if got := formatSchema(s); got != want {
+		t.Errorf("formatSchema() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The snapshot must parse back to the same definitions
+	parsed, err := parseSchema(want)
+	if err != nil {
+		t.Fatalf("parseSchema() failed: %v", err)
+	}
+	if got := formatSchema(parsed); got != want {
+		t.Errorf("formatSchema(parseSchema()) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSchemaHistory(t *testing.T) {
+	h := newSchemaHistory(2)
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	calls := 0
+	h.now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls) * time.Minute)
+	}
+	a, b := &dgo.Dgraph{}, &dgo.Dgraph{}
+	s := &schemaInfo{Predicates: []predicateSchema{{Predicate: "name", Type: "string"}}}
+
+	h.add(a, "dgraph_alter_schema", s)
+	h.add(b, "dgraph_alter_schema", s)
+	h.add(a, "dgraph_add_predicate", s)
+	h.add(a, "dgraph_alter_schema_from_source", s)
+
+	// a's oldest version was dropped, b's is kept
+	var versions []int
+	for _, v := range h.list(a) {
+		versions = append(versions, v.Version)
+	}
+	if !reflect.DeepEqual(versions, []int{4, 3}) {
+		t.Errorf("list(a) versions = %v, want [4 3]", versions)
+	}
+	if got := h.list(b); len(got) != 1 || got[0].Version != 2 || got[0].Predicates != 1 {
+		t.Errorf("list(b) = %+v, want version 2 with 1 predicate", got)
+	}
+
+	if _, err := h.get(a, 1); err == nil {
+		t.Error("get(a, 1) found a dropped version")
+	}
+	if _, err := h.get(a, 2); err == nil {
+		t.Error("get(a, 2) found another client's version")
+	}
+	v, err := h.get(a, 3)
+	if err != nil {
+		t.Fatalf("get(a, 3) failed: %v", err)
+	}
+	if v.Tool != "dgraph_add_predicate" || !v.Time.Equal(start.Add(3*time.Minute)) || v.schema != "name: string ." {
+		t.Errorf("get(a, 3) = %+v", v)
+	}
+}
+
+func TestPlanSchemaRollback(t *testing.T) {
+	current := &schemaInfo{
+		Predicates: []predicateSchema{
+			{Predicate: "name", Type: "string", Index: true, Tokenizer: []string{"exact", "term"}},
+			{Predicate: "nickname", Type: "string"},
+		},
+		Types: []typeSchema{{Name: "Pet", Fields: []typeField{{Name: "name"}}}},
+	}
+	v := schemaVersion{Version: 1, schema: "name: string @index(exact) .\nage: int ."}
+
+	plan, err := planSchemaRollback(current, v)
+	if err != nil {
+		t.Fatalf("planSchemaRollback() failed: %v", err)
+	}
+	if !reflect.DeepEqual(plan.Recreated, []string{"age"}) {
+		t.Errorf("Recreated = %v, want [age]", plan.Recreated)
+	}
+	if !reflect.DeepEqual(plan.LeftInPlace, []string{"nickname", "type Pet"}) {
+		t.Errorf("LeftInPlace = %v, want [nickname type Pet]", plan.LeftInPlace)
+	}
+	if !reflect.DeepEqual(plan.Reindex, []string{"name: index term dropped"}) {
+		t.Errorf("Reindex = %v", plan.Reindex)
+	}
+	if len(plan.Warnings) != 3 || !strings.Contains(plan.Warnings[0], "can't recover data lost to dropped predicates") {
+		t.Errorf("Warnings = %q", plan.Warnings)
+	}
+	if plan.Applied {
+		t.Error("planSchemaRollback() marked the plan applied")
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Limits on reading a schema from a source
+const (
+	maxSchemaSourceSize = 10 << 20
+	schemaFetchTimeout  = 30 * time.Second
+)
+
+// Apply a schema, dropping the cached schema afterwards. The cache is
+// dropped even if the alter fails, as it may have been partially applied.
+func alterSchema(ctx context.Context, client *dgo.Dgraph, schema string) error {
+	err := client.Alter(ctx, &api.Operation{Schema: schema})
+	invalidateCaches()
+	if err != nil {
+		return fmt.Errorf("schema alteration failed: %v", err)
+	}
+	return nil
+}
+
+// Split a list of directories as given in DGRAPH_SCHEMA_DIR or
+// DGRAPH_IMPORT_DIR
+func schemaDirs(value string) []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(value) {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// Resolve a schema file path, making sure it lies inside one of the allowed
+// directories once symlinks are followed. Relative paths are resolved
+// against the first directory.
+func resolveSchemaPath(path string, dirs []string) (string, error) {
+	return resolveAllowedPath(path, dirs, "schema file", "DGRAPH_SCHEMA_DIR")
+}
+
+// Resolve a path inside one of the directories allowed by the env variable,
+// following symlinks. kind describes the file in error messages.
+func resolveAllowedPath(path string, dirs []string, kind, env string) (string, error) {
+	if len(dirs) == 0 {
+		return "", fmt.Errorf("reading %ss is disabled; set %s to allow it", kind, env)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dirs[0], path)
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %v", kind, err)
+	}
+	for _, dir := range dirs {
+		root, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, resolved)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s %s is outside the directories allowed by %s", kind, path, env)
+}
+
+// Read at most maxSchemaSourceSize bytes of a schema
+func readSchema(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxSchemaSourceSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxSchemaSourceSize {
+		return "", fmt.Errorf("schema is larger than %d bytes", maxSchemaSourceSize)
+	}
+	return string(data), nil
+}
+
+// Read a schema file from one of the allowed directories
+func readSchemaFile(path string, dirs []string) (string, error) {
+	resolved, err := resolveSchemaPath(path, dirs)
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(resolved)
+	if err != nil {
+		return "", fmt.Errorf("failed to open schema file: %v", err)
+	}
+	defer f.Close()
+
+	schema, err := readSchema(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read schema file: %v", err)
+	}
+	return schema, nil
+}
+
+// Split a comma-separated list of hosts as given in
+// DGRAPH_SCHEMA_URL_HOSTS
+func schemaURLHosts(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// Check that a URL uses http or https and points at one of the allowed
+// hosts. A host listed with a port only matches that port.
+func checkSchemaURL(u *url.URL, hosts []string) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url must use http or https")
+	}
+	for _, host := range hosts {
+		if host == strings.ToLower(u.Host) || host == strings.ToLower(u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %s is not allowed by DGRAPH_SCHEMA_URL_HOSTS", u.Host)
+}
+
+// Download a schema over HTTP or HTTPS from one of the allowed hosts. The
+// assistant picks the URL, so without an allowlist it could make the server
+// reach internal services; downloads are disabled until hosts are allowed.
+// Redirects are followed only to allowed hosts, and the download is bounded
+// in time and size.
+func fetchSchemaURL(ctx context.Context, rawURL string, hosts []string) (string, error) {
+	if len(hosts) == 0 {
+		return "", fmt.Errorf("downloading schemas is disabled; set DGRAPH_SCHEMA_URL_HOSTS to allow it")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %v", err)
+	}
+	if err := checkSchemaURL(u, hosts); err != nil {
+		return "", err
+	}
+
+	client := &http.Client{
+		Timeout: schemaFetchTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return checkSchemaURL(req.URL, hosts)
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid url: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download schema: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download schema: %s", resp.Status)
+	}
+	if resp.ContentLength > maxSchemaSourceSize {
+		return "", fmt.Errorf("failed to download schema: schema is larger than %d bytes", maxSchemaSourceSize)
+	}
+
+	schema, err := readSchema(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to download schema: %v", err)
+	}
+	return schema, nil
+}
+
+// Create handler for the alter schema from source tool, reading files from
+// dirs and downloading from hosts
+func createSchemaFromSourceHandler(client *dgo.Dgraph, dirs, hosts []string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		path, _ := request.Params.Arguments["path"].(string)
+		url, _ := request.Params.Arguments["url"].(string)
+
+		var schema, source string
+		switch {
+		case path != "" && url != "":
+			return nil, fmt.Errorf("give either path or url, not both")
+		case path != "":
+			schema, err = readSchemaFile(path, dirs)
+			source = path
+		case url != "":
+			schema, err = fetchSchemaURL(ctx, url, hosts)
+			source = url
+		default:
+			return nil, fmt.Errorf("path or url is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := snapshotSchema(ctx, client, "dgraph_alter_schema_from_source"); err != nil {
+			return nil, err
+		}
+		if err := alterSchema(ctx, client, schema); err != nil {
+			return nil, err
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf("Schema from %s applied successfully", source)), nil
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveSchemaPath(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	for _, f := range []string{filepath.Join(allowed, "schema.dql"), filepath.Join(outside, "secret.dql")} {
+		if err := os.WriteFile(f, []byte("name: string ."), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.dql"), filepath.Join(allowed, "link.dql")); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := []string{allowed}
+	for _, path := range []string{"schema.dql", filepath.Join(allowed, "schema.dql")} {
+		if _, err := resolveSchemaPath(path, dirs); err != nil {
+			t.Errorf("resolveSchemaPath(%q) failed: %v", path, err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(outside, "secret.dql"),
+		"../" + filepath.Base(outside) + "/secret.dql",
+		"link.dql",
+		"missing.dql",
+	} {
+		if _, err := resolveSchemaPath(path, dirs); err == nil {
+			t.Errorf("resolveSchemaPath(%q) succeeded, want error", path)
+		}
+	}
+	if _, err := resolveSchemaPath(filepath.Join(allowed, "schema.dql"), nil); err == nil {
+		t.Errorf("resolveSchemaPath without allowed directories succeeded, want error")
+	}
+}
+
+func TestSchemaDirs(t *testing.T) {
+	sep := string(filepath.ListSeparator)
+	got := schemaDirs("/a" + sep + " " + sep + "/b")
+	if len(got) != 2 || got[0] != "/a" || got[1] != "/b" {
+		t.Errorf("schemaDirs = %v, want [/a /b]", got)
+	}
+	if got := schemaDirs(""); got != nil {
+		t.Errorf("schemaDirs(\"\") = %v, want nil", got)
+	}
+}
+
+func TestFetchSchemaURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schema.dql":
+			w.Write([]byte("name: string @index(exact) ."))
+		case "/large.dql":
+			w.Write(bytes.Repeat([]byte("#"), maxSchemaSourceSize+1))
+		case "/moved":
+			http.Redirect(w, r, "/schema.dql", http.StatusFound)
+		case "/internal":
+			http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	allowed := []string{"127.0.0.1"}
+	ctx := context.Background()
+
+	got, err := fetchSchemaURL(ctx, srv.URL+"/schema.dql", allowed)
+	if err != nil || !strings.Contains(got, "name: string") {
+		t.Errorf("fetchSchemaURL = %q, %v", got, err)
+	}
+	if got, err := fetchSchemaURL(ctx, srv.URL+"/moved", []string{u.Host}); err != nil || !strings.Contains(got, "name: string") {
+		t.Errorf("fetchSchemaURL through a redirect to an allowed host = %q, %v", got, err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		hosts   []string
+		wantErr string
+	}{
+		{"disabled", srv.URL + "/schema.dql", nil, "disabled"},
+		{"host not allowed", srv.URL + "/schema.dql", []string{"schemas.example.com"}, "not allowed"},
+		{"other port", srv.URL + "/schema.dql", []string{"127.0.0.1:1"}, "not allowed"},
+		{"redirect to a host not allowed", srv.URL + "/internal", allowed, "not allowed"},
+		{"missing file", srv.URL + "/missing", allowed, "404"},
+		{"too large", srv.URL + "/large.dql", allowed, "larger than"},
+		{"file url", "file:///etc/passwd", allowed, "http or https"},
+	}
+	for _, tt := range tests {
+		if _, err := fetchSchemaURL(ctx, tt.url, tt.hosts); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: fetchSchemaURL error = %v, want it to contain %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSchemaURLHosts(t *testing.T) {
+	got := schemaURLHosts(" Schemas.example.com, ,config.internal:8443,")
+	want := []string{"schemas.example.com", "config.internal:8443"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("schemaURLHosts() = %v, want %v", got, want)
+	}
+}
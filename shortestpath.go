@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Build a shortest path query between two nodes along an edge predicate,
+// optionally weighted by a facet of the edge. Returns the nodes on the path
+// under "path" and Dgraph's path structure under "_path_".
+func buildShortestPathQuery(from, to, predicate, weightFacet string, maxDepth int, fields []string) (string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", err
+	}
+	if weightFacet != "" {
+		if err := validateName("facet", weightFacet); err != nil {
+			return "", err
+		}
+	}
+	for _, f := range fields {
+		if err := validateName("field", f); err != nil {
+			return "", err
+		}
+	}
+
+	args := fmt.Sprintf("from: %s, to: %s", from, to)
+	if maxDepth > 0 {
+		args += fmt.Sprintf(", depth: %d", maxDepth)
+	}
+	edge := predicate
+	if weightFacet != "" {
+		edge += fmt.Sprintf(" @facets(%s)", weightFacet)
+	}
+	selection := append([]string{"uid"}, fields...)
+
+	return fmt.Sprintf(`{
+	p as shortest(%s) {
+		%s
+	}
+	path(func: uid(p)) {
+		%s
+	}
+}`, args, edge, strings.Join(selection, "\n\t\t")), nil
+}
+
+// Create handler for the shortest path tool
+func createShortestPathHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		from, err := uidArgument(request, "from")
+		if err != nil {
+			return nil, err
+		}
+		to, err := uidArgument(request, "to")
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+		weightFacet, _ := request.Params.Arguments["weight_facet"].(string)
+
+		maxDepth, err := intArgument(request, "max_depth", 0)
+		if err != nil {
+			return nil, err
+		}
+		if maxDepth < 0 {
+			return nil, fmt.Errorf("max_depth must not be negative")
+		}
+
+		fields, err := stringsArgument(request, "fields")
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := buildShortestPathQuery(from, to, predicate, weightFacet, maxDepth, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestBuildShortestPathQuery(t *testing.T) {
+	got, err := buildShortestPathQuery("0x1", "0x2", "friend", "", 0, nil)
+	if err != nil {
+		t.Fatalf("buildShortestPathQuery failed: %v", err)
+	}
+	want := `{
+	p as shortest(from: 0x1, to: 0x2) {
+		friend
+	}
+	path(func: uid(p)) {
+		uid
+	}
+}`
+	if got != want {
+		t.Errorf("buildShortestPathQuery\n got: %s\nwant: %s", got, want)
+	}
+
+	got, err = buildShortestPathQuery("0x1", "0x2", "road", "distance", 5, []string{"name"})
+	if err != nil {
+		t.Fatalf("buildShortestPathQuery failed: %v", err)
+	}
+	want = `{
+	p as shortest(from: 0x1, to: 0x2, depth: 5) {
+		road @facets(distance)
+	}
+	path(func: uid(p)) {
+		uid
+		name
+	}
+}`
+	if got != want {
+		t.Errorf("buildShortestPathQuery weighted\n got: %s\nwant: %s", got, want)
+	}
+
+	for _, tt := range [][2]string{{"friend }", ""}, {"road", "w)"}} {
+		if _, err := buildShortestPathQuery("0x1", "0x2", tt[0], tt[1], 0, nil); err == nil {
+			t.Errorf("buildShortestPathQuery(%q, %q) succeeded, want error", tt[0], tt[1])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Default shutdown settings
+const (
+	defaultShutdownTimeout = 10 * time.Second
+	discardTimeout         = 5 * time.Second
+)
+
+// activityTracker keeps track of in-flight tool calls and open transactions
+// so that shutdown can wait for the former and discard the latter
+type activityTracker struct {
+	mu       sync.Mutex
+	draining bool
+	calls    sync.WaitGroup
+	inFlight int
+	txns     map[*dgo.Txn]struct{}
+}
+
+// activity tracks all tool calls and transactions made by the server
+var activity = newActivityTracker()
+
+// Create an empty activity tracker
+func newActivityTracker() *activityTracker {
+	return &activityTracker{txns: make(map[*dgo.Txn]struct{})}
+}
+
+// Tool handler middleware rejecting new calls once draining has started
+func (a *activityTracker) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		a.mu.Lock()
+		if a.draining {
+			a.mu.Unlock()
+			return nil, fmt.Errorf("server is shutting down")
+		}
+		a.calls.Add(1)
+		a.inFlight++
+		a.mu.Unlock()
+
+		defer func() {
+			a.mu.Lock()
+			a.inFlight--
+			a.mu.Unlock()
+			a.calls.Done()
+		}()
+
+		return next(ctx, request)
+	}
+}
+
+// Register a transaction as open until finishTxn is called
+func (a *activityTracker) startTxn(txn *dgo.Txn) *dgo.Txn {
+	a.mu.Lock()
+	a.txns[txn] = struct{}{}
+	a.mu.Unlock()
+	return txn
+}
+
+// Discard a transaction and stop tracking it. Like txn.Discard, this is a
+// no-op for transactions that have already been committed.
+func (a *activityTracker) finishTxn(ctx context.Context, txn *dgo.Txn) {
+	a.mu.Lock()
+	delete(a.txns, txn)
+	a.mu.Unlock()
+	txn.Discard(ctx)
+}
+
+// Stop accepting tool calls and wait for in-flight ones to finish. Returns
+// how many calls were drained and how many were still running at the timeout.
+func (a *activityTracker) drain(timeout time.Duration) (drained, pending int) {
+	a.mu.Lock()
+	a.draining = true
+	drained = a.inFlight
+	a.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		a.calls.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+
+	a.mu.Lock()
+	pending = a.inFlight
+	a.mu.Unlock()
+	return drained - pending, pending
+}
+
+// Discard every transaction that is still open, returning how many there were
+func (a *activityTracker) discardOpenTxns() int {
+	a.mu.Lock()
+	txns := make([]*dgo.Txn, 0, len(a.txns))
+	for txn := range a.txns {
+		txns = append(txns, txn)
+	}
+	a.txns = make(map[*dgo.Txn]struct{})
+	a.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), discardTimeout)
+	defer cancel()
+	for _, txn := range txns {
+		txn.Discard(ctx)
+	}
+	return len(txns)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestActivityTrackerTxns(t *testing.T) {
+	a := newActivityTracker()
+	client := dgo.NewDgraphClient(&recordingDgraphClient{})
+
+	first := a.startTxn(client.NewTxn())
+	second := a.startTxn(client.NewTxn())
+	if len(a.txns) != 2 {
+		t.Fatalf("tracking %d transactions, want 2", len(a.txns))
+	}
+	a.finishTxn(context.Background(), first)
+	if _, ok := a.txns[first]; ok || len(a.txns) != 1 {
+		t.Errorf("finished transaction is still tracked")
+	}
+
+	if n := a.discardOpenTxns(); n != 1 {
+		t.Errorf("discardOpenTxns() = %d, want 1", n)
+	}
+	if len(a.txns) != 0 {
+		t.Errorf("tracking %d transactions after discarding them, want 0", len(a.txns))
+	}
+	// Finishing a discarded transaction is harmless
+	a.finishTxn(context.Background(), second)
+}
+
+func TestActivityTrackerDrainIdle(t *testing.T) {
+	a := newActivityTracker()
+	if drained, pending := a.drain(time.Second); drained != 0 || pending != 0 {
+		t.Errorf("drain() = %d, %d; want 0, 0", drained, pending)
+	}
+
+	handler := a.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("ok"), nil
+	})
+	if _, err := handler(context.Background(), mcp.CallToolRequest{}); err == nil || !strings.Contains(err.Error(), "shutting down") {
+		t.Errorf("call after drain error = %v, want a shutting down error", err)
+	}
+}
+
+func TestActivityTrackerDrainWithTxnInFlight(t *testing.T) {
+	a := newActivityTracker()
+	client := dgo.NewDgraphClient(&recordingDgraphClient{})
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := a.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		txn := a.startTxn(client.NewTxn())
+		defer a.finishTxn(ctx, txn)
+		close(started)
+		<-release
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := handler(context.Background(), mcp.CallToolRequest{})
+		done <- err
+	}()
+	<-started
+
+	// The call outlives a short timeout and keeps its transaction open
+	if drained, pending := a.drain(10 * time.Millisecond); drained != 0 || pending != 1 {
+		t.Errorf("drain() while the call runs = %d, %d; want 0, 1", drained, pending)
+	}
+
+	// Once the call is let go, a second drain waits for it to finish
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	if drained, pending := a.drain(time.Second); drained != 1 || pending != 0 {
+		t.Errorf("drain() = %d, %d; want 1, 0", drained, pending)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("call in flight failed: %v", err)
+	}
+	if n := a.discardOpenTxns(); n != 0 {
+		t.Errorf("discardOpenTxns() = %d, want 0 once the call finished its transaction", n)
+	}
+}
+
+func TestActivityTrackerDiscardsTxnLeftOpen(t *testing.T) {
+	a := newActivityTracker()
+	client := dgo.NewDgraphClient(&recordingDgraphClient{})
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	handler := a.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		a.startTxn(client.NewTxn())
+		close(started)
+		<-release
+		return nil, nil
+	})
+	go handler(context.Background(), mcp.CallToolRequest{})
+	<-started
+
+	// A call still running at the timeout leaves its transaction for
+	// shutdown to discard
+	if _, pending := a.drain(10 * time.Millisecond); pending != 1 {
+		t.Errorf("drain() pending = %d, want 1", pending)
+	}
+	if n := a.discardOpenTxns(); n != 1 {
+		t.Errorf("discardOpenTxns() = %d, want 1", n)
+	}
+}
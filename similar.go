@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Limits of the similar text tool
+const (
+	maxSimilarTerms      = 32
+	maxSimilarCandidates = 1000
+	defaultSimilarFirst  = 10
+)
+
+// Split text into distinct lowercase terms, roughly as Dgraph's term
+// tokenizer does
+func splitTerms(text string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, term := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Build a query finding the nodes sharing terms with a text. DQL can't
+// count how many of the terms each node matches, so alongside the
+// candidates (anyofterms over the whole text) a block per term lists the
+// nodes matching that term, and the candidates are ranked from those.
+// Terms are passed as variables $t0, $t1, ...
+func buildSimilarTextQuery(predicate string, terms []string, fields []string) (string, map[string]string, error) {
+	if err := validateName("predicate", predicate); err != nil {
+		return "", nil, err
+	}
+	for _, f := range fields {
+		if err := validateField("field", f); err != nil {
+			return "", nil, err
+		}
+	}
+	if len(terms) == 0 {
+		return "", nil, fmt.Errorf("text has no terms to match")
+	}
+	if len(terms) > maxSimilarTerms {
+		return "", nil, fmt.Errorf("text has %d distinct terms, more than the maximum of %d", len(terms), maxSimilarTerms)
+	}
+	if len(fields) == 0 {
+		fields = []string{predicate}
+	}
+
+	vars := map[string]string{"$text": strings.Join(terms, " ")}
+	params := []string{"$text: string"}
+	var blocks strings.Builder
+	for i, term := range terms {
+		name := fmt.Sprintf("$t%d", i)
+		vars[name] = term
+		params = append(params, name+": string")
+		fmt.Fprintf(&blocks, "\tt%d(func: anyofterms(%s, %s), first: %d) {\n\t\tuid\n\t}\n", i, predicate, name, maxSimilarCandidates)
+	}
+
+	query := fmt.Sprintf(`query similar(%s) {
+%s	candidates(func: anyofterms(%s, $text), first: %d) {
+		uid
+		%s
+	}
+}`, strings.Join(params, ", "), blocks.String(), predicate, maxSimilarCandidates, strings.Join(fields, "\n\t\t"))
+	return query, vars, nil
+}
+
+// Rank the candidates of a similar text query by the number of terms they
+// match, most first, keeping the first n. Each node gets a matched_terms
+// field.
+func rankSimilarNodes(data []byte, termCount, n int) ([]map[string]interface{}, error) {
+	var blocks map[string][]map[string]interface{}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return nil, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	matches := map[string]int{}
+	for i := 0; i < termCount; i++ {
+		for _, node := range blocks[fmt.Sprintf("t%d", i)] {
+			if uid, ok := node["uid"].(string); ok {
+				matches[uid]++
+			}
+		}
+	}
+
+	nodes := blocks["candidates"]
+	for _, node := range nodes {
+		uid, _ := node["uid"].(string)
+		node["matched_terms"] = matches[uid]
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i]["matched_terms"].(int) > nodes[j]["matched_terms"].(int)
+	})
+	if len(nodes) > n {
+		nodes = nodes[:n]
+	}
+	if nodes == nil {
+		nodes = []map[string]interface{}{}
+	}
+	return nodes, nil
+}
+
+// Create handler for the similar text tool
+func createSimilarTextHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		predicate, ok := request.Params.Arguments["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("predicate must be a string")
+		}
+
+		text, ok := request.Params.Arguments["text"].(string)
+		if !ok {
+			return nil, fmt.Errorf("text must be a string")
+		}
+
+		fields, err := fieldsArgument(ctx, client, request, "fields")
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			fields = []string{predicate}
+		}
+		if fields, err = langFields(ctx, client, request, fields, "fields"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", defaultSimilarFirst)
+		if err != nil {
+			return nil, err
+		}
+		if first < 1 {
+			return nil, fmt.Errorf("first must be positive")
+		}
+
+		terms := splitTerms(text)
+		query, vars, err := buildSimilarTextQuery(predicate, terms, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkIndexedPredicate(schema, predicate, "anyofterms"); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query with the terms as variables
+		resp, err := txn.QueryWithVars(ctx, query, vars)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		nodes, err := rankSimilarNodes(resp.Json, len(terms), first)
+		if err != nil {
+			return nil, err
+		}
+
+		out, err := json.Marshal(map[string]interface{}{
+			"terms":   terms,
+			"similar": nodes,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitTerms(t *testing.T) {
+	got := splitTerms("The Matrix: Reloaded, the sequel (2003)")
+	want := []string{"the", "matrix", "reloaded", "sequel", "2003"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitTerms = %v, want %v", got, want)
+	}
+}
+
+func TestBuildSimilarTextQuery(t *testing.T) {
+	query, vars, err := buildSimilarTextQuery("title", []string{"matrix", "reloaded"}, nil)
+	if err != nil {
+		t.Fatalf("buildSimilarTextQuery failed: %v", err)
+	}
+	for _, want := range []string{
+		"query similar($text: string, $t0: string, $t1: string)",
+		"t1(func: anyofterms(title, $t1), first: 1000)",
+		"candidates(func: anyofterms(title, $text), first: 1000)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("buildSimilarTextQuery missing %q:\n%s", want, query)
+		}
+	}
+	if vars["$text"] != "matrix reloaded" || vars["$t0"] != "matrix" || vars["$t1"] != "reloaded" {
+		t.Errorf("buildSimilarTextQuery vars = %v", vars)
+	}
+	if _, err := parseQueryBlocks(query); err != nil {
+		t.Errorf("buildSimilarTextQuery produced an unparsable query: %v", err)
+	}
+
+	if _, _, err := buildSimilarTextQuery("title", nil, nil); err == nil {
+		t.Errorf("buildSimilarTextQuery without terms succeeded, want error")
+	}
+	if _, _, err := buildSimilarTextQuery("title }", []string{"a"}, nil); err == nil {
+		t.Errorf("buildSimilarTextQuery with invalid predicate succeeded, want error")
+	}
+}
+
+func TestRankSimilarNodes(t *testing.T) {
+	data := []byte(`{
+		"t0": [{"uid": "0x1"}, {"uid": "0x2"}, {"uid": "0x3"}],
+		"t1": [{"uid": "0x3"}],
+		"t2": [{"uid": "0x3"}, {"uid": "0x2"}],
+		"candidates": [{"uid": "0x1", "title": "a"}, {"uid": "0x2", "title": "b"}, {"uid": "0x3", "title": "c"}]
+	}`)
+
+	nodes, err := rankSimilarNodes(data, 3, 2)
+	if err != nil {
+		t.Fatalf("rankSimilarNodes failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0]["uid"] != "0x3" || nodes[0]["matched_terms"] != 3 || nodes[1]["uid"] != "0x2" || nodes[1]["matched_terms"] != 2 {
+		t.Errorf("rankSimilarNodes = %v", nodes)
+	}
+
+	nodes, err = rankSimilarNodes([]byte(`{"candidates": []}`), 1, 5)
+	if err != nil || nodes == nil || len(nodes) != 0 {
+		t.Errorf("rankSimilarNodes without candidates = %v, %v", nodes, err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// maxSnapshots is the largest number of read snapshots kept for reuse
+const maxSnapshots = 256
+
+// snapshotKey identifies a read snapshot: a read timestamp of a client,
+// which is specific to a namespace login
+type snapshotKey struct {
+	client *dgo.Dgraph
+	readTs uint64
+}
+
+// snapshot is a read-only transaction kept to query at its read timestamp
+type snapshot struct {
+	mu       sync.Mutex
+	txn      *dgo.Txn
+	lastUsed time.Time
+}
+
+// snapshotRegistry keeps the read-only transactions of recent queries by
+// their read timestamp, so that later queries can pass it as read_ts to
+// read the same database version. dgo can't start a transaction at a given
+// timestamp, so the transaction that first read at it is reused. Snapshots
+// idle for longer than the TTL are dropped, as are the least recently used
+// ones beyond maxSnapshots.
+type snapshotRegistry struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	snapshots map[snapshotKey]*snapshot
+}
+
+// Create an empty snapshot registry
+func newSnapshotRegistry(ttl time.Duration) *snapshotRegistry {
+	return &snapshotRegistry{
+		ttl:       ttl,
+		now:       time.Now,
+		snapshots: make(map[snapshotKey]*snapshot),
+	}
+}
+
+// Keep a read-only transaction that has run a query at readTs
+func (r *snapshotRegistry) add(client *dgo.Dgraph, txn *dgo.Txn, readTs uint64) {
+	if readTs == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expireLocked()
+
+	key := snapshotKey{client, readTs}
+	if _, ok := r.snapshots[key]; ok {
+		return
+	}
+	if len(r.snapshots) >= maxSnapshots {
+		var oldest snapshotKey
+		var oldestUsed time.Time
+		for k, s := range r.snapshots {
+			if oldestUsed.IsZero() || s.lastUsed.Before(oldestUsed) {
+				oldest, oldestUsed = k, s.lastUsed
+			}
+		}
+		delete(r.snapshots, oldest)
+	}
+	r.snapshots[key] = &snapshot{txn: txn, lastUsed: r.now()}
+}
+
+// Run fn with the read-only transaction reading at readTs
+func (r *snapshotRegistry) use(client *dgo.Dgraph, readTs uint64, fn func(txn *dgo.Txn) error) error {
+	r.mu.Lock()
+	r.expireLocked()
+	s, ok := r.snapshots[snapshotKey{client, readTs}]
+	if ok {
+		s.lastUsed = r.now()
+	}
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown or expired read_ts %d; run the query without read_ts to get a new one", readTs)
+	}
+
+	// A dgo transaction must not be used concurrently
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.txn)
+}
+
+// Drop snapshots idle for longer than the TTL. Read-only transactions hold
+// nothing on the server, so they need no discarding.
+func (r *snapshotRegistry) expireLocked() {
+	for key, s := range r.snapshots {
+		if r.now().Sub(s.lastUsed) >= r.ttl {
+			delete(r.snapshots, key)
+		}
+	}
+}
+
+// Report the read timestamp of a query in the result's _meta field, so
+// that clients can pass it as read_ts. Cached results have none.
+func withReadTs(ctx context.Context, result *mcp.CallToolResult, resp *api.Response) *mcp.CallToolResult {
+	readTs := readTimestamp(resp)
+	if readTs == 0 {
+		return result
+	}
+	slog.Debug("Query read snapshot", "read_ts", readTs)
+	if result.Meta == nil {
+		result.Meta = make(map[string]interface{})
+	}
+	result.Meta["read_ts"] = readTs
+	return result
+}
+
+// Return the read timestamp of a query response, or 0 without one
+func readTimestamp(resp *api.Response) uint64 {
+	if resp == nil || resp.Txn == nil {
+		return 0
+	}
+	return resp.Txn.StartTs
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSnapshotRegistry(t *testing.T) {
+	client := newTestTxnClient()
+	other := newTestTxnClient()
+	now := time.Now()
+	r := newSnapshotRegistry(time.Minute)
+	r.now = func() time.Time { return now }
+
+	txn := client.NewReadOnlyTxn()
+	r.add(client, txn, 42)
+	r.add(client, client.NewReadOnlyTxn(), 0)
+
+	var used *dgo.Txn
+	if err := r.use(client, 42, func(txn *dgo.Txn) error {
+		used = txn
+		return nil
+	}); err != nil {
+		t.Fatalf("use failed: %v", err)
+	}
+	if used != txn {
+		t.Errorf("use did not pass the snapshot's transaction")
+	}
+
+	// Snapshots belong to the client that read them
+	if err := r.use(other, 42, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("use with another client succeeded, want error")
+	}
+	if err := r.use(client, 0, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("use of read_ts 0 succeeded, want error")
+	}
+
+	// Using a snapshot keeps it alive
+	now = now.Add(50 * time.Second)
+	if err := r.use(client, 42, func(*dgo.Txn) error { return nil }); err != nil {
+		t.Fatalf("use before expiry failed: %v", err)
+	}
+	now = now.Add(50 * time.Second)
+	if err := r.use(client, 42, func(*dgo.Txn) error { return nil }); err != nil {
+		t.Fatalf("use after refresh failed: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if err := r.use(client, 42, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("use after expiry succeeded, want error")
+	}
+}
+
+func TestSnapshotRegistryEviction(t *testing.T) {
+	client := newTestTxnClient()
+	now := time.Now()
+	r := newSnapshotRegistry(time.Hour)
+	r.now = func() time.Time { return now }
+
+	for ts := uint64(1); ts <= maxSnapshots+1; ts++ {
+		now = now.Add(time.Second)
+		r.add(client, client.NewReadOnlyTxn(), ts)
+	}
+	if len(r.snapshots) != maxSnapshots {
+		t.Errorf("registry holds %d snapshots, want %d", len(r.snapshots), maxSnapshots)
+	}
+	if err := r.use(client, 1, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("least recently used snapshot was not evicted")
+	}
+	if err := r.use(client, maxSnapshots+1, func(*dgo.Txn) error { return nil }); err != nil {
+		t.Errorf("newest snapshot was evicted: %v", err)
+	}
+}
+
+func TestWithReadTs(t *testing.T) {
+	ctx := context.Background()
+	result := withReadTs(ctx, mcp.NewToolResultText("{}"), &api.Response{Txn: &api.TxnContext{StartTs: 7}})
+	if result.Meta["read_ts"] != uint64(7) {
+		t.Errorf("read_ts = %v, want 7", result.Meta["read_ts"])
+	}
+
+	result = withReadTs(ctx, mcp.NewToolResultText("{}"), &api.Response{Json: []byte("{}")})
+	if _, ok := result.Meta["read_ts"]; ok {
+		t.Errorf("read_ts set for a response without a transaction")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Default size in bytes above which results are split into chunks
+const defaultStreamThreshold = 1 << 20
+
+// Split a JSON query result into chunks of at most roughly maxSize bytes.
+// Each chunk is a valid JSON object holding a slice of one result block,
+// e.g. {"q": [node, node, ...]}, so clients can process chunks on their
+// own. Results that aren't a JSON object are returned as a single chunk.
+func splitJSONResult(data []byte, maxSize int) []string {
+	var blocks map[string]json.RawMessage
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return []string{string(data)}
+	}
+
+	var chunks []string
+	emit := func(name string, value interface{}) {
+		chunk, err := json.Marshal(map[string]interface{}{name: value})
+		if err == nil {
+			chunks = append(chunks, string(chunk))
+		}
+	}
+
+	for _, name := range sortedKeys(blocks) {
+		var nodes []json.RawMessage
+		if err := json.Unmarshal(blocks[name], &nodes); err != nil {
+			emit(name, blocks[name])
+			continue
+		}
+
+		var (
+			batch []json.RawMessage
+			size  int
+		)
+		for _, node := range nodes {
+			if len(batch) > 0 && size+len(node) > maxSize {
+				emit(name, batch)
+				batch, size = nil, 0
+			}
+			batch = append(batch, node)
+			size += len(node) + 1
+		}
+		if len(batch) > 0 || len(nodes) == 0 {
+			if batch == nil {
+				batch = []json.RawMessage{}
+			}
+			emit(name, batch)
+		}
+	}
+
+	if len(chunks) == 0 {
+		return []string{string(data)}
+	}
+	return chunks
+}
+
+// Tool handler middleware splitting dgraph_query results larger than
+// threshold into several content blocks. Other tools return results of
+// their own shape, which splitJSONResult can't split meaningfully.
+func chunkLargeResults(threshold int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if request.Params.Name != "dgraph_query" || err != nil || result == nil || result.IsError || threshold <= 0 || len(result.Content) == 0 {
+				return result, err
+			}
+			text, ok := result.Content[0].(mcp.TextContent)
+			if !ok || len(text.Text) <= threshold {
+				return result, err
+			}
+
+			chunks := splitJSONResult([]byte(text.Text), threshold)
+			if len(chunks) == 1 {
+				return result, err
+			}
+
+			content := make([]mcp.Content, 0, len(chunks)+len(result.Content)-1)
+			for _, chunk := range chunks {
+				content = append(content, mcp.NewTextContent(chunk))
+			}
+			result.Content = append(content, result.Content[1:]...)
+			return result, nil
+		}
+	}
+}
+
+// Send a progress notification if the client asked for progress
+func notifyProgress(ctx context.Context, request mcp.CallToolRequest, progress, total int) {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return
+	}
+	srv := server.ServerFromContext(ctx)
+	if srv == nil {
+		return
+	}
+	err := srv.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+		"progressToken": request.Params.Meta.ProgressToken,
+		"progress":      progress,
+		"total":         total,
+	})
+	if err != nil {
+		slog.Debug("Failed to send progress notification", "error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestSplitJSONResult(t *testing.T) {
+	var nodes []string
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, `{"uid":"0x1","name":"node"}`)
+	}
+	data := `{"q":[` + strings.Join(nodes, ",") + `],"empty":[],"count":3}`
+
+	chunks := splitJSONResult([]byte(data), 100)
+	total := 0
+	for _, chunk := range chunks {
+		var parsed map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(chunk), &parsed); err != nil {
+			t.Fatalf("chunk %s is not valid JSON: %v", chunk, err)
+		}
+		if len(parsed) != 1 {
+			t.Errorf("chunk %s has %d blocks, want 1", chunk, len(parsed))
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(parsed["q"], &items) == nil {
+			total += len(items)
+		}
+	}
+	if total != 10 {
+		t.Errorf("chunks hold %d nodes of q, want 10", total)
+	}
+	if len(chunks) < 5 {
+		t.Errorf("got %d chunks, want the 10 nodes split into several", len(chunks))
+	}
+
+	if got := splitJSONResult([]byte("not json"), 10); len(got) != 1 || got[0] != "not json" {
+		t.Errorf("splitJSONResult of non-JSON = %v", got)
+	}
+}
+
+func TestChunkLargeResults(t *testing.T) {
+	data := `{"q":[{"uid":"0x1"},{"uid":"0x2"},{"uid":"0x3"}]}`
+	handler := chunkLargeResults(20)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(data), nil
+	})
+
+	var request mcp.CallToolRequest
+	request.Params.Name = "dgraph_query"
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("handler failed: %v", err)
+	}
+	if len(result.Content) != 3 {
+		t.Errorf("got %d content blocks, want 3", len(result.Content))
+	}
+
+	small := chunkLargeResults(1000)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(data), nil
+	})
+	if result, _ := small(context.Background(), request); len(result.Content) != 1 {
+		t.Errorf("small result was split into %d blocks", len(result.Content))
+	}
+
+	// Only dgraph_query results are split
+	request.Params.Name = "dgraph_get_node"
+	if result, _ := handler(context.Background(), request); len(result.Content) != 1 {
+		t.Errorf("dgraph_get_node result was split into %d blocks", len(result.Content))
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// Indent a JSON document for reading. Data that isn't valid JSON is
+// returned unchanged.
+func prettyJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		slog.Debug("Result is not valid JSON, returning it unindented", "error", err)
+		return data
+	}
+	return buf.Bytes()
+}
+
+// Remove the given keys from every object in a JSON document, at any depth.
+// The document is streamed token by token, so the order of the remaining
+// keys and the exact form of numbers are kept.
+func stripJSONKeys(data []byte, keys map[string]bool) ([]byte, error) {
+	if len(keys) == 0 {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var buf bytes.Buffer
+	if err := copyJSONValue(dec, &buf, keys); err != nil {
+		return nil, fmt.Errorf("failed to strip fields from result: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Copy the next JSON value from dec to buf, dropping object keys in keys.
+// A nil buf skips the value.
+func copyJSONValue(dec *json.Decoder, buf *bytes.Buffer, keys map[string]bool) error {
+	write := func(s string) {
+		if buf != nil {
+			buf.WriteString(s)
+		}
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		write("{")
+		first := true
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := keyTok.(string)
+			if keys[key] {
+				if err := copyJSONValue(dec, nil, keys); err != nil {
+					return err
+				}
+				continue
+			}
+			if !first {
+				write(",")
+			}
+			first = false
+			encoded, _ := json.Marshal(key)
+			write(string(encoded) + ":")
+			if err := copyJSONValue(dec, buf, keys); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		write("}")
+		return err
+	case json.Delim('['):
+		write("[")
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				write(",")
+			}
+			if err := copyJSONValue(dec, buf, keys); err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+		write("]")
+		return err
+	}
+
+	encoded, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	write(string(encoded))
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestStripJSONKeys(t *testing.T) {
+	tests := []struct {
+		data string
+		keys map[string]bool
+		want string
+	}{
+		{
+			`{"q": [{"uid": "0x1", "name": "Alice", "dgraph.type": ["Person"], "friend": [{"uid": "0x2", "name": "Bob"}]}]}`,
+			map[string]bool{"uid": true},
+			`{"q":[{"name":"Alice","dgraph.type":["Person"],"friend":[{"name":"Bob"}]}]}`,
+		},
+		{
+			`{"q": [{"uid": "0x1", "dgraph.type": ["Person"], "age": 1.50, "ok": true, "nick": null}]}`,
+			map[string]bool{"uid": true, "dgraph.type": true},
+			`{"q":[{"age":1.50,"ok":true,"nick":null}]}`,
+		},
+		{
+			`{"q": [{"zeta": 1, "alpha": "uid"}]}`,
+			map[string]bool{"uid": true},
+			`{"q":[{"zeta":1,"alpha":"uid"}]}`,
+		},
+		{
+			`{"q": []}`,
+			nil,
+			`{"q": []}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := stripJSONKeys([]byte(tt.data), tt.keys)
+		if err != nil {
+			t.Errorf("stripJSONKeys(%s) failed: %v", tt.data, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("stripJSONKeys(%s)\n got: %s\nwant: %s", tt.data, got, tt.want)
+		}
+	}
+
+	if _, err := stripJSONKeys([]byte(`{"q": [`), map[string]bool{"uid": true}); err == nil {
+		t.Errorf("stripJSONKeys with truncated JSON succeeded, want error")
+	}
+}
+
+func TestPrettyJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"object", `{"q":[{"uid":"0x1","age":30}]}`, "{\n  \"q\": [\n    {\n      \"uid\": \"0x1\",\n      \"age\": 30\n    }\n  ]\n}"},
+		{"empty", `{}`, "{}"},
+		{"invalid", `{"q":[`, `{"q":[`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(prettyJSON([]byte(tt.data))); got != tt.want {
+				t.Errorf("prettyJSON(%s) = %q, want %q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
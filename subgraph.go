@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Largest number of nodes a subgraph export may hold
+const maxSubgraphNodes = 10000
+
+// subgraphNode collects what an export knows about one node. A node
+// reached along several paths is merged from all of them.
+type subgraphNode struct {
+	uid    string
+	values map[string][]interface{} // scalar values by key, e.g. name or name@en
+	seen   map[string]bool          // encoded values already collected
+	edges  map[string][]string      // target uids by edge predicate
+}
+
+// subgraph is the set of nodes and edges reachable from the roots
+type subgraph struct {
+	nodes map[string]*subgraphNode
+	order []string // uids in the order they were found
+	edges int
+	geo   map[string]bool // predicates holding geo values
+}
+
+// Build the traversal query of a subgraph export. Every predicate the
+// schema defines is selected, edges with uid type are followed. Passwords
+// can't be read and Dgraph's internal predicates other than dgraph.type
+// aren't data, so both are left out.
+func buildSubgraphQuery(schema *schemaInfo, uids []string, depth int, follow []string) (string, map[string]bool, error) {
+	followed := map[string]bool{}
+	for _, edge := range follow {
+		p, ok := schema.predicate(edge)
+		if !ok || p.Type != "uid" {
+			return "", nil, fmt.Errorf("%s is not an edge predicate in the schema", edge)
+		}
+		followed[edge] = true
+	}
+
+	selection := []string{"uid"}
+	geo := map[string]bool{}
+	for _, p := range schema.Predicates {
+		switch {
+		case p.Type == "password":
+			continue
+		case strings.HasPrefix(p.Predicate, "dgraph.") && p.Predicate != "dgraph.type":
+			continue
+		case predicateAccess.check(p.Predicate) != nil:
+			continue
+		case p.Type == "uid" && len(followed) > 0 && !followed[p.Predicate]:
+			continue
+		}
+		if err := validateName("predicate", p.Predicate); err != nil {
+			continue
+		}
+		if p.Type == "geo" {
+			geo[p.Predicate] = true
+		}
+		name := p.Predicate
+		if p.Lang {
+			// Select the values in every language, not only untagged ones
+			name += "@*"
+		}
+		selection = append(selection, name)
+	}
+
+	query := fmt.Sprintf(`{
+	q(func: uid(%s)) @recurse(depth: %d, loop: false) {
+		%s
+	}
+}`, strings.Join(uids, ", "), depth, strings.Join(selection, "\n\t\t"))
+	return query, geo, nil
+}
+
+// Collect the nodes and edges of a traversal result. Nodes are visited
+// once per path reaching them; cycles are cut by the query's loop: false.
+func collectSubgraph(data []byte, geo map[string]bool) (*subgraph, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var result struct {
+		Q []map[string]interface{} `json:"q"`
+	}
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse query response: %v", err)
+	}
+
+	g := &subgraph{nodes: map[string]*subgraphNode{}, geo: geo}
+	for _, root := range result.Q {
+		if err := g.add(root); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Add a node and, recursively, the nodes it links to
+func (g *subgraph) add(obj map[string]interface{}) error {
+	uid, ok := obj["uid"].(string)
+	if !ok {
+		return fmt.Errorf("node without uid in query result")
+	}
+	node, ok := g.nodes[uid]
+	if !ok {
+		if len(g.nodes) == maxSubgraphNodes {
+			return fmt.Errorf("subgraph has more than %d nodes; lower depth or follow fewer edges", maxSubgraphNodes)
+		}
+		node = &subgraphNode{uid: uid, values: map[string][]interface{}{}, seen: map[string]bool{}, edges: map[string][]string{}}
+		g.nodes[uid] = node
+		g.order = append(g.order, uid)
+	}
+
+	for _, key := range sortedKeys(obj) {
+		if key == "uid" || strings.ContainsAny(key, "(|") {
+			continue
+		}
+		values, ok := obj[key].([]interface{})
+		if !ok {
+			values = []interface{}{obj[key]}
+		}
+		for _, value := range values {
+			child, isNode := value.(map[string]interface{})
+			if isNode && !g.geo[key] {
+				childUID, _ := child["uid"].(string)
+				if err := g.add(child); err != nil {
+					return err
+				}
+				if id := key + "\x00" + childUID; !node.seen[id] {
+					node.seen[id] = true
+					node.edges[key] = append(node.edges[key], childUID)
+					g.edges++
+				}
+				continue
+			}
+			encoded, _ := json.Marshal(value)
+			if id := key + "\x00" + string(encoded); !node.seen[id] {
+				node.seen[id] = true
+				node.values[key] = append(node.values[key], value)
+			}
+		}
+	}
+	return nil
+}
+
+// Name the blank node replacing a uid, so an import creates new nodes
+// instead of overwriting the ones with the same uid
+func subgraphBlankNode(uid string) string {
+	return "_:n" + uid
+}
+
+// Write the subgraph as N-Quads on blank nodes
+func (g *subgraph) nquads() string {
+	var b strings.Builder
+	for _, uid := range g.order {
+		node := g.nodes[uid]
+		subject := subgraphBlankNode(uid)
+		for _, key := range sortedKeys(node.values) {
+			predicate, lang, _ := strings.Cut(key, "@")
+			for _, value := range node.values[key] {
+				literal := formatRDFLiteral(value, lang)
+				if g.geo[predicate] {
+					encoded, _ := json.Marshal(value)
+					literal = fmt.Sprintf("%q^^<geo:geojson>", encoded)
+				}
+				fmt.Fprintf(&b, "%s <%s> %s .\n", subject, predicate, literal)
+			}
+		}
+		for _, predicate := range sortedKeys(node.edges) {
+			for _, target := range node.edges[predicate] {
+				fmt.Fprintf(&b, "%s <%s> %s .\n", subject, predicate, subgraphBlankNode(target))
+			}
+		}
+	}
+	return b.String()
+}
+
+// Write the subgraph as a list of JSON objects on blank nodes, one per
+// node, linking to each other by uid
+func (g *subgraph) objects() []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0, len(g.order))
+	for _, uid := range g.order {
+		node := g.nodes[uid]
+		obj := map[string]interface{}{"uid": subgraphBlankNode(uid)}
+		for key, values := range node.values {
+			if len(values) == 1 {
+				obj[key] = values[0]
+			} else {
+				obj[key] = values
+			}
+		}
+		for predicate, targets := range node.edges {
+			refs := make([]map[string]string, len(targets))
+			for i, target := range targets {
+				refs[i] = map[string]string{"uid": subgraphBlankNode(target)}
+			}
+			obj[predicate] = refs
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// Create handler for the subgraph export tool
+func createSubgraphExportHandler(client *dgo.Dgraph, maxDepth int) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		roots, err := stringsArgument(request, "uids")
+		if err != nil {
+			return nil, err
+		}
+		if len(roots) == 0 {
+			return nil, fmt.Errorf("uids must list at least one uid")
+		}
+		for i, uid := range roots {
+			if roots[i], err = normalizeUID(uid); err != nil {
+				return nil, err
+			}
+		}
+		depth, err := intArgument(request, "depth", 3)
+		if err != nil {
+			return nil, err
+		}
+		if depth < 1 || depth > maxDepth {
+			return nil, fmt.Errorf("depth must be between 1 and %d", maxDepth)
+		}
+		format, ok := request.Params.Arguments["format"].(string)
+		if !ok && request.Params.Arguments["format"] != nil {
+			return nil, fmt.Errorf("format must be a string")
+		}
+		if format == "" {
+			format = "nquads"
+		}
+		if format != "nquads" && format != "json" {
+			return nil, fmt.Errorf("format must be nquads or json")
+		}
+		follow, err := stringsArgument(request, "edges")
+		if err != nil {
+			return nil, err
+		}
+		for _, edge := range follow {
+			if err := predicateAccess.check(edge); err != nil {
+				return nil, err
+			}
+		}
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		query, geo, err := buildSubgraphQuery(schema, roots, depth, follow)
+		if err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+		g, err := collectSubgraph(resp.Json, geo)
+		if err != nil {
+			return nil, err
+		}
+
+		out := map[string]interface{}{
+			"message": fmt.Sprintf("Exported %d nodes and %d edges", len(g.nodes), g.edges),
+			"nodes":   len(g.nodes),
+			"edges":   g.edges,
+			"format":  format,
+		}
+		if format == "json" {
+			out["json"] = g.objects()
+		} else {
+			out["nquads"] = g.nquads()
+		}
+		encoded, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode export: %v", err)
+		}
+		return mcp.NewToolResultText(string(encoded)), nil
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildSubgraphQuery(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "dgraph.type", Type: "string", List: true},
+		{Predicate: "dgraph.xid", Type: "string"},
+		{Predicate: "name", Type: "string", Lang: true},
+		{Predicate: "secret", Type: "password"},
+		{Predicate: "location", Type: "geo"},
+		{Predicate: "friend", Type: "uid", List: true},
+		{Predicate: "boss", Type: "uid"},
+	}}
+
+	query, geo, err := buildSubgraphQuery(schema, []string{"0x1", "0x2"}, 2, nil)
+	if err != nil {
+		t.Fatalf("buildSubgraphQuery() failed: %v", err)
+	}
+	want := "{\n\tq(func: uid(0x1, 0x2)) @recurse(depth: 2, loop: false) {\n\t\tuid\n\t\tdgraph.type\n\t\tname@*\n\t\tlocation\n\t\tfriend\n\t\tboss\n\t}\n}"
+	if query != want {
+		t.Errorf("buildSubgraphQuery() =\n%s\nwant\n%s", query, want)
+	}
+	if !geo["location"] || len(geo) != 1 {
+		t.Errorf("geo predicates = %v, want location", geo)
+	}
+
+	query, _, err = buildSubgraphQuery(schema, []string{"0x1"}, 1, []string{"friend"})
+	if err != nil {
+		t.Fatalf("buildSubgraphQuery() with edges failed: %v", err)
+	}
+	if strings.Contains(query, "boss") || !strings.Contains(query, "friend") {
+		t.Errorf("buildSubgraphQuery() with edges = %s, want only friend followed", query)
+	}
+
+	if _, _, err := buildSubgraphQuery(schema, []string{"0x1"}, 1, []string{"name"}); err == nil {
+		t.Errorf("buildSubgraphQuery() following a scalar succeeded, want error")
+	}
+}
+
+func TestCollectSubgraph(t *testing.T) {
+	// Alice and Bob are friends of each other, and both know Carol
+	data := `{"q": [{
+		"uid": "0x1", "name": "Alice", "name@fr": "Alice", "age": 30,
+		"location": {"type": "Point", "coordinates": [1, 2]},
+		"friend": [
+			{"uid": "0x2", "name": "Bob", "friend": [{"uid": "0x1"}, {"uid": "0x3"}]},
+			{"uid": "0x3", "name": "Carol"}
+		]
+	}, {"uid": "0x3", "name": "Carol"}]}`
+
+	g, err := collectSubgraph([]byte(data), map[string]bool{"location": true})
+	if err != nil {
+		t.Fatalf("collectSubgraph() failed: %v", err)
+	}
+	if len(g.nodes) != 3 || g.edges != 4 {
+		t.Errorf("collectSubgraph() = %d nodes and %d edges, want 3 and 4", len(g.nodes), g.edges)
+	}
+
+	want := `_:n0x1 <age> "30"^^<xs:int> .
+_:n0x1 <location> "{\"coordinates\":[1,2],\"type\":\"Point\"}"^^<geo:geojson> .
+_:n0x1 <name> "Alice" .
+_:n0x1 <name> "Alice"@fr .
+_:n0x1 <friend> _:n0x2 .
+_:n0x1 <friend> _:n0x3 .
+_:n0x2 <name> "Bob" .
+_:n0x2 <friend> _:n0x1 .
+_:n0x2 <friend> _:n0x3 .
+_:n0x3 <name> "Carol" .
+`
+	if got := g.nquads(); got != want {
+		t.Errorf("nquads() =\n%s\nwant\n%s", got, want)
+	}
+	if err := validateNQuads(strings.TrimSuffix(g.nquads(), "\n"), false); err != nil {
+		t.Errorf("nquads() is not valid N-Quads: %v", err)
+	}
+
+	objects, err := json.Marshal(g.objects())
+	if err != nil {
+		t.Fatalf("failed to encode objects: %v", err)
+	}
+	wantJSON := `[{"age":30,"friend":[{"uid":"_:n0x2"},{"uid":"_:n0x3"}],"location":{"coordinates":[1,2],"type":"Point"},"name":"Alice","name@fr":"Alice","uid":"_:n0x1"},` +
+		`{"friend":[{"uid":"_:n0x1"},{"uid":"_:n0x3"}],"name":"Bob","uid":"_:n0x2"},` +
+		`{"name":"Carol","uid":"_:n0x3"}]`
+	if string(objects) != wantJSON {
+		t.Errorf("objects() =\n%s\nwant\n%s", objects, wantJSON)
+	}
+
+	if _, err := collectSubgraph([]byte(`{"q": [{"name": "x"}]}`), nil); err == nil {
+		t.Errorf("collectSubgraph() of a node without uid succeeded, want error")
+	}
+}
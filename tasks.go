@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Admin queries for background work: ongoing operations and predicates
+// being indexed on each instance, and the status of a single task such as
+// an export or backup
+const (
+	ongoingTasksQuery = `query { health { instance address status ongoing indexing } }`
+	taskStatusQuery   = `query ($id: String!) { task(input: {id: $id}) { kind status lastUpdated } }`
+)
+
+// instanceTasks is the background work reported by one cluster instance
+type instanceTasks struct {
+	Instance string   `json:"instance"`
+	Address  string   `json:"address"`
+	Status   string   `json:"status"`
+	Ongoing  []string `json:"ongoing"`
+	Indexing []string `json:"indexing"`
+}
+
+// clusterTasks summarizes the background work across the cluster
+type clusterTasks struct {
+	Busy      bool            `json:"busy"`
+	Ongoing   []string        `json:"ongoing"`
+	Indexing  []string        `json:"indexing"`
+	Instances []instanceTasks `json:"instances"`
+}
+
+// Summarize the health response of the admin endpoint
+func summarizeTasks(data json.RawMessage) (clusterTasks, error) {
+	var health struct {
+		Health []instanceTasks `json:"health"`
+	}
+	if err := json.Unmarshal(data, &health); err != nil {
+		return clusterTasks{}, fmt.Errorf("failed to parse health response: %v", err)
+	}
+
+	ongoing, indexing := map[string]bool{}, map[string]bool{}
+	tasks := clusterTasks{Instances: []instanceTasks{}}
+	for _, inst := range health.Health {
+		for _, op := range inst.Ongoing {
+			ongoing[op] = true
+		}
+		for _, p := range inst.Indexing {
+			indexing[p] = true
+		}
+		if inst.Ongoing == nil {
+			inst.Ongoing = []string{}
+		}
+		if inst.Indexing == nil {
+			inst.Indexing = []string{}
+		}
+		tasks.Instances = append(tasks.Instances, inst)
+	}
+	tasks.Ongoing = sortedKeys(ongoing)
+	tasks.Indexing = sortedKeys(indexing)
+	tasks.Busy = len(tasks.Ongoing) > 0 || len(tasks.Indexing) > 0
+	sort.Slice(tasks.Instances, func(i, j int) bool { return tasks.Instances[i].Address < tasks.Instances[j].Address })
+	return tasks, nil
+}
+
+// Create handler for the tasks tool. Dgraph has no API for cancelling
+// queries or tasks, so this only reports them.
+func createTasksHandler(endpoint, authToken string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	headers := adminHeaders(authToken)
+
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		taskID := ""
+		if v, ok := request.Params.Arguments["task_id"]; ok {
+			if taskID, ok = v.(string); !ok {
+				return nil, fmt.Errorf("task_id must be a string")
+			}
+		}
+
+		query, variables := ongoingTasksQuery, map[string]interface{}(nil)
+		if taskID != "" {
+			query, variables = taskStatusQuery, map[string]interface{}{"id": taskID}
+		}
+
+		body, err := postGraphQL(ctx, endpoint, headers, query, variables)
+		if err != nil {
+			return nil, fmt.Errorf("admin request failed: %v", err)
+		}
+		data, messages, err := parseGraphQLResponse(body)
+		if err != nil {
+			return nil, err
+		}
+		if len(messages) > 0 {
+			return nil, fmt.Errorf("admin request failed: %s", strings.Join(messages, "; "))
+		}
+
+		// A single task is returned as Dgraph reports it
+		if taskID != "" {
+			return mcp.NewToolResultText(string(data)), nil
+		}
+
+		tasks, err := summarizeTasks(data)
+		if err != nil {
+			return nil, err
+		}
+		out, err := json.Marshal(tasks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(out)), nil
+	}
+}
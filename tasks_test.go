@@ -0,0 +1,39 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeTasks(t *testing.T) {
+	data := []byte(`{"health": [
+		{"instance": "alpha", "address": "alpha2:7080", "status": "healthy", "ongoing": ["opIndexing"], "indexing": ["name", "email"]},
+		{"instance": "alpha", "address": "alpha1:7080", "status": "healthy", "ongoing": ["opIndexing", "opRollup"], "indexing": ["name"]},
+		{"instance": "zero", "address": "zero1:5080", "status": "healthy"}
+	]}`)
+
+	tasks, err := summarizeTasks(data)
+	if err != nil {
+		t.Fatalf("summarizeTasks failed: %v", err)
+	}
+	if !tasks.Busy {
+		t.Errorf("Busy = false, want true")
+	}
+	if !reflect.DeepEqual(tasks.Ongoing, []string{"opIndexing", "opRollup"}) {
+		t.Errorf("Ongoing = %v", tasks.Ongoing)
+	}
+	if !reflect.DeepEqual(tasks.Indexing, []string{"email", "name"}) {
+		t.Errorf("Indexing = %v", tasks.Indexing)
+	}
+	if len(tasks.Instances) != 3 || tasks.Instances[0].Address != "alpha1:7080" || tasks.Instances[2].Ongoing == nil {
+		t.Errorf("Instances = %+v", tasks.Instances)
+	}
+
+	idle, err := summarizeTasks([]byte(`{"health": [{"instance": "alpha", "address": "alpha1:7080", "status": "healthy", "ongoing": [], "indexing": []}]}`))
+	if err != nil {
+		t.Fatalf("summarizeTasks failed: %v", err)
+	}
+	if idle.Busy || len(idle.Ongoing) != 0 || len(idle.Indexing) != 0 {
+		t.Errorf("idle cluster = %+v, want not busy", idle)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// queryTemplate is a named, parameterized query predefined by an operator.
+// Its parameters are the variables declared in the query header, e.g.
+// `query q($name: string, $first: int = 10)`.
+type queryTemplate struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Query       string            `json:"query"`
+	Params      map[string]string `json:"params,omitempty"` // parameter descriptions
+	vars        []queryVariable
+}
+
+// templateParam describes a template parameter in the template listing
+type templateParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// templateListing describes a template in the template listing
+type templateListing struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Params      []templateParam `json:"params"`
+}
+
+// templateRegistry holds the query templates by name
+type templateRegistry struct {
+	templates map[string]*queryTemplate
+}
+
+// Load query templates from a JSON file holding a template or a list of
+// them, or from a directory of such files
+func loadQueryTemplates(path string) (*templateRegistry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+
+	r := &templateRegistry{templates: map[string]*queryTemplate{}}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		templates, err := parseQueryTemplates(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		for _, t := range templates {
+			if err := r.add(t); err != nil {
+				return nil, fmt.Errorf("%s: %v", file, err)
+			}
+		}
+	}
+	if len(r.templates) == 0 {
+		return nil, fmt.Errorf("no query templates found in %s", path)
+	}
+	return r, nil
+}
+
+// Parse a template or a list of templates
+func parseQueryTemplates(data []byte) ([]*queryTemplate, error) {
+	var templates []*queryTemplate
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(data, &templates); err != nil {
+			return nil, fmt.Errorf("invalid templates: %v", err)
+		}
+		return templates, nil
+	}
+	var t queryTemplate
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+	return []*queryTemplate{&t}, nil
+}
+
+// Check a template and add it to the registry
+func (r *templateRegistry) add(t *queryTemplate) error {
+	if err := validateName("template", t.Name); err != nil {
+		return err
+	}
+	if _, ok := r.templates[t.Name]; ok {
+		return fmt.Errorf("duplicate template %q", t.Name)
+	}
+	if _, err := parseQueryBlocks(t.Query); err != nil {
+		return fmt.Errorf("template %q: %v", t.Name, err)
+	}
+	vars, err := parseQueryVariables(t.Query)
+	if err != nil {
+		return fmt.Errorf("template %q: %v", t.Name, err)
+	}
+	for param := range t.Params {
+		if !declaresVariable(vars, param) {
+			return fmt.Errorf("template %q describes parameter %q, which its query doesn't declare", t.Name, param)
+		}
+	}
+	t.vars = vars
+	r.templates[t.Name] = t
+	return nil
+}
+
+// Check whether a variable is declared, with or without its leading $
+func declaresVariable(vars []queryVariable, name string) bool {
+	for _, v := range vars {
+		if v.Name == "$"+strings.TrimPrefix(name, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// Return the template names in sorted order
+func (r *templateRegistry) names() []string {
+	return sortedKeys(r.templates)
+}
+
+// List the templates with their parameters
+func (r *templateRegistry) list() []templateListing {
+	listing := []templateListing{}
+	for _, name := range r.names() {
+		t := r.templates[name]
+		params := []templateParam{}
+		for _, v := range t.vars {
+			name := strings.TrimPrefix(v.Name, "$")
+			params = append(params, templateParam{
+				Name:        name,
+				Type:        v.Type,
+				Required:    !v.HasDefault,
+				Default:     v.Default,
+				Description: t.Params[name],
+			})
+		}
+		listing = append(listing, templateListing{Name: t.Name, Description: t.Description, Params: params})
+	}
+	return listing
+}
+
+// Build the query variables of a template run from the given parameters,
+// rejecting unknown parameters and missing required ones
+func (t *queryTemplate) bindParams(params map[string]interface{}) (map[string]string, error) {
+	for name := range params {
+		if !declaresVariable(t.vars, name) {
+			return nil, fmt.Errorf("template %q has no parameter %q", t.Name, strings.TrimPrefix(name, "$"))
+		}
+	}
+	vars, err := toQueryVars(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range t.vars {
+		if _, ok := vars[v.Name]; !ok && !v.HasDefault {
+			return nil, fmt.Errorf("template %q needs parameter %q", t.Name, strings.TrimPrefix(v.Name, "$"))
+		}
+	}
+	return vars, nil
+}
+
+// Describe the templates in the run template tool description
+func (r *templateRegistry) toolDescription() string {
+	return "Run a query template predefined by the operator, passing its parameters as query variables. Available templates: " +
+		strings.Join(r.names(), ", ") + ". Read dgraph://templates for their descriptions and parameters"
+}
+
+// Create handler for the run template tool
+func createRunTemplateHandler(client *dgo.Dgraph, templates *templateRegistry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		name, ok := request.Params.Arguments["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("name must be a string")
+		}
+		t, ok := templates.templates[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown template %q; available templates: %s", name, strings.Join(templates.names(), ", "))
+		}
+
+		params := map[string]interface{}{}
+		if v, ok := request.Params.Arguments["params"]; ok && v != nil {
+			if params, ok = v.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("params must be an object")
+			}
+		}
+		vars, err := t.bindParams(params)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(t.Query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute the template with its parameters as variables
+		resp, err := txn.QueryWithVars(ctx, t.Query, vars)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		return mcp.NewToolResultText(string(resp.Json)), nil
+	}
+}
+
+// Create handler for the templates resource
+func createTemplatesResourceHandler(templates *templateRegistry) func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		out, err := json.Marshal(templates.list())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode templates: %v", err)
+		}
+
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      "dgraph://templates",
+				MIMEType: "application/json",
+				Text:     string(out),
+			},
+		}, nil
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTemplates = `[
+	{
+		"name": "people_by_name",
+		"description": "Find people by name",
+		"query": "query q($name: string, $first: int = 10) { q(func: eq(name, $name), first: $first) { uid name } }",
+		"params": {"name": "The exact name"}
+	},
+	{
+		"name": "all_people",
+		"query": "{ q(func: type(Person)) { uid name } }"
+	}
+]`
+
+func TestLoadQueryTemplates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "people.json"), []byte(testTemplates), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "movies.json"), []byte(`{"name": "movies", "query": "{ q(func: type(Movie)) { uid } }"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := loadQueryTemplates(dir)
+	if err != nil {
+		t.Fatalf("loadQueryTemplates() error = %v", err)
+	}
+	if got, want := r.names(), []string{"all_people", "movies", "people_by_name"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("names() = %v, want %v", got, want)
+	}
+
+	listing := r.list()
+	want := templateListing{
+		Name:        "people_by_name",
+		Description: "Find people by name",
+		Params: []templateParam{
+			{Name: "name", Type: "string", Required: true, Description: "The exact name"},
+			{Name: "first", Type: "int", Default: "10"},
+		},
+	}
+	if !reflect.DeepEqual(listing[2], want) {
+		t.Errorf("list()[2] = %+v, want %+v", listing[2], want)
+	}
+	if len(listing[0].Params) != 0 || listing[0].Params == nil {
+		t.Errorf("list()[0].Params = %#v, want an empty list", listing[0].Params)
+	}
+}
+
+func TestLoadQueryTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates string
+		wantErr   string
+	}{
+		{name: "invalid JSON", templates: `[{`, wantErr: "invalid templates"},
+		{name: "invalid name", templates: `{"name": "bad name", "query": "{ q(func: has(a)) { uid } }"}`, wantErr: "invalid template name"},
+		{name: "duplicate", templates: `[{"name": "a", "query": "{ q(func: has(a)) { uid } }"}, {"name": "a", "query": "{ q(func: has(a)) { uid } }"}]`, wantErr: "duplicate template"},
+		{name: "invalid query", templates: `{"name": "a", "query": "{ q(func: has(a)) "}`, wantErr: "template \"a\""},
+		{name: "undeclared parameter", templates: `{"name": "a", "query": "{ q(func: has(a)) { uid } }", "params": {"x": "X"}}`, wantErr: "doesn't declare"},
+		{name: "empty", templates: `[]`, wantErr: "no query templates"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "templates.json")
+			if err := os.WriteFile(path, []byte(tt.templates), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadQueryTemplates(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadQueryTemplates() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	r := &templateRegistry{templates: map[string]*queryTemplate{}}
+	templates, err := parseQueryTemplates([]byte(testTemplates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.add(templates[0]); err != nil {
+		t.Fatal(err)
+	}
+	tmpl := r.templates["people_by_name"]
+
+	tests := []struct {
+		name    string
+		params  map[string]interface{}
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "required only", params: map[string]interface{}{"name": "Alice"}, want: map[string]string{"$name": "Alice"}},
+		{name: "with default override", params: map[string]interface{}{"$name": "Alice", "first": float64(5)}, want: map[string]string{"$name": "Alice", "$first": "5"}},
+		{name: "missing required", params: map[string]interface{}{"first": float64(5)}, wantErr: true},
+		{name: "unknown parameter", params: map[string]interface{}{"name": "Alice", "age": float64(3)}, wantErr: true},
+		{name: "invalid value", params: map[string]interface{}{"name": []interface{}{"Alice"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tmpl.bindParams(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("bindParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindParams() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
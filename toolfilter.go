@@ -0,0 +1,89 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Tools that can change data, the schema or the cluster. Transactions are
+// only needed for mutations, and GraphQL operations may be mutations.
+var mutatingTools = map[string]bool{
+	"dgraph_mutate":                   true,
+	"dgraph_mutate_json_array":        true,
+	"dgraph_mutate_preview":           true,
+	"dgraph_mutate_batch_atomic":      true,
+	"dgraph_merge_nodes":              true,
+	"dgraph_schema_rollback":          true,
+	"dgraph_upsert":                   true,
+	"dgraph_upsert_by_xid":            true,
+	"dgraph_rename_predicate":         true,
+	"dgraph_delete_by_query":          true,
+	"dgraph_do":                       true,
+	"dgraph_import_file":              true,
+	"dgraph_alter_schema":             true,
+	"dgraph_alter_schema_from_source": true,
+	"dgraph_add_predicate":            true,
+	"dgraph_begin_txn":                true,
+	"dgraph_commit_txn":               true,
+	"dgraph_discard_txn":              true,
+	"dgraph_graphql":                  true,
+	"dgraph_admin":                    true,
+}
+
+// toolFilter decides which tools are registered, from the comma-separated
+// MCP_ENABLED_TOOLS and MCP_DISABLED_TOOLS lists. An empty enabled list
+// enables every tool; disabled tools are removed from what is enabled. In
+// read-only mode mutating tools are never registered.
+type toolFilter struct {
+	enabled  map[string]bool
+	disabled map[string]bool
+	readOnly bool
+	seen     map[string]bool
+}
+
+// Create a tool filter from the enabled and disabled tool lists
+func newToolFilter(enabled, disabled string, readOnly bool) *toolFilter {
+	return &toolFilter{
+		enabled:  parseToolList(enabled),
+		disabled: parseToolList(disabled),
+		readOnly: readOnly,
+		seen:     make(map[string]bool),
+	}
+}
+
+// Parse a comma-separated list of tool names
+func parseToolList(list string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// Check whether a tool should be registered
+func (f *toolFilter) allow(name string) bool {
+	f.seen[name] = true
+	if f.readOnly && mutatingTools[name] {
+		return false
+	}
+	if len(f.enabled) > 0 && !f.enabled[name] {
+		return false
+	}
+	return !f.disabled[name]
+}
+
+// List the configured names that matched no tool, which are likely typos
+func (f *toolFilter) unknown() []string {
+	var names []string
+	for _, list := range []map[string]bool{f.enabled, f.disabled} {
+		for name := range list {
+			if !f.seen[name] {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToolFilter(t *testing.T) {
+	tests := []struct {
+		enabled  string
+		disabled string
+		readOnly bool
+		allowed  []string
+	}{
+		{"", "", false, []string{"dgraph_query", "dgraph_mutate", "dgraph_alter_schema"}},
+		{"", "dgraph_mutate, dgraph_alter_schema", false, []string{"dgraph_query"}},
+		{"dgraph_query,dgraph_mutate", "", false, []string{"dgraph_query", "dgraph_mutate"}},
+		{"dgraph_query,dgraph_mutate", "dgraph_mutate", false, []string{"dgraph_query"}},
+		{"", "", true, []string{"dgraph_query"}},
+		{"dgraph_mutate", "", true, nil},
+	}
+
+	for _, tt := range tests {
+		f := newToolFilter(tt.enabled, tt.disabled, tt.readOnly)
+		var allowed []string
+		for _, name := range []string{"dgraph_query", "dgraph_mutate", "dgraph_alter_schema"} {
+			if f.allow(name) {
+				allowed = append(allowed, name)
+			}
+		}
+		if !reflect.DeepEqual(allowed, tt.allowed) {
+			t.Errorf("enabled %q, disabled %q, read-only %t: allowed %v, want %v", tt.enabled, tt.disabled, tt.readOnly, allowed, tt.allowed)
+		}
+	}
+}
+
+func TestToolFilterUnknown(t *testing.T) {
+	f := newToolFilter("dgraph_query,dgraph_qeury", ",dgraph_mutat,", false)
+	f.allow("dgraph_query")
+	f.allow("dgraph_mutate")
+
+	if got := f.unknown(); !reflect.DeepEqual(got, []string{"dgraph_mutat", "dgraph_qeury"}) {
+		t.Errorf("unknown() = %v", got)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// Span export settings
+const (
+	defaultServiceName = "dgraph-mcp-server"
+	spanExportInterval = 5 * time.Second
+	spanExportTimeout  = 10 * time.Second
+)
+
+// spanTracer records spans of tool calls and the Dgraph calls they make.
+// The OpenTelemetry SDK batches them and hands them to the exporter,
+// dropping spans when the exporter falls too far behind.
+type spanTracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+// Create a tracer handing spans to exporter, reporting service as the
+// service name
+func newSpanTracer(exporter sdktrace.SpanExporter, service string) *spanTracer {
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter, sdktrace.WithBatchTimeout(spanExportInterval)),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+	return &spanTracer{provider: provider, tracer: provider.Tracer(defaultServiceName)}
+}
+
+// Create a tracer exporting to an OTLP/HTTP collector. The exporter reads
+// the collector's address, such as http://localhost:4318, from
+// OTEL_EXPORTER_OTLP_ENDPOINT, along with the other OTEL_EXPORTER_OTLP_*
+// settings, and sends spans to its /v1/traces path.
+func newOTLPTracer(ctx context.Context, service string) (*spanTracer, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %v", err)
+	}
+	return newSpanTracer(exporter, service), nil
+}
+
+// Export the spans still queued and stop the tracer
+func (t *spanTracer) shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
+
+// SSE context function continuing the trace of the HTTP request carrying a
+// message, as given in its W3C traceparent header
+func traceparentContext(ctx context.Context, r *http.Request) context.Context {
+	return propagation.TraceContext{}.Extract(ctx, propagation.HeaderCarrier(r.Header))
+}
+
+// Tool handler middleware recording a span per tool call, with the tool
+// name, the length of its query argument and the size of its result
+func (t *spanTracer) toolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		ctx, span := t.tracer.Start(ctx, "tools/call "+request.Params.Name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attribute.String("mcp.tool.name", request.Params.Name)))
+		if query, ok := request.Params.Arguments["query"].(string); ok {
+			span.SetAttributes(attribute.Int("db.query.length", len(query)))
+		}
+		defer func() {
+			failed := err
+			if result != nil {
+				size := 0
+				for _, content := range result.Content {
+					if text, ok := content.(mcp.TextContent); ok {
+						size += len(text.Text)
+					}
+				}
+				span.SetAttributes(attribute.Int("mcp.result.size", size))
+				if result.IsError && failed == nil {
+					failed = fmt.Errorf("tool returned an error result")
+				}
+			}
+			if failed != nil {
+				span.SetStatus(codes.Error, failed.Error())
+			}
+			span.End()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// Client interceptors recording a span per Dgraph call, a child of the tool
+// call's span. otelgrpc records the call and its status; dgraphSpanAttributes
+// adds the query length and the size of the JSON result.
+func (t *spanTracer) unaryInterceptors() []grpc.UnaryClientInterceptor {
+	return []grpc.UnaryClientInterceptor{
+		otelgrpc.UnaryClientInterceptor(otelgrpc.WithTracerProvider(t.provider)),
+		dgraphSpanAttributes,
+	}
+}
+
+// Client interceptor adding what a Dgraph call sends and returns to the
+// span of the call
+func dgraphSpanAttributes(ctx context.Context, method string, req, reply interface{}, conn *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.String("db.system", "dgraph"))
+	if r, ok := req.(*api.Request); ok {
+		span.SetAttributes(attribute.Int("db.query.length", len(r.Query)), attribute.Int("db.mutations", len(r.Mutations)))
+	}
+
+	err := invoker(ctx, method, req, reply, conn, opts...)
+	if r, ok := reply.(*api.Response); ok && err == nil {
+		span.SetAttributes(attribute.Int("db.result.size", len(r.Json)))
+	}
+	return err
+}
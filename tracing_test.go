@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestTraceparentContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"valid", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"invalid version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"zero trace id", "00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"zero span id", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"not hex", "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", false},
+		{"short", "00-4bf92f3577b34da6-00f067aa0ba902b7-01", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("POST", "/message", nil)
+		if tt.header != "" {
+			r.Header.Set("traceparent", tt.header)
+		}
+		sc := trace.SpanContextFromContext(traceparentContext(context.Background(), r))
+		if sc.IsValid() != tt.ok {
+			t.Errorf("%s: traceparentContext() valid = %v, want %v", tt.name, sc.IsValid(), tt.ok)
+		}
+		if tt.ok && (!sc.IsRemote() || sc.SpanID().String() != "00f067aa0ba902b7") {
+			t.Errorf("%s: traceparentContext() span = %s, remote %v", tt.name, sc.SpanID(), sc.IsRemote())
+		}
+	}
+}
+
+// Dial a connection for the interceptors to report on. It never connects,
+// as the tests replace the call itself.
+func testConn(t *testing.T) *grpc.ClientConn {
+	conn, err := grpc.Dial("localhost:9080", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// Call through the tracer's interceptors, with invoker standing in for Dgraph
+func invokeTraced(ctx context.Context, tracer *spanTracer, conn *grpc.ClientConn, req, reply interface{}, invoker grpc.UnaryInvoker) error {
+	interceptors := tracer.unaryInterceptors()
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return interceptor(ctx, method, req, reply, cc, next, opts...)
+		}
+	}
+	return invoker(ctx, "/api.Dgraph/Query", req, reply, conn)
+}
+
+// Collect the attributes of a span by key
+func spanAttributes(s tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range s.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestSpanTracer(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newSpanTracer(exporter, "test")
+	conn := testConn(t)
+
+	// A tool call continuing a remote trace makes a failing Dgraph call
+	r := httptest.NewRequest("POST", "/message", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := traceparentContext(context.Background(), r)
+	handler := tracer.toolMiddleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return nil, invokeTraced(ctx, tracer, conn, &api.Request{Query: "{ q() }"}, &api.Response{},
+			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return fmt.Errorf("connection refused")
+			})
+	})
+	var request mcp.CallToolRequest
+	request.Params.Name = "dgraph_query"
+	request.Params.Arguments = map[string]interface{}{"query": "{ q() }"}
+	if _, err := handler(ctx, request); err == nil {
+		t.Fatalf("handler succeeded, want the Dgraph error")
+	}
+
+	// Flush rather than shut down, which clears the in-memory exporter
+	if err := tracer.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("exported %d spans, want 2", len(spans))
+	}
+	dgraph, tool := spans[0], spans[1]
+	if tool.Name != "tools/call dgraph_query" || dgraph.Name != "api.Dgraph/Query" {
+		t.Errorf("span names = %q, %q", tool.Name, dgraph.Name)
+	}
+	if tool.SpanKind != trace.SpanKindServer || dgraph.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kinds = %v, %v, want server and client", tool.SpanKind, dgraph.SpanKind)
+	}
+	if tool.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || dgraph.SpanContext.TraceID() != tool.SpanContext.TraceID() {
+		t.Errorf("trace ids = %s, %s, want the remote trace", tool.SpanContext.TraceID(), dgraph.SpanContext.TraceID())
+	}
+	if tool.Parent.SpanID().String() != "00f067aa0ba902b7" || dgraph.Parent.SpanID() != tool.SpanContext.SpanID() {
+		t.Errorf("parents = %s, %s, want the remote span and the tool span", tool.Parent.SpanID(), dgraph.Parent.SpanID())
+	}
+	if tool.Status.Code != codes.Error || dgraph.Status.Code != codes.Error {
+		t.Errorf("status codes = %v, %v, want errors", tool.Status.Code, dgraph.Status.Code)
+	}
+	attrs := spanAttributes(tool)
+	if attrs["mcp.tool.name"].AsString() != "dgraph_query" || attrs["db.query.length"].AsInt64() != 7 {
+		t.Errorf("tool span attributes = %v", tool.Attributes)
+	}
+	if attrs := spanAttributes(dgraph); attrs["db.system"].AsString() != "dgraph" || attrs["db.query.length"].AsInt64() != 7 {
+		t.Errorf("Dgraph span attributes = %v", dgraph.Attributes)
+	}
+}
+
+func TestDgraphSpanAttributes(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tracer := newSpanTracer(exporter, "test")
+
+	req := &api.Request{Query: "{ q(func: uid(0x1)) { uid } }", Mutations: []*api.Mutation{{}}}
+	err := invokeTraced(context.Background(), tracer, testConn(t), req, &api.Response{},
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			reply.(*api.Response).Json = []byte(`{"q":[{"uid":"0x1"}]}`)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	// Flush rather than shut down, which clears the in-memory exporter
+	if err := tracer.provider.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("ForceFlush() failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	attrs := spanAttributes(spans[0])
+	if attrs["db.mutations"].AsInt64() != 1 || attrs["db.result.size"].AsInt64() != 21 {
+		t.Errorf("span attributes = %v, want 1 mutation and a 21 byte result", spans[0].Attributes)
+	}
+	if spans[0].Status.Code == codes.Error {
+		t.Errorf("successful call marked as an error")
+	}
+}
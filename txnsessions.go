@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+const (
+	// How long an open transaction may sit unused before it is discarded
+	defaultTxnTTL = 5 * time.Minute
+	// How often idle transactions are looked for between tool calls
+	txnExpiryInterval = 10 * time.Second
+)
+
+// openTxn is a transaction kept open across tool calls
+type openTxn struct {
+	mu       sync.Mutex
+	txn      *dgo.Txn
+	lastUsed time.Time
+}
+
+// txnRegistry holds transactions started with dgraph_begin_txn until they are
+// committed, discarded or expire. A dgo transaction must not be used
+// concurrently, so each one is locked while a tool call is using it.
+type txnRegistry struct {
+	mu   sync.Mutex
+	ttl  time.Duration
+	now  func() time.Time
+	txns map[string]*openTxn
+}
+
+// Create an empty transaction registry
+func newTxnRegistry(ttl time.Duration) *txnRegistry {
+	return &txnRegistry{
+		ttl:  ttl,
+		now:  time.Now,
+		txns: make(map[string]*openTxn),
+	}
+}
+
+// Register a new transaction and return its id
+func (r *txnRegistry) begin(txn *dgo.Txn) (string, error) {
+	id, err := newTxnID()
+	if err != nil {
+		return "", err
+	}
+
+	r.expire()
+
+	r.mu.Lock()
+	r.txns[id] = &openTxn{txn: activity.startTxn(txn), lastUsed: r.now()}
+	r.mu.Unlock()
+	return id, nil
+}
+
+// Run fn with the transaction registered under id
+func (r *txnRegistry) use(id string, fn func(txn *dgo.Txn) error) error {
+	r.expire()
+
+	r.mu.Lock()
+	ot, ok := r.txns[id]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown or expired transaction %q", id)
+	}
+
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	ot.lastUsed = r.now()
+	defer func() { ot.lastUsed = r.now() }()
+	return fn(ot.txn)
+}
+
+// Remove the transaction registered under id and commit or discard it
+func (r *txnRegistry) finish(ctx context.Context, id string, commit bool) error {
+	r.mu.Lock()
+	ot, ok := r.txns[id]
+	delete(r.txns, id)
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown or expired transaction %q", id)
+	}
+
+	// Wait for any call still using the transaction
+	ot.mu.Lock()
+	defer ot.mu.Unlock()
+	defer activity.finishTxn(ctx, ot.txn)
+
+	if commit {
+		return ot.txn.Commit(ctx)
+	}
+	return nil
+}
+
+// Discard transactions that have been idle for longer than the TTL
+func (r *txnRegistry) expire() {
+	var expired []*openTxn
+	r.mu.Lock()
+	for id, ot := range r.txns {
+		// Skip transactions a call is currently using
+		if !ot.mu.TryLock() {
+			continue
+		}
+		if r.now().Sub(ot.lastUsed) >= r.ttl {
+			delete(r.txns, id)
+			expired = append(expired, ot)
+		}
+		ot.mu.Unlock()
+	}
+	r.mu.Unlock()
+
+	if len(expired) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), discardTimeout)
+	defer cancel()
+	for _, ot := range expired {
+		activity.finishTxn(ctx, ot.txn)
+	}
+}
+
+// Discard idle transactions every interval until ctx is done, so they don't
+// hold on to Dgraph resources while no tool calls are made
+func (r *txnRegistry) expireEvery(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.expire()
+		}
+	}
+}
+
+// Generate a random transaction id
+func newTxnID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate transaction id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Create handler for the begin transaction tool
+func createBeginTxnHandler(client *dgo.Dgraph, txns *txnRegistry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := txns.begin(client.NewTxn())
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprintf(`{"txn_id": %q}`, id)), nil
+	}
+}
+
+// Create handler for the commit and discard transaction tools
+func createFinishTxnHandler(txns *txnRegistry, commit bool) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, ok := request.Params.Arguments["txn_id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("txn_id must be a string")
+		}
+
+		err := txns.finish(ctx, id, commit)
+		if commit {
+			// Committed mutations change data and can add predicates to the schema
+			invalidateCaches()
+		}
+		if err != nil {
+			if commit {
+				return nil, fmt.Errorf("commit failed: %v", err)
+			}
+			return nil, err
+		}
+
+		if commit {
+			return mcp.NewToolResultText("Transaction committed"), nil
+		}
+		return mcp.NewToolResultText("Transaction discarded"), nil
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+)
+
+func newTestTxnClient() *dgo.Dgraph {
+	return dgo.NewDgraphClient(api.NewDgraphClient(nil))
+}
+
+func TestTxnRegistryLifecycle(t *testing.T) {
+	client := newTestTxnClient()
+	r := newTxnRegistry(time.Minute)
+
+	id, err := r.begin(client.NewTxn())
+	if err != nil {
+		t.Fatalf("begin failed: %v", err)
+	}
+
+	var used *dgo.Txn
+	if err := r.use(id, func(txn *dgo.Txn) error {
+		used = txn
+		return nil
+	}); err != nil {
+		t.Fatalf("use failed: %v", err)
+	}
+	if used == nil {
+		t.Fatalf("use did not pass the transaction")
+	}
+
+	if err := r.finish(context.Background(), id, true); err != nil {
+		t.Fatalf("finish failed: %v", err)
+	}
+	if err := r.use(id, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("use after finish succeeded, want error")
+	}
+	if err := r.finish(context.Background(), id, false); err == nil {
+		t.Errorf("second finish succeeded, want error")
+	}
+}
+
+func TestTxnRegistryExpiry(t *testing.T) {
+	client := newTestTxnClient()
+	now := time.Now()
+	r := newTxnRegistry(time.Minute)
+	r.now = func() time.Time { return now }
+
+	stale, _ := r.begin(client.NewTxn())
+	now = now.Add(30 * time.Second)
+	fresh, _ := r.begin(client.NewTxn())
+
+	// Using a transaction keeps it alive
+	now = now.Add(45 * time.Second)
+	if err := r.use(fresh, func(*dgo.Txn) error { return nil }); err != nil {
+		t.Fatalf("use of fresh transaction failed: %v", err)
+	}
+	if err := r.use(stale, func(*dgo.Txn) error { return nil }); err == nil {
+		t.Errorf("use of expired transaction succeeded, want error")
+	}
+
+	now = now.Add(45 * time.Second)
+	if err := r.use(fresh, func(*dgo.Txn) error { return nil }); err != nil {
+		t.Errorf("use of recently used transaction failed: %v", err)
+	}
+}
+
+func TestTxnRegistryExpiresInBackground(t *testing.T) {
+	client := newTestTxnClient()
+	r := newTxnRegistry(10 * time.Millisecond)
+	id, _ := r.begin(client.NewTxn())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		r.expireEvery(ctx, 5*time.Millisecond)
+		close(stopped)
+	}()
+
+	// No further calls are made, the ticker alone must discard the transaction
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.mu.Lock()
+		_, open := r.txns[id]
+		r.mu.Unlock()
+		if !open {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("idle transaction was not discarded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatalf("expiry loop did not stop on cancellation")
+	}
+}
+
+func TestTxnRegistryConcurrentUse(t *testing.T) {
+	client := newTestTxnClient()
+	r := newTxnRegistry(time.Minute)
+	id, _ := r.begin(client.NewTxn())
+
+	var (
+		wg     sync.WaitGroup
+		active int
+		mu     sync.Mutex
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.use(id, func(*dgo.Txn) error {
+				mu.Lock()
+				active++
+				if active > 1 {
+					t.Errorf("transaction used concurrently")
+				}
+				mu.Unlock()
+				time.Sleep(time.Millisecond)
+				mu.Lock()
+				active--
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+}
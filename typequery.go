@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Build a query fetching all nodes of a type, optionally filtered. Without
+// predicates all of the type's predicates are expanded. With after the
+// nodes start after that uid, for iterating in uid order.
+func buildTypeQuery(typeName string, predicates []string, first, offset int, after string, filter compiledFilter) (string, error) {
+	if err := validateName("type", typeName); err != nil {
+		return "", err
+	}
+	for _, p := range predicates {
+		if err := validateField("predicate", p); err != nil {
+			return "", err
+		}
+	}
+
+	args := fmt.Sprintf("func: type(%s)", typeName)
+	if first > 0 {
+		args += fmt.Sprintf(", first: %d", first)
+	}
+	if offset > 0 {
+		args += fmt.Sprintf(", offset: %d", offset)
+	}
+	if after != "" {
+		args += ", after: " + after
+	}
+
+	fields := "expand(_all_)"
+	if len(predicates) > 0 {
+		fields = strings.Join(predicates, "\n\t\t")
+	}
+
+	return filter.withHeader(fmt.Sprintf(`{
+	q(%s)%s {
+		uid
+		%s
+	}
+}`, args, filter.directive(), fields)), nil
+}
+
+// Create handler for the query by type tool
+func createTypeQueryHandler(client *dgo.Dgraph) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		typeName, ok := request.Params.Arguments["type"].(string)
+		if !ok {
+			return nil, fmt.Errorf("type must be a string")
+		}
+
+		predicates, err := stringsArgument(request, "predicates")
+		if err != nil {
+			return nil, err
+		}
+		if predicates, err = langFields(ctx, client, request, predicates, "predicates"); err != nil {
+			return nil, err
+		}
+
+		first, err := intArgument(request, "first", 0)
+		if err != nil {
+			return nil, err
+		}
+		offset, err := intArgument(request, "offset", 0)
+		if err != nil {
+			return nil, err
+		}
+		if first < 0 || offset < 0 {
+			return nil, fmt.Errorf("first and offset must not be negative")
+		}
+		var after string
+		if request.Params.Arguments["after"] != nil {
+			if offset > 0 {
+				return nil, fmt.Errorf("offset cannot be combined with after")
+			}
+			if after, err = uidArgument(request, "after"); err != nil {
+				return nil, err
+			}
+		}
+
+		filter, err := filterArgument(request, "filter")
+		if err != nil {
+			return nil, err
+		}
+
+		query, err := buildTypeQuery(typeName, predicates, first, offset, after, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.QueryWithVars(ctx, query, filter.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v", err)
+		}
+
+		// A full page continues at the cursor given in the result's _meta field
+		result := mcp.NewToolResultText(string(resp.Json))
+		var nodes struct {
+			Q []uidNode `json:"q"`
+		}
+		if err := json.Unmarshal(resp.Json, &nodes); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+		if cursor := nextCursor(resultUIDs(nodes.Q), first); cursor != "" {
+			result.Meta = map[string]interface{}{"next_cursor": cursor}
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestBuildTypeQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		typeName   string
+		predicates []string
+		first      int
+		offset     int
+		after      string
+		want       string
+	}{
+		{
+			name:     "expands all predicates",
+			typeName: "Person",
+			want:     "{\n\tq(func: type(Person)) {\n\t\tuid\n\t\texpand(_all_)\n\t}\n}",
+		},
+		{
+			name:       "selected predicates",
+			typeName:   "Person",
+			predicates: []string{"name", "age"},
+			want:       "{\n\tq(func: type(Person)) {\n\t\tuid\n\t\tname\n\t\tage\n\t}\n}",
+		},
+		{
+			name:       "first and offset",
+			typeName:   "Person",
+			predicates: []string{"name"},
+			first:      10,
+			offset:     20,
+			want:       "{\n\tq(func: type(Person), first: 10, offset: 20) {\n\t\tuid\n\t\tname\n\t}\n}",
+		},
+		{
+			name:       "after a uid",
+			typeName:   "Person",
+			predicates: []string{"name"},
+			first:      10,
+			after:      "0x2a",
+			want:       "{\n\tq(func: type(Person), first: 10, after: 0x2a) {\n\t\tuid\n\t\tname\n\t}\n}",
+		},
+		{
+			name:       "language-tagged predicate",
+			typeName:   "Film",
+			predicates: []string{"name@en"},
+			want:       "{\n\tq(func: type(Film)) {\n\t\tuid\n\t\tname@en\n\t}\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildTypeQuery(tt.typeName, tt.predicates, tt.first, tt.offset, tt.after, compiledFilter{})
+			if err != nil {
+				t.Fatalf("buildTypeQuery() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("buildTypeQuery() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTypeQueryFilter(t *testing.T) {
+	filter, err := compileFilter(map[string]interface{}{"or": []interface{}{
+		map[string]interface{}{"predicate": "age", "op": "lt", "value": float64(18)},
+		map[string]interface{}{"predicate": "guardian", "op": "has"},
+	}})
+	if err != nil {
+		t.Fatalf("compileFilter() failed: %v", err)
+	}
+
+	got, err := buildTypeQuery("Person", nil, 5, 0, "", filter)
+	if err != nil {
+		t.Fatalf("buildTypeQuery() failed: %v", err)
+	}
+	want := "query filtered($f0: int) {\n\tq(func: type(Person), first: 5) @filter((lt(age, $f0) OR has(guardian))) {\n\t\tuid\n\t\texpand(_all_)\n\t}\n}"
+	if got != want {
+		t.Errorf("buildTypeQuery() =\n%s\nwant\n%s", got, want)
+	}
+	if filter.Vars["$f0"] != "18" {
+		t.Errorf("filter vars = %v, want $f0 = 18", filter.Vars)
+	}
+}
+
+func TestBuildTypeQueryErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		typeName   string
+		predicates []string
+		wantErr    string
+	}{
+		{"invalid type", "Person) { uid } q2(func: has(password)", nil, "invalid type"},
+		{"empty type", "", nil, "invalid type"},
+		{"invalid predicate", "Person", []string{"name } password {"}, "invalid predicate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildTypeQuery(tt.typeName, tt.predicates, 0, 0, "", compiledFilter{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("buildTypeQuery() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTypeQueryHandlerArguments(t *testing.T) {
+	handler := createTypeQueryHandler(dgo.NewDgraphClient())
+	tests := []struct {
+		name    string
+		args    map[string]interface{}
+		wantErr string
+	}{
+		{"missing type", map[string]interface{}{}, "type must be a string"},
+		{"negative first", map[string]interface{}{"type": "Person", "first": float64(-1)}, "must not be negative"},
+		{"offset and after", map[string]interface{}{"type": "Person", "offset": float64(10), "after": "0x1"}, "offset cannot be combined with after"},
+		{"invalid after", map[string]interface{}{"type": "Person", "after": "abc"}, "uid"},
+		{"invalid filter", map[string]interface{}{"type": "Person", "filter": map[string]interface{}{"predicate": "age", "op": "regexp", "value": "x"}}, "unknown op"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request mcp.CallToolRequest
+			request.Params.Arguments = tt.args
+			_, err := handler(context.Background(), request)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("handler error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Normalize a uid given as 0x-prefixed hex or decimal into Dgraph's
+// canonical lowercase 0x form. Uid 0 is never assigned and is rejected.
+func normalizeUID(uid string) (string, error) {
+	s := strings.TrimSpace(uid)
+
+	var (
+		n   uint64
+		err error
+	)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		n, err = strconv.ParseUint(s[2:], 16, 64)
+	} else {
+		n, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil || n == 0 {
+		return "", fmt.Errorf("invalid uid %q: expected a non-zero 0x-prefixed hex or decimal value", uid)
+	}
+
+	return "0x" + strconv.FormatUint(n, 16), nil
+}
+
+// Get a uid argument, accepting both strings and JSON numbers
+func uidArgument(request mcp.CallToolRequest, name string) (string, error) {
+	switch v := request.Params.Arguments[name].(type) {
+	case string:
+		return normalizeUID(v)
+	case float64:
+		if v != float64(uint64(v)) {
+			return "", fmt.Errorf("invalid %s %v: must be an integer", name, v)
+		}
+		return normalizeUID(strconv.FormatUint(uint64(v), 10))
+	default:
+		return "", fmt.Errorf("%s must be a string", name)
+	}
+}
+
+// Create handler for the uid normalization tool
+func createNormalizeUIDHandler() func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		uid, err := uidArgument(request, "uid")
+		if err != nil {
+			return nil, err
+		}
+
+		return mcp.NewToolResultText(uid), nil
+	}
+}
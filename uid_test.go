@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestNormalizeUID(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"0x1", "0x1"},
+		{"0X1F", "0x1f"},
+		{"0xABCDEF", "0xabcdef"},
+		{"1", "0x1"},
+		{"31", "0x1f"},
+		{" 255 ", "0xff"},
+		{"18446744073709551615", "0xffffffffffffffff"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeUID(tt.in)
+		if err != nil {
+			t.Errorf("normalizeUID(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("normalizeUID(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeUIDInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"0",
+		"0x0",
+		"0x",
+		"-1",
+		"abc",
+		"0xZZ",
+		"1.5",
+		"_:alice",
+		"18446744073709551616",
+	} {
+		if got, err := normalizeUID(in); err == nil {
+			t.Errorf("normalizeUID(%q) = %q, want error", in, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Run an upsert request in a new transaction per attempt, retrying when a
+// conflicting transaction aborts it. Every retry re-runs the query, so the
+// mutation applies to what the query finds at that point.
+func runUpsert(ctx context.Context, client *dgo.Dgraph, retry retryPolicy, req *api.Request) (*api.Response, int, error) {
+	var resp *api.Response
+	attempts, err := retry.do(ctx, func() error {
+		txn := activity.startTxn(client.NewTxn())
+		defer activity.finishTxn(ctx, txn)
+
+		var err error
+		resp, err = txn.Do(ctx, req)
+		return err
+	})
+	if attempts > 1 {
+		slog.Debug("Upsert retried after aborts", "attempts", attempts, "error", err)
+	}
+	return resp, attempts, err
+}
+
+// Create handler for the upsert tool
+func createUpsertHandler(client *dgo.Dgraph, limits queryLimits, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		query, ok := request.Params.Arguments["query"].(string)
+		if !ok || strings.TrimSpace(query) == "" {
+			return nil, fmt.Errorf("query must be a non-empty string")
+		}
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		mutation, ok := request.Params.Arguments["mutation"].(string)
+		if !ok && request.Params.Arguments["mutation"] != nil {
+			return nil, fmt.Errorf("mutation must be a string")
+		}
+		deletion, ok := request.Params.Arguments["delete"].(string)
+		if !ok && request.Params.Arguments["delete"] != nil {
+			return nil, fmt.Errorf("delete must be a string")
+		}
+		if strings.TrimSpace(mutation) == "" && strings.TrimSpace(deletion) == "" {
+			return nil, fmt.Errorf("mutation or delete must be given")
+		}
+
+		if err := validateNQuads(mutation, false); err != nil {
+			return nil, fmt.Errorf("mutation: %v", err)
+		}
+		if err := validateNQuads(deletion, true); err != nil {
+			return nil, fmt.Errorf("delete: %v", err)
+		}
+		if err := predicateAccess.checkNQuads(mutation); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkNQuads(deletion); err != nil {
+			return nil, err
+		}
+
+		vars, err := queryVarsArgument(request, "variables")
+		if err != nil {
+			return nil, err
+		}
+
+		mu := &api.Mutation{}
+		cond, ok := request.Params.Arguments["cond"].(string)
+		if !ok && request.Params.Arguments["cond"] != nil {
+			return nil, fmt.Errorf("cond must be a string")
+		}
+		if strings.TrimSpace(cond) != "" {
+			if mu.Cond, err = upsertCond(cond, query); err != nil {
+				return nil, err
+			}
+		}
+		if mutation != "" {
+			mu.SetNquads = []byte(mutation)
+		}
+		if deletion != "" {
+			mu.DelNquads = []byte(deletion)
+		}
+
+		// Run the query and mutation as one committed upsert block
+		resp, attempts, err := runUpsert(ctx, client, retry, &api.Request{
+			Query:     query,
+			Vars:      vars,
+			Mutations: []*api.Mutation{mu},
+			CommitNow: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed after %d attempts: %v", attempts, err)
+		}
+
+		// Mutations change data and can add predicates to the schema
+		invalidateCaches()
+
+		out, err := json.Marshal(map[string]interface{}{
+			"message": "Upsert committed",
+			"uids":    resp.Uids,
+			"query":   json.RawMessage(resp.Json),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode upsert response: %v", err)
+		}
+		return addDebugMeta(ctx, mcp.NewToolResultText(string(out)), "attempts", attempts), nil
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// varDefRe matches a variable definition such as `v as uid` or `A as me(...)`
+	varDefRe = regexp.MustCompile(`\b([A-Za-z_]\w*)\s+as\b`)
+	// condCallRe matches a function call in an @if condition, e.g. len(v)
+	condCallRe = regexp.MustCompile(`([A-Za-z_]\w*)\s*\(`)
+	// condLenRe matches a variable reference in an @if condition
+	condLenRe = regexp.MustCompile(`\blen\s*\(\s*([^()\s]*)\s*\)`)
+)
+
+// condFunctions are the functions Dgraph accepts in an @if condition
+var condFunctions = map[string]bool{
+	"eq": true, "le": true, "lt": true, "ge": true, "gt": true,
+	"len": true, "and": true, "or": true, "not": true,
+}
+
+// Blank out string literals, regular expression literals and comments,
+// keeping offsets, so that DQL
+// keywords inside them aren't matched
+func blankLiterals(s string) (string, error) {
+	b := []byte(s)
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '"':
+			end, err := skipString(s, i)
+			if err != nil {
+				return "", err
+			}
+			for j := i + 1; j < end; j++ {
+				b[j] = ' '
+			}
+			i = end
+		case '/':
+			if !isRegexpStart(s, i) {
+				continue
+			}
+			end, err := skipRegexp(s, i)
+			if err != nil {
+				return "", err
+			}
+			for j := i + 1; j < end; j++ {
+				b[j] = ' '
+			}
+			i = end
+		case '#':
+			for ; i < len(b) && b[i] != '\n'; i++ {
+				b[i] = ' '
+			}
+		}
+	}
+	return string(b), nil
+}
+
+// Return the variables a query defines with `name as`
+func queryDefinedVariables(query string) (map[string]bool, error) {
+	blanked, err := blankLiterals(query)
+	if err != nil {
+		return nil, err
+	}
+	defined := map[string]bool{}
+	for _, m := range varDefRe.FindAllStringSubmatch(blanked, -1) {
+		defined[m[1]] = true
+	}
+	return defined, nil
+}
+
+// Check the condition of a conditional upsert against the variables its
+// query defines and return it as an @if directive. The condition may be
+// given with or without the @if wrapper, e.g. eq(len(v), 0).
+func upsertCond(cond, query string) (string, error) {
+	cond = strings.TrimSpace(cond)
+	if rest, ok := strings.CutPrefix(cond, "@if"); ok {
+		rest = strings.TrimSpace(rest)
+		if rest == "" || rest[0] != '(' {
+			return "", fmt.Errorf("cond must be a condition such as eq(len(v), 0) or @if(eq(len(v), 0))")
+		}
+		end, err := matchingDelim(rest, 0)
+		if err != nil {
+			return "", fmt.Errorf("cond: %v", err)
+		}
+		if end != len(rest)-1 {
+			return "", fmt.Errorf("cond has text after @if(...)")
+		}
+		cond = strings.TrimSpace(rest[1:end])
+	}
+	if cond == "" {
+		return "", fmt.Errorf("cond must not be empty")
+	}
+	if strings.Contains(cond, `"`) {
+		return "", fmt.Errorf("cond can only compare len() of variables with numbers")
+	}
+	if _, err := matchingDelim("("+cond+")", 0); err != nil {
+		return "", fmt.Errorf("cond: %v", err)
+	}
+
+	for _, m := range condCallRe.FindAllStringSubmatch(cond, -1) {
+		if !condFunctions[strings.ToLower(m[1])] {
+			return "", fmt.Errorf("cond uses %s(), but only eq, le, lt, ge, gt, len, and, or and not are supported", m[1])
+		}
+	}
+
+	refs := condLenRe.FindAllStringSubmatch(cond, -1)
+	if len(refs) == 0 {
+		return "", fmt.Errorf("cond must reference a query variable through len(), e.g. eq(len(v), 0)")
+	}
+	defined, err := queryDefinedVariables(query)
+	if err != nil {
+		return "", err
+	}
+	for _, m := range refs {
+		if err := validateName("variable", m[1]); err != nil {
+			return "", fmt.Errorf("cond: %v", err)
+		}
+		if !defined[m[1]] {
+			return "", fmt.Errorf("cond references variable %q, which the query doesn't define; define it with e.g. %s as uid", m[1], m[1])
+		}
+	}
+	return "@if(" + cond + ")", nil
+}
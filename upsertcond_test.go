@@ -0,0 +1,59 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryDefinedVariables(t *testing.T) {
+	query := `query q($email: string) {
+	A as q(func: eq(email, $email)) {
+		v as uid
+		n as name # w as comment
+		friend { f as uid }
+	}
+	me(func: eq(name, "x as y")) { uid }
+	re(func: regexp(name, /x as y/)) { uid }
+}`
+	got, err := queryDefinedVariables(query)
+	if err != nil {
+		t.Fatalf("queryDefinedVariables() error = %v", err)
+	}
+	want := map[string]bool{"A": true, "v": true, "n": true, "f": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("queryDefinedVariables() = %v, want %v", got, want)
+	}
+}
+
+func TestUpsertCond(t *testing.T) {
+	query := `{ q(func: eq(email, "a@b.c")) { v as uid } p(func: has(name)) { w as uid } }`
+	tests := []struct {
+		name    string
+		cond    string
+		want    string
+		wantErr bool
+	}{
+		{"bare", "eq(len(v), 0)", "@if(eq(len(v), 0))", false},
+		{"wrapped", " @if( eq(len(v), 1) ) ", "@if(eq(len(v), 1))", false},
+		{"combined", "gt(len(v), 0) AND not(eq(len(w), 0))", "@if(gt(len(v), 0) AND not(eq(len(w), 0)))", false},
+		{"undefined variable", "eq(len(x), 0)", "", true},
+		{"no variable", "eq(1, 1)", "", true},
+		{"unsupported function", "eq(len(uid(v)), 0)", "", true},
+		{"string", `eq(len(v), "0")`, "", true},
+		{"unbalanced", "eq(len(v), 0", "", true},
+		{"text after @if", "@if(eq(len(v), 0)) @filter(has(x))", "", true},
+		{"empty @if", "@if()", "", true},
+		{"@if without parentheses", "@if eq(len(v), 0)", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := upsertCond(tt.cond, query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("upsertCond(%q) error = %v, wantErr %v", tt.cond, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("upsertCond(%q) = %q, want %q", tt.cond, got, tt.want)
+			}
+		})
+	}
+}
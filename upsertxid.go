@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/dgraph-io/dgo/v2/protos/api"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Build an upsert block finding the node whose xid predicate equals $xid and
+// setting predicates on it. The mutation sets uid(v), so Dgraph updates the
+// node if the query found one and creates it otherwise.
+func buildXIDUpsert(xidPredicate, xidValue string, set map[string]interface{}, typeName string) (string, []byte, error) {
+	if err := validateName("xid predicate", xidPredicate); err != nil {
+		return "", nil, err
+	}
+	if typeName != "" {
+		if err := validateName("type", typeName); err != nil {
+			return "", nil, err
+		}
+	}
+
+	node := map[string]interface{}{}
+	for _, predicate := range sortedKeys(set) {
+		switch predicate {
+		case "uid":
+			return "", nil, fmt.Errorf("set must not contain uid; the node is found by %s", xidPredicate)
+		case xidPredicate:
+			return "", nil, fmt.Errorf("set must not contain the xid predicate %q; give its value as xid_value", xidPredicate)
+		}
+		if err := validateField("predicate", predicate); err != nil {
+			return "", nil, err
+		}
+		node[predicate] = set[predicate]
+	}
+	node["uid"] = "uid(v)"
+	node[xidPredicate] = xidValue
+	if typeName != "" {
+		node["dgraph.type"] = typeName
+	}
+
+	setJSON, err := json.Marshal(node)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode mutation: %v", err)
+	}
+
+	query := fmt.Sprintf(`query q($xid: string) {
+	node(func: eq(%s, $xid)) {
+		v as uid
+	}
+}`, xidPredicate)
+	return query, setJSON, nil
+}
+
+// Check that an xid predicate can be looked up with eq
+func checkXIDPredicate(schema *schemaInfo, predicate string) error {
+	p, ok := schema.predicate(predicate)
+	if !ok {
+		return fmt.Errorf("predicate %q is not in the schema; add it with an index, e.g. %s: string @index(exact) @upsert .", predicate, predicate)
+	}
+	if p.Type != "string" {
+		return fmt.Errorf("predicate %q has type %s; xid predicates must be strings", predicate, p.Type)
+	}
+	if _, missing := missingTokenizer(p, "eq"); missing {
+		return fmt.Errorf("predicate %q has no index usable by eq; add one with dgraph_alter_schema, e.g. %s: string @index(exact) @upsert .", predicate, predicate)
+	}
+	return nil
+}
+
+// Create handler for the upsert by xid tool
+func createUpsertByXIDHandler(client *dgo.Dgraph, retry retryPolicy) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		xidPredicate, ok := request.Params.Arguments["xid_predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("xid_predicate must be a string")
+		}
+
+		xidValue, ok := request.Params.Arguments["xid_value"].(string)
+		if !ok || xidValue == "" {
+			return nil, fmt.Errorf("xid_value must be a non-empty string")
+		}
+
+		set := map[string]interface{}{}
+		if v, ok := request.Params.Arguments["set"]; ok && v != nil {
+			if set, ok = v.(map[string]interface{}); !ok {
+				return nil, fmt.Errorf("set must be an object")
+			}
+		}
+
+		typeName := ""
+		if v, ok := request.Params.Arguments["type"]; ok {
+			if typeName, ok = v.(string); !ok {
+				return nil, fmt.Errorf("type must be a string")
+			}
+		}
+
+		// Write untagged strings of @lang predicates in the given language
+		tag, langSchema, err := langTagArgument(ctx, client, request)
+		if err != nil {
+			return nil, err
+		}
+		if tag != "" && tagLangValues(langSchema, set, tag) == 0 {
+			return nil, noLangPredicatesError(tag)
+		}
+
+		query, setJSON, err := buildXIDUpsert(xidPredicate, xidValue, set, typeName)
+		if err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+		if err := predicateAccess.checkJSON(set); err != nil {
+			return nil, err
+		}
+
+		refresh, err := boolArgument(request, "refresh", false)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := fetchSchema(ctx, client, refresh)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkXIDPredicate(schema, xidPredicate); err != nil {
+			return nil, err
+		}
+
+		// Find or create the node and apply the mutation atomically
+		resp, attempts, err := runUpsert(ctx, client, retry, &api.Request{
+			Query:     query,
+			Vars:      map[string]string{"$xid": xidValue},
+			Mutations: []*api.Mutation{{SetJson: setJSON}},
+			CommitNow: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("upsert failed after %d attempts: %v", attempts, err)
+		}
+
+		// Mutations change data and can add predicates to the schema
+		invalidateCaches()
+
+		var found struct {
+			Node []struct {
+				UID string `json:"uid"`
+			} `json:"node"`
+		}
+		if err := json.Unmarshal(resp.Json, &found); err != nil {
+			return nil, fmt.Errorf("failed to parse upsert response: %v", err)
+		}
+
+		uids := []string{}
+		for _, n := range found.Node {
+			uids = append(uids, n.UID)
+		}
+		created := len(uids) == 0
+		if created {
+			uids = append(uids, resp.Uids["uid(v)"])
+		}
+
+		out, err := json.Marshal(map[string]interface{}{
+			"created": created,
+			"uids":    uids,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode upsert response: %v", err)
+		}
+
+		result := mcp.NewToolResultText(string(out))
+		if len(uids) > 1 {
+			warning := fmt.Sprintf("Warning: %d nodes have %s %q, and all of them were updated. Add @upsert to the predicate's schema to prevent duplicates.", len(uids), xidPredicate, xidValue)
+			result.Content = append(result.Content, mcp.NewTextContent(warning))
+		}
+		return addDebugMeta(ctx, result, "attempts", attempts), nil
+	}
+}
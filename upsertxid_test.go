@@ -0,0 +1,65 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildXIDUpsert(t *testing.T) {
+	query, setJSON, err := buildXIDUpsert("xid", "user-42", map[string]interface{}{"name": "Alice", "age": 30.0}, "Person")
+	if err != nil {
+		t.Fatalf("buildXIDUpsert failed: %v", err)
+	}
+	if !strings.Contains(query, "query q($xid: string)") || !strings.Contains(query, "node(func: eq(xid, $xid))") || !strings.Contains(query, "v as uid") {
+		t.Errorf("buildXIDUpsert query = %s", query)
+	}
+
+	var node map[string]interface{}
+	if err := json.Unmarshal(setJSON, &node); err != nil {
+		t.Fatalf("buildXIDUpsert produced invalid JSON: %v", err)
+	}
+	want := map[string]interface{}{"uid": "uid(v)", "xid": "user-42", "name": "Alice", "age": 30.0, "dgraph.type": "Person"}
+	for k, v := range want {
+		if node[k] != v {
+			t.Errorf("node[%q] = %v, want %v", k, node[k], v)
+		}
+	}
+
+	for _, set := range []map[string]interface{}{
+		{"uid": "0x1"},
+		{"xid": "other"},
+		{"name }": "x"},
+	} {
+		if _, _, err := buildXIDUpsert("xid", "user-42", set, ""); err == nil {
+			t.Errorf("buildXIDUpsert(%v) succeeded, want error", set)
+		}
+	}
+}
+
+func TestCheckXIDPredicate(t *testing.T) {
+	schema := &schemaInfo{Predicates: []predicateSchema{
+		{Predicate: "xid", Type: "string", Index: true, Tokenizer: []string{"exact"}, Upsert: true},
+		{Predicate: "code", Type: "string", Index: true, Tokenizer: []string{"trigram"}},
+		{Predicate: "num", Type: "int", Index: true, Tokenizer: []string{"int"}},
+	}}
+
+	tests := []struct {
+		predicate string
+		want      string
+	}{
+		{"xid", ""},
+		{"code", "no index usable by eq"},
+		{"num", "must be strings"},
+		{"missing", "not in the schema"},
+	}
+	for _, tt := range tests {
+		err := checkXIDPredicate(schema, tt.predicate)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("checkXIDPredicate(%q) failed: %v", tt.predicate, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("checkXIDPredicate(%q) = %v, want error containing %q", tt.predicate, err, tt.want)
+		}
+	}
+}
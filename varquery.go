@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dgraph-io/dgo/v2"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// varQueryBlock is one block of a chained query. Var blocks only define
+// variables for later blocks; the others are returned under their name.
+type varQueryBlock struct {
+	Name   string // the result block name, unused for var blocks
+	Var    bool   // whether the block is a var block
+	As     string // the variable bound to the uids the block matches
+	Func   string // the root function, which may use earlier variables, e.g. uid(A)
+	Filter string // an optional @filter expression
+	First  int    // an optional result limit
+	Fields string // the fields to select, which may define value variables
+
+	// Value variables the block defines, by name, e.g. score: math(a + b)
+	Values map[string]string
+	// Value variables the block returns, by the key they are returned
+	// under, e.g. score: score selects score: val(score)
+	Project map[string]string
+}
+
+var (
+	// valueVarDefRe matches a value variable defined in a field list, e.g.
+	// f as friend
+	valueVarDefRe = regexp.MustCompile(`\b([A-Za-z_]\w*)\s+as\s`)
+	// valRefRe matches a reference to a value variable, e.g. val(score)
+	valRefRe = regexp.MustCompile(`\bval\(\s*([A-Za-z_]\w*)\s*\)`)
+)
+
+// Read an object of strings from a block
+func stringMap(value interface{}) (map[string]string, bool) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	m := make(map[string]string, len(obj))
+	for k, v := range obj {
+		if m[k], ok = v.(string); !ok {
+			return nil, false
+		}
+	}
+	return m, true
+}
+
+// Read the blocks of a chained query from the tool arguments
+func varQueryBlocksArgument(request mcp.CallToolRequest, name string) ([]varQueryBlock, error) {
+	items, ok := request.Params.Arguments[name].([]interface{})
+	if !ok || len(items) == 0 {
+		return nil, fmt.Errorf("%s must be a non-empty list of blocks", name)
+	}
+
+	blocks := make([]varQueryBlock, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("block %d must be an object", i)
+		}
+
+		var b varQueryBlock
+		for key, value := range obj {
+			var ok bool
+			switch key {
+			case "name":
+				b.Name, ok = value.(string)
+			case "var":
+				b.Var, ok = value.(bool)
+			case "as":
+				b.As, ok = value.(string)
+			case "func":
+				b.Func, ok = value.(string)
+			case "filter":
+				b.Filter, ok = value.(string)
+			case "fields":
+				b.Fields, ok = value.(string)
+			case "values":
+				b.Values, ok = stringMap(value)
+			case "project":
+				b.Project, ok = stringMap(value)
+			case "first":
+				var f float64
+				f, ok = value.(float64)
+				b.First = int(f)
+				ok = ok && f == float64(b.First)
+			default:
+				return nil, fmt.Errorf("block %d has unknown field %q", i, key)
+			}
+			if !ok {
+				return nil, fmt.Errorf("block %d has an invalid %s", i, key)
+			}
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// Build a multi-block query from its blocks, returning the query and the
+// names of its result blocks
+func buildVarQuery(blocks []varQueryBlock) (string, []string, error) {
+	var (
+		b     strings.Builder
+		names []string
+		seen  = map[string]bool{}
+	)
+	b.WriteString("{")
+	for i, block := range blocks {
+		name := "var"
+		if !block.Var {
+			if err := validateName("block", block.Name); err != nil {
+				return "", nil, fmt.Errorf("block %d: %v", i, err)
+			}
+			if block.Name == "var" || seen[block.Name] {
+				return "", nil, fmt.Errorf("block %d: block name %q is reserved or already used", i, block.Name)
+			}
+			seen[block.Name] = true
+			name = block.Name
+			names = append(names, name)
+		}
+		if block.As != "" {
+			if err := validateName("variable", block.As); err != nil {
+				return "", nil, fmt.Errorf("block %d: %v", i, err)
+			}
+			name = block.As + " as " + name
+		}
+		if strings.TrimSpace(block.Func) == "" {
+			return "", nil, fmt.Errorf("block %d has no func", i)
+		}
+		if block.First < 0 {
+			return "", nil, fmt.Errorf("block %d: first must not be negative", i)
+		}
+
+		args := "func: " + block.Func
+		if block.First > 0 {
+			args += fmt.Sprintf(", first: %d", block.First)
+		}
+		directives := ""
+		if block.Filter != "" {
+			directives = " @filter(" + block.Filter + ")"
+		}
+		fields := strings.TrimSpace(block.Fields)
+		if fields == "" {
+			fields = "uid"
+		}
+		for _, name := range sortedKeys(block.Values) {
+			if strings.TrimSpace(block.Values[name]) == "" {
+				return "", nil, fmt.Errorf("block %d: value %q has no expression", i, name)
+			}
+			fields += "\n\t\t" + name + " as " + strings.TrimSpace(block.Values[name])
+		}
+		if len(block.Project) > 0 && block.Var {
+			return "", nil, fmt.Errorf("block %d: var blocks return nothing to project values into", i)
+		}
+		for _, key := range sortedKeys(block.Project) {
+			if err := validateName("projection", key); err != nil {
+				return "", nil, fmt.Errorf("block %d: %v", i, err)
+			}
+			fields += fmt.Sprintf("\n\t\t%s: val(%s)", key, block.Project[key])
+		}
+		fmt.Fprintf(&b, "\n\t%s(%s)%s {\n\t\t%s\n\t}", name, args, directives, fields)
+	}
+	b.WriteString("\n}")
+
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("at least one block must be a result block")
+	}
+	if err := checkValueVars(blocks); err != nil {
+		return "", nil, err
+	}
+
+	// Catch unbalanced fragments before they can change the block structure
+	query := b.String()
+	parsed, err := parseQueryBlocks(query)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(parsed) != len(blocks) {
+		return "", nil, fmt.Errorf("block fields or filters must not contain unbalanced braces")
+	}
+	return query, names, nil
+}
+
+// Check that every value variable is defined once, and that val()
+// references and projections name a value variable some block defines.
+// Value variables are defined in values or with "x as" in fields.
+func checkValueVars(blocks []varQueryBlock) error {
+	defined := map[string]bool{}
+	define := func(i int, name string) error {
+		if err := validateName("variable", name); err != nil {
+			return fmt.Errorf("block %d: %v", i, err)
+		}
+		if defined[name] {
+			return fmt.Errorf("block %d: variable %s is defined more than once", i, name)
+		}
+		defined[name] = true
+		return nil
+	}
+	for i, block := range blocks {
+		for _, m := range valueVarDefRe.FindAllStringSubmatch(block.Fields, -1) {
+			if err := define(i, m[1]); err != nil {
+				return err
+			}
+		}
+		for _, name := range sortedKeys(block.Values) {
+			if err := define(i, name); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Variables are resolved across the whole query, so a block may refer
+	// to values a later block defines
+	undefined := func(name string) error {
+		return fmt.Errorf("val(%s) refers to no value variable; define %s in a block's values or fields first", name, name)
+	}
+	for i, block := range blocks {
+		for _, key := range sortedKeys(block.Project) {
+			name := block.Project[key]
+			if err := validateName("variable", name); err != nil {
+				return fmt.Errorf("block %d: projection %s: %v", i, key, err)
+			}
+			if !defined[name] {
+				return fmt.Errorf("block %d: projection %s: %v", i, key, undefined(name))
+			}
+		}
+		exprs := []string{block.Func, block.Filter, block.Fields}
+		for _, name := range sortedKeys(block.Values) {
+			exprs = append(exprs, block.Values[name])
+		}
+		for _, expr := range exprs {
+			for _, m := range valRefRe.FindAllStringSubmatch(expr, -1) {
+				if !defined[m[1]] {
+					return fmt.Errorf("block %d: %v", i, undefined(m[1]))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Create handler for the var query tool
+func createVarQueryHandler(client *dgo.Dgraph, limits queryLimits) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		client, err := clientFromContext(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks, err := varQueryBlocksArgument(request, "blocks")
+		if err != nil {
+			return nil, err
+		}
+
+		query, names, err := buildVarQuery(blocks)
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.check(query); err != nil {
+			return nil, err
+		}
+
+		if err := predicateAccess.checkQuery(query); err != nil {
+			return nil, err
+		}
+
+		// Create read-only transaction
+		txn := client.NewReadOnlyTxn()
+		defer txn.Discard(ctx)
+
+		// Execute query
+		resp, err := txn.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("query failed: %v\nGenerated query:\n%s%s", err, query, notIndexedHint(ctx, client, query, err))
+		}
+
+		// Return only the result blocks
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(resp.Json, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse query response: %v", err)
+		}
+		out := make(map[string]json.RawMessage, len(names))
+		for _, name := range names {
+			out[name] = result[name]
+			if out[name] == nil {
+				out[name] = json.RawMessage("[]")
+			}
+		}
+
+		data, err := json.Marshal(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %v", err)
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildVarQuery(t *testing.T) {
+	blocks := []varQueryBlock{
+		{Var: true, As: "A", Func: "type(Person)", Filter: "gt(age, 30)", Fields: "f as friend"},
+		{Name: "friends", Func: "uid(f)", First: 10, Fields: "name"},
+		{Name: "people", Func: "uid(A)"},
+	}
+	got, names, err := buildVarQuery(blocks)
+	if err != nil {
+		t.Fatalf("buildVarQuery failed: %v", err)
+	}
+	want := `{
+	A as var(func: type(Person)) @filter(gt(age, 30)) {
+		f as friend
+	}
+	friends(func: uid(f), first: 10) {
+		name
+	}
+	people(func: uid(A)) {
+		uid
+	}
+}`
+	if got != want {
+		t.Errorf("buildVarQuery\n got: %s\nwant: %s", got, want)
+	}
+	if !reflect.DeepEqual(names, []string{"friends", "people"}) {
+		t.Errorf("buildVarQuery names = %v", names)
+	}
+}
+
+func TestBuildVarQueryInvalid(t *testing.T) {
+	tests := map[string][]varQueryBlock{
+		"no result block":   {{Var: true, As: "A", Func: "has(name)"}},
+		"missing name":      {{Func: "has(name)"}},
+		"duplicate name":    {{Name: "a", Func: "has(x)"}, {Name: "a", Func: "has(y)"}},
+		"reserved name":     {{Name: "var", Func: "has(x)"}},
+		"missing func":      {{Name: "a"}},
+		"bad variable":      {{Name: "a", As: "A B", Func: "has(x)"}},
+		"unbalanced fields": {{Name: "a", Func: "has(x)", Fields: "name } b(func: has(y)) { name"}},
+	}
+	for name, blocks := range tests {
+		if _, _, err := buildVarQuery(blocks); err == nil {
+			t.Errorf("%s: buildVarQuery succeeded, want error", name)
+		}
+	}
+}
+
+func TestBuildVarQueryValues(t *testing.T) {
+	blocks := []varQueryBlock{
+		{Var: true, Func: "type(Post)", Fields: "l as likes", Values: map[string]string{"score": "math(l * 2)"}},
+		{Name: "top", Func: "uid(score), orderdesc: val(score)", First: 3, Fields: "title", Project: map[string]string{"points": "score"}},
+	}
+	got, _, err := buildVarQuery(blocks)
+	if err != nil {
+		t.Fatalf("buildVarQuery failed: %v", err)
+	}
+	want := `{
+	var(func: type(Post)) {
+		l as likes
+		score as math(l * 2)
+	}
+	top(func: uid(score), orderdesc: val(score), first: 3) {
+		title
+		points: val(score)
+	}
+}`
+	if got != want {
+		t.Errorf("buildVarQuery\n got: %s\nwant: %s", got, want)
+	}
+}
+
+func TestCheckValueVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocks  []varQueryBlock
+		wantErr bool
+	}{
+		{
+			name: "defined in fields",
+			blocks: []varQueryBlock{
+				{Var: true, Func: "has(age)", Fields: "a as age"},
+				{Name: "q", Func: "uid(a)", Project: map[string]string{"age": "a"}},
+			},
+		},
+		{
+			name: "defined by a later block",
+			blocks: []varQueryBlock{
+				{Name: "q", Func: "uid(s)", Project: map[string]string{"s": "s"}},
+				{Var: true, Func: "has(age)", Values: map[string]string{"s": "age"}},
+			},
+		},
+		{
+			name:    "undefined projection",
+			blocks:  []varQueryBlock{{Name: "q", Func: "has(age)", Project: map[string]string{"score": "score"}}},
+			wantErr: true,
+		},
+		{
+			name:    "undefined val reference",
+			blocks:  []varQueryBlock{{Name: "q", Func: "has(age)", Filter: "gt(val(x), 3)"}},
+			wantErr: true,
+		},
+		{
+			name: "defined twice",
+			blocks: []varQueryBlock{
+				{Var: true, Func: "has(age)", Fields: "a as age"},
+				{Name: "q", Func: "uid(a)", Values: map[string]string{"a": "age"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "invalid variable",
+			blocks:  []varQueryBlock{{Name: "q", Func: "has(age)", Values: map[string]string{"a b": "age"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkValueVars(tt.blocks)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkValueVars() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}